@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/afomera/spin/internal/agent"
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/registry"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/afomera/spin/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+// agentCmd represents the agent command
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Restore running projects on login",
+	Long: `Install a user service that restores spin environments when you log in, a
+launchd agent on macOS and a systemd user unit on Linux.
+
+Projects opt in with spin agent enable. On login, the agent runs spin up for
+each of them that was running when you logged out, that is every project spin
+down hasn't stopped. spin up starts the control API along with the processes,
+which supervises them from then on. The agent writes its output to
+~/.spin/agent.log.
+
+Example:
+  spin agent install      # Install the service
+  spin agent enable       # Restore the project in this directory on login
+  spin agent status       # Show the service and the projects it restores`,
+}
+
+// agentInstallCmd represents the agent install command
+var agentInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the service starting the agent on login",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		exe, err := os.Executable()
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		if err := agent.Install(exe); err != nil {
+			fmt.Printf("%sError installing the agent: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		path, _ := agent.Path()
+		lg.Printf("%sInstalled the agent in %s%s\n", lg.Green, path, lg.Reset)
+
+		if userCfg, err := userconfig.Load(); err == nil && len(userCfg.AutostartProjects) == 0 {
+			fmt.Printf("%sNo projects are restored yet, run spin agent enable in a project%s\n", lg.Yellow, lg.Reset)
+		}
+	},
+}
+
+// agentUninstallCmd represents the agent uninstall command
+var agentUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the service starting the agent",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := agent.Uninstall(); err != nil {
+			fmt.Printf("%sError removing the agent: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		lg.Printf("%sRemoved the agent%s\n", lg.Green, lg.Reset)
+	},
+}
+
+// agentEnableCmd represents the agent enable command
+var agentEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Restore the project in this directory on login",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := agentProjectDir()
+		userCfg := loadUserConfig()
+		for _, project := range userCfg.AutostartProjects {
+			if project == dir {
+				fmt.Printf("%s%s is already restored on login%s\n", lg.Yellow, dir, lg.Reset)
+				return
+			}
+		}
+		userCfg.AutostartProjects = append(userCfg.AutostartProjects, dir)
+		saveUserConfig(userCfg)
+
+		lg.Printf("%s%s is restored on login%s\n", lg.Green, dir, lg.Reset)
+		if !agent.Installed() {
+			fmt.Printf("%sThe agent isn't installed yet, run spin agent install%s\n", lg.Yellow, lg.Reset)
+		}
+	},
+}
+
+// agentDisableCmd represents the agent disable command
+var agentDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop restoring the project in this directory",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := agentProjectDir()
+		userCfg := loadUserConfig()
+		var projects []string
+		for _, project := range userCfg.AutostartProjects {
+			if project != dir {
+				projects = append(projects, project)
+			}
+		}
+		if len(projects) == len(userCfg.AutostartProjects) {
+			fmt.Printf("%s%s isn't restored on login%s\n", lg.Yellow, dir, lg.Reset)
+			return
+		}
+		userCfg.AutostartProjects = projects
+		saveUserConfig(userCfg)
+		lg.Printf("%s%s is no longer restored on login%s\n", lg.Green, dir, lg.Reset)
+	},
+}
+
+// agentStatusCmd represents the agent status command
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the service and the projects it restores",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if path, err := agent.Path(); err != nil {
+			fmt.Printf("%s%v%s\n", lg.Yellow, err, lg.Reset)
+		} else if agent.Installed() {
+			fmt.Printf("Agent: %sinstalled%s in %s\n", lg.Green, lg.Reset, path)
+		} else {
+			fmt.Printf("Agent: %snot installed%s, run spin agent install\n", lg.Yellow, lg.Reset)
+		}
+
+		userCfg := loadUserConfig()
+		if len(userCfg.AutostartProjects) == 0 {
+			fmt.Println("No projects are restored on login")
+			return
+		}
+		running := runningProcessCounts()
+		fmt.Println("\nProjects restored on login:")
+		for _, dir := range userCfg.AutostartProjects {
+			name, restore := autostartState(dir)
+			state := lg.Yellow + "stopped, not restored"
+			switch {
+			case name == "":
+				state = lg.Red + "no spin.config.json"
+			case running[name] > 0:
+				state = lg.Green + "running"
+			case restore:
+				state = lg.Cyan + "restored on next login"
+			}
+			fmt.Printf("  %s  %s%s\n", dir, state, lg.Reset)
+		}
+	},
+}
+
+// agentRunCmd restores the projects on login, run by the service
+var agentRunCmd = &cobra.Command{
+	Use:    "run",
+	Short:  "Restore the projects that were running",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("%s spin agent restoring projects\n", time.Now().Format(time.RFC3339))
+		exe, err := os.Executable()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		userCfg := loadUserConfig()
+		running := runningProcessCounts()
+		for _, dir := range userCfg.AutostartProjects {
+			name, restore := autostartState(dir)
+			if name == "" || !restore || running[name] > 0 {
+				continue
+			}
+			fmt.Printf("-> spin up in %s\n", dir)
+			up := exec.Command(exe, "up")
+			up.Dir = dir
+			up.Stdout = os.Stdout
+			up.Stderr = os.Stderr
+			if err := up.Run(); err != nil {
+				fmt.Printf("Error restoring %s: %v\n", name, err)
+			}
+		}
+	},
+}
+
+// autostartState returns the name of the project in dir, empty when it has no
+// spin.config.json, and whether it was running when the user logged out,
+// which is when spin down didn't remove it from the registry
+func autostartState(dir string) (string, bool) {
+	cfg, err := config.LoadProject(filepath.Join(dir, "spin.config.json"))
+	if err != nil {
+		return "", false
+	}
+	entry, ok, err := registry.Get(cfg.Name)
+	return cfg.Name, err == nil && ok && entry.Dir == dir
+}
+
+// agentProjectDir returns the directory of the project in the current
+// directory, exiting when there is none
+func agentProjectDir() string {
+	if _, err := config.LoadProject(filepath.Join(".", "spin.config.json")); err != nil {
+		spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+	}
+	dir, err := filepath.Abs(".")
+	if err != nil {
+		fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+		os.Exit(1)
+	}
+	return dir
+}
+
+// loadUserConfig loads the user configuration, exiting on errors
+func loadUserConfig() *userconfig.Config {
+	userCfg, err := userconfig.Load()
+	if err != nil {
+		spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+	}
+	return userCfg
+}
+
+// saveUserConfig saves the user configuration, exiting on errors
+func saveUserConfig(userCfg *userconfig.Config) {
+	if err := userCfg.Save(); err != nil {
+		fmt.Printf("%sError saving configuration: %v%s\n", lg.Red, err, lg.Reset)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+	agentCmd.AddCommand(agentInstallCmd)
+	agentCmd.AddCommand(agentUninstallCmd)
+	agentCmd.AddCommand(agentEnableCmd)
+	agentCmd.AddCommand(agentDisableCmd)
+	agentCmd.AddCommand(agentStatusCmd)
+	agentCmd.AddCommand(agentRunCmd)
+}
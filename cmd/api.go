@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/afomera/spin/internal/api"
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/procfile"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// apiProcessName is the name spin up runs the control API under
+const apiProcessName = "spin-api"
+
+// apiCmd serves the control API editors and tools use instead of running
+// spin commands. spin up starts it in the background.
+var apiCmd = &cobra.Command{
+	Use:    "api",
+	Short:  "Serve the control API on ~/.spin/sockets/<app>.sock",
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig("spin.config.json")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		server := newAPIServer(cfg)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		socket, _ := api.SocketPath(cfg.Name)
+		fmt.Printf("%sServing the control API of %s on %s%s\n", lg.Blue, cfg.Name, socket, lg.Reset)
+		if err := server.Serve(ctx); err != nil {
+			fmt.Printf("%sError serving the control API: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+	},
+}
+
+// newAPIServer returns the control API of the project
+func newAPIServer(cfg *config.Config) *api.Server {
+	manager := process.GetManager(cfg)
+	manager.SetQuiet(true)
+
+	return &api.Server{
+		Config:  cfg,
+		Manager: manager,
+		Start: func(name string) error {
+			commandLine := procfileCommand(cfg, name)
+			if commandLine == "" {
+				return fmt.Errorf("process %s is not defined in %s", name, cfg.GetProcfilePath())
+			}
+			command, args := procfile.SplitCommand(commandLine)
+			return manager.StartProcess(cfg.Name, name, command, args, rubyEnv(processEnv(cfg, "."), ".", false), ".")
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(apiCmd)
+}
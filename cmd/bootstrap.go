@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/afomera/spin/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+// bootstrapCmd represents the bootstrap command
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Install the tools and system packages the project needs",
+	Long: `Install what a machine needs to work on the project: the tools listed in
+dependencies.tools and the packages of the system_packages section of
+spin.config.json.
+
+Missing tools are installed with a version manager when they have a version
+constraint, with their language's package manager (gem, corepack) or as a
+package of the operating system. Packages are installed with brew on macOS and
+apt or dnf on Linux. The commands are shown and confirmed before they run.
+
+Example:
+  spin bootstrap         # Show what is missing and install it after confirming
+  spin bootstrap --yes   # Install without asking`,
+	Run: func(cmd *cobra.Command, args []string) {
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		cfg, err := config.LoadConfig("spin.config.json")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		commands, warnings, err := bootstrapCommands(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", logger.Red, err, logger.Reset)
+			os.Exit(1)
+		}
+		for _, warning := range warnings {
+			logger.Warnf("%s", warning)
+		}
+		if len(commands) == 0 {
+			if len(warnings) > 0 {
+				os.Exit(1)
+			}
+			logger.Printf("%sEverything is installed%s\n", logger.Green, logger.Reset)
+			return
+		}
+
+		fmt.Printf("%sThe following commands will run:%s\n", logger.Blue, logger.Reset)
+		for _, command := range commands {
+			fmt.Printf("  %s\n", strings.Join(command, " "))
+		}
+
+		if !yes {
+			fmt.Printf("%sContinue? (y/N)%s\n", logger.Blue, logger.Reset)
+			reader := bufio.NewReader(os.Stdin)
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%sError reading input: %v%s\n", logger.Red, err, logger.Reset)
+				os.Exit(1)
+			}
+			response = strings.ToLower(strings.TrimSpace(response))
+			if response != "y" && response != "yes" {
+				fmt.Printf("%sBootstrap cancelled%s\n", logger.Yellow, logger.Reset)
+				return
+			}
+		}
+
+		failed := false
+		for _, command := range commands {
+			logger.Printf("\n%s-> %s%s\n", logger.Blue, strings.Join(command, " "), logger.Reset)
+			if err := tools.RunInstall(".", command); err != nil {
+				fmt.Printf("%sError: %v%s\n", logger.Red, err, logger.Reset)
+				failed = true
+			}
+		}
+		fmt.Println()
+
+		if failed || !checkDependencies(cfg, ".") {
+			os.Exit(1)
+		}
+		logger.Printf("%sBootstrap complete%s\n", logger.Green, logger.Reset)
+	},
+}
+
+// bootstrapCommands returns the commands that install the missing tools of
+// dependencies.tools and the missing packages of system_packages, and
+// warnings about outdated tools it can't upgrade
+func bootstrapCommands(cfg *config.Config) ([][]string, []string, error) {
+	checks, err := tools.CheckDependencies(".", cfg.Dependencies.Tools)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manager := tools.SystemPackageManager()
+	var commands [][]string
+	var packages []string
+	seen := make(map[string]bool)
+	addPackage := func(pkg string) {
+		if !seen[pkg] && !tools.PackageInstalled(manager, pkg) {
+			seen[pkg] = true
+			packages = append(packages, pkg)
+		}
+	}
+
+	var unknown, warnings []string
+	for _, c := range checks {
+		if c.OK {
+			continue
+		}
+		if command := c.InstallCommand(); command != nil {
+			commands = append(commands, command)
+			continue
+		}
+		if manager == "" {
+			unknown = append(unknown, c.Name)
+			continue
+		}
+		// Packages of the operating system rarely have the version asked
+		// for, and may not be where the installed tool came from
+		if c.Path != "" {
+			warnings = append(warnings, fmt.Sprintf("%s %s at %s doesn't meet %s, upgrade it or use a version manager like mise", c.Name, c.Installed, c.Path, c.Dependency))
+			continue
+		}
+		addPackage(tools.SystemPackage(c.Name, manager))
+	}
+
+	for _, pkg := range cfg.Packages.For(manager) {
+		addPackage(pkg)
+	}
+	if manager == "" && cfg.Packages != nil {
+		return nil, nil, fmt.Errorf("no supported package manager found, install brew, apt or dnf to install system_packages")
+	}
+	if len(unknown) > 0 {
+		return nil, nil, fmt.Errorf("no supported package manager found to install %s", strings.Join(unknown, ", "))
+	}
+
+	// System packages go first, tools like gem may come with them
+	if len(packages) > 0 {
+		commands = append([][]string{tools.InstallPackagesCommand(manager, packages)}, commands...)
+	}
+	return commands, warnings, nil
+}
+
+func init() {
+	rootCmd.AddCommand(bootstrapCmd)
+	bootstrapCmd.Flags().BoolP("yes", "y", false, "Install without asking for confirmation")
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/detector"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// buildCmd represents the build command
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build the app image",
+	Long: `Build the Docker image of the app, the image Procfile processes written like
+"web: docker:" run.
+
+The image is built as set in the build section of spin.config.json. Without
+one, the build section of the web or app service of a compose file, or a
+Dockerfile at the root of the project, is used. The image is tagged
+spin-<name> unless build.image names it.
+
+Example:
+  spin build                                  # Build the app image
+  spin build --no-cache --pull                # Build from fresh base images
+  spin build --cache-from ghcr.io/org/app     # Reuse layers of a pushed image
+  spin build --build-arg RUBY_VERSION=3.3.0   # Override a build argument
+  spin build --target development             # Build a stage of the Dockerfile`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		opts, err := appBuildOptions(cfg, ".")
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		if tag, _ := cmd.Flags().GetString("tag"); tag != "" {
+			opts.Tag = tag
+		}
+		if target, _ := cmd.Flags().GetString("target"); target != "" {
+			opts.Target = target
+		}
+		buildArgs, _ := cmd.Flags().GetStringArray("build-arg")
+		for _, pair := range buildArgs {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				// Like docker build, a bare name takes its value from the environment
+				value = os.Getenv(key)
+			}
+			opts.Args[key] = value
+		}
+		cacheFrom, _ := cmd.Flags().GetStringSlice("cache-from")
+		opts.CacheFrom = append(opts.CacheFrom, cacheFrom...)
+		opts.NoCache, _ = cmd.Flags().GetBool("no-cache")
+		opts.Pull, _ = cmd.Flags().GetBool("pull")
+
+		lg.Printf("%sBuilding %s%s%s from %s...%s\n", lg.Blue, lg.Cyan, opts.Tag, lg.Blue, opts.Dockerfile, lg.Reset)
+		if err := docker.BuildImage(opts); err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		lg.Printf("%sBuilt %s%s\n", lg.Green, opts.Tag, lg.Reset)
+	},
+}
+
+// appBuildOptions returns how the app image of the project in appPath is
+// built, from its build settings or else its Dockerfile or compose file
+func appBuildOptions(cfg *config.Config, appPath string) (docker.BuildOptions, error) {
+	build := cfg.Build
+	if build == nil {
+		detected := detector.DetectDockerBuild(appPath)
+		if detected == nil {
+			return docker.BuildOptions{}, fmt.Errorf("no Dockerfile found, add one or set build.dockerfile in spin.config.json")
+		}
+		build = &config.BuildConfig{Dockerfile: detected.Dockerfile, Context: detected.Context, Target: detected.Target, Args: detected.Args}
+	}
+
+	dockerfile := build.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	opts := docker.BuildOptions{
+		Tag:        cfg.GetImage(),
+		Dockerfile: filepath.Join(appPath, dockerfile),
+		Context:    filepath.Join(appPath, build.Context),
+		Target:     build.Target,
+		Args:       make(map[string]string),
+		CacheFrom:  append([]string{}, build.CacheFrom...),
+	}
+	for key, value := range build.Args {
+		opts.Args[key] = value
+	}
+	if _, err := os.Stat(opts.Dockerfile); err != nil {
+		return docker.BuildOptions{}, fmt.Errorf("dockerfile %s not found", opts.Dockerfile)
+	}
+	return opts, nil
+}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+	buildCmd.Flags().Bool("no-cache", false, "Build every step again instead of using the layer cache")
+	buildCmd.Flags().Bool("pull", false, "Pull newer versions of the base images")
+	buildCmd.Flags().StringSlice("cache-from", nil, "Images to use as cache sources, added to build.cache_from")
+	buildCmd.Flags().StringArray("build-arg", nil, "Build argument like KEY=value, overriding build.args")
+	buildCmd.Flags().String("target", "", "Stage of a multi-stage Dockerfile to build")
+	buildCmd.Flags().String("tag", "", "Tag the image differently than build.image")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/afomera/spin/internal/ci"
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// ciCmd represents the ci command
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Keep CI in line with the local environment",
+}
+
+// ciExportCmd represents the ci export command
+var ciExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print a CI pipeline derived from spin.config.json",
+	Long: `Print a CI pipeline that tests the project the way spin runs it locally: the
+Docker services of dependencies.services as service containers, the test env
+(with the ci env on top, when there is one), the Ruby, Node and Go versions
+of the project, its setup tasks or setup script, and its test script with
+its hooks.
+
+GitHub Actions jobs reach services on localhost like processes do locally.
+GitLab jobs reach them by their names, so addresses like localhost:5432 in
+the variables are rewritten to postgresql:5432.
+
+Example:
+  spin ci export                                           # GitHub Actions workflow
+  spin ci export --format gitlab                           # .gitlab-ci.yml
+  spin ci export --output .github/workflows/ci.yml         # Write the workflow`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		pipeline, err := ci.Export(cfg, ".", format)
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		if output == "" {
+			os.Stdout.Write(pipeline)
+			return
+		}
+
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(output, pipeline, 0644); err != nil {
+			fmt.Printf("%sError writing %s: %v%s\n", lg.Red, output, err, lg.Reset)
+			os.Exit(1)
+		}
+		lg.Printf("%sWrote %s%s\n", lg.Green, output, lg.Reset)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ciCmd)
+	ciCmd.AddCommand(ciExportCmd)
+	ciExportCmd.Flags().String("format", "github", "CI system to export for: "+strings.Join(ci.Formats, " or "))
+	ciExportCmd.Flags().StringP("output", "o", "", "Write the pipeline to this file instead of printing it")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/afomera/spin/internal/cleanup"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/spf13/cobra"
+)
+
+// cleanupCmd represents the cleanup command
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Remove leftovers from previous spin runs",
+	Long: `Remove resources spin no longer needs: stopped spin containers, dangling
+images of spin services, unused spin networks, stale process store entries,
+old log files and orphaned tmux sessions.
+
+Service volumes hold data and are kept. With --volumes, the volumes of
+services no container uses are removed instead, backups of major upgrades
+excepted.
+
+Example:
+  spin cleanup                          # Remove everything that is left over
+  spin cleanup --dry-run                # Only report what would be removed
+  spin cleanup --logs-older-than 72h    # Keep logs written in the last 3 days
+  spin cleanup --volumes --dry-run      # List the volumes no service uses`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		logAge, _ := cmd.Flags().GetDuration("logs-older-than")
+		volumes, _ := cmd.Flags().GetBool("volumes")
+		runCleanup(cleanup.Options{LogAge: logAge, Volumes: volumes}, dryRun)
+	},
+}
+
+// runCleanup lists the resources opts selects and removes them, unless it is
+// a dry run
+func runCleanup(opts cleanup.Options, dryRun bool) {
+	logger.Printf("%sLooking for leftover resources...%s\n", logger.Blue, logger.Reset)
+	resources, warnings := cleanup.Find(opts)
+	for _, warning := range warnings {
+		logger.Warnf("%v", warning)
+	}
+
+	if len(resources) == 0 {
+		logger.Printf("%sNothing to clean up%s\n", logger.Green, logger.Reset)
+		return
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Kind != resources[j].Kind {
+			return resources[i].Kind < resources[j].Kind
+		}
+		return resources[i].Name < resources[j].Name
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sKIND\tNAME\tREASON\tSIZE%s\n", logger.Cyan, logger.Reset)
+
+	for _, r := range resources {
+		size := "-"
+		if r.Size > 0 {
+			size = docker.FormatBytes(r.Size)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Kind, r.Name, r.Reason, size)
+	}
+	w.Flush()
+
+	if dryRun {
+		fmt.Printf("\n%sDry run: %d resources would be removed%s\n", logger.Yellow, len(resources), logger.Reset)
+		return
+	}
+
+	fmt.Println()
+	var removed int
+	var freed int64
+	for _, r := range resources {
+		if err := r.Remove(); err != nil {
+			logger.Warnf("failed to remove %s %s: %v", r.Kind, r.Name, err)
+			continue
+		}
+		removed++
+		freed += r.Size
+	}
+	logger.Printf("%sRemoved %d resources, freed %s%s\n", logger.Green, removed, docker.FormatBytes(freed), logger.Reset)
+}
+
+func init() {
+	rootCmd.AddCommand(cleanupCmd)
+	cleanupCmd.Flags().Bool("dry-run", false, "Report what would be removed without removing anything")
+	cleanupCmd.Flags().Duration("logs-older-than", 7*24*time.Hour, "Remove log files not written for this long")
+	cleanupCmd.Flags().Bool("volumes", false, "Remove the unused volumes of services instead, which deletes their data")
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/script"
+	"github.com/spf13/cobra"
+)
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish]",
+	Short: "Print the shell completion script",
+	Long: `Print the completion script of a shell, so it completes the commands and
+flags of spin with TAB. Names are completed too: those of running processes
+for spin logs and spin debug, of the services of spin.config.json for spin
+services, and of scripts for spin scripts run.
+
+Load it in the current shell, or add the line to your shell's startup file:
+  bash: source <(spin completion bash)     # needs the bash-completion package
+  zsh:  source <(spin completion zsh)
+  fish: spin completion fish | source
+
+Example:
+  spin completion zsh > "${fpath[1]}/_spin"
+  spin completion fish > ~/.config/fish/completions/spin.fish`,
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		default:
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		}
+	},
+}
+
+// completionFunc completes the arguments of a command
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// firstArg completes only the first argument, for commands taking one name
+func firstArg(complete completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
+
+// completeNames returns the names that start with toComplete and aren't
+// given yet, sorted
+func completeNames(names []string, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	given := make(map[string]bool, len(args))
+	for _, arg := range args {
+		given[arg] = true
+	}
+	var matches []string
+	for _, name := range names {
+		if !given[name] && strings.HasPrefix(name, toComplete) {
+			given[name] = true
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// processNames returns the names of the processes of the project in the
+// process store, nothing outside of a project
+func processNames() []string {
+	cfg, err := config.LoadConfig("spin.config.json")
+	if err != nil {
+		return nil
+	}
+	manager := process.GetManager(cfg)
+	manager.SetQuiet(true)
+	var names []string
+	for _, p := range manager.ListProcesses() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// serviceNames returns the names of the services of spin.config.json
+func serviceNames() []string {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+	return sortedServiceNames(cfg)
+}
+
+// completeProcessNames completes the names of running processes
+func completeProcessNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeNames(processNames(), args, toComplete)
+}
+
+// completeLogNames completes the names of running processes, and the name
+// the output of scripts is logged under
+func completeLogNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeNames(append(processNames(), script.OutputLogName), args, toComplete)
+}
+
+// completeServiceNames completes the names of the services of the project
+func completeServiceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeNames(serviceNames(), args, toComplete)
+}
+
+// completeProcessOrServiceNames completes the names of running processes and
+// of services, for spin down
+func completeProcessOrServiceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeNames(append(processNames(), serviceNames()...), args, toComplete)
+}
+
+// completeScriptNames completes the names of the scripts of the project
+func completeScriptNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	manager := script.NewManager()
+	if err := script.LoadAndRegisterScripts(manager, script.DefaultConfigPath()); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, s := range manager.List() {
+		names = append(names, s.Name)
+	}
+	return completeNames(names, args, toComplete)
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
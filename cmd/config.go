@@ -1,9 +1,19 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	spinconfig "github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/forge"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/notify"
+	"github.com/afomera/spin/internal/share"
+	"github.com/afomera/spin/internal/spinerr"
 	"github.com/afomera/spin/internal/userconfig"
 	"github.com/spf13/cobra"
 )
@@ -18,7 +28,13 @@ such as default organization name and git URL preferences.
 Example:
 	 spin config set-org myorg     # Set default organization
 	 spin config set-ssh true      # Prefer SSH URLs for git operations
-	 spin config show              # Show current configuration`,
+	 spin config set-backend native # Supervise processes without tmux
+	 spin config set-notifications on # Notify about crashed processes
+	 spin config set-shell bash    # Run scripts with bash
+	 spin config show              # Show current configuration
+	 spin config explain services  # Show where project settings come from
+	 spin config get services.redis.port      # Read a project setting
+	 spin config set services.redis.port 6380 # Change a project setting`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// If no subcommand is provided, show help
 		cmd.Help()
@@ -32,8 +48,7 @@ var configShowCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		config, err := userconfig.Load()
 		if err != nil {
-			fmt.Printf("Error loading configuration: %v\n", err)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
 		}
 
 		fmt.Println("Current Configuration:")
@@ -45,6 +60,37 @@ var configShowCmd = &cobra.Command{
 			fmt.Printf("Default Organization: %s\n", config.DefaultOrganization)
 		}
 		fmt.Printf("Prefer SSH: %v\n", config.PreferSSH)
+		fmt.Printf("Process Backend: %s\n", config.Backend())
+		fmt.Printf("Script Shell: %s\n", config.Shell())
+		if config.TemplatesRepo != "" {
+			fmt.Printf("Templates Repository: %s\n", config.TemplatesRepo)
+		}
+		for organization, host := range config.GitHosts {
+			fmt.Printf("Host of %s: %s", organization, host.Host)
+			if host.Provider != "" {
+				fmt.Printf(" (%s)", host.Provider)
+			}
+			fmt.Println()
+		}
+		fmt.Printf("Notifications: %v\n", config.Notifications)
+		if config.NotificationWebhook != "" {
+			fmt.Printf("Notification Webhook: %s\n", config.NotificationWebhook)
+		}
+		if config.ShareProvider != "" {
+			fmt.Printf("Share Provider: %s\n", config.ShareProvider)
+		}
+		if config.NgrokAuthToken != "" {
+			fmt.Println("ngrok Auth Token: (set)")
+		}
+		if config.DatabaseClient != "" {
+			fmt.Printf("Database Client: %s\n", config.DatabaseClient)
+		}
+		for project, key := range config.RailsMasterKeys {
+			if !strings.HasPrefix(key, "op://") && !strings.HasPrefix(key, "cmd:") {
+				key = "(set)"
+			}
+			fmt.Printf("Rails Master Key of %s: %s\n", project, key)
+		}
 	},
 }
 
@@ -63,8 +109,7 @@ Example:
 
 		config, err := userconfig.Load()
 		if err != nil {
-			fmt.Printf("Error loading configuration: %v\n", err)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
 		}
 
 		config.DefaultOrganization = orgName
@@ -93,8 +138,7 @@ Example:
 
 		config, err := userconfig.Load()
 		if err != nil {
-			fmt.Printf("Error loading configuration: %v\n", err)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
 		}
 
 		config.PreferSSH = preferSSH
@@ -107,9 +151,623 @@ Example:
 	},
 }
 
+// configSetBackendCmd represents the config set-backend command
+var configSetBackendCmd = &cobra.Command{
+	Use:   "set-backend [tmux|native]",
+	Short: "Set how processes are supervised",
+	Long: `Set the backend that runs the processes started by spin up and spin run.
+
+tmux runs every process in its own tmux session and is the default. native runs
+every process in a pseudo-terminal supervised by spin itself, so tmux doesn't
+need to be installed. Both capture output for spin logs and support spin debug.
+
+Processes keep the backend they were started with, restart them to switch.
+
+Example:
+  spin config set-backend native  # Supervise processes without tmux
+  spin config set-backend tmux    # Go back to tmux sessions`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{userconfig.BackendTmux, userconfig.BackendNative},
+	Run: func(cmd *cobra.Command, args []string) {
+		backend := args[0]
+		if backend != userconfig.BackendTmux && backend != userconfig.BackendNative {
+			fmt.Printf("Error: unknown process backend %q, use tmux or native\n", backend)
+			os.Exit(1)
+		}
+
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		config.ProcessBackend = backend
+		if err := config.Save(); err != nil {
+			fmt.Printf("Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Process backend set to: %s\n", backend)
+	},
+}
+
+// configSetShellCmd represents the config set-shell command
+var configSetShellCmd = &cobra.Command{
+	Use:   "set-shell [shell]",
+	Short: "Set the shell scripts run in",
+	Long: `Set the shell the commands of scripts and their hooks run in. The command is
+passed to the shell with -c, so pipes, quotes and variables work like they do in
+a terminal. Scripts with an interpreter in spin.config.json use it instead.
+
+Run without a shell to go back to sh.
+
+Example:
+  spin config set-shell bash  # Run scripts with bash
+  spin config set-shell       # Go back to sh`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		shell := ""
+		if len(args) > 0 {
+			shell = args[0]
+		}
+
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		config.ScriptShell = shell
+		if err := config.Save(); err != nil {
+			fmt.Printf("Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Script shell set to: %s\n", config.Shell())
+	},
+}
+
+// configSetTemplatesCmd represents the config set-templates command
+var configSetTemplatesCmd = &cobra.Command{
+	Use:   "set-templates [git-url]",
+	Short: "Set the repository of project templates",
+	Long: `Set a git repository with the organization's project templates for
+spin setup --template. Every top-level directory of the repository is a template,
+whose files are written into the new project. Files can use [[ .Name ]] for the
+name of the app. Templates replace built-in ones with the same name.
+
+Run without a URL to only use the built-in templates.
+
+Example:
+  spin config set-templates git@github.com:myorg/spin-templates.git
+  spin config set-templates            # Only use built-in templates`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		url := ""
+		if len(args) > 0 {
+			url = args[0]
+		}
+
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		config.TemplatesRepo = url
+		if err := config.Save(); err != nil {
+			fmt.Printf("Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if url == "" {
+			fmt.Println("Templates repository removed")
+			return
+		}
+		fmt.Printf("Templates repository set to: %s\n", url)
+	},
+}
+
+// configSetHostCmd represents the config set-host command
+var configSetHostCmd = &cobra.Command{
+	Use:   "set-host [organization] [host]",
+	Short: "Set where an organization's repositories are hosted",
+	Long: `Set the git host spin fetch clones the repositories of an organization from,
+e.g. gitlab.com, bitbucket.org or a self-hosted server. Whether the host runs
+GitHub, GitLab or Bitbucket is guessed from its name, use --provider for hosts
+whose name doesn't tell. GitLab subgroups use the host of their group.
+
+Run without a host to go back to github.com.
+
+Example:
+  spin config set-host myorg gitlab.com
+  spin config set-host myorg git.example.com --provider gitlab
+  spin config set-host myorg           # Go back to github.com`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		organization := args[0]
+		provider, _ := cmd.Flags().GetString("provider")
+		if provider != "" && provider != forge.GitHub && provider != forge.GitLab && provider != forge.Bitbucket {
+			fmt.Printf("Error: unknown provider %q, use github, gitlab or bitbucket\n", provider)
+			os.Exit(1)
+		}
+
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		if len(args) == 1 {
+			delete(config.GitHosts, organization)
+		} else {
+			if config.GitHosts == nil {
+				config.GitHosts = make(map[string]userconfig.GitHost)
+			}
+			config.GitHosts[organization] = userconfig.GitHost{Host: args[1], Provider: provider}
+		}
+		if err := config.Save(); err != nil {
+			fmt.Printf("Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Host of %s set to: %s\n", organization, config.HostFor(organization).Host)
+	},
+}
+
+// configSetNotificationsCmd represents the config set-notifications command
+var configSetNotificationsCmd = &cobra.Command{
+	Use:   "set-notifications [on|off]",
+	Short: "Set whether to notify about crashes and unhealthy services",
+	Long: `Set whether spin shows a desktop notification when a process exits
+unexpectedly or a service turns unhealthy. Notifications use osascript on macOS
+and notify-send on Linux, and are also posted to the webhook if one is set.
+
+Notifications are sent by the monitor process spin up starts.
+
+Example:
+  spin config set-notifications on   # Enable notifications
+  spin config set-notifications off  # Disable notifications`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"on", "off"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if args[0] != "on" && args[0] != "off" {
+			fmt.Printf("Error: use on or off, not %q\n", args[0])
+			os.Exit(1)
+		}
+
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		config.Notifications = args[0] == "on"
+		if err := config.Save(); err != nil {
+			fmt.Printf("Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Notifications set to: %v\n", config.Notifications)
+	},
+}
+
+// configSetWebhookCmd represents the config set-webhook command
+var configSetWebhookCmd = &cobra.Command{
+	Use:   "set-webhook [url]",
+	Short: "Set a webhook notifications are posted to",
+	Long: `Set a URL every notification is posted to as JSON with a title, message
+and time, e.g. a Slack or Discord compatible relay. Run without a URL to remove
+the webhook.
+
+Example:
+  spin config set-webhook https://example.com/hooks/spin
+  spin config set-webhook            # Remove the webhook`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		url := ""
+		if len(args) > 0 {
+			url = args[0]
+		}
+
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		config.NotificationWebhook = url
+		if err := config.Save(); err != nil {
+			fmt.Printf("Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if url == "" {
+			fmt.Println("Notification webhook removed")
+			return
+		}
+		fmt.Printf("Notification webhook set to: %s\n", url)
+	},
+}
+
+// configSetShareCmd represents the config set-share command
+var configSetShareCmd = &cobra.Command{
+	Use:   "set-share [provider] [auth-token]",
+	Short: "Set how spin share exposes processes",
+	Long: `Set the provider spin share exposes processes with, ngrok or cloudflared,
+and the auth token ngrok signs in with. cloudflared quick tunnels need no
+token.
+
+Run without a provider to use whichever is installed.
+
+Example:
+  spin config set-share ngrok 2abc...       # Use ngrok with an auth token
+  spin config set-share cloudflared         # Use cloudflared quick tunnels
+  spin config set-share                     # Use whichever is installed`,
+	Args:      cobra.MaximumNArgs(2),
+	ValidArgs: share.Providers,
+	Run: func(cmd *cobra.Command, args []string) {
+		provider, token := "", ""
+		if len(args) > 0 {
+			provider = args[0]
+			if provider != "ngrok" && provider != "cloudflared" {
+				fmt.Printf("Error: use ngrok or cloudflared, not %q\n", provider)
+				os.Exit(1)
+			}
+		}
+		if len(args) > 1 {
+			token = args[1]
+		}
+
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		config.ShareProvider = provider
+		if token != "" {
+			config.NgrokAuthToken = token
+		}
+		if err := config.Save(); err != nil {
+			fmt.Printf("Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if provider == "" {
+			fmt.Println("Share provider set to: whichever is installed")
+			return
+		}
+		fmt.Printf("Share provider set to: %s\n", provider)
+	},
+}
+
+// configSetDBClientCmd represents the config set-db-client command
+var configSetDBClientCmd = &cobra.Command{
+	Use:   "set-db-client [client]",
+	Short: "Set the client spin db gui opens",
+	Long: `Set the database client spin db gui opens, like tableplus or pgcli. Run
+without a client to let spin pick one that fits the database.
+
+Example:
+  spin config set-db-client tableplus  # Open databases in TablePlus
+  spin config set-db-client            # Pick the client by the database`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: dbClientNames(),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := ""
+		if len(args) > 0 {
+			client = args[0]
+			if _, ok := dbClients[client]; !ok {
+				fmt.Printf("Error: unknown client %q, use one of %s\n", client, strings.Join(dbClientNames(), ", "))
+				os.Exit(1)
+			}
+		}
+
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		config.DatabaseClient = client
+		if err := config.Save(); err != nil {
+			fmt.Printf("Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if client == "" {
+			fmt.Println("Database client set to: picked by the database")
+			return
+		}
+		fmt.Printf("Database client set to: %s\n", client)
+	},
+}
+
+// configSetMasterKeyCmd represents the config set-master-key command
+var configSetMasterKeyCmd = &cobra.Command{
+	Use:   "set-master-key [key|reference]",
+	Short: "Set the Rails master key of a project",
+	Long: `Set the key Rails decrypts config/credentials.yml.enc with, for projects
+whose config/master.key isn't checked out. spin up, spin run, scripts, hooks
+and setup tasks pass it to the project as RAILS_MASTER_KEY.
+
+Instead of the key itself, a reference to a secret manager can be stored:
+  op://vault/item/field   read with the 1Password CLI
+  cmd:<command>           the output of a command, like a keychain lookup
+
+The key is kept for the project in the current directory unless --project is
+given. Run without a key to remove it.
+
+Example:
+  spin config set-master-key 0123abcd...
+  spin config set-master-key op://Development/myapp/master_key
+  spin config set-master-key 'cmd:security find-generic-password -w -s myapp'
+  spin config set-master-key --project myapp   # Remove the key of myapp`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		project, _ := cmd.Flags().GetString("project")
+		if project == "" {
+			project = scriptApp()
+		}
+		if project == "" {
+			fmt.Println("Error: no spin.config.json in the current directory, pass --project")
+			os.Exit(1)
+		}
+
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		if len(args) == 0 {
+			delete(config.RailsMasterKeys, project)
+		} else {
+			if config.RailsMasterKeys == nil {
+				config.RailsMasterKeys = make(map[string]string)
+			}
+			config.RailsMasterKeys[project] = args[0]
+		}
+		if err := config.Save(); err != nil {
+			fmt.Printf("Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) == 0 {
+			fmt.Printf("Removed the Rails master key of %s\n", project)
+			return
+		}
+		fmt.Printf("Rails master key of %s set\n", project)
+	},
+}
+
+// configExplainCmd represents the config explain command
+var configExplainCmd = &cobra.Command{
+	Use:   "explain [key]",
+	Short: "Show where project settings come from",
+	Long: `Show the settings of the project under a key, like services.redis.port, and
+whether each comes from spin.config.json or from the untracked
+spin.config.local.json that overrides it. Without a key, all settings are shown.
+Values are shown as written, before variables like ${PORT} are replaced.
+
+Example:
+  spin config explain                       # Show all settings
+  spin config explain services.redis.port   # Show where the port of redis comes from
+  spin config explain env.development       # Show the development env`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := ""
+		if len(args) > 0 {
+			key = args[0]
+		}
+
+		settings, err := spinconfig.Explain("spin.config.json", key)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, setting := range settings {
+			value, source := setting.Value, setting.Source
+			if setting.Removed {
+				value = "(removed)"
+			}
+			if setting.Overrides != "" {
+				source += fmt.Sprintf(" (overrides %s)", setting.Overrides)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, value, source)
+		}
+		w.Flush()
+	},
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Show a setting of the project",
+	Long: `Show a setting of spin.config.json by its dotted key, with the overrides of
+spin.config.local.json applied. Strings are printed as is, other values as JSON.
+Items of lists are selected by their index.
+
+Example:
+  spin config get services.redis.port
+  spin config get env.development
+  spin config get dependencies.services.0`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		value, err := spinconfig.GetValue("spin.config.json", args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if s, ok := value.(string); ok {
+			fmt.Println(s)
+			return
+		}
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	},
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Change a setting of the project",
+	Long: `Change a setting of spin.config.json by its dotted key and show the change.
+Values that parse as JSON, like 6380, true or ["redis"], are stored as such,
+anything else as a string. Missing objects on the way are created. The change
+is only saved when spin.config.json is still valid with it, so misspelled keys
+and values of the wrong type are rejected.
+
+Pass --local to write to the untracked spin.config.local.json instead.
+
+Example:
+  spin config set services.redis.port 6380
+  spin config set env.development.RAILS_LOG_LEVEL debug
+  spin config set dependencies.services '["postgresql","redis"]'
+  spin config set services.postgresql.port 5433 --local
+  spin config set version 2.0 --string`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		local, _ := cmd.Flags().GetBool("local")
+		asString, _ := cmd.Flags().GetBool("string")
+
+		var value interface{} = args[1]
+		if !asString {
+			value = spinconfig.ParseValue(args[1])
+		}
+
+		before, after, err := spinconfig.SetValue("spin.config.json", args[0], value, local)
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		file := "spin.config.json"
+		if local {
+			file = spinconfig.LocalFile
+		}
+		if string(before) == string(after) {
+			fmt.Printf("%s is unchanged\n", file)
+			return
+		}
+		fmt.Printf("Updated %s:\n", file)
+		printDiff(string(before), string(after))
+	},
+}
+
+// printDiff prints the lines that differ between two versions of a file,
+// with a line of context around each change
+func printDiff(before string, after string) {
+	a := strings.Split(strings.TrimRight(before, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(after, "\n"), "\n")
+	if before == "" {
+		a = nil
+	}
+
+	// Longest common subsequence of the lines, from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	const context = 1
+	lastPrinted := -1
+	for n, l := range lines {
+		near := false
+		for k := max(n-context, 0); k <= n+context && k < len(lines); k++ {
+			if lines[k].op != ' ' {
+				near = true
+				break
+			}
+		}
+		if !near {
+			continue
+		}
+		if lastPrinted >= 0 && n > lastPrinted+1 {
+			fmt.Println("  ...")
+		}
+		lastPrinted = n
+		switch l.op {
+		case '+':
+			fmt.Printf("%s+ %s%s\n", lg.Green, l.text, lg.Reset)
+		case '-':
+			fmt.Printf("%s- %s%s\n", lg.Red, l.text, lg.Reset)
+		default:
+			fmt.Printf("  %s\n", l.text)
+		}
+	}
+}
+
+// configTestNotificationCmd represents the config test-notification command
+var configTestNotificationCmd = &cobra.Command{
+	Use:   "test-notification",
+	Short: "Send a test notification",
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := userconfig.Load()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load the user configuration", err))
+		}
+
+		n := notify.Notification{Title: "spin", Message: "Notifications are working", Time: time.Now()}
+		if err := notify.Deliver(config, n); err != nil {
+			fmt.Printf("Error sending notification: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Test notification sent")
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configSetOrgCmd)
 	configCmd.AddCommand(configSetSSHCmd)
+	configCmd.AddCommand(configSetBackendCmd)
+	configCmd.AddCommand(configSetShellCmd)
+	configCmd.AddCommand(configSetTemplatesCmd)
+	configCmd.AddCommand(configSetHostCmd)
+	configCmd.AddCommand(configSetNotificationsCmd)
+	configCmd.AddCommand(configSetWebhookCmd)
+	configCmd.AddCommand(configSetShareCmd)
+	configCmd.AddCommand(configSetMasterKeyCmd)
+	configCmd.AddCommand(configSetDBClientCmd)
+	configCmd.AddCommand(configTestNotificationCmd)
+	configCmd.AddCommand(configExplainCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+
+	configSetHostCmd.Flags().String("provider", "", "Provider of the host: github, gitlab or bitbucket")
+	configSetCmd.Flags().Bool("local", false, "Write to spin.config.local.json instead of spin.config.json")
+	configSetCmd.Flags().Bool("string", false, "Store the value as a string even if it parses as JSON")
+	configSetMasterKeyCmd.Flags().String("project", "", "Project to set the key of, the one in the current directory by default")
 }
@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"github.com/afomera/spin/internal/config"
 	"github.com/afomera/spin/internal/dashboard"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/afomera/spin/internal/web"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
@@ -14,13 +21,39 @@ var dashboardCmd = &cobra.Command{
 	Use:   "dashboard",
 	Short: "Interactive dashboard for managing processes",
 	Long: `A terminal user interface for managing and monitoring your development processes.
-Provides real-time process status, resource usage, and quick actions for process control.`,
+Provides real-time process status, resource usage, and quick actions for process control.
+
+The project's Docker services are listed below the processes with their status,
+health and port. Select one and press S to start, s to stop or r to restart it.
+
+Press c to follow the logs of all processes in one view. Lines are prefixed
+with the process name, press space to mute or unmute the selected process.
+
+Press a to attach to the selected process, for example to answer a binding.irb
+prompt. The dashboard is suspended until you detach with Ctrl+D.
+
+Press l to view the logs of the selected process, starting with the end of its
+log file. Warnings and errors are highlighted, press n and p to jump between
+errors. Press f to stop scrolling to new lines while reading older ones, and
+again to resume. --scrollback sets how many lines are kept.
+
+Press e to see the environment the selected process was started with and x to
+list and run the project's scripts.
+
+With --web, the dashboard is served as a web page on localhost instead, with
+the status of processes and services, buttons to restart and stop processes
+and their logs streamed live.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load project config from current directory
 		configPath := filepath.Join(".", "spin.config.json")
 		cfg, err := config.LoadConfig(configPath)
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		if web, _ := cmd.Flags().GetBool("web"); web {
+			port, _ := cmd.Flags().GetInt("port")
+			serveWebDashboard(cfg, port)
 			return
 		}
 
@@ -30,6 +63,7 @@ Provides real-time process status, resource usage, and quick actions for process
 			fmt.Printf("Error initializing dashboard: %v\n", err)
 			return
 		}
+		model.Scrollback, _ = cmd.Flags().GetInt("scrollback")
 
 		// Run the dashboard
 		p := tea.NewProgram(model, tea.WithAltScreen())
@@ -39,6 +73,23 @@ Provides real-time process status, resource usage, and quick actions for process
 	},
 }
 
+// serveWebDashboard serves the web dashboard until interrupted
+func serveWebDashboard(cfg *config.Config, port int) {
+	server := &web.Server{API: newAPIServer(cfg), Port: port}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("%sServing the dashboard of %s on %s%s (press Ctrl+C to stop)\n", lg.Blue, cfg.Name, server.URL(), lg.Reset)
+	if err := server.Serve(ctx); err != nil {
+		fmt.Printf("%sError serving the dashboard: %v%s\n", lg.Red, err, lg.Reset)
+		os.Exit(1)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(dashboardCmd)
+	dashboardCmd.Flags().Bool("web", false, "Serve the dashboard as a web page on localhost")
+	dashboardCmd.Flags().Int("port", 4040, "Port the web dashboard is served on")
+	dashboardCmd.Flags().Int("scrollback", dashboard.DefaultConfig().MaxLogBuffer, "Lines of logs to keep in the logs views")
 }
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/detector"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/afomera/spin/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+// dbClients are the clients spin db gui opens, with the engines they connect
+// to. Clients without engines connect to all of them.
+var dbClients = map[string][]string{
+	"tableplus":     nil,
+	"open":          nil,
+	"psql":          {config.EnginePostgres},
+	"pgcli":         {config.EnginePostgres},
+	"mysql":         {config.EngineMySQL},
+	"mycli":         {config.EngineMySQL},
+	"redis-cli":     {config.EngineRedis},
+	"redis-insight": {config.EngineRedis},
+	"mongosh":       {config.EngineMongo},
+}
+
+// dbClientNames returns the names of the clients spin db gui knows
+func dbClientNames() []string {
+	names := make([]string, 0, len(dbClients))
+	for name := range dbClients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Work with the databases of the project",
+}
+
+// dbGuiCmd represents the db gui command
+var dbGuiCmd = &cobra.Command{
+	Use:   "gui [service]",
+	Short: "Open a client connected to a database service",
+	Long: `Open a database client connected to a service of the project, with its port,
+user and password filled in. Without a service, the first of the project's
+databases is opened, preferring PostgreSQL and MySQL over Redis and MongoDB.
+
+The client is picked from --client, the databaseClient of the user
+configuration, or else TablePlus when it is installed on macOS, then pgcli,
+psql, mycli, mysql, redis-cli or mongosh, whichever fits the database.
+GUI clients are opened through their URLs:
+  tableplus       TablePlus
+  redis-insight   Redis Insight
+  open            Whichever app handles the database's URLs
+
+The database of Rails apps is read from config/database.yml.
+
+Example:
+  spin db gui                       # Open the main database
+  spin db gui redis                 # Open the redis service
+  spin db gui --client tableplus    # Open it in TablePlus
+  spin db gui --database myapp_test # Connect to another database
+  spin db gui --print               # Only print the connection URL`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		} else if name = mainDatabase(cfg); name == "" {
+			fmt.Printf("%sError: the project has no PostgreSQL, MySQL, Redis or MongoDB service%s\n", lg.Red, lg.Reset)
+			os.Exit(1)
+		}
+		svc, ok := cfg.Services[name]
+		if !ok || svc == nil {
+			fmt.Printf("%sError: no service named %s%s\n", lg.Red, name, lg.Reset)
+			os.Exit(1)
+		}
+		engine := svc.Engine()
+		if engine == "" {
+			fmt.Printf("%sError: %s runs %s, which spin can't connect a client to%s\n", lg.Red, name, svc.Image, lg.Reset)
+			os.Exit(1)
+		}
+
+		u, err := url.Parse(svc.URL())
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		database, _ := cmd.Flags().GetString("database")
+		if database == "" && engine != config.EngineRedis {
+			database = railsDatabase()
+		}
+		if database != "" {
+			u.Path = "/" + database
+		}
+
+		if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+			fmt.Println(u.String())
+			return
+		}
+
+		client, _ := cmd.Flags().GetString("client")
+		if client == "" {
+			if userCfg, err := userconfig.Load(); err == nil {
+				client = userCfg.DatabaseClient
+			}
+		}
+		if client == "" {
+			client = defaultDBClient(engine)
+		}
+		if err := checkDBClient(client, engine); err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		lg.Printf("%sOpening %s%s%s in %s%s\n", lg.Blue, lg.Cyan, name, lg.Blue, client, lg.Reset)
+		if err := openDBClient(client, u); err != nil {
+			fmt.Printf("%sError opening %s: %v%s\n", lg.Red, client, err, lg.Reset)
+			os.Exit(1)
+		}
+	},
+}
+
+// mainDatabase returns the name of the service the project keeps its data
+// in, preferring relational databases, or "" without one
+func mainDatabase(cfg *config.Config) string {
+	names := make([]string, 0, len(cfg.Services))
+	for name := range cfg.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, engine := range []string{config.EnginePostgres, config.EngineMySQL, config.EngineRedis, config.EngineMongo} {
+		for _, name := range names {
+			if svc := cfg.Services[name]; svc != nil && svc.Engine() == engine {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// railsDatabase returns the development database of config/database.yml,
+// or "" when the project isn't a Rails app
+func railsDatabase() string {
+	rails, err := detector.DetectRails(".")
+	if err != nil {
+		return ""
+	}
+	return rails.Database.Settings["database"]
+}
+
+// defaultDBClient picks the client for an engine: TablePlus when it is
+// installed on macOS, otherwise the engine's command line client
+func defaultDBClient(engine string) string {
+	if runtime.GOOS == "darwin" {
+		if _, err := os.Stat("/Applications/TablePlus.app"); err == nil {
+			return "tableplus"
+		}
+	}
+	switch engine {
+	case config.EnginePostgres:
+		if _, err := exec.LookPath("pgcli"); err == nil {
+			return "pgcli"
+		}
+		return "psql"
+	case config.EngineMySQL:
+		if _, err := exec.LookPath("mycli"); err == nil {
+			return "mycli"
+		}
+		return "mysql"
+	case config.EngineRedis:
+		return "redis-cli"
+	}
+	return "mongosh"
+}
+
+// checkDBClient checks that spin knows a client and that it connects to an
+// engine
+func checkDBClient(client string, engine string) error {
+	engines, ok := dbClients[client]
+	if !ok {
+		return fmt.Errorf("unknown client %q, use one of %s", client, strings.Join(dbClientNames(), ", "))
+	}
+	if len(engines) == 0 {
+		return nil
+	}
+	for _, e := range engines {
+		if e == engine {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s can't connect to %s", client, engine)
+}
+
+// openDBClient opens a client connected to the database at u. GUI clients
+// are opened through their URLs, command line clients run in the terminal.
+func openDBClient(client string, u *url.URL) error {
+	switch client {
+	case "tableplus":
+		tableplusURL := *u
+		if tableplusURL.Scheme == "postgres" {
+			tableplusURL.Scheme = "postgresql"
+		}
+		if runtime.GOOS == "darwin" {
+			return exec.Command("open", "-a", "TablePlus", tableplusURL.String()).Start()
+		}
+		return openBrowser(tableplusURL.String())
+	case "redis-insight":
+		return openBrowser("redisinsight://databases/connect?redisUrl=" + url.QueryEscape(u.String()))
+	case "open":
+		return openBrowser(u.String())
+	}
+
+	// Command line clients get the URL without the password, which is passed
+	// in the environment to keep it off the command line
+	password, _ := u.User.Password()
+	withoutPassword := *u
+	withoutPassword.User = nil
+	if username := u.User.Username(); username != "" {
+		withoutPassword.User = url.User(username)
+	}
+
+	var c *exec.Cmd
+	var env []string
+	switch client {
+	case "psql", "pgcli":
+		c = exec.Command(client, withoutPassword.String())
+		env = []string{"PGPASSWORD=" + password}
+	case "mysql":
+		// The mysql client takes no URLs
+		c = exec.Command("mysql", "-h", "127.0.0.1", "-P", u.Port(), "-u", u.User.Username())
+		if database := strings.TrimPrefix(u.Path, "/"); database != "" {
+			c.Args = append(c.Args, database)
+		}
+		env = []string{"MYSQL_PWD=" + password}
+	case "mycli":
+		c = exec.Command("mycli", withoutPassword.String())
+		env = []string{"MYSQL_PWD=" + password}
+	case "redis-cli":
+		c = exec.Command("redis-cli", "-u", withoutPassword.String())
+		env = []string{"REDISCLI_AUTH=" + password}
+	default:
+		// mongosh reads no password from the environment and asks for it
+		// instead
+		c = exec.Command(client, withoutPassword.String())
+		if password != "" {
+			lg.Printf("%sThe password of the service is printed by spin db gui --print%s\n", lg.Blue, lg.Reset)
+		}
+	}
+	if password != "" {
+		c.Env = append(os.Environ(), env...)
+	}
+	if _, err := exec.LookPath(c.Args[0]); err != nil {
+		return fmt.Errorf("%s isn't installed", c.Args[0])
+	}
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbGuiCmd)
+	dbGuiCmd.Flags().String("client", "", "Client to open: "+strings.Join(dbClientNames(), ", "))
+	dbGuiCmd.Flags().String("database", "", "Database to connect to, the one of config/database.yml by default")
+	dbGuiCmd.Flags().Bool("print", false, "Print the connection URL instead of opening a client")
+}
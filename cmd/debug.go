@@ -6,6 +6,7 @@ import (
 
 	"github.com/afomera/spin/internal/config"
 	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/spinerr"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -18,18 +19,26 @@ var debugCmd = &cobra.Command{
 This is particularly useful for processes that require input, like Rails console
 or debugging sessions.
 
+Without a name, a list of the running processes to pick from is shown.
+
 Example:
+  spin debug         # Pick the process
   spin debug web     # Debug the web process (e.g., when hitting binding.irb)
   spin debug console # Attach to a Rails console session`,
-	Args: cobra.ExactArgs(1),
+	Args:              pickableArg,
+	ValidArgsFunction: firstArg(completeProcessNames),
 	Run: func(cmd *cobra.Command, args []string) {
-		processName := args[0]
-
 		// Load configuration
 		cfg, err := config.LoadConfig("spin.config.json")
 		if err != nil {
-			fmt.Printf("Error loading configuration: %v\n", err)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		var processName string
+		if len(args) > 0 {
+			processName = args[0]
+		} else {
+			processName = pickName("process", "Attach to:", namesToOptions(processNames()))
 		}
 
 		fmt.Printf("Attaching to process '%s' in debug mode...\n", processName)
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"os/exec"
+	"os"
 
+	"github.com/afomera/spin/internal/doctor"
 	"github.com/afomera/spin/internal/logger"
 	"github.com/spf13/cobra"
 )
@@ -11,36 +13,126 @@ import (
 // doctorCmd represents the doctor command
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
-	Short: "Check system requirements for Spin",
-	Long:  `Check if required dependencies (tmux, docker) are installed and available.`,
+	Short: "Check system and project requirements for Spin",
+	Long: `Check if required dependencies (tmux, docker) are installed and available.
+
+Inside a project it also checks spin.config.json for errors and unknown keys,
+the Procfile, the installed versions of the tools the project asks for, ports
+taken by other programs, stopped or unhealthy services, stale service volumes
+and the disk space left for Docker.
+
+With --fix, problems that can be fixed safely are fixed: missing tool versions
+are installed, stopped services started, unhealthy services restarted and
+leftover containers, images and logs cleaned up when disk space runs low.
+Stale volumes hold data and are only reported.
+
+With --json the results are printed as JSON, every check with an id, status
+(ok, warn or fail), message and remediation. With --exit-code spin doctor
+exits with 1 when a check fails, so CI pipelines and onboarding scripts can
+assert a machine meets the requirements.
+
+Example:
+  spin doctor                      # Check everything
+  spin doctor --fix                # Check and fix what can be fixed safely
+  spin doctor --json --exit-code   # Report as JSON and fail when a check fails`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("\nChecking system requirements...\n\n")
+		fix, _ := cmd.Flags().GetBool("fix")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+		if asJSON {
+			if fix {
+				fmt.Fprintf(os.Stderr, "%sError: --fix can't be combined with --json%s\n", logger.Red, logger.Reset)
+				os.Exit(1)
+			}
 
-		// Check tmux
-		if _, err := exec.LookPath("tmux"); err == nil {
-			fmt.Printf("  %s✓%s tmux: %sinstalled%s\n", logger.Green, logger.Reset, logger.Cyan, logger.Reset)
-		} else {
-			fmt.Printf("  %s⚠%s tmux: %snot found%s\n", logger.Yellow, logger.Reset, logger.Red, logger.Reset)
+			checks := doctor.Run(".")
+			if checks == nil {
+				checks = []doctor.Check{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(struct {
+				OK     bool           `json:"ok"`
+				Checks []doctor.Check `json:"checks"`
+			}{!doctor.Failed(checks), checks}); err != nil {
+				fmt.Fprintf(os.Stderr, "%sError encoding results: %v%s\n", logger.Red, err, logger.Reset)
+				os.Exit(1)
+			}
+			if exitCode && doctor.Failed(checks) {
+				os.Exit(1)
+			}
+			return
 		}
 
-		// Check docker
-		if _, err := exec.LookPath("docker"); err == nil {
-			// Check if docker daemon is running
-			cmd := exec.Command("docker", "info")
-			if err := cmd.Run(); err == nil {
-				fmt.Printf("  %s✓%s docker: %srunning%s\n", logger.Green, logger.Reset, logger.Cyan, logger.Reset)
+		fmt.Printf("\nChecking requirements...\n")
+		checks := doctor.Run(".")
+		printChecks(checks, !fix)
+
+		if fix {
+			fixed := 0
+			for _, check := range checks {
+				if !check.Fixable() {
+					continue
+				}
+				logger.Printf("%sFixing %s...%s\n", logger.Blue, check.Name, logger.Reset)
+				if err := check.Apply(); err != nil {
+					fmt.Printf("  %s✗%s %v\n", logger.Red, logger.Reset, err)
+					continue
+				}
+				fixed++
+			}
+			if fixed == 0 {
+				fmt.Printf("%sNothing to fix automatically%s\n\n", logger.Yellow, logger.Reset)
 			} else {
-				fmt.Printf("  %s⚠%s docker: %sinstalled but not running%s\n", logger.Yellow, logger.Reset, logger.Red, logger.Reset)
-				fmt.Printf("  %s→%s please start Docker Desktop to use docker features%s\n", logger.Blue, logger.Reset, logger.Reset)
+				fmt.Printf("\nChecking again...\n")
+				checks = doctor.Run(".")
+				printChecks(checks, false)
 			}
-		} else {
-			fmt.Printf("  %s⚠%s docker: %snot found%s\n", logger.Yellow, logger.Reset, logger.Red, logger.Reset)
 		}
 
-		fmt.Println()
+		if exitCode && doctor.Failed(checks) {
+			os.Exit(1)
+		}
 	},
 }
 
+// printChecks prints the results of the checks by group with how to fix
+// what they found, and points out --fix when suggestFix is set
+func printChecks(checks []doctor.Check, suggestFix bool) {
+	group := ""
+	fixable := 0
+	for _, check := range checks {
+		if check.Group != group {
+			group = check.Group
+			fmt.Printf("\n%s%s%s\n", logger.Cyan, group, logger.Reset)
+		}
+
+		switch check.Status {
+		case doctor.StatusOK:
+			fmt.Printf("  %s✓%s %s: %s%s%s\n", logger.Green, logger.Reset, check.Name, logger.Cyan, check.Message, logger.Reset)
+		case doctor.StatusWarn:
+			fmt.Printf("  %s⚠%s %s: %s%s%s\n", logger.Yellow, logger.Reset, check.Name, logger.Yellow, check.Message, logger.Reset)
+		default:
+			fmt.Printf("  %s✗%s %s: %s%s%s\n", logger.Red, logger.Reset, check.Name, logger.Red, check.Message, logger.Reset)
+		}
+		if check.Status != doctor.StatusOK && check.Fix != "" {
+			fmt.Printf("  %s→%s %s\n", logger.Blue, logger.Reset, check.Fix)
+		}
+		if check.Fixable() {
+			fixable++
+		}
+	}
+	fmt.Println()
+
+	if suggestFix && fixable > 0 {
+		fmt.Printf("%s%d problem(s) can be fixed with 'spin doctor --fix'%s\n\n", logger.Yellow, fixable, logger.Reset)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("fix", false, "Fix the problems that can be fixed safely")
+	doctorCmd.Flags().Bool("json", false, "Print the results as JSON")
+	doctorCmd.Flags().Bool("exit-code", false, "Exit with 1 when a check fails")
 }
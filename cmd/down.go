@@ -1,51 +1,91 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/afomera/spin/internal/config"
 	lg "github.com/afomera/spin/internal/logger"
 	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/procfile"
+	"github.com/afomera/spin/internal/registry"
 	"github.com/afomera/spin/internal/service"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/setup"
+	"github.com/afomera/spin/internal/spinerr"
 	"github.com/spf13/cobra"
 )
 
 // downCmd represents the down command
 var downCmd = &cobra.Command{
-	Use:   "down",
+	Use:   "down [process|service...]",
 	Short: "Stop all running processes",
-	Long: `Stop all running processes and clean up tmux sessions.
+	Long: `Stop all running processes and clean up tmux sessions. The dev container
+processes run in, if the project has one, is stopped and removed.
+
+Given names of processes or services, only those are stopped and everything
+else keeps running. A scaled process stops with all its instances, and a
+shared service is only stopped once no other project uses it. Lifecycle hooks
+only run when the whole project goes down.
+
+With --purge, the environment of the project is torn down too: the containers
+of its services and their named volumes, the spin network once no containers
+use it, the output logs of its processes and its entries in the process
+store. Shared services other projects use are left alone, as are directories
+of the host mounted into services. What will be deleted is listed and has to
+be confirmed, unless --yes is given.
 
 Example:
-  spin down     # Stop all processes`,
+  spin down               # Stop all processes
+  spin down worker redis  # Stop the worker and redis, leave web running
+  spin down --purge       # Stop everything and delete the project's data`,
+	ValidArgsFunction: completeProcessOrServiceNames,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load configuration from current directory
 		configPath := filepath.Join(".", "spin.config.json")
-		cfg, err := config.LoadConfig(configPath)
-		if err == nil && cfg != nil {
-			// Initialize service manager
-			svcManager := service.NewServiceManager()
-			if len(cfg.Dependencies.Services) > 0 {
-				fmt.Printf("%sStopping services...%s\n", lg.Blue, lg.Reset)
-				for _, serviceName := range cfg.Dependencies.Services {
-					svc, err := service.CreateService(serviceName, cfg)
-					if err != nil {
-						fmt.Printf("%sWarning: Failed to create service %s: %v%s\n", lg.Yellow, serviceName, err, lg.Reset)
-						continue
-					}
-					svcManager.RegisterService(svc)
+		cfg, err := config.LoadProject(configPath)
 
-					if svc.IsRunning() {
-						fmt.Printf("Stopping %s%s%s...\n", lg.Cyan, serviceName, lg.Reset)
-						if err := svcManager.StopService(serviceName); err != nil {
-							fmt.Printf("%sWarning: Failed to stop service %s: %v%s\n", lg.Yellow, serviceName, err, lg.Reset)
-						}
-					}
-				}
+		if len(args) > 0 {
+			if purgeFlag, _ := cmd.Flags().GetBool("purge"); purgeFlag {
+				spinerr.Exit(spinerr.New(spinerr.Validation, "--purge tears the whole project down and can't be given names").
+					WithFix("Run 'spin down --purge' without names"))
+			}
+			if err != nil {
+				spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+			}
+			downSelected(cfg, args)
+			return
+		}
+
+		var purge *purgePlan
+		if purgeFlag, _ := cmd.Flags().GetBool("purge"); purgeFlag {
+			if err != nil {
+				spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+			}
+			purge = planPurge(cfg)
+			yes, _ := cmd.Flags().GetBool("yes")
+			if !purge.confirm(yes) {
+				fmt.Printf("%sDown cancelled%s\n", lg.Yellow, lg.Reset)
+				return
 			}
 		}
 
+		if err == nil && cfg != nil {
+			if err := runLifecycleHooks(cfg, "pre_down", "."); err != nil {
+				lg.Warnf("%v", err)
+			}
+			if err := runAppHooks(cfg, "pre_down", "."); err != nil {
+				lg.Warnf("%v", err)
+			}
+
+			stopServices(cfg, cfg.Dependencies.Services)
+		}
+
 		// Get the process manager instance with config
 		manager := process.GetManager(cfg)
 
@@ -53,21 +93,300 @@ Example:
 		processes := manager.ListProcesses()
 		if len(processes) == 0 {
 			fmt.Printf("%sNo running processes%s\n", lg.Yellow, lg.Reset)
-			return
+		} else {
+			lg.Printf("%sStopping all processes...%s\n", lg.Blue, lg.Reset)
+			for _, p := range processes {
+				// Services were stopped above, shared ones are left running
+				if p.Type == process.ProcessTypeDocker {
+					continue
+				}
+				lg.Printf("Stopping %s%s%s...\n", lg.Cyan, p.Name, lg.Reset)
+				if err := manager.StopProcess(p.AppName, p.Name); err != nil {
+					lg.Warnf("Failed to stop %s: %v", p.Name, err)
+				}
+			}
+
+			lg.Printf("%sAll processes stopped%s\n", lg.Green, lg.Reset)
 		}
 
-		fmt.Printf("%sStopping all processes...%s\n", lg.Blue, lg.Reset)
-		for _, p := range processes {
-			fmt.Printf("Stopping %s%s%s...\n", lg.Cyan, p.Name, lg.Reset)
-			if err := manager.StopProcess(p.AppName, p.Name); err != nil {
-				fmt.Printf("%sWarning: Failed to stop %s: %v%s\n", lg.Yellow, p.Name, err, lg.Reset)
+		// Containers of docker: processes are removed, even when the
+		// process manager didn't get to stop them
+		if cfg != nil {
+			entries, err := procfile.Resolve(cfg, ".", procfile.Selection{})
+			if err == nil && usesAppImage(entries) {
+				if dm, err := docker.NewServiceManager("./data"); err == nil {
+					if err := dm.RemoveProcessContainers(cfg.Name); err != nil {
+						lg.Warnf("%v", err)
+					}
+				}
 			}
 		}
 
-		fmt.Printf("%sAll processes stopped%s\n", lg.Green, lg.Reset)
+		// Processes started in the dev container end with it
+		if cfg != nil && cfg.DevContainer != nil {
+			if dm, err := docker.NewServiceManager("./data"); err == nil && dm.IsRunning(docker.DevContainerName(cfg.Name)) {
+				fmt.Printf("Stopping dev container...\n")
+				if err := dm.StopDevContainer(cfg.Name); err != nil {
+					lg.Warnf("%v", err)
+				}
+			}
+		}
+
+		if purge != nil {
+			purge.run(cfg)
+		}
+
+		if cfg != nil {
+			if err := registry.Unregister(cfg.Name); err != nil {
+				lg.Warnf("%v", err)
+			}
+			if err := runLifecycleHooks(cfg, "post_down", "."); err != nil {
+				lg.Warnf("%v", err)
+			}
+			if err := runAppHooks(cfg, "post_down", "."); err != nil {
+				lg.Warnf("%v", err)
+			}
+		}
 	},
 }
 
+// stopServices stops services of a project. Shared services keep running
+// while other projects use them.
+func stopServices(cfg *config.Config, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	trackServices(cfg)
+	svcManager := service.NewServiceManager()
+	lg.Printf("%sStopping services...%s\n", lg.Blue, lg.Reset)
+	for _, serviceName := range names {
+		if svcCfg, ok := cfg.Services[serviceName]; ok && svcCfg.Shared {
+			users, err := docker.ReleaseShared(serviceName, cfg.Name)
+			if err != nil {
+				lg.Warnf("%v", err)
+			} else if len(users) > 0 {
+				fmt.Printf("%sLeaving shared service %s running for %s%s\n", lg.Yellow, serviceName, strings.Join(users, ", "), lg.Reset)
+				continue
+			}
+		}
+
+		svc, err := service.CreateService(serviceName, cfg)
+		if err != nil {
+			lg.Warnf("Failed to create service %s: %v", serviceName, err)
+			continue
+		}
+		svcManager.RegisterService(svc)
+
+		if svc.IsRunning() {
+			lg.Printf("Stopping %s%s%s...\n", lg.Cyan, serviceName, lg.Reset)
+			if err := svcManager.StopService(serviceName); err != nil {
+				lg.Warnf("Failed to stop service %s: %v", serviceName, err)
+			}
+		}
+	}
+}
+
+// downSelected stops the named processes and services of a project. Names
+// that are neither a running process nor a service are rejected before
+// anything is stopped.
+func downSelected(cfg *config.Config, names []string) {
+	manager := process.GetManager(cfg)
+	running := manager.ListProcesses()
+
+	var services, processes []string
+	for _, name := range names {
+		if _, ok := cfg.Services[name]; ok {
+			services = append(services, name)
+			continue
+		}
+		instances := process.Instances(running, name)
+		if len(instances) == 0 {
+			spinerr.Exit(spinerr.New(spinerr.Process, name+" is neither a running process nor a service").
+				WithFix("See what is running with 'spin ps'"))
+		}
+		processes = append(processes, instances...)
+	}
+
+	if len(processes) > 0 {
+		lg.Printf("%sStopping processes...%s\n", lg.Blue, lg.Reset)
+		for _, name := range processes {
+			lg.Printf("Stopping %s%s%s...\n", lg.Cyan, name, lg.Reset)
+			if err := manager.StopProcess(cfg.Name, name); err != nil {
+				lg.Warnf("Failed to stop %s: %v", name, err)
+			}
+		}
+	}
+	stopServices(cfg, services)
+	lg.Printf("%sStopped %s%s\n", lg.Green, strings.Join(names, ", "), lg.Reset)
+}
+
+// purgePlan is what spin down --purge deletes
+type purgePlan struct {
+	services []string // Services whose containers are removed
+	volumes  []string // Named volumes of the services
+	kept     []string // Services whose containers the project didn't create
+	network  bool     // Whether the spin network exists
+	logDir   string   // Output directory of the project, empty without one
+	entries  []string // Process store keys of the project
+}
+
+// planPurge finds what spin down --purge deletes for a project
+func planPurge(cfg *config.Config) *purgePlan {
+	plan := &purgePlan{}
+
+	names := make([]string, 0, len(cfg.Services))
+	for name := range cfg.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if dm, err := docker.NewServiceManager("./data"); err == nil {
+		for _, name := range names {
+			svc := cfg.Services[name]
+			if svc.Shared && sharedWithOthers(name, cfg.Name) {
+				continue
+			}
+			if _, err := dm.FindContainer(name); err == nil {
+				// Containers are named after their service only, another
+				// project may have created it and keep its data in it
+				if !dm.OwnsContainer(name) {
+					plan.kept = append(plan.kept, name)
+					continue
+				}
+				plan.services = append(plan.services, name)
+			}
+			for _, volume := range dm.ServiceVolumes(name, svc) {
+				if users, err := dm.VolumeUsers(volume, name); err != nil || len(users) > 0 {
+					continue
+				}
+				plan.volumes = append(plan.volumes, volume)
+			}
+		}
+		plan.network = dm.NetworkExists()
+	}
+
+	if dir, err := process.LogDir(cfg.Name); err == nil {
+		if _, err := os.Stat(dir); err == nil {
+			plan.logDir = dir
+		}
+	}
+	plan.entries = projectEntries(cfg)
+	return plan
+}
+
+// sharedWithOthers checks if projects other than project use a shared service
+func sharedWithOthers(name string, project string) bool {
+	users, err := docker.SharedUsers(name)
+	if err != nil {
+		return true
+	}
+	for _, user := range users {
+		if user != project {
+			return true
+		}
+	}
+	return false
+}
+
+// projectEntries returns the process store keys of a project
+func projectEntries(cfg *config.Config) []string {
+	entries, err := process.GetManager(cfg).Store().Entries()
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for key, info := range entries {
+		if info.AppName == cfg.Name {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// confirm lists what will be deleted and asks to go on, unless yes is set
+func (p *purgePlan) confirm(yes bool) bool {
+	fmt.Printf("%sspin down --purge will delete:%s\n", lg.Blue, lg.Reset)
+	for _, name := range p.services {
+		fmt.Printf("  container %sspin_%s%s\n", lg.Cyan, name, lg.Reset)
+	}
+	for _, volume := range p.volumes {
+		fmt.Printf("  volume    %s%s%s\n", lg.Cyan, volume, lg.Reset)
+	}
+	if p.network {
+		fmt.Printf("  network   %s%s%s (once no containers use it)\n", lg.Cyan, docker.NetworkName, lg.Reset)
+	}
+	for _, name := range p.kept {
+		fmt.Printf("%sKeeping spin_%s and its volumes, the project didn't create it%s\n", lg.Yellow, name, lg.Reset)
+	}
+	if p.logDir != "" {
+		fmt.Printf("  logs      %s%s%s\n", lg.Cyan, p.logDir, lg.Reset)
+	}
+	for _, key := range p.entries {
+		fmt.Printf("  entry     %s%s%s (process store)\n", lg.Cyan, key, lg.Reset)
+	}
+	fmt.Println()
+
+	if yes {
+		return true
+	}
+	fmt.Printf("%sContinue? (y/N)%s\n", lg.Blue, lg.Reset)
+	response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
+
+// run deletes what the plan lists, once processes and services are stopped
+func (p *purgePlan) run(cfg *config.Config) {
+	lg.Printf("%sPurging %s...%s\n", lg.Blue, cfg.Name, lg.Reset)
+	if len(p.services) > 0 || len(p.volumes) > 0 || p.network {
+		dm, err := docker.NewServiceManager("./data")
+		if err != nil {
+			lg.Warnf("%v", err)
+		} else {
+			for _, name := range p.services {
+				if err := dm.RemoveService(name, true); err != nil {
+					lg.Warnf("%v", err)
+				}
+			}
+			for _, volume := range p.volumes {
+				if err := dm.Client().VolumeRemove(context.Background(), volume, true); err != nil {
+					lg.Warnf("failed to remove volume %s: %v", volume, err)
+				}
+			}
+			if p.network {
+				removed, err := dm.RemoveNetwork()
+				if err != nil {
+					lg.Warnf("%v", err)
+				} else if !removed {
+					fmt.Printf("%sLeaving network %s, other containers use it%s\n", lg.Yellow, docker.NetworkName, lg.Reset)
+				}
+			}
+		}
+	}
+
+	if p.logDir != "" {
+		if err := os.RemoveAll(p.logDir); err != nil {
+			lg.Warnf("%v", err)
+		}
+	}
+	// Stopping removed most entries, the rest belong to services
+	if entries := projectEntries(cfg); len(entries) > 0 {
+		if err := process.GetManager(cfg).Store().RemoveEntries(entries); err != nil {
+			lg.Warnf("%v", err)
+		}
+	}
+	// The database is gone with the volumes, so spin up migrates it again
+	if err := setup.ResetUp("."); err != nil {
+		lg.Warnf("%v", err)
+	}
+	lg.Printf("%s%s purged%s\n", lg.Green, cfg.Name, lg.Reset)
+}
+
 func init() {
 	rootCmd.AddCommand(downCmd)
+	downCmd.Flags().Bool("purge", false, "Also delete the project's containers, volumes, logs and store entries")
+	downCmd.Flags().BoolP("yes", "y", false, "Purge without asking for confirmation")
 }
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/script"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// envNamePattern matches the characters that can't be used in the name of
+// an environment variable
+var envNamePattern = regexp.MustCompile(`[^A-Z0-9_]+`)
+
+// envShells are the shells spin env prints statements for
+var envShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print the environment of the project for a shell",
+	Long: `Print export statements for the environment processes of the project get,
+so ad-hoc commands run with the same variables:
+
+  - <SERVICE>_HOST, <SERVICE>_PORT and, for databases spin knows, like
+    postgres, mysql, redis and mongo, <SERVICE>_URL of each service
+  - the variables of .env
+  - the variables of an env of spin.config.json, development by default
+
+Later sources win. With --direnv, a .envrc that loads them is printed
+instead, and reloads when spin.config.json or .env change.
+
+Example:
+  eval "$(spin env)"                   # bash and zsh
+  spin env --shell fish | source       # fish
+  spin env --shell powershell | Invoke-Expression
+  spin env --env test                  # The test env instead
+  spin env --direnv > .envrc           # Load the environment with direnv`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		envName, _ := cmd.Flags().GetString("env")
+		if direnv, _ := cmd.Flags().GetBool("direnv"); direnv {
+			printDirenv(envName)
+			return
+		}
+
+		shell, _ := cmd.Flags().GetString("shell")
+		if !isEnvShell(shell) {
+			fmt.Fprintf(os.Stderr, "%sError: unknown shell %q, use %s%s\n", lg.Red, shell, strings.Join(envShells, ", "), lg.Reset)
+			os.Exit(1)
+		}
+		cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		env := projectEnv(cfg, envName)
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Println(exportStatement(shell, key, env[key]))
+		}
+	},
+}
+
+// projectEnv returns the environment of a project: the addresses of its
+// services, the variables of .env and those of an env of spin.config.json
+func projectEnv(cfg *config.Config, envName string) map[string]string {
+	env := make(map[string]string)
+	for name, svc := range cfg.Services {
+		if svc == nil || svc.PublishedPort() == 0 {
+			continue
+		}
+		prefix := envNamePattern.ReplaceAllString(strings.ToUpper(name), "_")
+		env[prefix+"_HOST"] = "localhost"
+		env[prefix+"_PORT"] = fmt.Sprint(svc.PublishedPort())
+		url := svc.URL()
+		// Projects sharing a database service each have their own database
+		if svc.Shared && docker.HasProjectDatabases(svc) {
+			url = docker.ProjectDatabaseURL(svc, cfg.Name)
+			if _, ok := env["DATABASE_URL"]; !ok && url != "" {
+				env["DATABASE_URL"] = url
+			}
+		}
+		if url != "" {
+			env[prefix+"_URL"] = url
+		}
+	}
+	if cfg.Foreman != nil {
+		for key, value := range cfg.Foreman.Env {
+			env[key] = value
+		}
+	}
+	for key, value := range cfg.GetEnvVars(envName) {
+		env[key] = value
+	}
+	return env
+}
+
+// exportStatement returns the statement setting a variable in a shell
+func exportStatement(shell string, key string, value string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf("set -gx %s '%s';", key, strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value))
+	case "powershell":
+		return fmt.Sprintf("$env:%s = '%s'", key, strings.ReplaceAll(value, "'", "''"))
+	}
+	return fmt.Sprintf("export %s=%s", key, script.ShellQuote(value))
+}
+
+// printDirenv prints a .envrc loading the environment of an env
+func printDirenv(envName string) {
+	command := "spin env"
+	if envName != "development" {
+		command += " --env " + script.ShellQuote(envName)
+	}
+	fmt.Println("# Loads the environment of the project, see spin env --help")
+	fmt.Println("watch_file spin.config.json .env")
+	fmt.Printf("eval \"$(%s)\"\n", command)
+}
+
+func isEnvShell(shell string) bool {
+	for _, s := range envShells {
+		if s == shell {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.Flags().String("shell", "bash", "Shell to print statements for: bash, zsh, fish or powershell")
+	envCmd.Flags().String("env", "development", "Env of spin.config.json to use")
+	envCmd.Flags().Bool("direnv", false, "Print a .envrc for direnv instead")
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/afomera/spin/internal/events"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// eventsCmd represents the events command
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show what happened to processes, services and scripts",
+	Long: `Show the event stream spin records in ~/.spin/events.jsonl: processes being
+started, stopped, restarted or crashing, services turning healthy or unhealthy
+and scripts being run.
+
+Example:
+  spin events                       # Last 20 events of all projects
+  spin events --follow              # Keep printing new events
+  spin events --project myapp -n 50 # Last 50 events of myapp
+  spin events --type process.crashed --type service.unhealthy`,
+	Run: func(cmd *cobra.Command, args []string) {
+		project, _ := cmd.Flags().GetString("project")
+		typeNames, _ := cmd.Flags().GetStringSlice("type")
+		count, _ := cmd.Flags().GetInt("lines")
+		follow, _ := cmd.Flags().GetBool("follow")
+
+		filter := events.Filter{App: project}
+		for _, name := range typeNames {
+			t, err := parseEventType(name)
+			if err != nil {
+				fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+				os.Exit(1)
+			}
+			filter.Types = append(filter.Types, t)
+		}
+
+		recent, err := events.Recent(count, filter)
+		if err != nil {
+			fmt.Printf("%sError reading events: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		for _, e := range recent {
+			printEvent(e)
+		}
+
+		if !follow {
+			if len(recent) == 0 {
+				fmt.Printf("%sNo events recorded%s\n", lg.Yellow, lg.Reset)
+			}
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := events.Follow(ctx, filter, printEvent); err != nil {
+			fmt.Printf("%sError following events: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+	},
+}
+
+// parseEventType validates an event type given on the command line
+func parseEventType(name string) (events.Type, error) {
+	for _, t := range events.Types {
+		if string(t) == name {
+			return t, nil
+		}
+	}
+
+	names := make([]string, 0, len(events.Types))
+	for _, t := range events.Types {
+		names = append(names, string(t))
+	}
+	return "", fmt.Errorf("unknown event type %q, use one of %s", name, strings.Join(names, ", "))
+}
+
+// printEvent prints a single event on one line
+func printEvent(e events.Event) {
+	color := lg.Blue
+	switch e.Type {
+	case events.ProcessCrashed, events.ServiceUnhealthy, events.ScriptFailed:
+		color = lg.Red
+	case events.ServiceHealthy, events.ProcessStarted:
+		color = lg.Green
+	case events.ProcessStopped, events.ServiceIdle:
+		color = lg.Yellow
+	}
+
+	subject := e.Name
+	if e.App != "" {
+		subject = e.App + "/" + e.Name
+	}
+
+	line := fmt.Sprintf("%s  %s%-17s%s  %s", e.Time.Format("2006-01-02 15:04:05"), color, e.Type, lg.Reset, subject)
+	if e.Message != "" {
+		line += "  " + e.Message
+	}
+	fmt.Println(line)
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+	eventsCmd.Flags().BoolP("follow", "f", false, "Keep printing new events")
+	eventsCmd.Flags().String("project", "", "Only show events of this project")
+	eventsCmd.Flags().StringSlice("type", nil, "Only show events of these types")
+	eventsCmd.Flags().IntP("lines", "n", 20, "Number of past events to show")
+}
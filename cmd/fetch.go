@@ -1,27 +1,53 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/forge"
 	lg "github.com/afomera/spin/internal/logger"
 	"github.com/afomera/spin/internal/script"
+	"github.com/afomera/spin/internal/setup"
+	"github.com/afomera/spin/internal/spinerr"
 	"github.com/afomera/spin/internal/userconfig"
 	"github.com/spf13/cobra"
 )
 
 var (
-	fetchRepoFlag string // Flag to specify repository in org/name format
-	skipSetup     bool   // Flag to skip running setup scripts
+	fetchRepoFlag string   // Flag to specify repository in org/name format
+	skipSetup     []string // Flag to skip running setup, all of it or some setup tasks
+	fetchAll      bool     // Flag to fetch several repositories of an organization
 )
 
+// skipAllSetup is the value of a bare --skip-setup, which skips all of setup
+const skipAllSetup = "all"
+
+// cloneMarker is the file in .git marking a resumable clone that isn't
+// finished yet
+const cloneMarker = "spin-clone"
+
+// fetchAttempts is how often a step of a resumable clone is tried before
+// giving up
+const fetchAttempts = 3
+
+// cloneOptions are how spin fetch clones repositories
+type cloneOptions struct {
+	Branch            string // Branch to check out, the default branch when empty
+	Depth             int    // Commits of history to fetch, all of them when 0
+	RecurseSubmodules bool
+	Resumable         bool // Clone in steps, which are kept when git is interrupted
+}
+
 // fetchCmd represents the fetch command
 var fetchCmd = &cobra.Command{
-	Use:   "fetch [app-name]",
+	Use:   "fetch [app-name | org/app][@branch] | --all [org]",
 	Short: "Clone and setup an existing application",
 	Long: `Fetch clones an existing application repository and sets it up for development.
 It expects the application to have a spin.config.json file.
@@ -29,9 +55,37 @@ It expects the application to have a spin.config.json file.
 If run inside a repository with a spin.config.json file, it will fetch the latest changes.
 Otherwise, it will clone the repository and set it up.
 
+Repositories are cloned from github.com unless another host is set for the
+organization with spin config set-host, e.g. for GitLab or Bitbucket. Append
+@branch to clone a branch other than the default one.
+
+Private repositories cloned over HTTPS authenticate with GH_TOKEN or
+GITHUB_TOKEN (or the gh CLI), GITLAB_TOKEN or BITBUCKET_TOKEN. The token is
+only used for the clone and not stored in the repository.
+
+Large repositories can be cloned with less history with --depth, and with
+--resumable in steps: the latest commit of the branch first, then the rest of
+the history. Each step is tried again when the connection drops, and running
+the same spin fetch after an interruption continues from the last step that
+completed. --recurse-submodules clones the submodules too.
+
+--skip-setup leaves out all of setup, --skip-setup=seed,db only those setup
+tasks.
+
+With --all, the repositories of an organization on GitHub that have a
+spin.config.json are listed, and the ones picked are cloned and set up one
+after another, so the whole stack can be pulled down in one session.
+
 Example:
   spin fetch myapp
+  spin fetch myorg/myapp
+  spin fetch myorg/myapp@feature-branch
   spin fetch myapp --repo=myorg/myapp
+  spin fetch myapp --depth 1 --recurse-submodules
+  spin fetch bigapp --resumable        # Run it again to resume after an interruption
+  spin fetch myapp --skip-setup=seed   # Run the setup tasks except seed
+  spin fetch --all                 # Pick repositories of the default organization
+  spin fetch --all myorg --yes     # Fetch every repository of myorg
   spin fetch (in a repository with spin.config.json)`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -48,14 +102,40 @@ Example:
 			os.Exit(1)
 		}
 
+		opts, err := fetchCloneOptions(cmd)
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		if fetchAll {
+			if opts.Branch != "" {
+				fmt.Printf("%sError: --branch can't be given with --all%s\n", lg.Red, lg.Reset)
+				os.Exit(1)
+			}
+			organization := userCfg.DefaultOrganization
+			if len(args) > 0 {
+				organization = args[0]
+			}
+			if organization == "" {
+				fmt.Printf("%sError: give an organization or set a default one with 'spin config set-org'%s\n", lg.Red, lg.Reset)
+				os.Exit(1)
+			}
+			yes, _ := cmd.Flags().GetBool("yes")
+			if err := fetchOrganization(userCfg, organization, yes, opts); err != nil {
+				fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Check if we're in a git repository with spin.config.json
 		if _, err := os.Stat(".git"); err == nil {
 			if _, err := os.Stat("spin.config.json"); err == nil {
 				// We're in a repository with spin.config.json, fetch latest changes
 				cfg, err := config.LoadConfig("spin.config.json")
 				if err != nil {
-					fmt.Printf("%sError loading spin.config.json: %v%s\n", lg.Red, err, lg.Reset)
-					os.Exit(1)
+					spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 				}
 
 				// Get current branch
@@ -66,7 +146,7 @@ Example:
 					os.Exit(1)
 				}
 				currentBranch := strings.TrimSpace(string(branchOutput))
-				fmt.Printf("%sFetching latest changes for %s%s%s...\n", lg.Blue, lg.Cyan, cfg.Repository.GetFullName(), lg.Reset)
+				lg.Printf("%sFetching latest changes for %s%s%s...\n", lg.Blue, lg.Cyan, cfg.Repository.GetFullName(), lg.Reset)
 				fetchCmd := exec.Command("git", "fetch", "origin", currentBranch)
 				fetchCmd.Stdout = os.Stdout
 				fetchCmd.Stderr = os.Stderr
@@ -83,8 +163,14 @@ Example:
 					fmt.Printf("%sError merging changes: %v%s\n", lg.Red, err, lg.Reset)
 					os.Exit(1)
 				}
+				if opts.RecurseSubmodules {
+					if err := runGit("submodule", "update", "--init", "--recursive"); err != nil {
+						fmt.Printf("%sError updating submodules: %v%s\n", lg.Red, err, lg.Reset)
+						os.Exit(1)
+					}
+				}
 
-				fmt.Printf("%s✨ Successfully updated %s%s%s\n", lg.Green, lg.Cyan, cfg.Repository.GetFullName(), lg.Reset)
+				lg.Printf("%s✨ Successfully updated %s%s%s\n", lg.Green, lg.Cyan, cfg.Repository.GetFullName(), lg.Reset)
 				return
 			}
 		}
@@ -94,16 +180,30 @@ Example:
 			fmt.Printf("%sError: app name is required when not in a repository%s\n", lg.Red, lg.Reset)
 			os.Exit(1)
 		}
-		appName := args[0]
+		appName, branch, _ := strings.Cut(args[0], "@")
+		if branch != "" && opts.Branch != "" && branch != opts.Branch {
+			fmt.Printf("%sError: @%s and --branch %s name different branches%s\n", lg.Red, branch, opts.Branch, lg.Reset)
+			os.Exit(1)
+		}
+		if branch != "" {
+			opts.Branch = branch
+		}
 
-		// Parse repository information if provided via flag
+		// Parse repository information if provided as org/app or via flag
 		var repo *config.Repository
-		if fetchRepoFlag != "" {
-			repo, err = config.ParseRepositoryString(fetchRepoFlag)
+		repoSpec := fetchRepoFlag
+		if strings.Contains(appName, "/") {
+			repoSpec = appName
+		}
+		if repoSpec != "" {
+			repo, err = config.ParseRepositoryString(repoSpec)
 			if err != nil {
 				fmt.Printf("%sError parsing repository: %v%s\n", lg.Red, err, lg.Reset)
 				os.Exit(1)
 			}
+			if repoSpec == appName {
+				appName = repo.Name
+			}
 		} else {
 			// Use default organization from user config, or prompt if not set
 			defaultOrg := userCfg.DefaultOrganization
@@ -119,107 +219,420 @@ Example:
 			}
 		}
 
-		// Clone the repository
-		fmt.Printf("%sCloning repository %s%s%s...\n", lg.Blue, lg.Cyan, repo.GetFullName(), lg.Reset)
-		gitCmd := exec.Command("git", "clone", repo.GetCloneURL(userCfg.PreferSSH), appName)
-		gitCmd.Stdout = os.Stdout
-		gitCmd.Stderr = os.Stderr
-
-		if err := gitCmd.Run(); err != nil {
-			fmt.Printf("%sError cloning repository: %v%s\n", lg.Red, err, lg.Reset)
+		if err := cloneAndSetup(userCfg, repo, appName, opts, fetchRepoFlag != ""); err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
 			os.Exit(1)
 		}
 
-		// Check for spin.config.json
-		configPath := filepath.Join(appName, "spin.config.json")
-		if _, err := os.Stat(configPath); os.IsNotExist(err) {
-			fmt.Printf("%sNo spin.config.json found, running project detection...%s\n", lg.Blue, lg.Reset)
+		lg.Printf("\n%s✨ Successfully fetched %s%s%s\n", lg.Green, lg.Cyan, appName, lg.Reset)
+		lg.Printf("%sRepository:%s %s\n", lg.Blue, lg.Reset, repo.GetFullName())
 
-			// Change to the app directory to run init
-			if err := os.Chdir(appName); err != nil {
-				fmt.Printf("%sError changing to app directory: %v%s\n", lg.Red, err, lg.Reset)
-				os.Exit(1)
-			}
+		fmt.Printf("\n%sNext steps:%s\n", lg.Purple, lg.Reset)
+		if setupSkipped() {
+			fmt.Printf("  %s1.%s cd %s%s%s\n", lg.Yellow, lg.Reset, lg.Cyan, appName, lg.Reset)
+			fmt.Printf("  %s2.%s Review %sspin.config.json%s\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
+			fmt.Printf("  %s3.%s Run %sspin setup%s to install dependencies\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
+			fmt.Printf("  %s4.%s Run %sspin up%s to start development\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
+		} else {
+			fmt.Printf("  %s1.%s cd %s%s%s\n", lg.Yellow, lg.Reset, lg.Cyan, appName, lg.Reset)
+			fmt.Printf("  %s2.%s Run %sspin up%s to start development\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
+		}
+	},
+}
 
-			// Run the init command in the current directory
-			initArgs := []string{"."}
-			initCmd.Run(initCmd, initArgs)
+// fetchCloneOptions reads how to clone from the flags of spin fetch
+func fetchCloneOptions(cmd *cobra.Command) (cloneOptions, error) {
+	var opts cloneOptions
+	opts.Branch, _ = cmd.Flags().GetString("branch")
+	opts.Depth, _ = cmd.Flags().GetInt("depth")
+	opts.RecurseSubmodules, _ = cmd.Flags().GetBool("recurse-submodules")
+	opts.Resumable, _ = cmd.Flags().GetBool("resumable")
+	if opts.Depth < 0 {
+		return opts, fmt.Errorf("--depth must be a positive number of commits")
+	}
+	return opts, nil
+}
 
-			// Change back to the original directory
-			if err := os.Chdir(".."); err != nil {
-				fmt.Printf("%sError changing back to original directory: %v%s\n", lg.Red, err, lg.Reset)
-				os.Exit(1)
-			}
-		} else {
-			// Load existing config and update repository information if needed
-			cfg, err := config.LoadConfig(configPath)
-			if err != nil {
-				fmt.Printf("%sError loading configuration: %v%s\n", lg.Red, err, lg.Reset)
-				os.Exit(1)
+// setupSkipped reports whether --skip-setup was given without naming tasks
+func setupSkipped() bool {
+	for _, name := range skipSetup {
+		if name == skipAllSetup {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchOrganization lists the repositories of an organization that have a
+// spin.config.json, and clones and sets up the ones picked
+func fetchOrganization(userCfg *userconfig.Config, organization string, all bool, opts cloneOptions) error {
+	host := userCfg.HostFor(organization)
+	if host.GetProvider() != forge.GitHub {
+		return fmt.Errorf("listing repositories is only supported on GitHub, fetch the repositories of %s one by one", organization)
+	}
+
+	lg.Printf("%sLooking for repositories of %s%s%s with a spin.config.json...%s\n", lg.Blue, lg.Cyan, organization, lg.Blue, lg.Reset)
+	repos, err := forge.NewGitHubClient(host.Host).SpinRepositories(organization)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		fmt.Printf("%sNo repositories of %s have a spin.config.json%s\n", lg.Yellow, organization, lg.Reset)
+		return nil
+	}
+
+	// Repositories cloned before are listed but never picked again
+	cloned := make(map[int]bool)
+	fmt.Println()
+	for i, repo := range repos {
+		fmt.Printf("  %s%2d.%s %s", lg.Yellow, i+1, lg.Reset, repo.Name)
+		if _, err := os.Stat(repo.Name); err == nil && !cloneIncomplete(repo.Name) {
+			cloned[i] = true
+			fmt.Printf(" %s(already cloned)%s", lg.Green, lg.Reset)
+		} else if repo.Description != "" {
+			fmt.Printf(" - %s", repo.Description)
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+
+	var picked []int
+	if all {
+		for i := range repos {
+			picked = append(picked, i)
+		}
+	} else {
+		fmt.Printf("%sRepositories to fetch (e.g. 1,3-5 or all, empty to cancel):%s ", lg.Blue, lg.Reset)
+		response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && response == "" {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		picked, err = parseSelection(strings.TrimSpace(response), len(repos))
+		if err != nil {
+			return err
+		}
+	}
+
+	var fetched, failed []string
+	for _, i := range picked {
+		if cloned[i] {
+			continue
+		}
+		repo := &config.Repository{Organization: organization, Name: repos[i].Name}
+		fmt.Printf("\n%s==> %s%s\n", lg.Purple, repo.Name, lg.Reset)
+		if err := cloneAndSetup(userCfg, repo, repo.Name, opts, false); err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			failed = append(failed, repo.Name)
+			continue
+		}
+		fetched = append(fetched, repo.Name)
+	}
+
+	fmt.Println()
+	if len(fetched) > 0 {
+		lg.Printf("%s✨ Fetched %s%s\n", lg.Green, strings.Join(fetched, ", "), lg.Reset)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to fetch %s", strings.Join(failed, ", "))
+	}
+	if len(fetched) == 0 {
+		fmt.Printf("%sNothing to fetch%s\n", lg.Yellow, lg.Reset)
+	}
+	return nil
+}
+
+// parseSelection parses a selection like 1,3-5 or all of a numbered list
+// with n entries into indexes
+func parseSelection(selection string, n int) ([]int, error) {
+	if selection == "" {
+		return nil, nil
+	}
+	if selection == "all" {
+		picked := make([]int, n)
+		for i := range picked {
+			picked[i] = i
+		}
+		return picked, nil
+	}
+
+	var picked []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(selection, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		last := first
+		if err == nil && isRange {
+			last, err = strconv.Atoi(strings.TrimSpace(to))
+		}
+		if err != nil || first < 1 || last > n || first > last {
+			return nil, fmt.Errorf("invalid selection %q, pick numbers between 1 and %d", part, n)
+		}
+		for i := first - 1; i < last; i++ {
+			if !seen[i] {
+				seen[i] = true
+				picked = append(picked, i)
 			}
+		}
+	}
+	return picked, nil
+}
 
-			// Update repository information if it was provided via flag
-			if fetchRepoFlag != "" {
-				cfg.Repository = *repo
-				if err := cfg.Save(configPath); err != nil {
-					fmt.Printf("%sError updating config file: %v%s\n", lg.Red, err, lg.Reset)
-					os.Exit(1)
-				}
+// cloneAndSetup clones a repository into dir from the host of its
+// organization and runs its setup tasks or setup script, except those
+// --skip-setup skips. Projects without a spin.config.json are initialized
+// first. Resumable clones that were interrupted in dir are continued.
+func cloneAndSetup(userCfg *userconfig.Config, repo *config.Repository, dir string, opts cloneOptions, saveRepo bool) error {
+	host := userCfg.HostFor(repo.Organization)
+	if host.Host != forge.DefaultHost {
+		repo.Host = host.Host
+	}
+	var authEnv []string
+	if !userCfg.PreferSSH {
+		authEnv = forge.AuthEnv(host.GetProvider(), repo.GetHost())
+	}
+
+	if cloneIncomplete(dir) {
+		lg.Printf("%sResuming the clone of %s%s%s from %s...\n", lg.Blue, lg.Cyan, repo.GetFullName(), lg.Reset, repo.GetHost())
+		opts.Resumable = true
+	} else {
+		lg.Printf("%sCloning repository %s%s%s from %s...\n", lg.Blue, lg.Cyan, repo.GetFullName(), lg.Reset, repo.GetHost())
+	}
+	if opts.Resumable {
+		if err := resumableClone(authEnv, repo.GetCloneURL(userCfg.PreferSSH), dir, opts); err != nil {
+			return fmt.Errorf("failed to clone repository: %w, run the same spin fetch again to resume", err)
+		}
+	} else {
+		cloneArgs := []string{"clone"}
+		if opts.Branch != "" {
+			cloneArgs = append(cloneArgs, "--branch", opts.Branch)
+		}
+		if opts.Depth > 0 {
+			cloneArgs = append(cloneArgs, "--depth", strconv.Itoa(opts.Depth))
+		}
+		if opts.RecurseSubmodules {
+			cloneArgs = append(cloneArgs, "--recurse-submodules")
+			if opts.Depth > 0 {
+				cloneArgs = append(cloneArgs, "--shallow-submodules")
 			}
 		}
+		cloneArgs = append(cloneArgs, repo.GetCloneURL(userCfg.PreferSSH), dir)
+		gitCmd := exec.Command("git", cloneArgs...)
+		gitCmd.Env = append(os.Environ(), authEnv...)
+		gitCmd.Stdout = os.Stdout
+		gitCmd.Stderr = os.Stderr
+		if err := gitCmd.Run(); err != nil {
+			return fmt.Errorf("failed to clone repository: %w", err)
+		}
+	}
+
+	// Check for spin.config.json
+	configPath := filepath.Join(dir, "spin.config.json")
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		lg.Printf("%sNo spin.config.json found, running project detection...%s\n", lg.Blue, lg.Reset)
+
+		// Change to the app directory to run init
+		if err := os.Chdir(dir); err != nil {
+			return fmt.Errorf("failed to change to app directory: %w", err)
+		}
+
+		// Run the init command in the current directory
+		initArgs := []string{"."}
+		initCmd.Run(initCmd, initArgs)
 
-		// Load the final config to check for setup scripts
-		cfg, err := config.LoadConfig(configPath)
+		// Change back to the original directory
+		if err := os.Chdir(".."); err != nil {
+			return fmt.Errorf("failed to change back to original directory: %w", err)
+		}
+	} else if saveRepo {
+		// Update repository information if it was given explicitly
+		cfg, err := config.LoadRaw(configPath)
 		if err != nil {
-			fmt.Printf("%sError loading configuration: %v%s\n", lg.Red, err, lg.Reset)
-			os.Exit(1)
+			return fmt.Errorf("failed to load configuration: %w", err)
 		}
+		cfg.Repository = *repo
+		if err := cfg.Save(configPath); err != nil {
+			return fmt.Errorf("failed to update config file: %w", err)
+		}
+	}
 
-		// Run setup scripts if they exist and not skipped
-		if !skipSetup {
-			if setupScript, ok := cfg.Scripts["setup"]; ok {
-				fmt.Printf("\n%sRunning setup script...%s\n", lg.Blue, lg.Reset)
+	// Load the final config to check for setup scripts
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if setupSkipped() {
+		return nil
+	}
 
-				// Create a new script instance
-				s := &script.Script{
-					Name:        "setup",
-					Command:     setupScript.Command,
-					Description: setupScript.Description,
-					Env:         setupScript.Env,
-				}
+	// Run setup tasks or the setup script if they exist
+	if len(cfg.Setup) > 0 {
+		if unknown := setup.UnknownTasks(cfg, skipSetup); len(unknown) > 0 {
+			lg.Warnf("%s has no setup tasks named %s", repo.Name, strings.Join(unknown, ", "))
+		}
+		lg.Printf("\n%sRunning setup tasks...%s\n", lg.Blue, lg.Reset)
+		if err := setup.Run(cfg, dir, false, skipSetup); err != nil {
+			return fmt.Errorf("failed to run setup: %w", err)
+		}
+		lg.Printf("%sSetup completed successfully%s\n", lg.Green, lg.Reset)
+	} else if setupScript, ok := cfg.Scripts["setup"]; ok {
+		if len(skipSetup) > 0 {
+			lg.Warnf("%s has a setup script instead of setup tasks, running all of it", repo.Name)
+		}
+		lg.Printf("\n%sRunning setup script...%s\n", lg.Blue, lg.Reset)
 
-				// Execute the script with the proper working directory
-				opts := &script.RunOptions{
-					WorkDir: appName,
-					Env:     cfg.GetEnvVars("development"),
-				}
+		// Create a new script instance
+		s := &script.Script{
+			Name:        "setup",
+			Command:     setupScript.Command,
+			Description: setupScript.Description,
+			Env:         setupScript.Env,
+		}
 
-				if err := s.Execute(opts); err != nil {
-					fmt.Printf("%sError running setup script: %v%s\n", lg.Red, err, lg.Reset)
-					os.Exit(1)
-				}
-				fmt.Printf("%sSetup completed successfully%s\n", lg.Green, lg.Reset)
+		// Execute the script with the proper working directory
+		opts := &script.RunOptions{
+			WorkDir: dir,
+			Env:     cfg.GetEnvVars("development"),
+		}
+
+		if err := s.Execute(opts); err != nil {
+			return fmt.Errorf("failed to run setup script: %w", err)
+		}
+		lg.Printf("%sSetup completed successfully%s\n", lg.Green, lg.Reset)
+	}
+	return nil
+}
+
+// cloneIncomplete reports whether dir holds a resumable clone that was
+// interrupted
+func cloneIncomplete(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git", cloneMarker))
+	return err == nil
+}
+
+// resumableClone clones a repository into dir like git clone, in steps that
+// are kept when git is interrupted: the repository is initialized, the
+// latest commits of the branch are fetched and checked out, then the rest of
+// the history and the submodules are fetched. A marker in .git is removed
+// once all steps completed, until then running it again resumes.
+func resumableClone(authEnv []string, url string, dir string, opts cloneOptions) error {
+	git := func(args ...string) error {
+		c := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		c.Env = append(os.Environ(), authEnv...)
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		return c.Run()
+	}
+	// Fetches are tried again, since large repositories take long enough for
+	// connections to drop
+	retry := func(args ...string) error {
+		var err error
+		for attempt := 1; attempt <= fetchAttempts; attempt++ {
+			if err = git(args...); err == nil {
+				return nil
+			}
+			if attempt < fetchAttempts {
+				lg.Warnf("git %s failed, trying again (%d/%d)", args[0], attempt+1, fetchAttempts)
+				time.Sleep(time.Duration(attempt) * 2 * time.Second)
 			}
 		}
+		return err
+	}
 
-		fmt.Printf("\n%s✨ Successfully fetched %s%s%s\n", lg.Green, lg.Cyan, appName, lg.Reset)
-		fmt.Printf("%sRepository:%s %s\n", lg.Blue, lg.Reset, repo.GetFullName())
+	if !cloneIncomplete(dir) {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+			return fmt.Errorf("%s already exists and isn't empty", dir)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err := git("init", "--quiet"); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".git", cloneMarker), []byte(url+"\n"), 0644); err != nil {
+			return err
+		}
+		if err := git("remote", "add", "origin", url); err != nil {
+			return err
+		}
+	}
 
-		fmt.Printf("\n%sNext steps:%s\n", lg.Purple, lg.Reset)
-		if skipSetup {
-			fmt.Printf("  %s1.%s cd %s%s%s\n", lg.Yellow, lg.Reset, lg.Cyan, appName, lg.Reset)
-			fmt.Printf("  %s2.%s Review %sspin.config.json%s\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
-			fmt.Printf("  %s3.%s Run %sspin setup%s to install dependencies\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
-			fmt.Printf("  %s4.%s Run %sspin up%s to start development\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
-		} else {
-			fmt.Printf("  %s1.%s cd %s%s%s\n", lg.Yellow, lg.Reset, lg.Cyan, appName, lg.Reset)
-			fmt.Printf("  %s2.%s Run %sspin up%s to start development\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
+	branch := opts.Branch
+	if branch == "" {
+		var err error
+		if branch, err = remoteDefaultBranch(authEnv, url); err != nil {
+			return err
 		}
-	},
+	}
+
+	// The latest commits of the branch, so there is something to work with
+	// early on
+	if exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", "HEAD").Run() != nil {
+		depth := opts.Depth
+		if depth == 0 {
+			depth = 1
+		}
+		lg.Printf("%sFetching the latest commits of %s...%s\n", lg.Blue, branch, lg.Reset)
+		if err := retry("fetch", "--depth", strconv.Itoa(depth), "origin", "+refs/heads/"+branch+":refs/remotes/origin/"+branch); err != nil {
+			return err
+		}
+		if err := git("checkout", "--quiet", "-b", branch, "--track", "origin/"+branch); err != nil {
+			return err
+		}
+	}
+
+	// The rest of the history, with the other branches
+	if opts.Depth == 0 {
+		out, _ := exec.Command("git", "-C", dir, "rev-parse", "--is-shallow-repository").Output()
+		if strings.TrimSpace(string(out)) == "true" {
+			lg.Printf("%sFetching the rest of the history...%s\n", lg.Blue, lg.Reset)
+			if err := retry("fetch", "--unshallow", "origin"); err != nil {
+				return err
+			}
+		}
+		exec.Command("git", "-C", dir, "remote", "set-head", "origin", "--auto").Run()
+	}
+
+	if opts.RecurseSubmodules {
+		args := []string{"submodule", "update", "--init", "--recursive"}
+		if opts.Depth > 0 {
+			args = append(args, "--depth", strconv.Itoa(opts.Depth))
+		}
+		if err := retry(args...); err != nil {
+			return err
+		}
+	}
+	return os.Remove(filepath.Join(dir, ".git", cloneMarker))
+}
+
+// remoteDefaultBranch returns the branch HEAD of a remote repository points
+// to
+func remoteDefaultBranch(authEnv []string, url string) (string, error) {
+	c := exec.Command("git", "ls-remote", "--symref", url, "HEAD")
+	c.Env = append(os.Environ(), authEnv...)
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the default branch of %s: %w", url, err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if ref, ok := strings.CutPrefix(line, "ref: refs/heads/"); ok {
+			branch, _, _ := strings.Cut(ref, "\t")
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("%s has no default branch, pick one with --branch", url)
 }
 
 func init() {
 	rootCmd.AddCommand(fetchCmd)
 	fetchCmd.Flags().StringVar(&fetchRepoFlag, "repo", "", "Repository in format organization/name")
-	fetchCmd.Flags().BoolVar(&skipSetup, "skip-setup", false, "Skip running setup scripts")
+	fetchCmd.Flags().StringSliceVar(&skipSetup, "skip-setup", nil, "Skip running setup, or with =task1,task2 only those setup tasks")
+	fetchCmd.Flags().Lookup("skip-setup").NoOptDefVal = skipAllSetup
+	fetchCmd.Flags().String("branch", "", "Branch to clone, like @branch")
+	fetchCmd.Flags().Int("depth", 0, "Clone only this many commits of history")
+	fetchCmd.Flags().Bool("recurse-submodules", false, "Clone the submodules too")
+	fetchCmd.Flags().Bool("resumable", false, "Clone in steps that a later spin fetch resumes when interrupted")
+	fetchCmd.Flags().BoolVar(&fetchAll, "all", false, "Pick repositories of an organization with a spin.config.json to fetch")
+	fetchCmd.Flags().BoolP("yes", "y", false, "With --all, fetch every repository without asking")
 }
@@ -56,7 +56,7 @@ Example:
 
 		configPath := filepath.Join(appPath, "spin.config.json")
 		if config.Exists(configPath) && !force {
-			fmt.Printf("%sWarning: spin.config.json already exists in %s%s\n", logger.Yellow, appPath, logger.Reset)
+			logger.Warnf("spin.config.json already exists in %s", appPath)
 			fmt.Printf("%sDo you want to overwrite it? (y/N)%s\n", logger.Blue, logger.Reset)
 
 			reader := bufio.NewReader(os.Stdin)
@@ -104,10 +104,10 @@ Example:
 		}
 
 		// Detect project type and configuration
-		fmt.Printf("\n%sAnalyzing project structure...%s\n", logger.Blue, logger.Reset)
+		logger.Printf("\n%sAnalyzing project structure...%s\n", logger.Blue, logger.Reset)
 		detected, err := config.DetectProjectType(appPath)
 		if err != nil {
-			fmt.Printf("%sWarning: Could not detect project type: %v%s\n", logger.Yellow, err, logger.Reset)
+			logger.Warnf("Could not detect project type: %v", err)
 			detected = &config.Config{
 				Type: "unknown",
 			}
@@ -153,27 +153,27 @@ Example:
 
 		// Add detected configurations
 		if detected != nil && detected.Rails != nil {
-			fmt.Printf("\n%sDetected Rails application:%s\n", logger.Blue, logger.Reset)
+			logger.Printf("\n%sDetected Rails application:%s\n", logger.Blue, logger.Reset)
 
 			// Ruby version
 			if detected.Rails.Ruby.Version != "" {
-				fmt.Printf("  %s✓%s Ruby Version: %s%s%s\n", logger.Green, logger.Reset, logger.Cyan, detected.Rails.Ruby.Version, logger.Reset)
+				logger.Printf("  %s✓%s Ruby Version: %s%s%s\n", logger.Green, logger.Reset, logger.Cyan, detected.Rails.Ruby.Version, logger.Reset)
 			} else {
 				fmt.Printf("  %s⚠%s Ruby Version: %snot found%s\n", logger.Yellow, logger.Reset, logger.Red, logger.Reset)
 			}
 
 			// Rails version
 			if detected.Rails.Rails.Version != "" {
-				fmt.Printf("  %s✓%s Rails Version: %s%s%s\n", logger.Green, logger.Reset, logger.Cyan, detected.Rails.Rails.Version, logger.Reset)
+				logger.Printf("  %s✓%s Rails Version: %s%s%s\n", logger.Green, logger.Reset, logger.Cyan, detected.Rails.Rails.Version, logger.Reset)
 			} else {
 				fmt.Printf("  %s⚠%s Rails Version: %snot found%s\n", logger.Yellow, logger.Reset, logger.Red, logger.Reset)
 			}
 
 			// Database
 			if detected.Rails.Database.Type != "" {
-				fmt.Printf("  %s✓%s Database: %s%s%s\n", logger.Green, logger.Reset, logger.Cyan, detected.Rails.Database.Type, logger.Reset)
+				logger.Printf("  %s✓%s Database: %s%s%s\n", logger.Green, logger.Reset, logger.Cyan, detected.Rails.Database.Type, logger.Reset)
 				for key, value := range detected.Rails.Database.Settings {
-					fmt.Printf("    %s-%s %s: %s%s%s\n", logger.Blue, logger.Reset, key, logger.Cyan, value, logger.Reset)
+					logger.Printf("    %s-%s %s: %s%s%s\n", logger.Blue, logger.Reset, key, logger.Cyan, value, logger.Reset)
 				}
 			} else {
 				fmt.Printf("  %s⚠%s Database: %snot configured%s\n", logger.Yellow, logger.Reset, logger.Red, logger.Reset)
@@ -181,14 +181,17 @@ Example:
 
 			// Services
 			if detected.Rails.Services.Redis {
-				fmt.Printf("  %s✓%s Redis: %senabled%s\n", logger.Green, logger.Reset, logger.Cyan, logger.Reset)
+				logger.Printf("  %s✓%s Redis: %senabled%s\n", logger.Green, logger.Reset, logger.Cyan, logger.Reset)
 			}
 			if detected.Rails.Services.Sidekiq {
-				fmt.Printf("  %s✓%s Sidekiq: %senabled%s\n", logger.Green, logger.Reset, logger.Cyan, logger.Reset)
+				logger.Printf("  %s✓%s Sidekiq: %senabled%s\n", logger.Green, logger.Reset, logger.Cyan, logger.Reset)
+			}
+			if detected.Rails.Services.AnyCable != "" {
+				logger.Printf("  %s✓%s AnyCable: %sRPC over %s%s\n", logger.Green, logger.Reset, logger.Cyan, detected.Rails.Services.AnyCable, logger.Reset)
 			}
 
 			// Scripts
-			fmt.Printf("\n%sGenerated Scripts:%s\n", logger.Blue, logger.Reset)
+			logger.Printf("\n%sGenerated Scripts:%s\n", logger.Blue, logger.Reset)
 			if script, ok := cfg.Scripts["setup"]; ok {
 				fmt.Printf("  %ssetup:%s %s\n", logger.Purple, logger.Reset, script.Command)
 			}
@@ -206,9 +209,9 @@ Example:
 			os.Exit(1)
 		}
 
-		fmt.Printf("\n%s✨ Successfully initialized %s%s%s\n", logger.Green, logger.Cyan, appName, logger.Reset)
-		fmt.Printf("%sRepository:%s %s\n", logger.Blue, logger.Reset, cfg.Repository.GetFullName())
-		fmt.Printf("%sConfiguration:%s %s\n", logger.Blue, logger.Reset, configPath)
+		logger.Printf("\n%s✨ Successfully initialized %s%s%s\n", logger.Green, logger.Cyan, appName, logger.Reset)
+		logger.Printf("%sRepository:%s %s\n", logger.Blue, logger.Reset, cfg.Repository.GetFullName())
+		logger.Printf("%sConfiguration:%s %s\n", logger.Blue, logger.Reset, configPath)
 
 		fmt.Printf("\n%sNext steps:%s\n", logger.Purple, logger.Reset)
 		fmt.Printf("  %s1.%s cd %s%s%s\n", logger.Yellow, logger.Reset, logger.Cyan, appName, logger.Reset)
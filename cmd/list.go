@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the running projects",
+	Long: `List the projects running on this machine, whichever directory they were
+started from, with the number of processes they run, the URL of their web
+process and their directory.
+
+spin up registers projects in ~/.spin/registry.json and spin down removes
+them. Projects whose processes are all gone are dropped from the list.
+
+Example:
+  spin list`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := registry.List()
+		if err != nil {
+			fmt.Printf("%sError reading the registry: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		running := runningProcessCounts()
+		var stale []string
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%sPROJECT\tPROCESSES\tURL\tUPTIME\tDIRECTORY%s\n", lg.Cyan, lg.Reset)
+		rows := 0
+		for _, entry := range entries {
+			count := running[entry.Name]
+			if count == 0 {
+				stale = append(stale, entry.Name)
+				continue
+			}
+			url := entry.URL()
+			if url == "" {
+				url = "-"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", entry.Name, count, url, process.FormatUptime(time.Since(entry.StartedAt)), entry.Dir)
+			rows++
+		}
+		if rows == 0 {
+			fmt.Fprintf(w, "%sNo running projects%s\n", lg.Yellow, lg.Reset)
+		}
+		w.Flush()
+
+		if len(stale) > 0 {
+			if err := registry.Unregister(stale...); err != nil {
+				lg.Warnf("%v", err)
+			}
+		}
+	},
+}
+
+// runningProcessCounts returns how many processes of each project are alive,
+// services left out
+func runningProcessCounts() map[string]int {
+	counts := make(map[string]int)
+	entries, err := process.GetManager(nil).Store().Entries()
+	if err != nil {
+		return counts
+	}
+	for _, info := range entries {
+		if info.Type != process.ProcessTypeDocker && process.IsAlive(info.Pid) {
+			counts[info.AppName]++
+		}
+	}
+	return counts
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+}
@@ -5,11 +5,12 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
-	"path/filepath"
 	"syscall"
 
 	"github.com/afomera/spin/internal/config"
 	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/script"
+	"github.com/afomera/spin/internal/spinerr"
 	"github.com/spf13/cobra"
 )
 
@@ -20,46 +21,65 @@ var logsCmd = &cobra.Command{
 	Long: `View the logs for a running process.
 Shows the process output in real-time.
 
+Without a name, a list of the running processes to pick from by typing part
+of the name is shown.
+
 Example:
+  spin logs         # Pick the process
   spin logs web     # View web process logs
-  spin logs worker  # View worker process logs`,
-	Args: cobra.ExactArgs(1),
+  spin logs worker  # View worker process logs
+  spin logs scripts # View the output of scripts`,
+	Args:              pickableArg,
+	ValidArgsFunction: firstArg(completeLogNames),
 	Run: func(cmd *cobra.Command, args []string) {
-		processName := args[0]
-
 		// Load configuration
 		cfg, err := config.LoadConfig("spin.config.json")
 		if err != nil {
-			fmt.Printf("Error loading configuration: %v\n", err)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		var processName string
+		if len(args) > 0 {
+			processName = args[0]
+		} else {
+			processName = pickName("process", "Show the logs of:", namesToOptions(append(processNames(), script.OutputLogName)))
 		}
 
 		// Get the process manager instance
 		manager := process.GetManager(cfg)
 
-		// Check if process exists
+		// Check if process exists, the output of scripts is logged under a
+		// name of its own
+		var logFile string
 		if _, err := manager.GetProcessStatus(cfg.Name, processName); err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+			if processName != script.OutputLogName {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			logFile, err = script.OutputPath(cfg.Name)
+			if err == nil {
+				_, err = os.Stat(logFile)
+			}
+			if err != nil {
+				fmt.Printf("Error: no script output recorded yet\n")
+				os.Exit(1)
+			}
+		} else {
+			// Find the process to get its log file path
+			proc, err := manager.FindProcess(processName)
+			if err != nil {
+				fmt.Printf("Error finding process: %v\n", err)
+				os.Exit(1)
+			}
+
+			// Use app-specific log directory
+			logFile, err = process.LogPath(proc.AppName, proc.Name)
+			if err != nil {
+				fmt.Printf("Error getting the log file: %v\n", err)
+				os.Exit(1)
+			}
 		}
 
-		// Find the process to get its log file path
-		proc, err := manager.FindProcess(processName)
-		if err != nil {
-			fmt.Printf("Error finding process: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Get spin directory
-		home, err := os.UserHomeDir()
-		if err != nil {
-			fmt.Printf("Error getting home directory: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Use app-specific log directory
-		logFile := filepath.Join(home, ".spin", "output", process.SanitizeAppName(proc.AppName), fmt.Sprintf("%s.log", proc.Name))
-
 		// First show recent output
 		tail := exec.Command("tail", "-n", "50", logFile)
 		tail.Stdout = os.Stdout
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/notify"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// monitorProcessName is the name spin up runs the crash monitor under
+const monitorProcessName = "spin-monitor"
+
+// monitorCmd publishes crash and health events and turns them into
+// notifications. spin up starts it in the background.
+var monitorCmd = &cobra.Command{
+	Use:    "monitor",
+	Short:  "Report crashed processes and unhealthy services",
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig("spin.config.json")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		interval, _ := cmd.Flags().GetDuration("interval")
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Printf("%sMonitoring %s for crashes and unhealthy services%s\n", lg.Blue, cfg.Name, lg.Reset)
+		notify.Monitor(ctx, cfg, monitorProcessName, interval)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+	monitorCmd.Flags().Duration("interval", notify.DefaultInterval, "Time between checks")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/procfile"
+	"github.com/afomera/spin/internal/registry"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// portFlagPattern matches the port a server is told to listen on, like
+// bin/rails server -p 3000
+var portFlagPattern = regexp.MustCompile(`(?:^|\s)(?:-p|--port)[\s=](\d+)`)
+
+// openCmd represents the open command
+var openCmd = &cobra.Command{
+	Use:   "open [project]",
+	Short: "Open a running project in the browser",
+	Long: `Open the web process of a running project in the browser, the project in
+the current directory by default. Projects are found by name in the registry
+spin up keeps in ~/.spin/registry.json, so they can be opened from anywhere.
+
+Example:
+  spin open              # Open the project in the current directory
+  spin open myapp        # Open another running project
+  spin open --print      # Only print the URL`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		} else {
+			cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+			if err != nil {
+				spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err).
+					WithFix("Run spin open <project> outside of a project, spin list shows the running ones"))
+			}
+			name = cfg.Name
+		}
+
+		entry, ok, err := registry.Get(name)
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		if !ok {
+			fmt.Printf("%s%s is not running, start it with spin up%s\n", lg.Red, name, lg.Reset)
+			os.Exit(1)
+		}
+		if entry.URL() == "" {
+			fmt.Printf("%s%s has no web process%s\n", lg.Red, name, lg.Reset)
+			os.Exit(1)
+		}
+
+		if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+			fmt.Println(entry.URL())
+			return
+		}
+		fmt.Printf("Opening %s%s%s\n", lg.Cyan, entry.URL(), lg.Reset)
+		if err := openBrowser(entry.URL()); err != nil {
+			fmt.Printf("%sError opening the browser: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+	},
+}
+
+// webPort returns the port the web process of a project listens on: the
+// PORT it gets, or the port of its command. Projects without a web process
+// have none.
+func webPort(entries []procfile.Entry, ports map[string]int) int {
+	for _, entry := range entries {
+		if config.ProcessType(entry.Name) != "web" {
+			continue
+		}
+		if port, ok := ports[entry.Name]; ok {
+			return port
+		}
+		return commandPort(entry.Command)
+	}
+	return 0
+}
+
+// commandPort returns the port a command tells a server to listen on with
+// -p or --port, or 3000 like Rails and most dev servers
+func commandPort(command string) int {
+	if match := portFlagPattern.FindStringSubmatch(command); match != nil {
+		if port, err := strconv.Atoi(match[1]); err == nil {
+			return port
+		}
+	}
+	return 3000
+}
+
+// openBrowser opens a URL in the default browser
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().Bool("print", false, "Print the URL instead of opening it")
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// maxPickerRows is how many options the picker shows at once
+const maxPickerRows = 10
+
+// pickOption is a name the picker offers, with a note shown next to it
+type pickOption struct {
+	Name   string
+	Detail string
+}
+
+// namesToOptions returns options for names without notes
+func namesToOptions(names []string) []pickOption {
+	options := make([]pickOption, len(names))
+	for i, name := range names {
+		options[i] = pickOption{Name: name}
+	}
+	return options
+}
+
+// canPick reports whether a picker can be shown, which needs a terminal to
+// read keys from and draw on
+func canPick() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// pickableArg accepts one name, or none when it can be picked instead
+func pickableArg(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && canPick() {
+		return nil
+	}
+	return cobra.ExactArgs(1)(cmd, args)
+}
+
+// pickName returns the option picked by fuzzy search, for commands called
+// without the name they need. what names the kind of option, like
+// "process". It exits when there is nothing to pick from or the picker is
+// cancelled.
+func pickName(what string, title string, options []pickOption) string {
+	if len(options) == 0 {
+		spinerr.Exit(spinerr.New(spinerr.Execution, fmt.Sprintf("no %s given", what), fmt.Sprintf("there is no %s to pick from", what)))
+	}
+	if !canPick() {
+		names := make([]string, len(options))
+		for i, option := range options {
+			names[i] = option.Name
+		}
+		spinerr.Exit(spinerr.New(spinerr.Execution, fmt.Sprintf("no %s given", what)).
+			WithFix(fmt.Sprintf("Name one of %s", strings.Join(names, ", "))))
+	}
+
+	final, err := tea.NewProgram(newPickerModel(title, options)).Run()
+	if err != nil {
+		spinerr.Exit(fmt.Errorf("failed to run the picker: %w", err))
+	}
+	picked := final.(*pickerModel).picked
+	if picked == "" {
+		os.Exit(spinerr.ExitFailure)
+	}
+	return picked
+}
+
+// pickerModel is a list of options filtered by what is typed
+type pickerModel struct {
+	title   string
+	options []pickOption
+	input   textinput.Model
+	matches []pickMatch
+	cursor  int
+	picked  string
+	done    bool // Picked or cancelled, the picker is cleared
+}
+
+// pickMatch is an option matching the filter, with the positions of the
+// matched characters
+type pickMatch struct {
+	option    pickOption
+	score     int
+	positions []int
+}
+
+// newPickerModel creates a picker of options, listed in their order until
+// something is typed
+func newPickerModel(title string, options []pickOption) *pickerModel {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "type to filter"
+	input.CharLimit = 64
+	input.Focus()
+	m := &pickerModel{title: title, options: options, input: input}
+	m.filter()
+	return m
+}
+
+func (m *pickerModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.done = true
+			return m, tea.Quit
+		case "up", "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case "enter":
+			if len(m.matches) > 0 {
+				m.picked = m.matches[m.cursor].option.Name
+				m.done = true
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	previous := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != previous {
+		m.filter()
+	}
+	return m, cmd
+}
+
+// filter updates the matches for the text typed, best matches first
+func (m *pickerModel) filter() {
+	query := m.input.Value()
+	m.matches = m.matches[:0]
+	for _, option := range m.options {
+		if score, positions, ok := fuzzyMatch(query, option.Name); ok {
+			m.matches = append(m.matches, pickMatch{option: option, score: score, positions: positions})
+		}
+	}
+	sort.SliceStable(m.matches, func(i, j int) bool {
+		return m.matches[i].score > m.matches[j].score
+	})
+	m.cursor = 0
+}
+
+func (m *pickerModel) View() string {
+	if m.done {
+		return ""
+	}
+	s := strings.Builder{}
+	s.WriteString(m.title + "\n\n")
+	s.WriteString(m.input.View() + "\n\n")
+
+	// Scroll so the cursor stays in view
+	start := 0
+	if m.cursor >= maxPickerRows {
+		start = m.cursor - maxPickerRows + 1
+	}
+	width := 0
+	for _, match := range m.matches {
+		width = max(width, len(match.option.Name))
+	}
+	for i := start; i < len(m.matches) && i < start+maxPickerRows; i++ {
+		match := m.matches[i]
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		name := highlight(match.option.Name, match.positions)
+		padding := strings.Repeat(" ", width-len(match.option.Name))
+		s.WriteString(fmt.Sprintf("%s %s%s  %s%s%s\n", cursor, name, padding, lg.Blue, match.option.Detail, lg.Reset))
+	}
+	if len(m.matches) == 0 {
+		s.WriteString("  No matches\n")
+	}
+	s.WriteString(fmt.Sprintf("\n%d/%d · ↑/↓ to move · enter to pick · esc to cancel\n", len(m.matches), len(m.options)))
+	return s.String()
+}
+
+// highlight colors the characters of name at positions
+func highlight(name string, positions []int) string {
+	if len(positions) == 0 {
+		return name
+	}
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p] = true
+	}
+	var b strings.Builder
+	for i, r := range name {
+		if matched[i] {
+			b.WriteString(lg.Cyan + string(r) + lg.Reset)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// fuzzyMatch reports whether the characters of query appear in name in
+// order, ignoring case, and scores the match: characters in a row and at the
+// start of words count more, so "db" ranks "db-replica" above "dashboard". It
+// returns the byte positions of the matched characters.
+func fuzzyMatch(query string, name string) (int, []int, bool) {
+	if query == "" {
+		return 0, nil, true
+	}
+	q := []rune(strings.ToLower(query))
+	var positions []int
+	score, qi, last := 0, 0, -2
+	prev := rune(0)
+	for i, r := range strings.ToLower(name) {
+		if qi < len(q) && r == q[qi] {
+			positions = append(positions, i)
+			score++
+			if i == last+1 {
+				score += 2
+			}
+			if i == 0 || !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+				score += 3
+			}
+			last = i
+			qi++
+		}
+		prev = r
+	}
+	if qi < len(q) {
+		return 0, nil, false
+	}
+	// Shorter names are closer to what was typed
+	return score*100 - len(name), positions, true
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// prefetchCmd represents the prefetch command
+var prefetchCmd = &cobra.Command{
+	Use:   "prefetch",
+	Short: "Pull the Docker images of the project ahead of time",
+	Long: `Pull the images of the project's services and of its dev container in
+parallel, so spin up doesn't have to download them later, like before a flight
+or when setting up a new laptop. The images of the apps of a monorepo and of
+spin.config.local.json are included, services with pull_policy "never" are
+left out.
+
+With --all, the images of every project spin remembers (see spin projects)
+are pulled, each of them once.
+
+Example:
+  spin prefetch              # Pull the images of the project
+  spin prefetch --all        # Pull the images of every project
+  spin prefetch --missing    # Only pull images that aren't there yet
+  spin prefetch --jobs 2     # Pull two images at a time`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		missing, _ := cmd.Flags().GetBool("missing")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		if jobs < 1 {
+			jobs = 1
+		}
+
+		// The image each project and service uses, so they are pulled once
+		users := make(map[string][]string)
+		if all {
+			for _, dir := range projectDirs(loadUserConfig()) {
+				if projectMissing(dir) {
+					continue
+				}
+				cfg, err := config.LoadProject(filepath.Join(dir, "spin.config.json"))
+				if err != nil {
+					lg.Warnf("skipping %s: %v", dir, err)
+					continue
+				}
+				addProjectImages(users, cfg, cfg.Name+"/")
+			}
+		} else {
+			cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+			if err != nil {
+				spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+			}
+			addProjectImages(users, cfg, "")
+		}
+		if len(users) == 0 {
+			fmt.Printf("%sNo images to pull%s\n", lg.Yellow, lg.Reset)
+			return
+		}
+
+		dm, err := docker.NewServiceManager("./data")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
+		}
+		if _, err := dm.Client().Ping(context.Background()); err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "Docker isn't running", err).
+				WithFix("Start Docker Desktop, or the Docker daemon with 'sudo systemctl start docker'"))
+		}
+		images := make([]string, 0, len(users))
+		for image := range users {
+			if missing && dm.ImageExists(image) {
+				continue
+			}
+			images = append(images, image)
+		}
+		sort.Strings(images)
+		if len(images) == 0 {
+			lg.Printf("%sAll %d images are there already%s\n", lg.Green, len(users), lg.Reset)
+			return
+		}
+
+		if jobs > len(images) {
+			jobs = len(images)
+		}
+		lg.Printf("%sPulling %d images, %d at a time...%s\n", lg.Blue, len(images), jobs, lg.Reset)
+		started := time.Now()
+		var (
+			mu     sync.Mutex
+			wg     sync.WaitGroup
+			failed []string
+		)
+		sem := make(chan struct{}, jobs)
+		for _, image := range images {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(image string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				start := time.Now()
+				err := dm.PullImageQuietly(image)
+
+				mu.Lock()
+				defer mu.Unlock()
+				used := strings.Join(users[image], ", ")
+				if err != nil {
+					failed = append(failed, image)
+					fmt.Printf("  %s✗%s %s %s(%s)%s: %v\n", lg.Red, lg.Reset, image, lg.Blue, used, lg.Reset, err)
+					return
+				}
+				lg.Printf("  %s✓%s %s %s(%s)%s %s\n", lg.Green, lg.Reset, image, lg.Blue, used, lg.Reset, time.Since(start).Round(100*time.Millisecond))
+			}(image)
+		}
+		wg.Wait()
+
+		if len(failed) > 0 {
+			fmt.Printf("%sFailed to pull %s%s\n", lg.Red, strings.Join(failed, ", "), lg.Reset)
+			os.Exit(1)
+		}
+		lg.Printf("%sPulled %d images in %s%s\n", lg.Green, len(images), time.Since(started).Round(time.Second), lg.Reset)
+	},
+}
+
+// addProjectImages records the images of a project's services and dev
+// container in users, under their names prefixed with prefix
+func addProjectImages(users map[string][]string, cfg *config.Config, prefix string) {
+	for name, svc := range cfg.Services {
+		if svc == nil || svc.Image == "" || svc.PullPolicy == docker.PullNever {
+			continue
+		}
+		users[svc.Image] = append(users[svc.Image], prefix+name)
+	}
+	// Dev containers built from a Dockerfile are built by spin up
+	if dev := cfg.DevContainer; dev != nil && dev.Image != "" && dev.Dockerfile == "" {
+		users[dev.Image] = append(users[dev.Image], prefix+"dev container")
+	}
+	for image := range users {
+		sort.Strings(users[image])
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(prefetchCmd)
+	prefetchCmd.Flags().Bool("all", false, "Pull the images of every project spin remembers")
+	prefetchCmd.Flags().Bool("missing", false, "Only pull images that aren't there yet")
+	prefetchCmd.Flags().Int("jobs", 4, "Number of images to pull at a time")
+}
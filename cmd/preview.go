@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/procfile"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// previewCmd represents the preview command
+var previewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Run a throwaway copy of the environment",
+	Long: `Run an isolated copy of the development environment, to review a branch
+without touching the services, data and processes of spin up.
+
+The preview gets a name of its own, like myapp-preview-3f2a. Its Docker
+services run as separate containers on random free ports with empty volumes,
+and its processes get random free ports too. Addresses of services in the
+development env, like localhost:5432, are rewritten to the new ports, and
+PGHOST, PGPORT, DATABASE_URL, REDIS_URL, MONGODB_URL and <SERVICE>_HOST,
+_PORT and _URL point at the preview's services, so nothing reaches the
+databases of the project. Rails apps get their database with rails db:prepare.
+
+The URLs of the processes are printed once everything runs. Press Ctrl+C, or
+close the terminal, to stop the preview, which removes its processes,
+containers and volumes.
+
+Example:
+  spin preview                    # Start a preview of the current branch
+  spin preview --only web         # Only start some processes
+  spin preview --name review-42   # Name the preview myapp-review-42`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		only, _ := cmd.Flags().GetStringSlice("only")
+		except, _ := cmd.Flags().GetStringSlice("except")
+		entries, err := procfile.Resolve(cfg, ".", procfile.Selection{Only: only, Except: except})
+		if err != nil {
+			fmt.Printf("%sError reading processes: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		suffix, _ := cmd.Flags().GetString("name")
+		if suffix == "" {
+			suffix = previewSuffix()
+		}
+		preview, err := newPreviewConfig(cfg, suffix)
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		lg.Printf("%sStarting preview %s%s%s...%s\n", lg.Blue, lg.Cyan, preview.Name, lg.Blue, lg.Reset)
+
+		// Ctrl+C or closing the terminal destroys the preview, also while it is starting
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		defer stop()
+
+		dm, err := docker.NewServiceManager("./data")
+		if err != nil && len(preview.Services) > 0 {
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
+		}
+		processManager := process.GetManager(preview)
+		destroy := func() {
+			destroyPreview(preview, dm, processManager, entries)
+		}
+
+		if len(preview.Services) > 0 {
+			lg.Printf("%sStarting services...%s\n", lg.Blue, lg.Reset)
+			if err := dm.StartServices(preview.Services, preview.Dependencies.Services); err != nil {
+				fmt.Printf("%sError starting services: %v%s\n", lg.Red, err, lg.Reset)
+				destroy()
+				os.Exit(1)
+			}
+		}
+
+		env := rubyEnv(processEnv(preview, "."), ".", true)
+		if _, err := os.Stat("Gemfile"); err == nil && ctx.Err() == nil {
+			lg.Printf("%sPreparing the database...%s\n", lg.Blue, lg.Reset)
+			prepare := exec.CommandContext(ctx, "bundle", "exec", "rails", "db:prepare")
+			prepare.Env = env
+			prepare.Stdout = os.Stdout
+			prepare.Stderr = os.Stderr
+			if err := prepare.Run(); err != nil && ctx.Err() == nil {
+				fmt.Printf("%sError preparing the database: %v%s\n", lg.Red, err, lg.Reset)
+				destroy()
+				os.Exit(1)
+			}
+		}
+		if usesAppImage(entries) && ctx.Err() == nil {
+			ensureAppImage(preview, ".")
+		}
+
+		ports := make(map[string]int)
+		for _, entry := range entries {
+			if ctx.Err() != nil {
+				break
+			}
+			port, err := freePort()
+			if err != nil {
+				fmt.Printf("%sError finding a free port: %v%s\n", lg.Red, err, lg.Reset)
+				destroy()
+				os.Exit(1)
+			}
+			ports[entry.Name] = port
+
+			command, args := procfile.SplitCommand(entry.Command)
+			if imageCommand, ok := procfile.ImageCommand(entry.Command); ok {
+				imageEnv := developmentEnv(preview, ".")
+				imageEnv["PORT"], imageEnv["PS"] = strconv.Itoa(port), entry.Name
+				command, args = imageRunArgs(preview, entry.Name, imageEnv, port, imageCommand)
+			}
+			entryEnv := append(env[:len(env):len(env)], fmt.Sprintf("PORT=%d", port), "PS="+entry.Name)
+			for key, value := range entry.Env {
+				entryEnv = append(entryEnv, key+"="+value)
+			}
+			if err := processManager.StartProcess(preview.Name, entry.Name, command, args, entryEnv, filepath.Join(".", entry.Dir)); err != nil {
+				fmt.Printf("%sError starting process %s: %v%s\n", lg.Red, entry.Name, err, lg.Reset)
+				destroy()
+				os.Exit(1)
+			}
+		}
+
+		if ctx.Err() == nil {
+			lg.Printf("\n%sPreview %s is running:%s\n", lg.Green, preview.Name, lg.Reset)
+			for _, entry := range entries {
+				fmt.Printf("  %-12s http://localhost:%d\n", entry.Name, ports[entry.Name])
+			}
+			for _, name := range preview.Dependencies.Services {
+				if svc, ok := preview.Services[name]; ok && svc.PublishedPort() != 0 {
+					fmt.Printf("  %-12s localhost:%d\n", strings.TrimSuffix(name, "-"+suffix), svc.PublishedPort())
+				}
+			}
+			fmt.Printf("\n%sLogs are in ~/.spin/output/%s. Press Ctrl+C to destroy the preview.%s\n", lg.Yellow, process.SanitizeAppName(preview.Name), lg.Reset)
+			<-ctx.Done()
+		}
+
+		fmt.Println()
+		destroy()
+	},
+}
+
+// previewSuffix returns a random suffix that tells previews apart
+func previewSuffix() string {
+	b := make([]byte, 2)
+	rand.Read(b)
+	return "preview-" + hex.EncodeToString(b)
+}
+
+// newPreviewConfig returns the config of a preview of a project: the project
+// renamed with the suffix, and its Docker services renamed too, isolated and
+// published on free ports. Addresses of the services in the env are moved to
+// the new ports.
+func newPreviewConfig(cfg *config.Config, suffix string) (*config.Config, error) {
+	preview := *cfg
+	preview.Name = cfg.Name + "-" + suffix
+	preview.Services = make(map[string]*config.DockerServiceConfig)
+	preview.Dependencies.Services = nil
+	if cfg.Build == nil || cfg.Build.Image == "" {
+		// Previews run the app image of the project
+		build := config.BuildConfig{}
+		if cfg.Build != nil {
+			build = *cfg.Build
+		}
+		build.Image = cfg.GetImage()
+		preview.Build = &build
+	}
+
+	var replacements []string
+	serviceEnv := make(map[string]string)
+	for _, name := range cfg.Dependencies.Services {
+		svc, ok := cfg.Services[name]
+		if !ok {
+			svc = config.GetDefaultDockerConfig(name)
+		}
+		if svc == nil {
+			// Services like sqlite3 don't run in a container
+			continue
+		}
+		port := 0
+		if svc.Port != 0 {
+			var err error
+			if port, err = freePort(); err != nil {
+				return nil, fmt.Errorf("failed to find a free port: %w", err)
+			}
+		}
+		previewName := name + "-" + suffix
+		preview.Services[previewName] = docker.IsolatedService(previewName, svc, port)
+		preview.Dependencies.Services = append(preview.Dependencies.Services, previewName)
+		if port != 0 {
+			for _, host := range []string{"localhost", "127.0.0.1"} {
+				replacements = append(replacements, fmt.Sprintf("%s:%d", host, svc.PublishedPort()), fmt.Sprintf("%s:%d", host, port))
+			}
+			addPreviewServiceEnv(serviceEnv, name, preview.Services[previewName])
+		}
+	}
+	// Services depend on each other under their new names
+	for _, svc := range preview.Services {
+		dependsOn := make([]string, len(svc.DependsOn))
+		for i, dep := range svc.DependsOn {
+			dependsOn[i] = dep + "-" + suffix
+		}
+		svc.DependsOn = dependsOn
+	}
+
+	// Addresses are rewritten, and the variables naming the services are
+	// set whatever the project has, so a default like the PostgreSQL socket
+	// never reaches the databases of the project
+	replacer := strings.NewReplacer(replacements...)
+	preview.Env = make(map[string]config.EnvMap)
+	for name, env := range cfg.Env {
+		moved := make(config.EnvMap)
+		for key, value := range env {
+			moved[key] = replacer.Replace(value)
+		}
+		preview.Env[name] = moved
+	}
+	if preview.Env["development"] == nil {
+		preview.Env["development"] = make(config.EnvMap)
+	}
+	for key, value := range serviceEnv {
+		preview.Env["development"][key] = value
+	}
+	if cfg.Foreman != nil {
+		foreman := *cfg.Foreman
+		foreman.Env = make(map[string]string)
+		for key, value := range cfg.Foreman.Env {
+			foreman.Env[key] = replacer.Replace(value)
+		}
+		preview.Foreman = &foreman
+	}
+	return &preview, nil
+}
+
+// addPreviewServiceEnv adds the variables pointing at a service of a preview
+// to env: <SERVICE>_HOST, _PORT and _URL under its name in the project, and
+// those the clients of its database read. The first service of each database
+// gets DATABASE_URL, REDIS_URL or MONGODB_URL.
+func addPreviewServiceEnv(env map[string]string, name string, svc *config.DockerServiceConfig) {
+	port := strconv.Itoa(svc.PublishedPort())
+	prefix := envNamePattern.ReplaceAllString(strings.ToUpper(name), "_")
+	env[prefix+"_HOST"] = "localhost"
+	env[prefix+"_PORT"] = port
+	url := svc.URL()
+	if url != "" {
+		env[prefix+"_URL"] = url
+	}
+
+	first := func(key string) {
+		if _, ok := env[key]; !ok && url != "" {
+			env[key] = url
+		}
+	}
+	switch svc.Engine() {
+	case config.EnginePostgres:
+		if _, ok := env["PGPORT"]; !ok {
+			env["PGHOST"] = "localhost"
+			env["PGPORT"] = port
+		}
+		first("DATABASE_URL")
+	case config.EngineMySQL:
+		first("DATABASE_URL")
+	case config.EngineRedis:
+		first("REDIS_URL")
+	case config.EngineMongo:
+		first("MONGODB_URL")
+	}
+}
+
+// destroyPreview stops the processes of a preview and removes its containers
+// and volumes
+func destroyPreview(preview *config.Config, dm *docker.ServiceManager, processManager *process.Manager, entries []procfile.Entry) {
+	lg.Printf("%sDestroying preview %s...%s\n", lg.Blue, preview.Name, lg.Reset)
+	for _, entry := range entries {
+		if _, err := processManager.FindProcess(entry.Name); err != nil {
+			continue
+		}
+		if err := processManager.StopProcess(preview.Name, entry.Name); err != nil {
+			lg.Warnf("Failed to stop %s: %v", entry.Name, err)
+		}
+	}
+	if dm == nil {
+		return
+	}
+	if usesAppImage(entries) {
+		if err := dm.RemoveProcessContainers(preview.Name); err != nil {
+			lg.Warnf("%v", err)
+		}
+	}
+	for _, name := range preview.Dependencies.Services {
+		svc := preview.Services[name]
+		if dm.IsRunning(name) {
+			if err := dm.StopService(name, svc); err != nil {
+				lg.Warnf("Failed to stop %s: %v", name, err)
+			}
+		}
+		if err := dm.RemoveService(name, true); err != nil {
+			continue
+		}
+		if err := dm.RemoveServiceVolumes(name, svc); err != nil {
+			lg.Warnf("%v", err)
+		}
+	}
+	lg.Printf("%sPreview %s destroyed%s\n", lg.Green, preview.Name, lg.Reset)
+}
+
+// freePort returns a port nothing listens on
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+	previewCmd.Flags().String("name", "", "Suffix of the preview's name, random by default")
+	previewCmd.Flags().StringSlice("only", nil, "Only start these process groups or processes")
+	previewCmd.Flags().StringSlice("except", nil, "Start everything except these process groups or processes")
+	previewCmd.MarkFlagsMutuallyExclusive("only", "except")
+}
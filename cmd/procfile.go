@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/procfile"
+	"github.com/spf13/cobra"
+)
+
+// procfileCmd represents the procfile command
+var procfileCmd = &cobra.Command{
+	Use:   "procfile",
+	Short: "Generate and edit the project's Procfile",
+	Long: `Generate a Procfile from the detected project setup and add or remove
+processes without editing it by hand. The Procfile configured in
+spin.config.json is used, Procfile.dev otherwise.`,
+}
+
+var procfileGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a Procfile from the detected project setup",
+	Long: `Generate a Procfile with the processes the project needs: the Rails server,
+background job workers (Sidekiq, GoodJob, Delayed Job), the JavaScript bundler
+(bin/vite dev or the build script in watch mode) and CSS watchers.
+
+Scripts run with the package manager set in node.package_manager of
+spin.config.json, or the one detected from the lockfile (yarn, pnpm, bun or
+npm).
+
+Example:
+  spin procfile generate          # Write Procfile.dev
+  spin procfile generate --print  # Only print the generated Procfile
+  spin procfile generate --force  # Overwrite an existing Procfile`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := procfilePath()
+		entries := procfile.Generate(".", packageManager())
+		if len(entries) == 0 {
+			fmt.Fprintf(os.Stderr, "%sCould not detect any processes for this project%s\n", logger.Red, logger.Reset)
+			fmt.Fprintf(os.Stderr, "Add them with 'spin procfile add <name> <command>'\n")
+			os.Exit(1)
+		}
+
+		generated := procfile.New(entries)
+		if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+			fmt.Print(generated.String())
+			return
+		}
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if _, err := os.Stat(path); err == nil {
+				fmt.Fprintf(os.Stderr, "%s%s already exists, use --force to overwrite it%s\n", logger.Red, path, logger.Reset)
+				os.Exit(1)
+			}
+		}
+
+		if err := generated.Save(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError writing %s: %v%s\n", logger.Red, path, err, logger.Reset)
+			os.Exit(1)
+		}
+
+		logger.Printf("%sGenerated %s:%s\n", logger.Green, path, logger.Reset)
+		for _, entry := range entries {
+			fmt.Printf("  %s%s:%s %s\n", logger.Purple, entry.Name, logger.Reset, entry.Command)
+		}
+	},
+}
+
+var procfileAddCmd = &cobra.Command{
+	Use:   "add [name] [command...]",
+	Short: "Add or replace a process in the Procfile",
+	Long: `Add a process to the Procfile. If a process with the same name exists, its
+command is replaced in place.
+
+Example:
+  spin procfile add worker bundle exec sidekiq
+  spin procfile add css -- bin/rails tailwindcss:watch --poll`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := procfile.ValidateName(name); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", logger.Red, err, logger.Reset)
+			os.Exit(1)
+		}
+
+		path := procfilePath()
+		pf, err := procfile.Load(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "%sError reading %s: %v%s\n", logger.Red, path, err, logger.Reset)
+				os.Exit(1)
+			}
+			pf = procfile.New(nil)
+		}
+
+		_, exists := pf.Get(name)
+		pf.Set(name, strings.Join(args[1:], " "))
+		if err := pf.Save(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError writing %s: %v%s\n", logger.Red, path, err, logger.Reset)
+			os.Exit(1)
+		}
+
+		if exists {
+			logger.Printf("%sUpdated process %s%s%s in %s%s\n", logger.Green, logger.Cyan, name, logger.Green, path, logger.Reset)
+		} else {
+			logger.Printf("%sAdded process %s%s%s to %s%s\n", logger.Green, logger.Cyan, name, logger.Green, path, logger.Reset)
+		}
+	},
+}
+
+var procfileRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Remove a process from the Procfile",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		path := procfilePath()
+		pf, err := procfile.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError reading %s: %v%s\n", logger.Red, path, err, logger.Reset)
+			os.Exit(1)
+		}
+
+		if !pf.Remove(name) {
+			fmt.Fprintf(os.Stderr, "%sProcess %s%s%s not found in %s%s\n", logger.Red, logger.Cyan, name, logger.Red, path, logger.Reset)
+			os.Exit(1)
+		}
+		if err := pf.Save(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError writing %s: %v%s\n", logger.Red, path, err, logger.Reset)
+			os.Exit(1)
+		}
+
+		logger.Printf("%sRemoved process %s%s%s from %s%s\n", logger.Green, logger.Cyan, name, logger.Green, path, logger.Reset)
+	},
+}
+
+// procfilePath returns the Procfile configured for the project in the
+// current directory
+func procfilePath() string {
+	if cfg, err := config.LoadConfig("spin.config.json"); err == nil {
+		return cfg.GetProcfilePath()
+	}
+	return "Procfile.dev"
+}
+
+// packageManager returns the Node.js package manager configured for the
+// project in the current directory, or an empty string to detect it
+func packageManager() string {
+	if cfg, err := config.LoadConfig("spin.config.json"); err == nil && cfg.Node != nil {
+		return cfg.Node.PackageManager
+	}
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(procfileCmd)
+	procfileCmd.AddCommand(procfileGenerateCmd)
+	procfileCmd.AddCommand(procfileAddCmd)
+	procfileCmd.AddCommand(procfileRemoveCmd)
+
+	procfileGenerateCmd.Flags().Bool("force", false, "Overwrite an existing Procfile")
+	procfileGenerateCmd.Flags().Bool("print", false, "Print the generated Procfile instead of writing it")
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/registry"
+	"github.com/afomera/spin/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+// projectsCmd represents the projects command
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "Find the projects spin was used in",
+	Long: `Find the projects spin was used in, to get back to an environment or jump
+between them. Every directory with a spin.config.json a spin command runs in
+is remembered in the user configuration, with when it was last used.
+
+Example:
+  spin projects list                # Show the projects and whether they run
+  spin projects forget myapp        # Stop remembering a project
+  spin projects forget --missing    # Forget projects whose directory is gone
+  cd "$(spin projects cd-hint myapp)"`,
+}
+
+// projectsListCmd represents the projects list command
+var projectsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the projects, last used first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		userCfg := loadUserConfig()
+		if len(userCfg.Projects) == 0 {
+			fmt.Printf("%sNo projects yet, they are remembered once spin runs in them%s\n", lg.Yellow, lg.Reset)
+			return
+		}
+
+		running := runningProcessCounts()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%sPROJECT\tSTATE\tLAST USED\tDIRECTORY%s\n", lg.Cyan, lg.Reset)
+		for _, dir := range projectDirs(userCfg) {
+			project := userCfg.Projects[dir]
+			fmt.Fprintf(w, "%s\t%s\t%s ago\t%s\n", project.Name, projectState(dir, project, running), process.FormatUptime(time.Since(project.LastUsed)), dir)
+		}
+		w.Flush()
+	},
+}
+
+// projectsForgetCmd represents the projects forget command
+var projectsForgetCmd = &cobra.Command{
+	Use:   "forget [project|directory...]",
+	Short: "Stop remembering projects",
+	Run: func(cmd *cobra.Command, args []string) {
+		missing, _ := cmd.Flags().GetBool("missing")
+		if len(args) == 0 && !missing {
+			fmt.Printf("%sError: give projects to forget, or --missing%s\n", lg.Red, lg.Reset)
+			os.Exit(1)
+		}
+
+		userCfg := loadUserConfig()
+		var forgotten []string
+		for _, dir := range projectDirs(userCfg) {
+			project := userCfg.Projects[dir]
+			forget := missing && projectMissing(dir)
+			for _, arg := range args {
+				if abs, err := filepath.Abs(arg); arg == project.Name || (err == nil && abs == dir) {
+					forget = true
+				}
+			}
+			if forget {
+				delete(userCfg.Projects, dir)
+				forgotten = append(forgotten, dir)
+			}
+		}
+		if len(forgotten) == 0 {
+			fmt.Printf("%sNo matching projects%s\n", lg.Yellow, lg.Reset)
+			return
+		}
+		saveUserConfig(userCfg)
+		for _, dir := range forgotten {
+			lg.Printf("%sForgot %s%s\n", lg.Green, dir, lg.Reset)
+		}
+	},
+}
+
+// projectsCdHintCmd represents the projects cd-hint command
+var projectsCdHintCmd = &cobra.Command{
+	Use:   "cd-hint [project]",
+	Short: "Print the directory of a project",
+	Long: `Print the directory of a project, to cd into it. When several directories
+hold a project of that name, like worktrees, the last used one is printed.
+
+Example:
+  cd "$(spin projects cd-hint myapp)"
+
+  # Or define a shell function
+  spcd() { cd "$(spin projects cd-hint "$1")"; }`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		userCfg := loadUserConfig()
+		for _, dir := range projectDirs(userCfg) {
+			if userCfg.Projects[dir].Name == args[0] {
+				fmt.Println(dir)
+				return
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%sNo project named %s, see spin projects list%s\n", lg.Red, args[0], lg.Reset)
+		os.Exit(1)
+	},
+}
+
+// projectDirs returns the directories of the remembered projects, last used
+// first
+func projectDirs(userCfg *userconfig.Config) []string {
+	dirs := make([]string, 0, len(userCfg.Projects))
+	for dir := range userCfg.Projects {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return userCfg.Projects[dirs[i]].LastUsed.After(userCfg.Projects[dirs[j]].LastUsed)
+	})
+	return dirs
+}
+
+// projectState returns whether a project runs, is stopped or is gone
+func projectState(dir string, project userconfig.Project, running map[string]int) string {
+	if projectMissing(dir) {
+		return lg.Red + "missing" + lg.Reset
+	}
+	if entry, ok, err := registry.Get(project.Name); err == nil && ok && entry.Dir == dir && running[project.Name] > 0 {
+		return lg.Green + "running" + lg.Reset
+	}
+	return lg.Yellow + "stopped" + lg.Reset
+}
+
+// projectMissing checks if the spin.config.json of a project is gone
+func projectMissing(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "spin.config.json"))
+	return os.IsNotExist(err)
+}
+
+// trackProject remembers the project in the current directory. Commands spin
+// runs itself in the background are left out.
+func trackProject(cmd *cobra.Command) {
+	if cmd.Hidden {
+		return
+	}
+	cfg, err := config.Load(filepath.Join(".", "spin.config.json"))
+	if err != nil {
+		return
+	}
+	dir, err := filepath.Abs(".")
+	if err != nil {
+		return
+	}
+	userconfig.Update(func(userCfg *userconfig.Config) bool {
+		return userCfg.TouchProject(dir, cfg.Name, time.Now())
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(projectsCmd)
+	projectsCmd.AddCommand(projectsListCmd)
+	projectsCmd.AddCommand(projectsForgetCmd)
+	projectsCmd.AddCommand(projectsCdHintCmd)
+	projectsForgetCmd.Flags().Bool("missing", false, "Forget projects whose directory or spin.config.json is gone")
+}
@@ -1,13 +1,25 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
 	"github.com/afomera/spin/internal/config"
 	lg "github.com/afomera/spin/internal/logger"
 	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
 	"github.com/spf13/cobra"
 )
 
@@ -33,27 +45,60 @@ var psCmd = &cobra.Command{
 	Long: `List all running processes in the current development environment.
 Shows process names, statuses, and additional information.
 
+Before listing, the process store is reconciled with the running tmux sessions
+and Docker containers: untracked sessions of the app are adopted and entries of
+processes that are gone are removed.
+
+With --watch, a table of processes and services is refreshed in place every
+second, showing how CPU and memory changed since the last refresh and how long
+each has been running. Press Ctrl+C to stop watching.
+
+Processes scaled to several instances, like worker.1 and worker.2, are also
+summed up with the number of instances running.
+
+With --json, the processes are printed as a JSON array for scripts, without
+colors or help text.
+
 Example:
-  spin ps     # List all processes`,
+  spin ps             # List all processes
+  spin ps --watch     # Keep a live table of processes and services
+  spin ps --json      # Print the processes as JSON`,
 	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg, err := config.LoadConfig("spin.config.json")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		// Bring the store in line with what is actually running
+		manager := process.GetManager(cfg)
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			// Only the JSON goes to stdout
+			manager.SetQuiet(true)
+			if _, err := manager.Reconcile(); err != nil {
+				lg.Warnf("failed to reconcile processes: %v", err)
+			}
+			printProcessesJSON(manager.ListProcesses())
+			return
+		}
+		reconcileProcesses(manager)
+
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			interval, _ := cmd.Flags().GetDuration("interval")
+			watchProcesses(cfg, manager, interval)
+			return
+		}
+
 		// Create a new tabwriter for aligned output
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
 		// Print headers with cyan color
-		fmt.Fprintf(w, "%sAPP\tNAME\tSTATUS\tPID\tOUTPUT FILE\tINTERACTIVE\tERROR%s\n",
+		fmt.Fprintf(w, "%sAPP\tNAME\tSTATUS\tPID\tUPTIME\tOUTPUT FILE\tINTERACTIVE\tERROR%s\n",
 			lg.Cyan,
 			lg.Reset,
 		)
 
-		// Load configuration
-		cfg, err := config.LoadConfig("spin.config.json")
-		if err != nil {
-			fmt.Printf("%sError loading configuration: %v%s\n", lg.Red, err, lg.Reset)
-			os.Exit(1)
-		}
-
 		// Get all processes from the manager
-		manager := process.GetManager(cfg)
 		processes := manager.ListProcesses()
 
 		if len(processes) == 0 {
@@ -70,17 +115,27 @@ Example:
 					errStr = fmt.Sprintf("%s%s%s", lg.Red, p.Error.Error(), lg.Reset)
 				}
 
-				pid := 0
+				pid := "0"
 				if p.Command != nil && p.Command.Process != nil {
-					pid = p.Command.Process.Pid
+					pid = strconv.Itoa(p.Command.Process.Pid)
+				}
+				output := fmt.Sprintf("~/.spin/output/%s/%s.log", process.SanitizeAppName(p.AppName), p.Name)
+
+				// Services run in containers, their logs are Docker's
+				name := p.Name
+				if p.Type == process.ProcessTypeDocker {
+					name = p.Name + " (service)"
+					pid = "-"
+					output = fmt.Sprintf("spin services logs %s", p.Name)
 				}
 
-				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 					p.AppName,
-					p.Name,
+					name,
 					colorizeStatus(p.Status),
 					pid,
-					fmt.Sprintf("~/.spin/output/%s/%s.log", process.SanitizeAppName(p.AppName), p.Name),
+					process.FormatUptime(p.Uptime()),
+					output,
 					interactive,
 					errStr,
 				)
@@ -88,6 +143,8 @@ Example:
 		}
 
 		w.Flush()
+		printInstanceGroups(os.Stdout, processes)
+		printPublicURLs(os.Stdout, manager, processes)
 
 		// Print help text with blue color
 		fmt.Printf("\n%sTo view process output:%s\n", lg.Blue, lg.Reset)
@@ -97,6 +154,242 @@ Example:
 	},
 }
 
+// psEntry is a process as spin ps --json prints it
+type psEntry struct {
+	App         string `json:"app"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	PID         int    `json:"pid,omitempty"`
+	Uptime      int64  `json:"uptime_seconds"`
+	Output      string `json:"output,omitempty"` // Log file, empty for services
+	Interactive bool   `json:"interactive"`
+	Error       string `json:"error,omitempty"`
+}
+
+// printProcessesJSON prints processes as a JSON array
+func printProcessesJSON(processes []*process.Process) {
+	entries := make([]psEntry, 0, len(processes))
+	for _, p := range processes {
+		entry := psEntry{
+			App:         p.AppName,
+			Name:        p.Name,
+			Type:        string(p.Type),
+			Status:      string(p.Status),
+			Uptime:      int64(p.Uptime().Seconds()),
+			Interactive: p.IsDebug,
+		}
+		if p.Command != nil && p.Command.Process != nil {
+			entry.PID = p.Command.Process.Pid
+		}
+		if p.Type != process.ProcessTypeDocker {
+			entry.Output = p.OutputFile
+		}
+		if p.Error != nil {
+			entry.Error = p.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(entries); err != nil {
+		spinerr.Exit(err)
+	}
+}
+
+// reconcileProcesses fixes up the process store and reports what changed
+func reconcileProcesses(manager *process.Manager) {
+	fixes, err := manager.Reconcile()
+	if err != nil {
+		lg.Warnf("failed to reconcile processes: %v", err)
+	}
+	if len(fixes) == 0 {
+		return
+	}
+
+	fmt.Printf("%sReconciled process store:%s\n", lg.Blue, lg.Reset)
+	for _, fix := range fixes {
+		fmt.Printf("  %s %s%s%s (%s)\n", fix.Action, lg.Cyan, fix.Name, lg.Reset, fix.Reason)
+	}
+	fmt.Println()
+}
+
+// usage is a sample of a process or service shown by spin ps --watch
+type usage struct {
+	cpu    float64
+	memory uint64
+}
+
+// watchProcesses redraws the table of processes and services every interval
+// until interrupted
+func watchProcesses(cfg *config.Config, manager *process.Manager, interval time.Duration) {
+	manager.SetQuiet(true)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var dm *docker.ServiceManager
+	if len(cfg.Services) > 0 {
+		dm, _ = docker.NewServiceManager("")
+	}
+
+	previous := make(map[string]usage)
+	for {
+		var buf bytes.Buffer
+		previous = renderWatchTable(&buf, cfg, manager, dm, previous)
+
+		// Move to the top left and clear the screen before drawing
+		fmt.Print("\033[H\033[2J")
+		os.Stdout.Write(buf.Bytes())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// renderWatchTable writes the table of processes and services and returns
+// their usage for computing the changes on the next refresh
+func renderWatchTable(out io.Writer, cfg *config.Config, manager *process.Manager, dm *docker.ServiceManager, previous map[string]usage) map[string]usage {
+	current := make(map[string]usage)
+
+	fmt.Fprintf(out, "%s%s%s  %s  (Ctrl+C to stop)\n\n", lg.Blue, cfg.Name, lg.Reset, time.Now().Format("15:04:05"))
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sNAME\tTYPE\tSTATUS\tPID\tCPU\tMEMORY\tUPTIME%s\n", lg.Cyan, lg.Reset)
+
+	processes := manager.ListProcesses()
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Name < processes[j].Name
+	})
+
+	rows := 0
+	for _, p := range processes {
+		if p.Type == process.ProcessTypeDocker {
+			continue
+		}
+
+		pid := 0
+		if p.Command != nil && p.Command.Process != nil {
+			pid = p.Command.Process.Pid
+		}
+		uptime := process.FormatUptime(p.Uptime())
+
+		u := usage{cpu: p.CPUPercent, memory: p.MemoryUsage}
+		current[p.Name] = u
+		fmt.Fprintf(w, "%s\tprocess\t%s\t%d\t%s\t%s\t%s\n",
+			p.Name, colorizeStatus(p.Status), pid, formatCPU(u, previous[p.Name]), formatMemory(u, previous[p.Name]), uptime)
+		rows++
+	}
+
+	for _, name := range sortedServiceNames(cfg) {
+		if dm == nil {
+			fmt.Fprintf(w, "%s\tservice\t%sunknown%s\t-\t-\t-\t-\n", name, lg.Yellow, lg.Reset)
+			rows++
+			continue
+		}
+		if !dm.IsRunning(name) {
+			fmt.Fprintf(w, "%s\tservice\t%s\t-\t-\t-\t-\n", name, colorizeStatus(process.StatusStopped))
+			rows++
+			continue
+		}
+
+		uptime := "-"
+		if started, err := dm.StartedAt(name); err == nil {
+			uptime = process.FormatUptime(time.Since(started))
+		}
+		cpu, memory, err := dm.ServiceUsage(name)
+		if err != nil {
+			fmt.Fprintf(w, "%s\tservice\t%s\t-\t-\t-\t%s\n", name, colorizeStatus(process.StatusRunning), uptime)
+			rows++
+			continue
+		}
+
+		key := "service:" + name
+		u := usage{cpu: cpu, memory: memory}
+		current[key] = u
+		fmt.Fprintf(w, "%s\tservice\t%s\t-\t%s\t%s\t%s\n",
+			name, colorizeStatus(process.StatusRunning), formatCPU(u, previous[key]), formatMemory(u, previous[key]), uptime)
+		rows++
+	}
+
+	if rows == 0 {
+		fmt.Fprintf(w, "%sNo running processes or services%s\n", lg.Yellow, lg.Reset)
+	}
+	w.Flush()
+	printInstanceGroups(out, processes)
+	printPublicURLs(out, manager, processes)
+	return current
+}
+
+// printInstanceGroups writes how many instances of each scaled process are
+// running
+func printInstanceGroups(out io.Writer, processes []*process.Process) {
+	groups := process.GroupInstances(processes)
+	if len(groups) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "\n%sScaled processes:%s\n", lg.Blue, lg.Reset)
+	for _, group := range groups {
+		color := lg.Green
+		if group.Running < len(group.Instances) {
+			color = lg.Yellow
+		}
+		fmt.Fprintf(out, "  %s  %s%d/%d running%s (%s)\n", group.Type, color, group.Running, len(group.Instances), lg.Reset, strings.Join(group.Instances, ", "))
+	}
+}
+
+// printPublicURLs writes the public URLs of processes shared with spin share
+func printPublicURLs(out io.Writer, manager *process.Manager, processes []*process.Process) {
+	header := false
+	for _, p := range processes {
+		info, err := manager.Store().GetProcess(p.Name)
+		if err != nil || info.URL == "" {
+			continue
+		}
+		if !header {
+			fmt.Fprintf(out, "\n%sPublic URLs:%s\n", lg.Blue, lg.Reset)
+			header = true
+		}
+		fmt.Fprintf(out, "  %s  %s\n", p.Name, info.URL)
+	}
+}
+
+// formatCPU formats CPU usage with its change since the last refresh
+func formatCPU(u usage, prev usage) string {
+	s := fmt.Sprintf("%.1f%%", u.cpu)
+	if prev == (usage{}) {
+		return s
+	}
+	switch diff := u.cpu - prev.cpu; {
+	case diff >= 0.1:
+		s += fmt.Sprintf(" %s+%.1f%s", lg.Red, diff, lg.Reset)
+	case diff <= -0.1:
+		s += fmt.Sprintf(" %s%.1f%s", lg.Green, diff, lg.Reset)
+	}
+	return s
+}
+
+// formatMemory formats memory usage with its change since the last refresh
+func formatMemory(u usage, prev usage) string {
+	s := docker.FormatBytes(int64(u.memory))
+	if prev == (usage{}) {
+		return s
+	}
+	switch diff := int64(u.memory) - int64(prev.memory); {
+	case diff > 0:
+		s += fmt.Sprintf(" %s+%s%s", lg.Red, docker.FormatBytes(diff), lg.Reset)
+	case diff < 0:
+		s += fmt.Sprintf(" %s-%s%s", lg.Green, docker.FormatBytes(-diff), lg.Reset)
+	}
+	return s
+}
+
 func init() {
 	rootCmd.AddCommand(psCmd)
+	psCmd.Flags().BoolP("watch", "w", false, "Refresh a table of processes and services in place")
+	psCmd.Flags().Duration("interval", time.Second, "Time between refreshes with --watch")
+	psCmd.Flags().Bool("json", false, "Print the processes as JSON")
 }
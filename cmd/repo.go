@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/forge"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/afomera/spin/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+// repoCmd represents the repo command
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Work with the git repository of the project",
+	Long: `Work with the git repository of the project, the repository of
+spin.config.json on the host of its organization (see spin config set-host).`,
+}
+
+// repoOpenCmd represents the repo open command
+var repoOpenCmd = &cobra.Command{
+	Use:   "open",
+	Short: "Open the repository of the project in the browser",
+	Long: `Open the page of the project's repository on its git host in the browser.
+
+Example:
+  spin repo open           # Open the repository
+  spin repo open --print   # Only print the URL`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo := projectRepository()
+		host, _ := repoHost(repo)
+		openRepoURL(cmd, forge.WebURL(host, repo.GetFullName()))
+	},
+}
+
+// repoPRCmd represents the repo pr command
+var repoPRCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Open a pull request for the current branch",
+	Long: `Open the page creating a pull request from the current branch into the
+default branch of origin, or a merge request on GitLab.
+
+Example:
+  spin repo pr                  # Open a pull request into the default branch
+  spin repo pr --base release   # Open it into another branch
+  spin repo pr --print          # Only print the URL`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo := projectRepository()
+		branch := currentBranch()
+		base, _ := cmd.Flags().GetString("base")
+		if base == "" {
+			var err error
+			if base, err = defaultBranch(); err != nil {
+				fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+				os.Exit(1)
+			}
+		}
+		if branch == base {
+			fmt.Printf("%sError: %s is the base branch, check out the branch to open a pull request for%s\n", lg.Red, branch, lg.Reset)
+			os.Exit(1)
+		}
+		if err := exec.Command("git", "rev-parse", "--verify", "--quiet", "@{upstream}").Run(); err != nil {
+			lg.Warnf("%s isn't pushed, push it with 'git push -u origin %s'", branch, branch)
+		}
+
+		host, provider := repoHost(repo)
+		openRepoURL(cmd, forge.CompareURL(provider, host, repo.GetFullName(), base, branch))
+	},
+}
+
+// repoSyncCmd represents the repo sync command
+var repoSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Rebase the current branch on the default branch of origin",
+	Long: `Fetch origin and rebase the current branch on its default branch, like main,
+so it has the latest changes. The working tree has to be clean. Run spin up
+afterwards to install dependencies and run migrations that changed.
+
+Example:
+  spin repo sync`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		branch := currentBranch()
+		if gitDirty() {
+			fmt.Printf("%sError: the working tree has uncommitted changes, commit or stash them first%s\n", lg.Red, lg.Reset)
+			os.Exit(1)
+		}
+
+		lg.Printf("%sFetching origin...%s\n", lg.Blue, lg.Reset)
+		if err := runGit("fetch", "origin"); err != nil {
+			fmt.Printf("%sError fetching origin: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		base, err := defaultBranch()
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		lg.Printf("%sRebasing %s%s%s on origin/%s...%s\n", lg.Blue, lg.Cyan, branch, lg.Blue, base, lg.Reset)
+		if err := runGit("rebase", "origin/"+base); err != nil {
+			fmt.Printf("%sError rebasing %s: %v%s\n", lg.Red, branch, err, lg.Reset)
+			fmt.Printf("%sResolve the conflicts and run 'git rebase --continue', or undo it with 'git rebase --abort'%s\n", lg.Yellow, lg.Reset)
+			os.Exit(1)
+		}
+		lg.Printf("%s%s is up to date with origin/%s%s\n", lg.Green, branch, base, lg.Reset)
+	},
+}
+
+// projectRepository returns the repository of spin.config.json, exiting
+// when the project has none
+func projectRepository() *config.Repository {
+	cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+	if err != nil {
+		spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+	}
+	if cfg.Repository.Organization == "" || cfg.Repository.Name == "" {
+		fmt.Printf("%sError: spin.config.json has no repository, add one like {\"organization\": \"myorg\", \"name\": \"%s\"}%s\n", lg.Red, cfg.Name, lg.Reset)
+		os.Exit(1)
+	}
+	return &cfg.Repository
+}
+
+// repoHost returns the host of a repository and its provider, which is set
+// for the organization with spin config set-host or guessed from the host
+func repoHost(repo *config.Repository) (host string, provider string) {
+	if userCfg, err := userconfig.Load(); err == nil {
+		if h := userCfg.HostFor(repo.Organization); repo.Host == "" || repo.Host == h.Host {
+			return h.Host, h.GetProvider()
+		}
+	}
+	return repo.GetHost(), forge.Provider(repo.GetHost())
+}
+
+// currentBranch returns the branch checked out, exiting outside of a branch
+func currentBranch() string {
+	branch := gitBranch()
+	if branch == "" {
+		fmt.Printf("%sError: not in a git repository%s\n", lg.Red, lg.Reset)
+		os.Exit(1)
+	}
+	if branch == "HEAD" {
+		fmt.Printf("%sError: no branch is checked out%s\n", lg.Red, lg.Reset)
+		os.Exit(1)
+	}
+	return branch
+}
+
+// defaultBranch returns the default branch of origin, read from
+// origin/HEAD, or main or master when origin/HEAD isn't set
+func defaultBranch() (string, error) {
+	if out, err := exec.Command("git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD").Output(); err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(string(out)), "origin/"), nil
+	}
+	for _, branch := range []string{"main", "master"} {
+		if exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch).Run() == nil {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("can't tell the default branch of origin, set it with 'git remote set-head origin --auto'")
+}
+
+// runGit runs git with its output shown
+func runGit(args ...string) error {
+	c := exec.Command("git", args...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// openRepoURL opens a page of the repository, or prints it with --print
+func openRepoURL(cmd *cobra.Command, url string) {
+	if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+		fmt.Println(url)
+		return
+	}
+	fmt.Printf("Opening %s%s%s\n", lg.Cyan, url, lg.Reset)
+	if err := openBrowser(url); err != nil {
+		fmt.Printf("%sError opening the browser: %v%s\n", lg.Red, err, lg.Reset)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(repoCmd)
+	repoCmd.AddCommand(repoOpenCmd)
+	repoCmd.AddCommand(repoPRCmd)
+	repoCmd.AddCommand(repoSyncCmd)
+	repoOpenCmd.Flags().Bool("print", false, "Print the URL instead of opening it")
+	repoPRCmd.Flags().Bool("print", false, "Print the URL instead of opening it")
+	repoPRCmd.Flags().String("base", "", "Branch to open the pull request into, the default branch of origin by default")
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/afomera/spin/internal/events"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/metrics"
+	"github.com/afomera/spin/internal/script"
+	"github.com/spf13/cobra"
+)
+
+// reportSteps is the number of slowest steps spin report shows
+const reportSteps = 10
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show how long spin up, setup and scripts take",
+	Long: `Show how the time spent waiting on the environment develops, to find the
+speedups worth making. Everything is read from the history spin keeps in
+~/.spin, nothing is sent anywhere.
+
+The report covers:
+  - spin up and spin setup: runs, median and last duration, and the trend
+    from the first to the second half of the period
+  - the slowest steps: scripts, setup tasks and hooks by average duration,
+    with how often they failed
+  - the flakiest processes: how often they crashed or were restarted
+
+Example:
+  spin report            # The last 30 days
+  spin report --days 7   # The last week`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		app := scriptApp()
+		if app == "" {
+			fmt.Printf("%sError: no spin.config.json in the current directory%s\n", lg.Red, lg.Reset)
+			os.Exit(1)
+		}
+		days, _ := cmd.Flags().GetInt("days")
+		since := time.Now().AddDate(0, 0, -days)
+
+		fmt.Printf("%sReport of %s over the last %d days%s\n", lg.Blue, app, days, lg.Reset)
+		printCommandTimings(app, since)
+		printSlowestSteps(app, since)
+		printFlakyProcesses(app, since)
+	},
+}
+
+// printCommandTimings prints how long spin up and setup took
+func printCommandTimings(app string, since time.Time) {
+	fmt.Printf("\n%sCommands%s\n", lg.Blue, lg.Reset)
+	timings, err := script.Timings(app, since)
+	if err != nil {
+		fmt.Printf("%sError reading timings: %v%s\n", lg.Red, err, lg.Reset)
+		return
+	}
+	if len(timings) == 0 {
+		fmt.Println("  No runs of spin up or spin setup recorded")
+		return
+	}
+
+	byCommand := make(map[string][]script.Timing)
+	for _, t := range timings {
+		byCommand[t.Command] = append(byCommand[t.Command], t)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sCOMMAND\tRUNS\tFAILED\tMEDIAN\tLAST\tTREND\tHISTORY%s\n", lg.Cyan, lg.Reset)
+	for _, command := range []string{"up", "setup"} {
+		runs := byCommand[command]
+		if len(runs) == 0 {
+			continue
+		}
+		var durations []time.Duration
+		var values []float64
+		failed := 0
+		for _, t := range runs {
+			durations = append(durations, t.Duration)
+			values = append(values, t.Duration.Seconds())
+			if t.Failed {
+				failed++
+			}
+		}
+		fmt.Fprintf(w, "spin %s\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			command,
+			len(runs),
+			failed,
+			roundDuration(medianDuration(durations)),
+			roundDuration(durations[len(durations)-1]),
+			durationTrend(durations),
+			metrics.Sparkline(values, sparklineWidth),
+		)
+	}
+	w.Flush()
+}
+
+// stepStats sums up the runs of a script
+type stepStats struct {
+	name   string
+	runs   int
+	failed int
+	total  time.Duration
+	max    time.Duration
+}
+
+// printSlowestSteps prints the scripts, setup tasks and hooks that take the
+// longest on average
+func printSlowestSteps(app string, since time.Time) {
+	fmt.Printf("\n%sSlowest steps%s\n", lg.Blue, lg.Reset)
+	entries, err := script.History(app, "", 0)
+	if err != nil {
+		fmt.Printf("%sError reading script history: %v%s\n", lg.Red, err, lg.Reset)
+		return
+	}
+
+	byName := make(map[string]*stepStats)
+	for _, e := range entries {
+		if e.Started.Before(since) {
+			continue
+		}
+		s, ok := byName[e.Name]
+		if !ok {
+			s = &stepStats{name: e.Name}
+			byName[e.Name] = s
+		}
+		s.runs++
+		s.total += e.Duration
+		if e.Duration > s.max {
+			s.max = e.Duration
+		}
+		if e.ExitCode != 0 {
+			s.failed++
+		}
+	}
+	if len(byName) == 0 {
+		fmt.Println("  No scripts, setup tasks or hooks recorded")
+		return
+	}
+
+	steps := make([]*stepStats, 0, len(byName))
+	for _, s := range byName {
+		steps = append(steps, s)
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].total/time.Duration(steps[i].runs) > steps[j].total/time.Duration(steps[j].runs)
+	})
+	if len(steps) > reportSteps {
+		steps = steps[:reportSteps]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sSTEP\tRUNS\tAVERAGE\tMAX\tFAILED%s\n", lg.Cyan, lg.Reset)
+	for _, s := range steps {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%d%%\n", s.name, s.runs, roundDuration(s.total/time.Duration(s.runs)), roundDuration(s.max), s.failed*100/s.runs)
+	}
+	w.Flush()
+}
+
+// printFlakyProcesses prints the processes that crashed or were restarted
+// the most
+func printFlakyProcesses(app string, since time.Time) {
+	fmt.Printf("\n%sFlakiest processes%s\n", lg.Blue, lg.Reset)
+	recent, err := events.Recent(0, events.Filter{
+		App:   app,
+		Types: []events.Type{events.ProcessCrashed, events.ProcessRestarted},
+		Since: since,
+	})
+	if err != nil {
+		fmt.Printf("%sError reading events: %v%s\n", lg.Red, err, lg.Reset)
+		return
+	}
+
+	type flakiness struct {
+		name      string
+		crashes   int
+		restarts  int
+		lastCrash time.Time
+	}
+	byName := make(map[string]*flakiness)
+	for _, e := range recent {
+		f, ok := byName[e.Name]
+		if !ok {
+			f = &flakiness{name: e.Name}
+			byName[e.Name] = f
+		}
+		if e.Type == events.ProcessCrashed {
+			f.crashes++
+			f.lastCrash = e.Time
+		} else {
+			f.restarts++
+		}
+	}
+	if len(byName) == 0 {
+		fmt.Println("  No crashes or restarts recorded")
+		return
+	}
+
+	processes := make([]*flakiness, 0, len(byName))
+	for _, f := range byName {
+		processes = append(processes, f)
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		if processes[i].crashes != processes[j].crashes {
+			return processes[i].crashes > processes[j].crashes
+		}
+		return processes[i].restarts > processes[j].restarts
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sPROCESS\tCRASHES\tRESTARTS\tLAST CRASH%s\n", lg.Cyan, lg.Reset)
+	for _, f := range processes {
+		lastCrash := "-"
+		if !f.lastCrash.IsZero() {
+			lastCrash = f.lastCrash.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", f.name, f.crashes, f.restarts, lastCrash)
+	}
+	w.Flush()
+}
+
+// medianDuration returns the median of durations
+func medianDuration(durations []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// durationTrend compares the median of the second half of durations to the
+// first, like -20% when runs got faster
+func durationTrend(durations []time.Duration) string {
+	if len(durations) < 4 {
+		return "-"
+	}
+	half := len(durations) / 2
+	before := medianDuration(durations[:half])
+	after := medianDuration(durations[half:])
+	if before == 0 {
+		return "-"
+	}
+	change := float64(after-before) * 100 / float64(before)
+	color := lg.Green
+	if change > 0 {
+		color = lg.Red
+	}
+	return fmt.Sprintf("%s%+.0f%%%s", color, change, lg.Reset)
+}
+
+// roundDuration rounds a duration for display
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Minute {
+		return d.Round(time.Second)
+	}
+	return d.Round(100 * time.Millisecond)
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().Int("days", 30, "Number of days to report on")
+}
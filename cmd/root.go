@@ -1,10 +1,10 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 )
 
@@ -32,10 +32,34 @@ Spin is a CLI tool that helps developers manage their development environments.
 It provides commands for setting up, running, and managing applications across different
 technology stacks.
 
+Output is plain text without colors when NO_COLOR is set, with --no-color, or
+when it isn't a terminal, like when it is piped to a file or a CI log.
+
+Warnings and debug messages go to stderr. --verbose (-v) turns on timestamped
+debug messages of every module, --verbose=docker,process only of some of them
+(docker, process, notify, metrics, watcher). With --log-format json, progress
+messages, warnings and debug messages are JSON records, one per line, with
+their time, level, module and message.
+
+With --quiet, progress and success messages are left out and only results,
+warnings and errors are printed. Failures exit with a code telling what went
+wrong:
+  1  any other failure
+  3  spin.config.json is missing or invalid
+  4  Docker isn't reachable
+  5  a port is already in use
+  6  a service didn't become healthy in time
+  7  a process failed to start or stop
+
 Example usage:
   spin setup myapp
   spin up myapp
-  spin fetch myapp`,
+  spin fetch myapp
+  spin up --quiet || echo "spin up failed with $?"
+  spin up --verbose=docker --log-format json 2> spin.log`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		trackProject(cmd)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// If no subcommand is provided, print help
 		cmd.Help()
@@ -43,22 +67,36 @@ Example usage:
 }
 
 func init() {
-	var verbose bool
+	var verbose, logFormat string
+	var quiet, noColor bool
 	// Add persistent flags that will be available to all commands
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose debug output")
+	rootCmd.PersistentFlags().StringVarP(&verbose, "verbose", "v", "", "enable debug output, of all modules or of a list like --verbose=docker,process")
+	rootCmd.PersistentFlags().Lookup("verbose").NoOptDefVal = "all"
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only print results, warnings and errors")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "print plain text without colors")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logger.FormatText, "format of log messages, text or json")
 
-	// Update logger's verbose setting when the flag changes
+	// Update logger's verbose, quiet, format and color settings when the flags change
 	cobra.OnInitialize(func() {
-		logger.SetVerbose(verbose)
+		logger.SetVerboseModules(verbose)
+		logger.SetQuiet(quiet)
+		if err := logger.SetFormat(logFormat); err != nil {
+			spinerr.Exit(spinerr.New(spinerr.Validation, "invalid --log-format", err.Error()))
+		}
+		if noColor || logger.IsJSON() {
+			logger.DisableColor()
+			lipgloss.SetColorProfile(termenv.Ascii)
+		}
 	})
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once.
 func Execute() error {
+	// Errors are printed once, as a block saying how to fix them
+	rootCmd.SilenceErrors = true
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		spinerr.Exit(err)
 	}
 	return nil
 }
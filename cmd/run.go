@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/procfile"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run [process-name] [command...]",
+	Short: "Start a single process",
+	Long: `Start a single Procfile entry, or an arbitrary command under the given name,
+with the same tracking, logs and environment as processes started by spin up.
+
+Services listed for the process under "processes.services" in spin.config.json
+are started first.
+
+Example:
+  spin run web                          # Start the web entry of the Procfile
+  spin run console -- bin/rails console # Start a command under the name console`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig("spin.config.json")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		name := args[0]
+		if err := procfile.ValidateName(name); err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		commandLine := strings.Join(args[1:], " ")
+		if commandLine == "" {
+			commandLine = procfileCommand(cfg, name)
+			if commandLine == "" {
+				fmt.Printf("%sError: process %s is not defined in %s%s\n", lg.Red, name, cfg.GetProcfilePath(), lg.Reset)
+				fmt.Printf("%sPass a command to run it under this name: spin run %s -- <command>%s\n", lg.Yellow, name, lg.Reset)
+				os.Exit(1)
+			}
+		}
+
+		command, commandArgs := procfile.SplitCommand(commandLine)
+		if command == "" {
+			fmt.Printf("%sError: process %s has no command%s\n", lg.Red, name, lg.Reset)
+			os.Exit(1)
+		}
+
+		processManager := process.GetManager(cfg)
+		if _, err := processManager.FindProcess(name); err == nil {
+			fmt.Printf("%sProcess %s%s%s is already running%s\n", lg.Yellow, lg.Cyan, name, lg.Yellow, lg.Reset)
+			os.Exit(1)
+		}
+
+		if cfg.Processes != nil {
+			startServices(cfg, cfg.Processes.Services[name])
+		}
+
+		lg.Printf("%s-> Starting %s: %s%s\n", lg.Blue, name, commandLine, lg.Reset)
+		if err := processManager.StartProcess(cfg.Name, name, command, commandArgs, rubyEnv(processEnv(cfg, "."), ".", false), "."); err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Process, "failed to start "+name, err))
+		}
+
+		lg.Printf("%sProcess %s%s%s started, view its output with 'spin logs %s'%s\n", lg.Green, lg.Cyan, name, lg.Green, name, lg.Reset)
+	},
+}
+
+// procfileCommand returns the command of a Procfile entry, or an empty string
+// when the Procfile doesn't define it
+func procfileCommand(cfg *config.Config, name string) string {
+	entries, _ := procfile.Resolve(cfg, ".", procfile.Selection{})
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry.Command
+		}
+	}
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+}
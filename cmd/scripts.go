@@ -2,11 +2,20 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/credentials"
+	"github.com/afomera/spin/internal/events"
+	lg "github.com/afomera/spin/internal/logger"
 	"github.com/afomera/spin/internal/script"
+	"github.com/afomera/spin/internal/spinerr"
 )
 
 var (
@@ -22,11 +31,15 @@ func init() {
 	// Add subcommands
 	scriptsCmd.AddCommand(scriptsListCmd)
 	scriptsCmd.AddCommand(scriptsRunCmd)
+	scriptsCmd.AddCommand(scriptsHistoryCmd)
+	scriptsCmd.AddCommand(scriptsRerunLastCmd)
 
 	// Add flags
 	scriptsRunCmd.Flags().StringSliceVarP(&scriptEnv, "env", "e", []string{}, "Environment variables (KEY=VALUE)")
 	scriptsRunCmd.Flags().StringVarP(&workDir, "workdir", "w", "", "Working directory")
 	scriptsRunCmd.Flags().BoolVarP(&skipHookError, "skip-hook-error", "s", false, "Skip hook errors")
+	scriptsHistoryCmd.Flags().IntP("lines", "n", 20, "Number of runs to show")
+	scriptsRerunLastCmd.Flags().BoolVarP(&skipHookError, "skip-hook-error", "s", false, "Skip hook errors")
 }
 
 var scriptsCmd = &cobra.Command{
@@ -61,6 +74,10 @@ var scriptsListCmd = &cobra.Command{
 				desc = "No description available"
 			}
 			fmt.Printf("  - %s: %s\n", s.Name, desc)
+			for _, name := range s.ParamNames() {
+				param, declared := s.Params[name]
+				fmt.Printf("      --%s%s\n", name, describeParam(param, declared))
+			}
 		}
 
 		return nil
@@ -68,18 +85,42 @@ var scriptsListCmd = &cobra.Command{
 }
 
 var scriptsRunCmd = &cobra.Command{
-	Use:   "run [script]",
+	Use:   "run [script] [-- args...]",
 	Short: "Run a script",
-	Args:  cobra.ExactArgs(1),
+	Long: `Run a script with its pre and post hooks.
+
+Arguments after -- fill in the parameters of the script. Named parameters,
+used as {{name}} in the command, are given as --name value. The other
+arguments replace {{args}}, or are appended to the command if it doesn't use
+{{args}}.
+
+Without a script, the scripts are listed to pick one from by typing part of
+its name.
+
+Example:
+  spin scripts run                            # Pick the script
+  spin scripts run test                       # Run the test script
+  spin scripts run test -- spec/models        # Append arguments to the command
+  spin scripts run deploy -- --tag v2         # Set the tag parameter`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && canPick() {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	ValidArgsFunction: firstArg(completeScriptNames),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		scriptName := args[0]
 		manager := script.NewManager()
 
 		// Load scripts from config
 		configPath := script.DefaultConfigPath()
 		if err := script.LoadAndRegisterScripts(manager, configPath); err != nil {
-			return fmt.Errorf("failed to load scripts: %w", err)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load scripts", err))
+		}
+		if len(args) == 0 {
+			args = []string{pickName("script", "Run:", scriptOptions(manager))}
 		}
+		scriptName := args[0]
 
 		// Parse environment variables
 		env := make(map[string]string)
@@ -96,25 +137,182 @@ var scriptsRunCmd = &cobra.Command{
 			Env:              env,
 			WorkDir:          workDir,
 			SkipHooksOnError: skipHookError,
+			Args:             args[1:],
+		}
+
+		return runScript(manager, scriptName, opts)
+	},
+}
+
+// scriptOptions returns the scripts for the picker, with their descriptions
+func scriptOptions(manager *script.Manager) []pickOption {
+	var options []pickOption
+	for _, s := range manager.List() {
+		options = append(options, pickOption{Name: s.Name, Detail: s.Description})
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].Name < options[j].Name
+	})
+	return options
+}
+
+// runScript runs a script, recording the run in the history and the event log
+func runScript(manager *script.Manager, name string, opts *script.RunOptions) error {
+	app := scriptApp()
+	entry, opts := script.StartRun(app, name, opts)
+
+	// The master key is added after the run is recorded, keeping it out of
+	// the history
+	if env, err := credentials.WithMasterKey(app, ".", opts.Env); err == nil {
+		opts.Env = env
+	}
+
+	event := events.Event{Type: events.ScriptRun, App: app, Name: name}
+	runErr := manager.Run(name, opts)
+	if runErr != nil {
+		event.Type = events.ScriptFailed
+		event.Message = runErr.Error()
+	}
+	if err := entry.Finish(runErr); err != nil {
+		lg.Warnf("failed to record script history: %v", err)
+	}
+	if err := events.Publish(event); err != nil {
+		lg.Warnf("failed to record script run: %v", err)
+	}
+	if runErr != nil {
+		return fmt.Errorf("failed to run script: %w", runErr)
+	}
+
+	return nil
+}
+
+var scriptsHistoryCmd = &cobra.Command{
+	Use:   "history [script]",
+	Short: "Show recent script runs",
+	Long: `Show the recent runs of the project's scripts with their arguments, how long
+they took and how they exited. The output of every run is kept in a log file
+under ~/.spin/history, which helps debugging scripts that fail now and then.
+
+Example:
+  spin scripts history           # Last 20 runs
+  spin scripts history setup     # Last runs of the setup script
+  spin scripts history -n 50     # Last 50 runs`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: firstArg(completeScriptNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
 		}
+		count, _ := cmd.Flags().GetInt("lines")
 
-		// Run the script
-		if err := manager.Run(scriptName, opts); err != nil {
-			return fmt.Errorf("failed to run script: %w", err)
+		entries, err := script.History(scriptApp(), name, count)
+		if err != nil {
+			return fmt.Errorf("failed to read script history: %w", err)
+		}
+		if len(entries) == 0 {
+			fmt.Println("No script runs recorded")
+			return nil
 		}
 
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%sSTARTED\tSCRIPT\tARGS\tDURATION\tEXIT\tLOG%s\n", lg.Cyan, lg.Reset)
+		for _, e := range entries {
+			exit := fmt.Sprintf("%s%d%s", lg.Green, e.ExitCode, lg.Reset)
+			if e.ExitCode != 0 {
+				exit = fmt.Sprintf("%s%d%s", lg.Red, e.ExitCode, lg.Reset)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				e.Started.Format("2006-01-02 15:04:05"),
+				e.Name,
+				strings.Join(e.Args, " "),
+				e.Duration.Round(time.Millisecond),
+				exit,
+				e.LogFile,
+			)
+		}
+		w.Flush()
 		return nil
 	},
 }
 
+var scriptsRerunLastCmd = &cobra.Command{
+	Use:   "rerun-last [script]",
+	Short: "Run the last script again",
+	Long: `Run the last script of the project again with the same arguments, environment
+variables and working directory. Give a script name to rerun its last run.
+
+Example:
+  spin scripts rerun-last        # Rerun the last script
+  spin scripts rerun-last setup  # Rerun the last run of setup`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: firstArg(completeScriptNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		entries, err := script.History(scriptApp(), name, 1)
+		if err != nil {
+			return fmt.Errorf("failed to read script history: %w", err)
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("no script runs recorded")
+		}
+		last := entries[0]
+
+		manager := script.NewManager()
+		if err := script.LoadAndRegisterScripts(manager, script.DefaultConfigPath()); err != nil {
+			return fmt.Errorf("failed to load scripts: %w", err)
+		}
+
+		lg.Printf("%sRerunning %s %s%s\n", lg.Blue, last.Name, strings.Join(last.Args, " "), lg.Reset)
+		opts := &script.RunOptions{
+			Env:              last.Env,
+			WorkDir:          last.WorkDir,
+			SkipHooksOnError: skipHookError,
+			Args:             last.Args,
+		}
+		return runScript(manager, last.Name, opts)
+	},
+}
+
+// describeParam describes the default or requirement of a parameter
+func describeParam(param script.Param, declared bool) string {
+	var parts []string
+	if param.Description != "" {
+		parts = append(parts, param.Description)
+	}
+	switch {
+	case param.Default != "":
+		parts = append(parts, fmt.Sprintf("default: %s", param.Default))
+	case param.Required || !declared:
+		parts = append(parts, "required")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// scriptApp returns the name of the project scripts run in, if it has a spin.config.json
+func scriptApp() string {
+	cfg, err := config.LoadConfig("spin.config.json")
+	if err != nil {
+		return ""
+	}
+	return cfg.Name
+}
+
 // Add shorthand commands for common scripts
-func addShorthandCommand(name string) {
+func addShorthandCommand(name string) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   name,
+		Use:   name + " [-- args...]",
 		Short: fmt.Sprintf("Run the %s script", name),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Forward to scripts run command
-			return scriptsRunCmd.RunE(cmd, []string{name})
+			return scriptsRunCmd.RunE(cmd, append([]string{name}, args...))
 		},
 	}
 
@@ -124,11 +322,10 @@ func addShorthandCommand(name string) {
 	cmd.Flags().BoolVarP(&skipHookError, "skip-hook-error", "s", false, "Skip hook errors")
 
 	rootCmd.AddCommand(cmd)
+	return cmd
 }
 
 func init() {
-	// Add common shorthand commands
-	addShorthandCommand("setup")
-	addShorthandCommand("test")
+	// Add common shorthand commands, test is added with its own flags
 	addShorthandCommand("server")
 }
@@ -3,18 +3,25 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/afomera/spin/internal/cleanup"
 	"github.com/afomera/spin/internal/config"
 	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
 	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/docker/docker/api/types"
 	"github.com/spf13/cobra"
@@ -22,12 +29,24 @@ import (
 
 // loadConfig loads the spin.config.json file from the current directory
 func loadConfig() (*config.Config, error) {
+	return readConfig(config.LoadConfig)
+}
+
+// loadRawConfig loads spin.config.json as written, without replacing
+// variables, for commands that save it
+func loadRawConfig() (*config.Config, error) {
+	return readConfig(config.LoadRaw)
+}
+
+// readConfig loads the spin.config.json file from the current directory with
+// the given loader
+func readConfig(load func(string) (*config.Config, error)) (*config.Config, error) {
 	configPath := "spin.config.json"
 	if !config.Exists(configPath) {
 		return nil, fmt.Errorf("no spin.config.json found in current directory")
 	}
 
-	cfg, err := config.LoadConfig(configPath)
+	cfg, err := load(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("error loading config: %w", err)
 	}
@@ -40,6 +59,13 @@ func loadConfig() (*config.Config, error) {
 	return cfg, nil
 }
 
+// trackServices registers the process manager as the tracker of service
+// containers, so the services a command starts or stops show in spin ps and
+// the dashboard
+func trackServices(cfg *config.Config) {
+	process.GetManager(cfg)
+}
+
 var servicesCmd = &cobra.Command{
 	Use:   "services",
 	Short: "Manage services for your application",
@@ -52,8 +78,7 @@ var servicesListCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, err := loadConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
@@ -64,8 +89,7 @@ var servicesListCmd = &cobra.Command{
 
 		manager, err := docker.NewServiceManager("./data")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError creating service manager: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
 		}
 
 		for name, service := range cfg.Services {
@@ -81,6 +105,9 @@ var servicesListCmd = &cobra.Command{
 						} else {
 							health = "healthy" // Assume healthy if no health check configured
 						}
+						if container.State.Paused {
+							status = "paused"
+						}
 					}
 				}
 			}
@@ -89,6 +116,8 @@ var servicesListCmd = &cobra.Command{
 			coloredStatus := status
 			if status == "running" {
 				coloredStatus = fmt.Sprintf("%s%s%s", logger.Green, status, logger.Reset)
+			} else if status == "paused" {
+				coloredStatus = fmt.Sprintf("%s%s%s", logger.Yellow, status, logger.Reset)
 			} else {
 				coloredStatus = fmt.Sprintf("%s%s%s", logger.Red, status, logger.Reset)
 			}
@@ -122,68 +151,325 @@ var servicesListCmd = &cobra.Command{
 }
 
 var servicesStartCmd = &cobra.Command{
-	Use:   "start [service-name]",
-	Short: "Start a service",
-	Args:  cobra.ExactArgs(1),
+	Use:   "start [service-name...]",
+	Short: "Start services",
+	Long: `Start one or more services, or all of them with --all. The services they
+depend on are started first, and services that don't depend on each other
+start in parallel. When more than one service is started, a summary of the
+results is shown at the end. Without a name, the services are listed to pick
+one from by typing part of its name.
+
+Example:
+  spin services start
+  spin services start postgresql
+  spin services start postgresql redis
+  spin services start --all`,
+	Args:              serviceNamesArgs,
+	ValidArgsFunction: completeServiceNames,
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, err := loadConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError loading config: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
-		serviceName := args[0]
-		service, ok := cfg.Services[serviceName]
-		if !ok {
-			fmt.Fprintf(os.Stderr, "%sService %s%s%s not found%s\n", logger.Red, logger.Cyan, serviceName, logger.Red, logger.Reset)
-			os.Exit(1)
+		names := args
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			names = sortedServiceNames(cfg)
+		} else if len(names) == 0 {
+			names = []string{pickName("service", "Start:", serviceOptions(cfg))}
+		}
+		for _, serviceName := range names {
+			if _, ok := cfg.Services[serviceName]; !ok {
+				fmt.Fprintf(os.Stderr, "%sService %s%s%s not found%s\n", logger.Red, logger.Cyan, serviceName, logger.Red, logger.Reset)
+				os.Exit(1)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Println("No services configured")
+			return
 		}
+		trackServices(cfg)
 
 		manager, err := docker.NewServiceManager("./data")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError creating service manager: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
 		}
 
-		fmt.Printf("%sStarting %s%s%s service...%s\n", logger.Blue, logger.Cyan, serviceName, logger.Blue, logger.Reset)
-		if err := manager.StartService(serviceName, service); err != nil {
-			fmt.Fprintf(os.Stderr, "%sError starting service: %v%s\n", logger.Red, err, logger.Reset)
+		if len(names) == 1 {
+			serviceName := names[0]
+			logger.Printf("%sStarting %s%s%s service...%s\n", logger.Blue, logger.Cyan, serviceName, logger.Blue, logger.Reset)
+			if err := manager.StartServices(cfg.Services, []string{serviceName}); err != nil {
+				spinerr.Exit(spinerr.Wrap(spinerr.Service, "failed to start "+serviceName, err))
+			}
+			logger.Printf("%sService %s%s%s started successfully%s\n", logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
+			return
+		}
+
+		logger.Printf("%sStarting %s...%s\n", logger.Blue, strings.Join(names, ", "), logger.Reset)
+		results, err := manager.StartServicesBatch(cfg.Services, names)
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Service, "failed to start services", err))
+		}
+		if !printServiceResults(results) {
 			os.Exit(1)
 		}
-		fmt.Printf("%sService %s%s%s started successfully%s\n", logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
 	},
 }
 
 var servicesStopCmd = &cobra.Command{
-	Use:   "stop [service-name]",
-	Short: "Stop a service",
-	Args:  cobra.ExactArgs(1),
+	Use:   "stop [service-name...]",
+	Short: "Stop services",
+	Long: `Stop one or more services, or all of them with --all. Services are stopped
+before the services they depend on, and in parallel otherwise. When more than
+one service is stopped, a summary of the results is shown at the end.
+Without a name, the services are listed to pick one from.
+
+Shared services that other projects still use are left running unless --force
+is given.
+
+Example:
+  spin services stop
+  spin services stop redis
+  spin services stop postgresql redis
+  spin services stop --all`,
+	Args:              serviceNamesArgs,
+	ValidArgsFunction: completeServiceNames,
 	Run: func(cmd *cobra.Command, args []string) {
 		manager, err := docker.NewServiceManager("./data")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError creating service manager: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
 		}
 
-		serviceName := args[0]
-		fmt.Printf("%sStopping %s%s%s service...%s\n", logger.Blue, logger.Cyan, serviceName, logger.Blue, logger.Reset)
-		if err := manager.StopService(serviceName); err != nil {
-			fmt.Fprintf(os.Stderr, "%sError stopping service: %v%s\n", logger.Red, err, logger.Reset)
+		// Hooks are optional, so a missing config only skips them
+		cfg, err := loadConfig()
+		if err != nil {
+			cfg = &config.Config{Services: make(map[string]*config.DockerServiceConfig)}
+		}
+		services := cfg.Services
+		trackServices(cfg)
+
+		names := args
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			names = sortedServiceNames(cfg)
+		} else if len(names) == 0 {
+			names = []string{pickName("service", "Stop:", serviceOptions(cfg))}
+		}
+		if len(names) == 0 {
+			fmt.Println("No services configured")
+			return
+		}
+
+		// Other projects may still need a shared service
+		var skipped []docker.ServiceResult
+		force, _ := cmd.Flags().GetBool("force")
+		var stop []string
+		for _, serviceName := range names {
+			if users, err := docker.SharedUsers(serviceName); !force && err == nil && len(users) > 0 {
+				if len(names) == 1 {
+					fmt.Fprintf(os.Stderr, "%sService %s%s%s is shared and used by %s, pass --force to stop it anyway%s\n", logger.Red, logger.Cyan, serviceName, logger.Red, strings.Join(users, ", "), logger.Reset)
+					os.Exit(1)
+				}
+				skipped = append(skipped, docker.ServiceResult{
+					Name:   serviceName,
+					Result: docker.ResultSkipped,
+					Err:    fmt.Errorf("shared and used by %s, pass --force to stop it", strings.Join(users, ", ")),
+				})
+				continue
+			}
+			stop = append(stop, serviceName)
+		}
+
+		if len(names) == 1 {
+			serviceName := names[0]
+			logger.Printf("%sStopping %s%s%s service...%s\n", logger.Blue, logger.Cyan, serviceName, logger.Blue, logger.Reset)
+			if err := manager.StopService(serviceName, services[serviceName]); err != nil {
+				spinerr.Exit(spinerr.Wrap(spinerr.Service, "failed to stop "+serviceName, err))
+			}
+			logger.Printf("%sService %s%s%s stopped successfully%s\n", logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
+			return
+		}
+
+		logger.Printf("%sStopping %s...%s\n", logger.Blue, strings.Join(names, ", "), logger.Reset)
+		results := append(manager.StopServicesBatch(services, stop), skipped...)
+		if !printServiceResults(results) {
 			os.Exit(1)
 		}
-		fmt.Printf("%sService %s%s%s stopped successfully%s\n", logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
 	},
 }
 
+var servicesPauseCmd = &cobra.Command{
+	Use:   "pause [service-name...]",
+	Short: "Freeze running services without stopping them",
+	Long: `Freeze the processes of running services with the Docker pause API, or of all
+of them with --all. A paused service uses no CPU but keeps its memory, so it
+resumes instantly where it left off, without losing caches or waiting for a
+heavy service like Elasticsearch to warm up again.
+
+Resume services with 'spin services unpause'. 'spin services start' and
+'spin up' unpause them as well, and 'spin services stop' stops them.
+
+Example:
+  spin services pause elasticsearch
+  spin services pause --all`,
+	Args:              serviceNamesArgs,
+	ValidArgsFunction: completeServiceNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPauseCommand(cmd, args, true)
+	},
+}
+
+var servicesUnpauseCmd = &cobra.Command{
+	Use:   "unpause [service-name...]",
+	Short: "Resume paused services",
+	Long: `Resume services frozen with 'spin services pause', or all paused services with
+--all.
+
+Example:
+  spin services unpause elasticsearch
+  spin services unpause --all`,
+	Args:              serviceNamesArgs,
+	ValidArgsFunction: completeServiceNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPauseCommand(cmd, args, false)
+	},
+}
+
+// runPauseCommand pauses or unpauses the services named in args, or every
+// configured service with --all
+func runPauseCommand(cmd *cobra.Command, args []string, pause bool) {
+	manager, err := docker.NewServiceManager("./data")
+	if err != nil {
+		spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
+	}
+
+	names := args
+	all, _ := cmd.Flags().GetBool("all")
+	if all || len(names) == 0 {
+		cfg, err := loadConfig()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+		if all {
+			names = sortedServiceNames(cfg)
+		} else if pause {
+			names = []string{pickName("service", "Pause:", serviceOptions(cfg))}
+		} else {
+			names = []string{pickName("service", "Unpause:", serviceOptions(cfg))}
+		}
+	}
+
+	failed := false
+	for _, serviceName := range names {
+		paused := manager.IsPaused(serviceName)
+		switch {
+		case pause && paused:
+			fmt.Printf("%sService %s%s%s is already paused%s\n", logger.Yellow, logger.Cyan, serviceName, logger.Yellow, logger.Reset)
+		case pause && !manager.IsRunning(serviceName):
+			// --all skips stopped services quietly
+			if !all {
+				fmt.Fprintf(os.Stderr, "%sService %s%s%s is not running%s\n", logger.Red, logger.Cyan, serviceName, logger.Red, logger.Reset)
+				failed = true
+			}
+		case pause:
+			if err := manager.PauseService(serviceName); err != nil {
+				fmt.Fprintf(os.Stderr, "%sError pausing service: %v%s\n", logger.Red, err, logger.Reset)
+				failed = true
+				continue
+			}
+			logger.Printf("%sService %s%s%s paused%s\n", logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
+		case !paused:
+			if !all {
+				fmt.Fprintf(os.Stderr, "%sService %s%s%s is not paused%s\n", logger.Red, logger.Cyan, serviceName, logger.Red, logger.Reset)
+				failed = true
+			}
+		default:
+			if err := manager.UnpauseService(serviceName); err != nil {
+				fmt.Fprintf(os.Stderr, "%sError unpausing service: %v%s\n", logger.Red, err, logger.Reset)
+				failed = true
+				continue
+			}
+			logger.Printf("%sService %s%s%s resumed%s\n", logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// serviceNamesArgs accepts service names or --all, but not both
+func serviceNamesArgs(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	if all && len(args) > 0 {
+		return fmt.Errorf("pass service names or --all, not both")
+	}
+	if !all && len(args) == 0 && !canPick() {
+		return fmt.Errorf("requires at least one service name, or --all")
+	}
+	return nil
+}
+
+// serviceOptions returns the services of the project for the picker, with
+// their images
+func serviceOptions(cfg *config.Config) []pickOption {
+	var options []pickOption
+	for _, name := range sortedServiceNames(cfg) {
+		options = append(options, pickOption{Name: name, Detail: cfg.Services[name].Image})
+	}
+	return options
+}
+
+// printServiceResults prints a table of the results of a batch operation. It
+// reports whether every service succeeded.
+func printServiceResults(results []docker.ServiceResult) bool {
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sSERVICE\tRESULT\tTIME\tDETAILS%s\n", logger.Cyan, logger.Reset)
+
+	ok := true
+	for _, r := range results {
+		color := logger.Green
+		switch r.Result {
+		case docker.ResultFailed:
+			color = logger.Red
+			ok = false
+		case docker.ResultSkipped:
+			color = logger.Yellow
+			ok = false
+		case docker.ResultAlreadyRunning, docker.ResultNotRunning:
+			color = logger.Yellow
+		}
+
+		duration := "-"
+		if r.Duration > 0 {
+			duration = r.Duration.Round(100 * time.Millisecond).String()
+		}
+		details := ""
+		if r.Err != nil {
+			details = r.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s%s%s\t%s\t%s\n", r.Name, color, r.Result, logger.Reset, duration, details)
+	}
+	w.Flush()
+
+	// Failures that know how to fix themselves say so below the table
+	for _, r := range results {
+		var e *spinerr.Error
+		if errors.As(r.Err, &e) && e.Fix != "" {
+			fmt.Printf("%s%s:%s %s\n", logger.Blue, r.Name, logger.Reset, e.Fix)
+		}
+	}
+	return ok
+}
+
 var servicesLogsCmd = &cobra.Command{
-	Use:   "logs [service-name]",
-	Short: "View service logs",
-	Args:  cobra.ExactArgs(1),
+	Use:               "logs [service-name]",
+	Short:             "View service logs",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
 	Run: func(cmd *cobra.Command, args []string) {
 		manager, err := docker.NewServiceManager("./data")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError creating service manager: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
 		}
 
 		serviceName := args[0]
@@ -208,201 +494,454 @@ var servicesLogsCmd = &cobra.Command{
 	},
 }
 
+// Steps of the service wizard
+const (
+	stepType = iota
+	stepVersion
+	stepCustomVersion
+	stepName
+	stepPort
+	stepEnvAsk
+	stepEnvKey
+	stepEnvValue
+	stepVolumeAsk
+	stepVolume
+	stepReview
+)
+
+// wizardServiceTypes are the services the wizard can add
+var wizardServiceTypes = []string{"postgresql", "redis", "mysql", "mongodb", "elasticsearch", "memcached"}
+
+// maxVersionChoices limits the versions listed by the wizard
+const maxVersionChoices = 15
+
+var (
+	// imageTagPattern matches valid Docker image tags
+	imageTagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	// serviceNamePattern matches service names usable in container names
+	serviceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	// envKeyPattern matches environment variable names
+	envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+)
+
+// tagsMsg delivers the tags of an image fetched from the registry
+type tagsMsg struct {
+	tags []string
+	err  error
+}
+
+// serviceConfigModel is the wizard of spin services add
 type serviceConfigModel struct {
+	existing    map[string]*config.DockerServiceConfig
 	serviceType string
+	name        string
 	config      *config.DockerServiceConfig
 	step        int
-	err         error
 	choices     []string
 	cursor      int
-	input       string
+	input       textinput.Model
+	inputErr    string
 	envKey      string
-	envValue    string
-	volumePath  string
+	loading     bool
+	tagsErr     error
+	confirmed   bool
+}
+
+// newServiceConfigModel creates the wizard for a project's services
+func newServiceConfigModel(existing map[string]*config.DockerServiceConfig) *serviceConfigModel {
+	input := textinput.New()
+	input.CharLimit = 256
+	return &serviceConfigModel{
+		existing: existing,
+		step:     stepType,
+		choices:  wizardServiceTypes,
+		input:    input,
+	}
+}
+
+// fetchTags lists the tags of an image in the background
+func fetchTags(repository string) tea.Cmd {
+	return func() tea.Msg {
+		tags, err := docker.ListTags(repository)
+		return tagsMsg{tags: tags, err: err}
+	}
 }
 
 func (m *serviceConfigModel) Init() tea.Cmd {
 	return nil
 }
 
+// ask shows a yes/no question
+func (m *serviceConfigModel) ask(step int) {
+	m.step = step
+	m.choices = []string{"yes", "no"}
+	m.cursor = 0
+}
+
+// prompt shows a text input with a default value
+func (m *serviceConfigModel) prompt(step int, value string, placeholder string) tea.Cmd {
+	m.step = step
+	m.inputErr = ""
+	m.input.SetValue(value)
+	m.input.Placeholder = placeholder
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+// typing reports whether the current step takes text
+func (m *serviceConfigModel) typing() bool {
+	switch m.step {
+	case stepCustomVersion, stepName, stepPort, stepEnvKey, stepEnvValue, stepVolume:
+		return true
+	}
+	return false
+}
+
 func (m *serviceConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tagsMsg:
+		m.loading = false
+		m.tagsErr = msg.err
+		tags := msg.tags
+		if len(tags) == 0 {
+			tags = docker.KnownTags(docker.Repository(m.config.Image))
+		}
+		if len(tags) > maxVersionChoices {
+			tags = tags[:maxVersionChoices]
+		}
+		m.choices = append(tags, "other")
+		m.cursor = 0
+		// Start at the version spin uses by default
+		current := strings.TrimPrefix(m.config.Image, docker.Repository(m.config.Image)+":")
+		for i, tag := range tags {
+			if tag == current {
+				m.cursor = i
+			}
+		}
+		return m, nil
+
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "ctrl+c", "q":
+		case "ctrl+c", "esc":
+			m.config = nil
 			return m, tea.Quit
+		case "q":
+			if !m.typing() {
+				m.config = nil
+				return m, tea.Quit
+			}
 		case "up":
-			if m.cursor > 0 {
+			if !m.typing() && m.cursor > 0 {
 				m.cursor--
 			}
+			return m, nil
 		case "down":
-			if m.cursor < len(m.choices)-1 {
+			if !m.typing() && m.cursor < len(m.choices)-1 {
 				m.cursor++
 			}
+			return m, nil
 		case "enter":
-			switch m.step {
-			case 0: // Service type selection
-				m.serviceType = m.choices[m.cursor]
-				m.config = config.GetDefaultDockerConfig(m.serviceType)
-				m.step++
-				m.cursor = 0
-				m.choices = []string{"latest", "previous", "specific"}
-			case 1: // Version selection
-				version := ""
-				switch m.cursor {
-				case 0:
-					version = "latest"
-				case 1:
-					switch m.serviceType {
-					case "postgresql":
-						version = "14"
-					case "redis":
-						version = "6"
-					case "mysql":
-						version = "5.7"
-					}
-				case 2:
-					// TODO: Add specific version input
-					version = "latest"
-				}
-				m.config.Image = fmt.Sprintf("%s:%s", m.serviceType, version)
-				m.step++
-				m.input = fmt.Sprintf("%d", m.config.Port) // Default port
-			case 2: // Port configuration
-				if port, err := strconv.Atoi(m.input); err == nil {
-					m.config.Port = port
-					m.step++
-					m.choices = []string{"yes", "no"}
-					m.cursor = 0
-				}
-			case 3: // Configure environment variables?
-				if m.choices[m.cursor] == "yes" {
-					m.step = 4   // Go to env key input
-					m.input = "" // Reset input for env key
-				} else {
-					m.step = 6 // Skip to volume config
-					m.choices = []string{"yes", "no"}
-					m.cursor = 0
-				}
-			case 4: // Environment variable key
-				if m.input != "" {
-					m.envKey = m.input
-					m.input = "" // Reset input for value
-					m.step++
-				}
-			case 5: // Environment variable value
-				if m.input != "" {
-					if m.config.Environment == nil {
-						m.config.Environment = make(map[string]string)
-					}
-					m.config.Environment[m.envKey] = m.input
-					m.step = 3 // Back to env vars question
-					m.input = ""
-					m.choices = []string{"yes", "no"}
-					m.cursor = 0
-				}
-			case 6: // Configure volume?
-				if m.choices[m.cursor] == "yes" {
-					m.step++
-					m.input = "" // Reset input for volume path
-				} else {
-					return m, tea.Quit
-				}
-			case 7: // Volume path
-				if m.input != "" {
-					if m.config.Volumes == nil {
-						m.config.Volumes = make(map[string]string)
-					}
-					m.config.Volumes["data"] = m.input
-					return m, tea.Quit
-				}
-			}
-		case "backspace":
-			if len(m.input) > 0 {
-				m.input = m.input[:len(m.input)-1]
-			}
-		default:
-			if m.step == 2 || m.step == 4 || m.step == 5 || m.step == 7 {
-				m.input += msg.String()
-			}
+			return m, m.submit()
 		}
 	}
+
+	if m.typing() {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
 	return m, nil
 }
 
+// submit handles enter on the current step
+func (m *serviceConfigModel) submit() tea.Cmd {
+	value := strings.TrimSpace(m.input.Value())
+
+	switch m.step {
+	case stepType:
+		m.serviceType = m.choices[m.cursor]
+		m.config = config.GetDefaultDockerConfig(m.serviceType)
+		m.step = stepVersion
+		m.loading = true
+		m.choices = nil
+		return fetchTags(docker.Repository(m.config.Image))
+
+	case stepVersion:
+		if m.loading {
+			return nil
+		}
+		if m.choices[m.cursor] == "other" {
+			return m.prompt(stepCustomVersion, "", "e.g. 16.4-alpine")
+		}
+		m.config.Image = docker.Repository(m.config.Image) + ":" + m.choices[m.cursor]
+		return m.prompt(stepName, m.defaultName(), "")
+
+	case stepCustomVersion:
+		if err := validateImageTag(value); err != nil {
+			m.inputErr = err.Error()
+			return nil
+		}
+		m.config.Image = docker.Repository(m.config.Image) + ":" + value
+		return m.prompt(stepName, m.defaultName(), "")
+
+	case stepName:
+		if err := validateServiceName(value, m.existing); err != nil {
+			m.inputErr = err.Error()
+			return nil
+		}
+		m.name = value
+		return m.prompt(stepPort, strconv.Itoa(m.config.Port), "")
+
+	case stepPort:
+		port, err := strconv.Atoi(value)
+		if err == nil {
+			err = validateServicePort(port, m.existing)
+		} else {
+			err = fmt.Errorf("Enter a port between 1 and 65535")
+		}
+		if err != nil {
+			m.inputErr = err.Error()
+			return nil
+		}
+		m.config.Port = port
+		m.ask(stepEnvAsk)
+
+	case stepEnvAsk:
+		if m.choices[m.cursor] == "yes" {
+			return m.prompt(stepEnvKey, "", "e.g. POSTGRES_PASSWORD")
+		}
+		m.ask(stepVolumeAsk)
+
+	case stepEnvKey:
+		if err := validateEnvKey(value); err != nil {
+			m.inputErr = err.Error()
+			return nil
+		}
+		m.envKey = value
+		return m.prompt(stepEnvValue, m.config.Environment[value], "")
+
+	case stepEnvValue:
+		if m.config.Environment == nil {
+			m.config.Environment = make(map[string]string)
+		}
+		m.config.Environment[m.envKey] = m.input.Value()
+		m.input.Blur()
+		m.ask(stepEnvAsk)
+
+	case stepVolumeAsk:
+		if m.choices[m.cursor] == "yes" {
+			return m.prompt(stepVolume, m.config.Volumes["data"], "e.g. /var/lib/postgresql/data")
+		}
+		m.ask(stepReview)
+		m.choices = []string{"save", "cancel"}
+
+	case stepVolume:
+		if !strings.HasPrefix(value, "/") {
+			m.inputErr = "Enter the absolute path of the data directory inside the container"
+			return nil
+		}
+		if m.config.Volumes == nil {
+			m.config.Volumes = make(map[string]string)
+		}
+		m.config.Volumes["data"] = value
+		m.input.Blur()
+		m.ask(stepReview)
+		m.choices = []string{"save", "cancel"}
+
+	case stepReview:
+		if m.choices[m.cursor] == "save" {
+			m.confirmed = true
+		} else {
+			m.config = nil
+		}
+		return tea.Quit
+	}
+	return nil
+}
+
+// validateImageTag checks a tag given for the image of a service
+func validateImageTag(tag string) error {
+	if !imageTagPattern.MatchString(tag) {
+		return fmt.Errorf("Enter a tag of letters, digits, dots, dashes and underscores")
+	}
+	return nil
+}
+
+// validateServiceName checks the name of a new service
+func validateServiceName(name string, existing map[string]*config.DockerServiceConfig) error {
+	if !serviceNamePattern.MatchString(name) {
+		return fmt.Errorf("Use letters, digits, dots, dashes and underscores for the name")
+	}
+	if _, exists := existing[name]; exists {
+		return fmt.Errorf("Service %s already exists", name)
+	}
+	return nil
+}
+
+// validateServicePort checks that a port is valid and not used by another service
+func validateServicePort(port int, existing map[string]*config.DockerServiceConfig) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("Enter a port between 1 and 65535")
+	}
+	for name, svc := range existing {
+		if svc != nil && svc.Port == port {
+			return fmt.Errorf("Port %d is already used by %s", port, name)
+		}
+	}
+	return nil
+}
+
+// validateEnvKey checks the name of an environment variable
+func validateEnvKey(key string) error {
+	if !envKeyPattern.MatchString(key) {
+		return fmt.Errorf("Start %q with a letter or underscore, then use letters, digits and underscores", key)
+	}
+	return nil
+}
+
+// defaultName returns the name suggested for the new service, the type
+// unless a service already has that name
+func (m *serviceConfigModel) defaultName() string {
+	if _, exists := m.existing[m.serviceType]; !exists {
+		return m.serviceType
+	}
+	for i := 2; ; i++ {
+		name := fmt.Sprintf("%s%d", m.serviceType, i)
+		if _, exists := m.existing[name]; !exists {
+			return name
+		}
+	}
+}
+
+// viewChoices renders the choices with the cursor
+func (m *serviceConfigModel) viewChoices(s *strings.Builder) {
+	for i, choice := range m.choices {
+		cursor := " "
+		if m.cursor == i {
+			cursor = ">"
+		}
+		s.WriteString(fmt.Sprintf("%s %s\n", cursor, choice))
+	}
+}
+
 func (m *serviceConfigModel) View() string {
 	s := strings.Builder{}
 
 	switch m.step {
-	case 0:
+	case stepType:
 		s.WriteString("Select service type:\n\n")
-		for i, choice := range m.choices {
-			cursor := " "
-			if m.cursor == i {
-				cursor = ">"
-			}
-			s.WriteString(fmt.Sprintf("%s %s\n", cursor, choice))
-		}
-	case 1:
+		m.viewChoices(&s)
+	case stepVersion:
 		s.WriteString(fmt.Sprintf("Select %s version:\n\n", m.serviceType))
-		for i, choice := range m.choices {
-			cursor := " "
-			if m.cursor == i {
-				cursor = ">"
-			}
-			s.WriteString(fmt.Sprintf("%s %s\n", cursor, choice))
-		}
-	case 2:
-		s.WriteString(fmt.Sprintf("Enter port number for %s: %s\n", m.serviceType, m.input))
-	case 3:
-		s.WriteString("Configure environment variables?\n\n")
-		for i, choice := range m.choices {
-			cursor := " "
-			if m.cursor == i {
-				cursor = ">"
-			}
-			s.WriteString(fmt.Sprintf("%s %s\n", cursor, choice))
-		}
-	case 4:
-		s.WriteString("Enter environment variable name: ")
-		s.WriteString(m.input)
-	case 5:
-		s.WriteString(fmt.Sprintf("Enter value for %s: ", m.envKey))
-		s.WriteString(m.input)
-	case 6:
-		s.WriteString("Configure data volume?\n\n")
-		for i, choice := range m.choices {
-			cursor := " "
-			if m.cursor == i {
-				cursor = ">"
-			}
-			s.WriteString(fmt.Sprintf("%s %s\n", cursor, choice))
-		}
-	case 7:
-		s.WriteString("Enter volume path: ")
-		s.WriteString(m.input)
-	}
-
-	s.WriteString("\n\n(Press q to quit)\n")
+		if m.loading {
+			s.WriteString("Fetching tags from Docker Hub...\n")
+			break
+		}
+		if m.tagsErr != nil {
+			s.WriteString(fmt.Sprintf("%sCouldn't fetch tags (%v), showing known versions%s\n\n", logger.Yellow, m.tagsErr, logger.Reset))
+		}
+		m.viewChoices(&s)
+	case stepCustomVersion:
+		s.WriteString(fmt.Sprintf("Enter %s tag: %s\n", docker.Repository(m.config.Image), m.input.View()))
+	case stepName:
+		s.WriteString(fmt.Sprintf("Service name: %s\n", m.input.View()))
+	case stepPort:
+		s.WriteString(fmt.Sprintf("Port for %s: %s\n", m.name, m.input.View()))
+	case stepEnvAsk:
+		if len(m.config.Environment) > 0 {
+			s.WriteString("Environment:\n")
+			keys := make([]string, 0, len(m.config.Environment))
+			for key := range m.config.Environment {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				s.WriteString(fmt.Sprintf("  %s=%s\n", key, m.config.Environment[key]))
+			}
+			s.WriteString("\nAdd or change an environment variable?\n\n")
+		} else {
+			s.WriteString("Configure environment variables?\n\n")
+		}
+		m.viewChoices(&s)
+	case stepEnvKey:
+		s.WriteString(fmt.Sprintf("Environment variable name: %s\n", m.input.View()))
+	case stepEnvValue:
+		s.WriteString(fmt.Sprintf("Value for %s: %s\n", m.envKey, m.input.View()))
+	case stepVolumeAsk:
+		s.WriteString(fmt.Sprintf("Change the data volume (%s)?\n\n", m.config.Volumes["data"]))
+		m.viewChoices(&s)
+	case stepVolume:
+		s.WriteString(fmt.Sprintf("Data directory in the container: %s\n", m.input.View()))
+	case stepReview:
+		data, _ := json.MarshalIndent(map[string]*config.DockerServiceConfig{m.name: m.config}, "", "  ")
+		s.WriteString("The following service will be added to spin.config.json:\n\n")
+		s.WriteString(string(data))
+		s.WriteString("\n\n")
+		m.viewChoices(&s)
+	}
+
+	if m.inputErr != "" && m.typing() {
+		s.WriteString(fmt.Sprintf("\n%s%s%s\n", logger.Red, m.inputErr, logger.Reset))
+	}
+	if m.typing() {
+		s.WriteString("\n\n(Press esc to quit)\n")
+	} else {
+		s.WriteString("\n\n(Press q to quit)\n")
+	}
 
 	return s.String()
 }
 
 var servicesAddCmd = &cobra.Command{
-	Use:   "add",
+	Use:   "add [service-type]",
 	Short: "Add a new service",
+	Long: `Add a Docker service to spin.config.json with a wizard. Pick the type of
+service and one of the versions published on Docker Hub, or enter any tag. Then
+set its name, port, environment and data volume, and review the result before
+it is saved.
+
+Give the type of service as an argument to add it without the wizard, e.g. from
+scripts or CI. The service starts from the defaults of its type, which the
+flags override. Types: postgresql, redis, mysql, mongodb, elasticsearch and
+memcached.
+
+Example:
+  spin services add
+  spin services add redis
+  spin services add postgresql --version 16 --port 5433
+  spin services add postgresql --name analytics --env POSTGRES_DB=analytics \
+    --volume data=/var/lib/postgresql/data`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := loadConfig()
+		cfg, err := loadRawConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
-		model := &serviceConfigModel{
-			step:    0,
-			choices: []string{"postgresql", "redis", "mysql"},
+		if len(args) == 0 {
+			for _, flag := range []string{"name", "version", "port", "env", "volume"} {
+				if cmd.Flags().Changed(flag) {
+					fmt.Fprintf(os.Stderr, "Error: --%s needs the type of service, e.g. 'spin services add postgresql --%s ...'\n", flag, flag)
+					os.Exit(1)
+				}
+			}
+		} else {
+			name, svc, err := serviceFromFlags(cmd, args[0], cfg.Services)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			cfg.Services[name] = svc
+			if err := cfg.Save("spin.config.json"); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Service %s added successfully (%s on port %d)\n", name, svc.Image, svc.Port)
+			return
 		}
 
+		model := newServiceConfigModel(cfg.Services)
+
 		p := tea.NewProgram(model)
 		finalModel, err := p.Run()
 		if err != nil {
@@ -411,22 +950,13 @@ var servicesAddCmd = &cobra.Command{
 		}
 
 		m := finalModel.(*serviceConfigModel)
-		if m.config == nil {
+		if m.config == nil || !m.confirmed {
 			fmt.Println("Service configuration cancelled")
 			return
 		}
 
-		// Check if service already exists
-		if _, exists := cfg.Services[m.serviceType]; exists {
-			fmt.Fprintf(os.Stderr, "Service %s already exists\n", m.serviceType)
-			os.Exit(1)
-		}
-
 		// Add the service to config
-		if cfg.Services == nil {
-			cfg.Services = make(map[string]*config.DockerServiceConfig)
-		}
-		cfg.Services[m.serviceType] = m.config
+		cfg.Services[m.name] = m.config
 
 		// Save the updated config
 		if err := cfg.Save("spin.config.json"); err != nil {
@@ -434,19 +964,79 @@ var servicesAddCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		fmt.Printf("Service %s added successfully\n", m.serviceType)
+		fmt.Printf("Service %s added successfully\n", m.name)
 	},
 }
 
+// serviceFromFlags builds a service of a type from the flags of services add,
+// returning its name
+func serviceFromFlags(cmd *cobra.Command, serviceType string, existing map[string]*config.DockerServiceConfig) (string, *config.DockerServiceConfig, error) {
+	svc := config.GetDefaultDockerConfig(serviceType)
+	if svc == nil {
+		return "", nil, fmt.Errorf("unknown service type %s, use one of %s", serviceType, strings.Join(wizardServiceTypes, ", "))
+	}
+
+	name, _ := cmd.Flags().GetString("name")
+	if name == "" {
+		name = serviceType
+	}
+	if err := validateServiceName(name, existing); err != nil {
+		return "", nil, err
+	}
+
+	if version, _ := cmd.Flags().GetString("version"); version != "" {
+		if err := validateImageTag(version); err != nil {
+			return "", nil, err
+		}
+		svc.Image = docker.Repository(svc.Image) + ":" + version
+	}
+
+	if cmd.Flags().Changed("port") {
+		svc.Port, _ = cmd.Flags().GetInt("port")
+	}
+	if err := validateServicePort(svc.Port, existing); err != nil {
+		return "", nil, fmt.Errorf("%v, choose another with --port", err)
+	}
+
+	env, _ := cmd.Flags().GetStringArray("env")
+	for _, pair := range env {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return "", nil, fmt.Errorf("invalid --env %q, use KEY=VALUE", pair)
+		}
+		if err := validateEnvKey(key); err != nil {
+			return "", nil, err
+		}
+		if svc.Environment == nil {
+			svc.Environment = make(map[string]string)
+		}
+		svc.Environment[key] = value
+	}
+
+	volumes, _ := cmd.Flags().GetStringArray("volume")
+	for _, pair := range volumes {
+		volume, path, ok := strings.Cut(pair, "=")
+		if !ok || volume == "" || !strings.HasPrefix(path, "/") {
+			return "", nil, fmt.Errorf("invalid --volume %q, use NAME=/path/in/container", pair)
+		}
+		if svc.Volumes == nil {
+			svc.Volumes = make(map[string]string)
+		}
+		svc.Volumes[volume] = path
+	}
+
+	return name, svc, nil
+}
+
 var servicesRemoveCmd = &cobra.Command{
-	Use:   "remove [service-name]",
-	Short: "Remove a service",
-	Args:  cobra.ExactArgs(1),
+	Use:               "remove [service-name]",
+	Short:             "Remove a service",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := loadConfig()
+		cfg, err := loadRawConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
 		serviceName := args[0]
@@ -467,10 +1057,10 @@ var servicesRemoveCmd = &cobra.Command{
 		// Remove the service container and volumes if requested
 		removeVolumes, _ := cmd.Flags().GetBool("remove-volumes")
 		if removeVolumes {
+			trackServices(cfg)
 			manager, err := docker.NewServiceManager("./data")
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error creating service manager: %v\n", err)
-				os.Exit(1)
+				spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
 			}
 
 			if err := manager.RemoveService(serviceName, true); err != nil {
@@ -486,8 +1076,12 @@ var servicesRemoveCmd = &cobra.Command{
 var servicesCleanupCmd = &cobra.Command{
 	Use:   "cleanup [resource-type]",
 	Short: "Clean up unused resources",
-	Long: `Clean up unused resources like volumes.
-Example: spin services cleanup volumes`,
+	Long: `Remove the volumes of services no container uses, like spin cleanup
+--volumes. Backup volumes kept by major upgrades are left alone. See 'spin
+cleanup' for containers, images and other leftovers.
+
+Example:
+  spin services cleanup volumes`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		resourceType := args[0]
@@ -496,31 +1090,19 @@ Example: spin services cleanup volumes`,
 				logger.Red, resourceType, logger.Reset)
 			os.Exit(1)
 		}
-
-		manager, err := docker.NewServiceManager("./data")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError creating service manager: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
-		}
-
-		fmt.Printf("%sCleaning up unused volumes...%s\n", logger.Blue, logger.Reset)
-		if err := manager.CleanupVolumes(); err != nil {
-			fmt.Fprintf(os.Stderr, "%sError cleaning up volumes: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
-		}
-		fmt.Printf("%sVolumes cleaned up successfully%s\n", logger.Green, logger.Reset)
+		runCleanup(cleanup.Options{Volumes: true}, false)
 	},
 }
 
 var servicesRestartCmd = &cobra.Command{
-	Use:   "restart [service-name]",
-	Short: "Restart a service",
-	Args:  cobra.ExactArgs(1),
+	Use:               "restart [service-name]",
+	Short:             "Restart a service",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, err := loadConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError loading config: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
 		serviceName := args[0]
@@ -530,39 +1112,38 @@ var servicesRestartCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		trackServices(cfg)
+
 		manager, err := docker.NewServiceManager("./data")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError creating service manager: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
 		}
 
-		fmt.Printf("%sRestarting %s%s%s service...%s\n", logger.Blue, logger.Cyan, serviceName, logger.Blue, logger.Reset)
+		logger.Printf("%sRestarting %s%s%s service...%s\n", logger.Blue, logger.Cyan, serviceName, logger.Blue, logger.Reset)
 
 		// Stop the service
-		if err := manager.StopService(serviceName); err != nil {
-			fmt.Fprintf(os.Stderr, "%sError stopping service: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+		if err := manager.StopService(serviceName, service); err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Service, "failed to stop "+serviceName, err))
 		}
 
 		// Start the service
 		if err := manager.StartService(serviceName, service); err != nil {
-			fmt.Fprintf(os.Stderr, "%sError starting service: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Service, "failed to start "+serviceName, err))
 		}
 
-		fmt.Printf("%sService %s%s%s restarted successfully%s\n", logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
+		logger.Printf("%sService %s%s%s restarted successfully%s\n", logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
 	},
 }
 
 var servicesInfoCmd = &cobra.Command{
-	Use:   "info [service-name]",
-	Short: "Display detailed information about a service",
-	Args:  cobra.ExactArgs(1),
+	Use:               "info [service-name]",
+	Short:             "Display detailed information about a service",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, err := loadConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError loading config: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
 		serviceName := args[0]
@@ -574,8 +1155,7 @@ var servicesInfoCmd = &cobra.Command{
 
 		manager, err := docker.NewServiceManager("./data")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError creating service manager: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
 		}
 
 		containerID, err := manager.FindContainer(serviceName)
@@ -607,12 +1187,17 @@ var servicesInfoCmd = &cobra.Command{
 			if err == nil {
 				uptime = time.Since(startTime).Round(time.Second).String()
 			}
+			if container.State.Paused {
+				status = "paused"
+			}
 		}
 
 		// Colorize status
 		coloredStatus := status
 		if status == "running" {
 			coloredStatus = fmt.Sprintf("%s%s%s", logger.Green, status, logger.Reset)
+		} else if status == "paused" {
+			coloredStatus = fmt.Sprintf("%s%s%s", logger.Yellow, status, logger.Reset)
 		} else {
 			coloredStatus = fmt.Sprintf("%s%s%s", logger.Red, status, logger.Reset)
 		}
@@ -638,6 +1223,13 @@ var servicesInfoCmd = &cobra.Command{
 		fmt.Printf("%sHealth:%s %s\n", logger.Cyan, logger.Reset, coloredHealth)
 		fmt.Printf("%sUptime:%s %s\n", logger.Cyan, logger.Reset, uptime)
 		fmt.Printf("%sPort:%s %d -> %d\n", logger.Cyan, logger.Reset, service.Port, service.Port)
+		if service.Shared {
+			users, _ := docker.SharedUsers(serviceName)
+			fmt.Printf("%sShared by:%s %s\n", logger.Cyan, logger.Reset, strings.Join(users, ", "))
+			if docker.HasProjectDatabases(service) {
+				fmt.Printf("%sProject database:%s %s\n", logger.Cyan, logger.Reset, docker.ProjectDatabase(cfg.Name))
+			}
+		}
 
 		if len(service.Volumes) > 0 {
 			fmt.Printf("\n%sVolumes:%s\n", logger.Cyan, logger.Reset)
@@ -661,24 +1253,34 @@ var servicesInfoCmd = &cobra.Command{
 
 		if service.HealthCheck != nil {
 			fmt.Printf("\n%sHealth Check:%s\n", logger.Cyan, logger.Reset)
-			fmt.Printf("  %sCommand:%s %v\n", logger.Blue, logger.Reset, service.HealthCheck.Command)
+			switch hc := service.HealthCheck; {
+			case hc.TCP != 0:
+				fmt.Printf("  %sTCP:%s port %d\n", logger.Blue, logger.Reset, hc.TCP)
+			case hc.HTTP != nil:
+				fmt.Printf("  %sHTTP:%s %s\n", logger.Blue, logger.Reset, hc.HTTP.URL)
+			default:
+				fmt.Printf("  %sCommand:%s %v\n", logger.Blue, logger.Reset, hc.Command)
+			}
 			fmt.Printf("  %sInterval:%s %s\n", logger.Blue, logger.Reset, service.HealthCheck.Interval)
 			fmt.Printf("  %sTimeout:%s %s\n", logger.Blue, logger.Reset, service.HealthCheck.Timeout)
 			fmt.Printf("  %sRetries:%s %d\n", logger.Blue, logger.Reset, service.HealthCheck.Retries)
 			fmt.Printf("  %sStart Period:%s %s\n", logger.Blue, logger.Reset, service.HealthCheck.StartPeriod)
+			if service.HealthCheck.OnHost() {
+				fmt.Printf("  %sRuns on:%s the host\n", logger.Blue, logger.Reset)
+			}
 		}
 	},
 }
 
 var servicesEditCmd = &cobra.Command{
-	Use:   "edit [service-name]",
-	Short: "Edit service configuration",
-	Args:  cobra.ExactArgs(1),
+	Use:               "edit [service-name]",
+	Short:             "Edit service configuration",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := loadConfig()
+		cfg, err := loadRawConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError loading config: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
 		serviceName := args[0]
@@ -711,7 +1313,7 @@ var servicesEditCmd = &cobra.Command{
 			editor = "vim" // Default to vim
 		}
 
-		fmt.Printf("%sOpening configuration in %s...%s\n", logger.Blue, editor, logger.Reset)
+		logger.Printf("%sOpening configuration in %s...%s\n", logger.Blue, editor, logger.Reset)
 
 		cmd2 := exec.Command(editor, tmpfile.Name())
 		cmd2.Stdin = os.Stdin
@@ -751,14 +1353,14 @@ var servicesEditCmd = &cobra.Command{
 }
 
 var servicesExportCmd = &cobra.Command{
-	Use:   "export [service-name]",
-	Short: "Export service configuration",
-	Args:  cobra.ExactArgs(1),
+	Use:               "export [service-name]",
+	Short:             "Export service configuration",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, err := loadConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError loading config: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
 		serviceName := args[0]
@@ -768,7 +1370,7 @@ var servicesExportCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		fmt.Printf("%sExporting configuration for %s%s%s...%s\n", logger.Blue, logger.Cyan, serviceName, logger.Blue, logger.Reset)
+		logger.Printf("%sExporting configuration for %s%s%s...%s\n", logger.Blue, logger.Cyan, serviceName, logger.Blue, logger.Reset)
 		encoder := json.NewEncoder(os.Stdout)
 		encoder.SetIndent("", "  ")
 		if err := encoder.Encode(service); err != nil {
@@ -783,10 +1385,9 @@ var servicesImportCmd = &cobra.Command{
 	Short: "Import service configuration",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := loadConfig()
+		cfg, err := loadRawConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError loading config: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
 		data, err := os.ReadFile(args[0])
@@ -830,14 +1431,14 @@ var servicesImportCmd = &cobra.Command{
 }
 
 var servicesUpdateCmd = &cobra.Command{
-	Use:   "update [service-name]",
-	Short: "Update service image",
-	Args:  cobra.ExactArgs(1),
+	Use:               "update [service-name]",
+	Short:             "Update service image",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, err := loadConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError loading config: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
 		serviceName := args[0]
@@ -846,19 +1447,17 @@ var servicesUpdateCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "%sService %s%s%s not found%s\n", logger.Red, logger.Cyan, serviceName, logger.Red, logger.Reset)
 			os.Exit(1)
 		}
+		trackServices(cfg)
 
 		manager, err := docker.NewServiceManager("./data")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError creating service manager: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
 		}
 
-		// Stop the service if it's running
-		if manager.IsRunning(serviceName) {
-			if err := manager.StopService(serviceName); err != nil {
-				fmt.Fprintf(os.Stderr, "%sError stopping service: %v%s\n", logger.Red, err, logger.Reset)
-				os.Exit(1)
-			}
+		// Remember what the existing container runs before changing the image
+		oldService := *service
+		if current := manager.CurrentImage(serviceName); current != "" {
+			oldService.Image = current
 		}
 
 		// Check if specific version is requested
@@ -869,9 +1468,74 @@ var servicesUpdateCmd = &cobra.Command{
 			service.Image = fmt.Sprintf("%s:%s", imageParts[0], version)
 		}
 
+		// A major database upgrade can't reuse the old data directory
+		migrate, _ := cmd.Flags().GetString("migrate")
+		force, _ := cmd.Flags().GetBool("force")
+		upgrade := docker.DetectMajorUpgrade(oldService.Image, service.Image)
+		if upgrade == nil && migrate != "" {
+			fmt.Fprintf(os.Stderr, "%s--migrate is only used for major database upgrades%s\n", logger.Red, logger.Reset)
+			os.Exit(1)
+		}
+		if migrate != "" && migrate != docker.MigrateDump && migrate != docker.MigratePgUpgrade {
+			fmt.Fprintf(os.Stderr, "%sUnknown migration %s, expected %s or %s%s\n",
+				logger.Red, migrate, docker.MigrateDump, docker.MigratePgUpgrade, logger.Reset)
+			os.Exit(1)
+		}
+		if upgrade != nil && len(service.Volumes) > 0 {
+			fmt.Printf("%sWarning: %s%s%s is a major version upgrade (%s).%s\n",
+				logger.Yellow, logger.Cyan, serviceName, logger.Yellow, upgrade, logger.Reset)
+			fmt.Printf("%sThe existing data directory can't be read by the new version.%s\n", logger.Yellow, logger.Reset)
+			if migrate == "" && !force {
+				update := "spin services update " + serviceName
+				if version != "" {
+					update += " --version " + version
+				}
+				fmt.Fprintf(os.Stderr, "\n%sTo fix:%s\n", logger.Blue, logger.Reset)
+				if upgrade.Engine == "postgres" {
+					fmt.Fprintf(os.Stderr, "  %s --migrate=dump        # Dump and restore all databases\n", update)
+					fmt.Fprintf(os.Stderr, "  %s --migrate=pg_upgrade  # Upgrade the data directory in place\n", update)
+				}
+				fmt.Fprintf(os.Stderr, "  %s --force               # Start with an empty data directory\n", update)
+				fmt.Fprintf(os.Stderr, "\nThe old data is kept in a backup volume in every case.\n")
+				os.Exit(1)
+			}
+		}
+
 		fmt.Printf("%sUpdating %s%s%s to image %s%s%s...%s\n",
 			logger.Blue, logger.Cyan, serviceName, logger.Blue,
 			logger.Cyan, service.Image, logger.Blue, logger.Reset)
+
+		// Always fetch the newest image for the tag unless pulling is disabled
+		if service.PullPolicy != docker.PullNever {
+			if err := manager.PullImage(service.Image); err != nil {
+				fmt.Fprintf(os.Stderr, "%sError pulling image: %v\nSuggestion: Check if the specified version exists%s\n",
+					logger.Red, err, logger.Reset)
+				os.Exit(1)
+			}
+		}
+
+		if upgrade != nil && len(service.Volumes) > 0 {
+			backups, err := manager.UpgradeService(serviceName, &oldService, service, upgrade, migrate)
+			for key, backup := range backups {
+				logger.Printf("%sOld %s data kept in volume %s%s%s\n", logger.Blue, key, logger.Cyan, backup, logger.Reset)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%sError upgrading service: %v%s\n", logger.Red, err, logger.Reset)
+				os.Exit(1)
+			}
+			fmt.Printf("%sService %s%s%s upgraded successfully%s\n",
+				logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
+			return
+		}
+
+		// Stop the service if it's running
+		if manager.IsRunning(serviceName) {
+			if err := manager.StopService(serviceName, service); err != nil {
+				fmt.Fprintf(os.Stderr, "%sError stopping service: %v%s\n", logger.Red, err, logger.Reset)
+				os.Exit(1)
+			}
+		}
+
 		if err := manager.StartService(serviceName, service); err != nil {
 			fmt.Fprintf(os.Stderr, "%sError updating service: %v\nSuggestion: Check if the specified version exists%s\n",
 				logger.Red, err, logger.Reset)
@@ -889,14 +1553,12 @@ var servicesStatsCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, err := loadConfig()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError loading config: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
 		manager, err := docker.NewServiceManager("./data")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sError creating service manager: %v%s\n", logger.Red, err, logger.Reset)
-			os.Exit(1)
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
@@ -968,11 +1630,102 @@ var servicesStatsCmd = &cobra.Command{
 	},
 }
 
+var servicesWaitCmd = &cobra.Command{
+	Use:   "wait [service-name]",
+	Short: "Wait for a service to become healthy",
+	Long: `Block until a service reports healthy, then exit. Exits with a non-zero
+status if the service does not become healthy before the timeout, 6 like other
+health timeouts (see spin --help), which makes it usable from scripts and hooks.
+
+Example:
+  spin services wait postgresql
+  spin services wait redis --timeout 30s`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, err := docker.NewServiceManager("./data")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
+		}
+
+		serviceName := args[0]
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if err := manager.WaitForHealthy(serviceName, timeout); err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Service, serviceName+" is not healthy", err).
+				WithFix(fmt.Sprintf("Check its logs with 'spin services logs %s'", serviceName)).
+				WithCode(spinerr.ExitHealthTimeout))
+		}
+	},
+}
+
+var servicesSyncDataCmd = &cobra.Command{
+	Use:   "sync-data [service-name] [push|pull]",
+	Short: "Copy service volume data to or from the Docker engine",
+	Long: `Copy the contents of a service's volumes between a local directory and the
+Docker engine. This is mainly useful when DOCKER_HOST points to a remote
+machine, where local bind mounts are replaced by named volumes.
+
+Each volume is synced with a subdirectory of --dir named after the volume.
+
+Example:
+  spin services sync-data postgresql pull           # Download into ./data/postgresql
+  spin services sync-data postgresql push --dir=dump # Upload from ./dump`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: firstArg(completeServiceNames),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		serviceName := args[0]
+		service, ok := cfg.Services[serviceName]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%sService %s%s%s not found%s\n", logger.Red, logger.Cyan, serviceName, logger.Red, logger.Reset)
+			os.Exit(1)
+		}
+		if len(service.Volumes) == 0 {
+			fmt.Printf("%sService %s%s%s has no volumes to sync%s\n", logger.Yellow, logger.Cyan, serviceName, logger.Yellow, logger.Reset)
+			return
+		}
+
+		manager, err := docker.NewServiceManager("./data")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
+		}
+
+		dir, _ := cmd.Flags().GetString("dir")
+		if dir == "" {
+			dir = filepath.Join("data", serviceName)
+		}
+
+		switch args[1] {
+		case "pull":
+			logger.Printf("%sPulling %s%s%s data into %s...%s\n", logger.Blue, logger.Cyan, serviceName, logger.Blue, dir, logger.Reset)
+			err = manager.PullVolumes(serviceName, service, dir)
+		case "push":
+			logger.Printf("%sPushing %s into %s%s%s volumes...%s\n", logger.Blue, dir, logger.Cyan, serviceName, logger.Blue, logger.Reset)
+			err = manager.PushVolumes(serviceName, service, dir)
+		default:
+			fmt.Fprintf(os.Stderr, "%sUnknown direction %s, expected push or pull%s\n", logger.Red, args[1], logger.Reset)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError syncing data: %v%s\n", logger.Red, err, logger.Reset)
+			os.Exit(1)
+		}
+
+		logger.Printf("%sService %s%s%s data synced successfully%s\n", logger.Green, logger.Cyan, serviceName, logger.Green, logger.Reset)
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(servicesCmd)
 	servicesCmd.AddCommand(servicesListCmd)
 	servicesCmd.AddCommand(servicesStartCmd)
 	servicesCmd.AddCommand(servicesStopCmd)
+	servicesCmd.AddCommand(servicesPauseCmd)
+	servicesCmd.AddCommand(servicesUnpauseCmd)
 	servicesCmd.AddCommand(servicesRestartCmd)
 	servicesCmd.AddCommand(servicesLogsCmd)
 	servicesCmd.AddCommand(servicesAddCmd)
@@ -984,11 +1737,96 @@ func init() {
 	servicesCmd.AddCommand(servicesImportCmd)
 	servicesCmd.AddCommand(servicesUpdateCmd)
 	servicesCmd.AddCommand(servicesStatsCmd)
+	servicesCmd.AddCommand(servicesSyncDataCmd)
+	servicesCmd.AddCommand(servicesWaitCmd)
+	servicesCmd.AddCommand(servicesDoctorCmd)
 
 	// Add flags
 	servicesLogsCmd.Flags().IntP("tail", "n", 100, "Number of lines to show from the end of the logs")
 	servicesLogsCmd.Flags().BoolP("follow", "f", false, "Follow log output")
+	servicesAddCmd.Flags().String("name", "", "Service name (defaults to the type of service)")
+	servicesAddCmd.Flags().String("version", "", "Image tag to use instead of the default, e.g. 16")
+	servicesAddCmd.Flags().Int("port", 0, "Port to expose instead of the default")
+	servicesAddCmd.Flags().StringArray("env", nil, "Environment variable as KEY=VALUE (can be repeated)")
+	servicesAddCmd.Flags().StringArray("volume", nil, "Volume as NAME=/path/in/container (can be repeated)")
 	servicesRemoveCmd.Flags().Bool("remove-volumes", false, "Remove associated volumes")
 	servicesImportCmd.Flags().String("name", "", "Service name (defaults to filename without extension)")
 	servicesUpdateCmd.Flags().String("version", "", "Specific version to update to")
+	servicesUpdateCmd.Flags().String("migrate", "", "Carry data across a major database upgrade (dump or pg_upgrade)")
+	servicesUpdateCmd.Flags().Bool("force", false, "Allow a major database upgrade without migrating data")
+	servicesStartCmd.Flags().Bool("all", false, "Start every service in spin.config.json")
+	servicesStopCmd.Flags().Bool("all", false, "Stop every service in spin.config.json")
+	servicesPauseCmd.Flags().Bool("all", false, "Pause every running service")
+	servicesUnpauseCmd.Flags().Bool("all", false, "Unpause every paused service")
+	servicesStopCmd.Flags().Bool("force", false, "Stop a shared service even if other projects use it")
+	servicesWaitCmd.Flags().Duration("timeout", 60*time.Second, "How long to wait before giving up")
+	servicesSyncDataCmd.Flags().String("dir", "", "Local directory to sync with (defaults to ./data/<service>)")
+}
+
+var servicesDoctorCmd = &cobra.Command{
+	Use:   "doctor [service-name]",
+	Short: "Diagnose problems with services",
+	Long: `Run a series of checks against one or all configured services: image
+availability, port reachability from the host, volume mounts, the health check
+command inside the container and recent restart loops. Every problem found
+comes with a suggested fix.
+
+Example:
+  spin services doctor
+  spin services doctor postgresql`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: firstArg(completeServiceNames),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		var names []string
+		if len(args) == 1 {
+			if _, ok := cfg.Services[args[0]]; !ok {
+				fmt.Fprintf(os.Stderr, "%sService %s%s%s not found%s\n", logger.Red, logger.Cyan, args[0], logger.Red, logger.Reset)
+				os.Exit(1)
+			}
+			names = []string{args[0]}
+		} else {
+			for name := range cfg.Services {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+		if len(names) == 0 {
+			fmt.Printf("%sNo services configured%s\n", logger.Yellow, logger.Reset)
+			return
+		}
+
+		manager, err := docker.NewServiceManager("./data")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
+		}
+
+		failed := false
+		for _, name := range names {
+			fmt.Printf("\n%sChecking %s%s%s...%s\n\n", logger.Blue, logger.Cyan, name, logger.Blue, logger.Reset)
+			for _, result := range manager.Diagnose(name, cfg.Services[name]) {
+				switch result.Status {
+				case docker.DiagnosticOK:
+					fmt.Printf("  %s✓%s %s: %s\n", logger.Green, logger.Reset, result.Check, result.Message)
+				case docker.DiagnosticWarn:
+					fmt.Printf("  %s⚠%s %s: %s%s%s\n", logger.Yellow, logger.Reset, result.Check, logger.Yellow, result.Message, logger.Reset)
+				default:
+					failed = true
+					fmt.Printf("  %s✗%s %s: %s%s%s\n", logger.Red, logger.Reset, result.Check, logger.Red, result.Message, logger.Reset)
+				}
+				if result.Fix != "" {
+					fmt.Printf("    %sTo fix:%s %s\n", logger.Blue, logger.Reset, result.Fix)
+				}
+			}
+		}
+		fmt.Println()
+
+		if failed {
+			os.Exit(1)
+		}
+	},
 }
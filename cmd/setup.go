@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/scaffold"
+	"github.com/afomera/spin/internal/script"
+	"github.com/afomera/spin/internal/setup"
+	"github.com/spf13/cobra"
+)
+
+// setupCmd represents the setup command
+var setupCmd = &cobra.Command{
+	Use:   "setup [app-name] [-- args...]",
+	Short: "Set up the project",
+	Long: `Run the setup tasks of spin.config.json, like installing dependencies,
+creating the database and copying .env.example.
+
+Every task records a hash of its definition and of its inputs, e.g.
+Gemfile.lock, in .spin/state/setup.json, and is skipped while they are
+unchanged. A task with "creates" is skipped while that file exists. This makes
+spin setup fast to run again after pulling changes.
+
+Projects without setup tasks run their setup script instead. Either way, the
+tools listed in dependencies.tools must be installed first.
+
+With --template, a new project is scaffolded instead: spin.config.json,
+Procfile.dev and service definitions are written from a built-in template or
+one of the organization's templates, fetched from the git repository set with
+spin config set-templates.
+
+Example:
+  spin setup                              # Run the tasks that aren't up to date
+  spin setup --status                     # Show which tasks would run
+  spin setup --force                      # Run every task
+  spin setup --skip seed                  # Leave a task for later
+  spin setup myapp --template rails-api   # Scaffold a new project
+  spin setup --list-templates             # Show the available templates`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if list, _ := cmd.Flags().GetBool("list-templates"); list {
+			return listTemplates()
+		}
+		if name, _ := cmd.Flags().GetString("template"); name != "" {
+			force, _ := cmd.Flags().GetBool("force")
+			return scaffoldProject(name, args, force)
+		}
+
+		cfg, err := config.LoadConfig("spin.config.json")
+		status, _ := cmd.Flags().GetBool("status")
+		if skip, _ := cmd.Flags().GetBool("skip-tools-check"); err == nil && !skip && !status {
+			if !checkDependencies(cfg, ".") {
+				fmt.Printf("%sInstall the missing tools, or pass --skip-tools-check%s\n", lg.Yellow, lg.Reset)
+				os.Exit(1)
+			}
+		}
+		if err != nil || len(cfg.Setup) == 0 {
+			// Forward to the setup script
+			return scriptsRunCmd.RunE(cmd, append([]string{"setup"}, args...))
+		}
+
+		if status {
+			statuses, err := setup.Statuses(cfg, ".")
+			if err != nil {
+				return fmt.Errorf("failed to read setup state: %w", err)
+			}
+			for _, s := range statuses {
+				color := lg.Yellow
+				if s.Status == setup.StatusUpToDate {
+					color = lg.Green
+				}
+				fmt.Printf("  %s: %s%s%s", s.Task.Name, color, s.Status, lg.Reset)
+				if s.Record != nil {
+					fmt.Printf(" (last run %s)", s.Record.CompletedAt.Format("2006-01-02 15:04"))
+				}
+				fmt.Println()
+			}
+			return nil
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		skip, _ := cmd.Flags().GetStringSlice("skip")
+		if unknown := setup.UnknownTasks(cfg, skip); len(unknown) > 0 {
+			return fmt.Errorf("no setup tasks named %s", strings.Join(unknown, ", "))
+		}
+		lg.Printf("%sSetting up %s%s%s...%s\n", lg.Blue, lg.Cyan, cfg.Name, lg.Blue, lg.Reset)
+		started := time.Now()
+		err = setup.Run(cfg, ".", force, skip)
+		if err := script.RecordTiming(cfg.Name, "setup", started, err); err != nil {
+			lg.Warnf("not recording how long setup took: %v", err)
+		}
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		lg.Printf("%sSetup complete%s\n", lg.Green, lg.Reset)
+		return nil
+	},
+}
+
+// listTemplates prints the templates spin setup --template can scaffold
+func listTemplates() error {
+	if err := scaffold.Update(); err != nil {
+		lg.Warnf("%v", err)
+	}
+	templates, err := scaffold.List()
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	fmt.Println("Available templates:")
+	for _, t := range templates {
+		fmt.Printf("  - %s %s(%s)%s\n", t.Name, lg.Blue, t.Source, lg.Reset)
+	}
+	return nil
+}
+
+// scaffoldProject writes the files of a template into a new project
+// directory, or the current one when no name or "." is given
+func scaffoldProject(name string, args []string, force bool) error {
+	appPath := "."
+	if len(args) > 0 {
+		appPath = args[0]
+	}
+	appName := filepath.Base(appPath)
+	if appPath == "." {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		appName = filepath.Base(wd)
+	}
+
+	if err := scaffold.Update(); err != nil {
+		lg.Warnf("%v, using the templates fetched before", err)
+	}
+	tmpl, err := scaffold.Find(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(appPath, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	written, err := tmpl.Render(appPath, scaffold.Data{Name: appName}, force)
+	if err != nil {
+		return fmt.Errorf("failed to scaffold %s: %w (use --force to overwrite)", appName, err)
+	}
+
+	lg.Printf("\n%s✨ Scaffolded %s%s%s from the %s template%s\n", lg.Green, lg.Cyan, appName, lg.Green, tmpl.Name, lg.Reset)
+	for _, path := range written {
+		lg.Printf("  %s✓%s %s\n", lg.Green, lg.Reset, path)
+	}
+
+	fmt.Printf("\n%sNext steps:%s\n", lg.Purple, lg.Reset)
+	fmt.Printf("  %s1.%s cd %s%s%s\n", lg.Yellow, lg.Reset, lg.Cyan, appPath, lg.Reset)
+	fmt.Printf("  %s2.%s Run %sspin setup%s to install dependencies\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
+	fmt.Printf("  %s3.%s Run %sspin up%s to start development\n", lg.Yellow, lg.Reset, lg.Cyan, lg.Reset)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(setupCmd)
+	setupCmd.Flags().Bool("force", false, "Run every task, or overwrite existing files with --template")
+	setupCmd.Flags().Bool("status", false, "Show which tasks are up to date without running them")
+	setupCmd.Flags().StringSlice("skip", nil, "Setup tasks to leave out, like seed")
+	setupCmd.Flags().String("template", "", "Scaffold a new project from a template")
+	setupCmd.Flags().Bool("list-templates", false, "Show the available templates")
+	setupCmd.Flags().Bool("skip-tools-check", false, "Run even if tools of dependencies.tools are missing")
+
+	// Flags of the setup script
+	setupCmd.Flags().StringSliceVarP(&scriptEnv, "env", "e", []string{}, "Environment variables (KEY=VALUE)")
+	setupCmd.Flags().StringVarP(&workDir, "workdir", "w", "", "Working directory")
+	setupCmd.Flags().BoolVarP(&skipHookError, "skip-hook-error", "s", false, "Skip hook errors")
+}
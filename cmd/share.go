@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/script"
+	"github.com/afomera/spin/internal/share"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/afomera/spin/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+// shareCmd represents the share command
+var shareCmd = &cobra.Command{
+	Use:   "share [process]",
+	Short: "Expose a process on a public URL",
+	Long: `Expose a process, web by default, on a public URL through ngrok or a
+cloudflared quick tunnel, to show work in progress or receive webhooks.
+
+The tunnel runs as a process named share-<process>, which shows up in spin ps
+and stops with spin down. Its public URL is shown by spin ps, the dashboard
+and the control API, and follows the tunnel when it reconnects.
+
+The provider is whichever of ngrok and cloudflared is installed, or the one
+set with spin config set-share, which also stores the ngrok auth token. The
+port is the PORT the process was started with, the port its command passes
+with -p or --port, or 3000.
+
+Example:
+  spin share                      # Share the web process
+  spin share api --port 4000      # Share another process on a given port
+  spin share --provider cloudflared
+  spin share --stop               # Stop sharing the web process`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: firstArg(completeProcessNames),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+		name := "web"
+		if len(args) > 0 {
+			name = args[0]
+		}
+		shareName := share.ProcessName(name)
+		manager := process.GetManager(cfg)
+		port, _ := cmd.Flags().GetInt("port")
+		provider, _ := cmd.Flags().GetString("provider")
+
+		if serve, _ := cmd.Flags().GetBool("serve"); serve {
+			serveShare(cfg, manager, name, provider, port)
+			return
+		}
+
+		if stop, _ := cmd.Flags().GetBool("stop"); stop {
+			if _, err := manager.FindProcess(shareName); err != nil {
+				fmt.Printf("%s%s is not shared%s\n", lg.Yellow, name, lg.Reset)
+				return
+			}
+			if err := manager.StopProcess(cfg.Name, shareName); err != nil {
+				fmt.Printf("%sError stopping %s: %v%s\n", lg.Red, shareName, err, lg.Reset)
+				os.Exit(1)
+			}
+			lg.Printf("%sStopped sharing %s%s\n", lg.Green, name, lg.Reset)
+			return
+		}
+
+		if _, err := manager.FindProcess(shareName); err == nil {
+			if info, err := manager.Store().GetProcess(shareName); err == nil && info.URL != "" {
+				fmt.Printf("%s%s is shared at %s%s\n", lg.Green, name, info.URL, lg.Reset)
+				return
+			}
+			fmt.Printf("%s%s is already running, see spin logs %s%s\n", lg.Yellow, shareName, shareName, lg.Reset)
+			return
+		}
+		if _, err := manager.FindProcess(name); err != nil {
+			lg.Warnf("%s is not running, the URL will only answer once it is", name)
+		}
+
+		if port == 0 {
+			port = processPort(cfg, manager, name)
+		}
+		if provider == "" {
+			if userCfg, err := userconfig.Load(); err == nil {
+				provider = userCfg.ShareProvider
+			}
+		}
+		provider, err = share.Detect(provider)
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+		lg.Printf("%s-> Starting %s: %s on port %d%s\n", lg.Blue, shareName, provider, port, lg.Reset)
+		shareArgs := []string{"share", script.ShellQuote(name), "--serve", "--provider", provider, "--port", strconv.Itoa(port)}
+		if err := manager.StartProcess(cfg.Name, shareName, exe, shareArgs, os.Environ(), "."); err != nil {
+			fmt.Printf("%sError starting %s: %v%s\n", lg.Red, shareName, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		// The share process records the URL once the provider reports it
+		deadline := time.Now().Add(30 * time.Second)
+		for time.Now().Before(deadline) {
+			if info, err := manager.Store().GetProcess(shareName); err == nil && info.URL != "" {
+				fmt.Printf("%s%s is shared at %s%s\n", lg.Green, name, info.URL, lg.Reset)
+				return
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+		fmt.Printf("%sNo public URL yet, see spin logs %s%s\n", lg.Yellow, shareName, lg.Reset)
+	},
+}
+
+// serveShare runs the tunnel of a share process in the foreground and
+// records its public URL in the process store
+func serveShare(cfg *config.Config, manager *process.Manager, name string, provider string, port int) {
+	token := ""
+	if userCfg, err := userconfig.Load(); err == nil {
+		token = userCfg.NgrokAuthToken
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err := share.Run(ctx, provider, port, token, os.Stdout, func(url string) {
+		fmt.Printf("%sSharing %s at %s%s\n", lg.Green, name, url, lg.Reset)
+		if err := manager.Store().SetURL(cfg.Name, share.ProcessName(name), url); err != nil {
+			lg.Warnf("failed to record the URL: %v", err)
+		}
+	})
+	if err != nil {
+		fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+		os.Exit(1)
+	}
+}
+
+// processPort returns the port a process listens on: the PORT it was started
+// with, the port its command passes with -p or --port, or 3000
+func processPort(cfg *config.Config, manager *process.Manager, name string) int {
+	if vars, err := process.LoadEnv(cfg.Name, name); err == nil {
+		for _, v := range vars {
+			if v.Key == "PORT" {
+				if port, err := strconv.Atoi(v.Value); err == nil {
+					return port
+				}
+			}
+		}
+	}
+	info, _ := manager.Store().GetProcess(name)
+	return commandPort(info.CommandLine)
+}
+
+func init() {
+	rootCmd.AddCommand(shareCmd)
+	shareCmd.Flags().Int("port", 0, "Local port to expose, the process' port by default")
+	shareCmd.Flags().String("provider", "", "Tunnel provider, ngrok or cloudflared")
+	shareCmd.Flags().Bool("stop", false, "Stop sharing the process")
+	shareCmd.Flags().Bool("serve", false, "Run the tunnel in the foreground")
+	shareCmd.Flags().MarkHidden("serve")
+}
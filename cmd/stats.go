@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/metrics"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// metricsProcessName is the name spin up runs the metrics collector under
+const metricsProcessName = "spin-metrics"
+
+// sparklineWidth is the number of bars in the history sparklines
+const sparklineWidth = 30
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show resource usage of processes and services",
+	Long: `Show the CPU and memory usage of the app's processes and services.
+
+spin up records a sample of every process and service every few seconds under
+~/.spin/metrics, keeping the last two hours. Use --history to see how usage
+developed over the last minutes, which helps spotting memory leaks.
+
+Example:
+  spin stats                         # Current usage
+  spin stats --history               # Usage over the last 15 minutes
+  spin stats --history --minutes 60  # Usage over the last hour`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig("spin.config.json")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		if collect, _ := cmd.Flags().GetBool("collect"); collect {
+			interval, _ := cmd.Flags().GetDuration("interval")
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			metrics.Collect(ctx, cfg, interval)
+			return
+		}
+
+		if history, _ := cmd.Flags().GetBool("history"); history {
+			minutes, _ := cmd.Flags().GetInt("minutes")
+			printHistory(cfg, time.Duration(minutes)*time.Minute)
+			return
+		}
+
+		printCurrentStats(cfg)
+	},
+}
+
+// printCurrentStats prints the current usage of running processes and services
+func printCurrentStats(cfg *config.Config) {
+	manager := process.GetManager(cfg)
+	manager.SetQuiet(true)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sNAME\tTYPE\tCPU\tMEMORY%s\n", lg.Cyan, lg.Reset)
+
+	rows := 0
+	for _, p := range manager.ListProcesses() {
+		if p.Type == process.ProcessTypeDocker {
+			continue
+		}
+		fmt.Fprintf(w, "%s\tprocess\t%.1f%%\t%s\n", p.Name, p.CPUPercent, docker.FormatBytes(int64(p.MemoryUsage)))
+		rows++
+	}
+
+	if len(cfg.Services) > 0 {
+		if dm, err := docker.NewServiceManager(""); err == nil {
+			for _, name := range sortedServiceNames(cfg) {
+				if !dm.IsRunning(name) {
+					continue
+				}
+				cpu, memory, err := dm.ServiceUsage(name)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "%s\tservice\t%.1f%%\t%s\n", name, cpu, docker.FormatBytes(int64(memory)))
+				rows++
+			}
+		}
+	}
+
+	if rows == 0 {
+		fmt.Fprintf(w, "%sNo running processes or services%s\n", lg.Yellow, lg.Reset)
+	}
+	w.Flush()
+}
+
+// printHistory prints a summary and sparklines of the recorded usage
+func printHistory(cfg *config.Config, window time.Duration) {
+	since := time.Now().Add(-window)
+
+	type series struct {
+		name    string
+		kind    string
+		samples []metrics.Sample
+	}
+	var all []series
+
+	// Processes of the app, as recorded by the collector
+	appName := process.SanitizeAppName(cfg.Name)
+	names, _ := metrics.ProcessNames(appName)
+	for _, name := range names {
+		ring, err := metrics.ProcessRing(appName, name)
+		if err != nil {
+			continue
+		}
+		if samples, err := ring.Samples(since); err == nil && len(samples) > 0 {
+			all = append(all, series{name: name, kind: "process", samples: samples})
+		}
+	}
+
+	for _, name := range sortedServiceNames(cfg) {
+		ring, err := metrics.ServiceRing(name)
+		if err != nil {
+			continue
+		}
+		if samples, err := ring.Samples(since); err == nil && len(samples) > 0 {
+			all = append(all, series{name: name, kind: "service", samples: samples})
+		}
+	}
+
+	if len(all) == 0 {
+		fmt.Printf("%sNo usage recorded in the last %s%s\n", lg.Yellow, window, lg.Reset)
+		fmt.Printf("Usage is recorded while the %s process started by spin up is running\n", metricsProcessName)
+		return
+	}
+
+	fmt.Printf("%sUsage over the last %s%s\n\n", lg.Blue, window, lg.Reset)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sNAME\tTYPE\tCPU AVG\tCPU MAX\tMEMORY\tCHANGE\tCPU\tMEMORY%s\n", lg.Cyan, lg.Reset)
+	for _, s := range all {
+		summary := metrics.Summarize(s.samples)
+
+		change := "+" + docker.FormatBytes(summary.MemoryDiff)
+		if summary.MemoryDiff < 0 {
+			change = "-" + docker.FormatBytes(-summary.MemoryDiff)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%.1f%%\t%.1f%%\t%s\t%s\t%s\t%s\n",
+			s.name,
+			s.kind,
+			summary.AvgCPU,
+			summary.MaxCPU,
+			docker.FormatBytes(int64(summary.Last.MemoryUsage)),
+			change,
+			metrics.Sparkline(metrics.CPUValues(s.samples), sparklineWidth),
+			metrics.Sparkline(metrics.MemoryValues(s.samples), sparklineWidth),
+		)
+	}
+	w.Flush()
+}
+
+// sortedServiceNames returns the names of the configured services in order
+func sortedServiceNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Services))
+	for name := range cfg.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().Bool("history", false, "Show recorded usage instead of the current usage")
+	statsCmd.Flags().Int("minutes", 15, "Minutes of history to show")
+	statsCmd.Flags().Bool("collect", false, "Record usage in the foreground until interrupted")
+	statsCmd.Flags().Duration("interval", metrics.DefaultInterval, "Time between samples when collecting")
+	statsCmd.Flags().MarkHidden("collect")
+	statsCmd.Flags().MarkHidden("interval")
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/detector"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show a summary of the development environment",
+	Long: `Show a one-screen summary of the app: the git branch, the status, health and
+ports of its services, the status and uptime of its processes, pending
+database migrations and any problems found along the way.
+
+It's a quick, non-interactive complement to spin dashboard.
+
+Example:
+  spin status`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig("spin.config.json")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		var problems []string
+
+		fmt.Printf("\n%s%s%s", lg.Blue, cfg.Name, lg.Reset)
+		if branch := gitBranch(); branch != "" {
+			fmt.Printf(" on %s%s%s", lg.Cyan, branch, lg.Reset)
+			if gitDirty() {
+				fmt.Printf(" %s(uncommitted changes)%s", lg.Yellow, lg.Reset)
+			}
+		}
+		fmt.Printf("\n\n")
+
+		problems = append(problems, printServiceStatus(cfg)...)
+		problems = append(problems, printProcessStatus(cfg)...)
+
+		if pending, err := detector.PendingMigrations("."); err == nil {
+			fmt.Printf("%sMigrations%s\n", lg.Cyan, lg.Reset)
+			if len(pending) == 0 {
+				fmt.Printf("  %s✓%s up to date\n\n", lg.Green, lg.Reset)
+			} else {
+				for _, name := range pending {
+					fmt.Printf("  %s•%s %s\n", lg.Yellow, lg.Reset, name)
+				}
+				fmt.Println()
+				problems = append(problems, fmt.Sprintf("%d pending migration(s), run 'bin/rails db:migrate'", len(pending)))
+			}
+		}
+
+		if len(problems) == 0 {
+			fmt.Printf("%s✓ No problems found%s\n\n", lg.Green, lg.Reset)
+			return
+		}
+		fmt.Printf("%sProblems%s\n", lg.Red, lg.Reset)
+		for _, problem := range problems {
+			fmt.Printf("  %s⚠%s %s\n", lg.Yellow, lg.Reset, problem)
+		}
+		fmt.Println()
+	},
+}
+
+// printServiceStatus prints the status, health and port of every service of
+// the app and returns the problems found
+func printServiceStatus(cfg *config.Config) []string {
+	if len(cfg.Services) == 0 {
+		return nil
+	}
+
+	fmt.Printf("%sServices%s\n", lg.Cyan, lg.Reset)
+	dm, err := docker.NewServiceManager("")
+	if err != nil {
+		fmt.Printf("  %sDocker is not available: %v%s\n\n", lg.Red, err, lg.Reset)
+		return []string{"Docker is not available, start it to run services"}
+	}
+
+	var problems []string
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, name := range sortedServiceNames(cfg) {
+		service := cfg.Services[name]
+		port := "-"
+		if service.Port > 0 {
+			port = fmt.Sprintf("%d", service.Port)
+		}
+
+		if !dm.IsRunning(name) {
+			fmt.Fprintf(w, "  %s\t%s\t\t%s\t-\n", name, colorizeStatus(process.StatusStopped), port)
+			problems = append(problems, fmt.Sprintf("service %s is not running, start it with 'spin services start %s'", name, name))
+			continue
+		}
+
+		health, _ := dm.HealthStatus(name)
+		switch health {
+		case "healthy":
+			health = fmt.Sprintf("%s%s%s", lg.Green, health, lg.Reset)
+		case "unhealthy":
+			problems = append(problems, fmt.Sprintf("service %s is unhealthy, check 'spin services logs %s'", name, name))
+			health = fmt.Sprintf("%s%s%s", lg.Red, health, lg.Reset)
+		case "":
+		default:
+			health = fmt.Sprintf("%s%s%s", lg.Yellow, health, lg.Reset)
+		}
+
+		uptime := "-"
+		if started, err := dm.StartedAt(name); err == nil {
+			uptime = process.FormatUptime(time.Since(started))
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", name, colorizeStatus(process.StatusRunning), health, port, uptime)
+	}
+	w.Flush()
+	fmt.Println()
+	return problems
+}
+
+// printProcessStatus prints the status and uptime of the app's processes and
+// returns the problems found
+func printProcessStatus(cfg *config.Config) []string {
+	manager := process.GetManager(cfg)
+	manager.SetQuiet(true)
+
+	fmt.Printf("%sProcesses%s\n", lg.Cyan, lg.Reset)
+	var problems []string
+	rows := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, p := range manager.ListProcesses() {
+		if p.Type == process.ProcessTypeDocker {
+			continue
+		}
+		rows++
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", p.Name, colorizeStatus(p.Status), process.FormatUptime(p.Uptime()))
+
+		switch {
+		case p.Error != nil:
+			problems = append(problems, fmt.Sprintf("process %s failed: %v", p.Name, p.Error))
+		case p.Status != process.StatusRunning && p.Status != process.StatusStarting:
+			problems = append(problems, fmt.Sprintf("process %s is %s, check 'spin logs %s'", p.Name, p.Status, p.Name))
+		}
+	}
+	if rows == 0 {
+		fmt.Fprintf(w, "  %sNo running processes, start them with 'spin up'%s\n", lg.Yellow, lg.Reset)
+	}
+	w.Flush()
+	fmt.Println()
+	return problems
+}
+
+// gitBranch returns the branch checked out in the current directory
+func gitBranch() string {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitDirty reports whether the working tree has uncommitted changes
+func gitDirty() bool {
+	out, err := exec.Command("git", "status", "--porcelain").Output()
+	return err == nil && len(strings.TrimSpace(string(out))) > 0
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
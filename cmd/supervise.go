@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/afomera/spin/internal/events"
+	"github.com/afomera/spin/internal/process"
+	"github.com/spf13/cobra"
+)
+
+// superviseCmd runs a single process for the native process backend. It is
+// started in the background by spin up and spin run, not by users.
+var superviseCmd = &cobra.Command{
+	Use:    "supervise --socket [path] --log [path] -- [command...]",
+	Short:  "Supervise a process in a pseudo-terminal",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		app, _ := cmd.Flags().GetString("app")
+		name, _ := cmd.Flags().GetString("name")
+		socket, _ := cmd.Flags().GetString("socket")
+		logFile, _ := cmd.Flags().GetString("log")
+		stopTimeout, _ := cmd.Flags().GetDuration("stop-timeout")
+		signalName, _ := cmd.Flags().GetString("stop-signal")
+		stopSignal, err := process.ParseSignal(signalName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "spin supervise: %v\n", err)
+			os.Exit(1)
+		}
+
+		supervisor := &process.Supervisor{
+			CommandLine: strings.Join(args, " "),
+			LogFile:     logFile,
+			Socket:      socket,
+			StopSignal:  stopSignal,
+			StopTimeout: stopTimeout,
+			OnExit: func(err error) {
+				e := events.Event{Type: events.ProcessCrashed, App: app, Name: name}
+				if err != nil {
+					e.Message = err.Error()
+				}
+				if err := events.Publish(e); err != nil {
+					fmt.Fprintf(os.Stderr, "spin supervise: %v\n", err)
+				}
+			},
+		}
+		if err := supervisor.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "spin supervise: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(superviseCmd)
+	superviseCmd.Flags().String("app", "", "App the process belongs to, used in events")
+	superviseCmd.Flags().String("name", "", "Name of the process, used in events")
+	superviseCmd.Flags().String("socket", "", "Path of the control socket")
+	superviseCmd.Flags().String("log", "", "File to append the process output to")
+	superviseCmd.Flags().String("stop-signal", process.SignalName(process.DefaultStopSignal), "Signal sent to stop the process")
+	superviseCmd.Flags().Duration("stop-timeout", process.DefaultStopTimeout, "Time to exit before the process is killed")
+	superviseCmd.MarkFlagRequired("socket")
+	superviseCmd.MarkFlagRequired("log")
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	testCmd := addShorthandCommand("test")
+	testCmd.Long = `Run the test script.
+
+With --parallel N, spin creates N test databases in the project's PostgreSQL
+service, named like myapp_test_1 to myapp_test_N, loads the schema into each
+of them and runs the test script with the variables the parallel_tests gem
+expects: PARALLEL_TEST_PROCESSORS, PARALLEL_TEST_FIRST_IS_1 and the PG
+variables of the service. Workers pick their database by TEST_ENV_NUMBER.
+Rails' parallelize creates databases of its own and doesn't need --parallel.
+The schema is loaded in the dev container when the project has one. The
+databases are dropped when the tests finish.
+
+Example:
+  spin test                        # Run the test script
+  spin test -- spec/models         # Append arguments to the command
+  spin test --parallel 8           # Run on 8 test databases
+  spin test --parallel 8 --keep-databases`
+	testCmd.Flags().Int("parallel", 0, "Create this many test databases and run the tests on them in parallel")
+	testCmd.Flags().Bool("keep-databases", false, "Keep the parallel test databases after the run")
+
+	runTests := testCmd.RunE
+	testCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		workers, _ := cmd.Flags().GetInt("parallel")
+		if workers <= 0 {
+			return runTests(cmd, args)
+		}
+		keep, _ := cmd.Flags().GetBool("keep-databases")
+
+		cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+		name, svcCfg, err := testDatabaseService(cfg)
+		if err != nil {
+			return err
+		}
+		startServices(cfg, []string{name})
+
+		dm, err := docker.NewServiceManager("./data")
+		if err != nil {
+			return fmt.Errorf("failed to create Docker manager: %w", err)
+		}
+		lg.Printf("%sCreating %d test databases in %s...%s\n", lg.Blue, workers, name, lg.Reset)
+		databases, err := dm.CreateTestDatabases(name, svcCfg, cfg.Name, workers)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			defer func() {
+				lg.Printf("%sDropping test databases...%s\n", lg.Blue, lg.Reset)
+				if err := dm.DropTestDatabases(name, svcCfg, databases); err != nil {
+					lg.Warnf("%v", err)
+				}
+			}()
+		}
+
+		// Interrupting the tests ends them, spin stays to drop the databases
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(interrupts)
+
+		env := docker.TestDatabaseEnv(svcCfg, workers)
+		if cfg.Type == "rails" {
+			lg.Printf("%sLoading the schema into %s to %s...%s\n", lg.Blue, databases[0], databases[len(databases)-1], lg.Reset)
+			if err := prepareTestDatabases(cfg, name, svcCfg, env, workers); err != nil {
+				return err
+			}
+		}
+
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			scriptEnv = append(scriptEnv, key+"="+env[key])
+		}
+		return runTests(cmd, args)
+	}
+}
+
+// testDatabaseService returns the PostgreSQL Docker service of a project that
+// parallel test databases are created in
+func testDatabaseService(cfg *config.Config) (string, *config.DockerServiceConfig, error) {
+	for _, name := range cfg.Dependencies.Services {
+		if svcCfg, ok := cfg.Services[name]; ok && docker.IsPostgres(svcCfg) {
+			return name, svcCfg, nil
+		}
+	}
+	return "", nil, fmt.Errorf("parallel tests need a PostgreSQL Docker service in services and dependencies.services")
+}
+
+// prepareTestDatabases loads the schema into the test database of each
+// worker at the same time, with the TEST_ENV_NUMBER of the worker. Like the
+// preflight tasks of spin up, it runs in the dev container when the project
+// has one, and with the project's Ruby otherwise.
+func prepareTestDatabases(cfg *config.Config, service string, svcCfg *config.DockerServiceConfig, env map[string]string, workers int) error {
+	var dev *devContainer
+	hostEnv := rubyEnv(os.Environ(), ".", false)
+	if cfg.DevContainer != nil {
+		dev = startDevContainer(cfg, ".")
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			var output bytes.Buffer
+			workerEnv := map[string]string{"TEST_ENV_NUMBER": strconv.Itoa(n)}
+			for key, value := range env {
+				workerEnv[key] = value
+			}
+			var prepare *exec.Cmd
+			if dev != nil {
+				// The container reaches the service by its name
+				workerEnv["PGHOST"] = service
+				workerEnv["PGPORT"] = strconv.Itoa(svcCfg.Port)
+				prepare = dev.command("", workerEnv, "bundle", "exec", "rails", "db:test:prepare")
+			} else {
+				prepare = exec.Command("bundle", "exec", "rails", "db:test:prepare")
+				prepare.Env = append([]string{}, hostEnv...)
+				for key, value := range workerEnv {
+					prepare.Env = append(prepare.Env, key+"="+value)
+				}
+			}
+			prepare.Stdout = &output
+			prepare.Stderr = &output
+			if err := prepare.Run(); err != nil {
+				errs[n-1] = fmt.Errorf("failed to prepare test database %d: %w\n%s", n, err, output.String())
+			}
+		}(i + 1)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+// toolsCmd represents the tools command
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Check the tool versions the project needs",
+	Long: `Check the installed versions of Ruby, Node and Go against the versions the
+project asks for in .tool-versions, .ruby-version, .nvmrc, .node-version and
+go.mod. The go directive of go.mod is a minimum version, other versions match
+by prefix, so 3.3 is satisfied by 3.3.5.
+
+The tools listed in dependencies.tools of spin.config.json are looked up on
+the PATH and in node_modules/.bin and bin of the project. An entry can carry a
+version constraint, like "node >=20", "ruby ~> 3.2" or "yarn 4".
+
+Example:
+  spin tools            # Show required and installed versions
+  spin tools install    # Install missing versions with mise, asdf, rbenv or nodenv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if cfg, err := config.LoadConfig("spin.config.json"); err == nil && len(cfg.Dependencies.Tools) > 0 {
+			printDependencies(cfg)
+		}
+
+		checks := tools.CheckAll(".")
+		if len(checks) == 0 {
+			fmt.Printf("%sNo tool versions found in .tool-versions, .ruby-version, .nvmrc, .node-version or go.mod%s\n", lg.Yellow, lg.Reset)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%sTOOL\tREQUIRED\tINSTALLED\tSOURCE\tMANAGER%s\n", lg.Cyan, lg.Reset)
+		for _, c := range checks {
+			installed := c.Installed
+			if installed == "" {
+				installed = "not installed"
+			}
+			status := fmt.Sprintf("%s%s%s", lg.Green, installed, lg.Reset)
+			if !c.OK {
+				status = fmt.Sprintf("%s%s%s", lg.Red, installed, lg.Reset)
+			}
+			manager := tools.Manager(c.Tool)
+			if manager == "" {
+				manager = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Tool, c.Constraint(), status, c.Source, manager)
+		}
+		w.Flush()
+
+		for _, c := range checks {
+			if !c.OK {
+				fmt.Printf("\n%sTo fix %s: %s%s\n", lg.Yellow, c.Tool, c.Hint(), lg.Reset)
+			}
+		}
+	},
+}
+
+// printDependencies prints where the tools of dependencies.tools were found
+func printDependencies(cfg *config.Config) {
+	checks, err := tools.CheckDependencies(".", cfg.Dependencies.Tools)
+	if err != nil {
+		fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sDEPENDENCY\tINSTALLED\tPATH%s\n", lg.Cyan, lg.Reset)
+	for _, c := range checks {
+		installed := c.Installed
+		switch {
+		case c.Pending:
+			installed = "with the project's packages"
+		case c.Path == "":
+			installed = "not installed"
+		case installed == "":
+			installed = "yes"
+		}
+		color := lg.Green
+		if !c.OK {
+			color = lg.Red
+		}
+		fmt.Fprintf(w, "%s\t%s%s%s\t%s\n", c.Dependency, color, installed, lg.Reset, c.Path)
+	}
+	w.Flush()
+
+	for _, c := range checks {
+		if !c.OK {
+			fmt.Printf("\n%sTo fix %s: %s%s\n", lg.Yellow, c.Name, c.Hint(), lg.Reset)
+		}
+	}
+	fmt.Println()
+}
+
+var toolsInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the tool versions the project needs",
+	Long: `Install the versions of Ruby, Node and Go the project asks for that aren't
+installed yet, using the first of mise, asdf, rbenv or nodenv that handles the
+tool.
+
+Example:
+  spin tools install`,
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := false
+		installed := 0
+		for _, c := range tools.CheckAll(".") {
+			if c.OK {
+				continue
+			}
+			lg.Printf("%sInstalling %s %s...%s\n", lg.Blue, c.Tool, c.Version, lg.Reset)
+			if err := tools.Install(".", c.Requirement); err != nil {
+				fmt.Printf("%sError installing %s: %v%s\n", lg.Red, c.Tool, err, lg.Reset)
+				failed = true
+				continue
+			}
+			installed++
+		}
+		if failed {
+			os.Exit(1)
+		}
+		if installed == 0 {
+			lg.Printf("%sAll tool versions are installed%s\n", lg.Green, lg.Reset)
+			return
+		}
+
+		// The manager may not be activated in this shell, check again
+		if !checkTools(".") {
+			fmt.Printf("%sInstalled, but the versions above aren't on your PATH yet. Activate your version manager in your shell.%s\n", lg.Yellow, lg.Reset)
+			os.Exit(1)
+		}
+		lg.Printf("%sAll tool versions are installed%s\n", lg.Green, lg.Reset)
+	},
+}
+
+// checkDependencies prints the tools of dependencies.tools that are missing
+// or don't meet their version constraint, with how to install them, and
+// reports whether all of them are installed
+func checkDependencies(cfg *config.Config, dir string) bool {
+	checks, err := tools.CheckDependencies(dir, cfg.Dependencies.Tools)
+	if err != nil {
+		fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+		return false
+	}
+
+	ok := true
+	for _, c := range checks {
+		if c.OK {
+			continue
+		}
+		ok = false
+		if c.Path == "" {
+			fmt.Printf("%sError: %s is required but not installed%s\n", lg.Red, c.Name, lg.Reset)
+		} else {
+			installed := c.Installed
+			if installed == "" {
+				installed = "an unknown version"
+			}
+			fmt.Printf("%sError: %s is required, found %s%s\n", lg.Red, c.Dependency, installed, lg.Reset)
+		}
+		fmt.Printf("  %s\n", c.Hint())
+	}
+	return ok
+}
+
+// checkTools prints the tools of the project in dir whose installed version
+// doesn't match, with how to fix them, and reports whether all of them match
+func checkTools(dir string) bool {
+	ok := true
+	for _, c := range tools.CheckAll(dir) {
+		if c.OK {
+			continue
+		}
+		ok = false
+		installed := c.Installed
+		if installed == "" {
+			installed = "not installed"
+		}
+		fmt.Printf("%sError: %s requires %s %s, found %s%s\n", lg.Red, c.Source, c.Tool, c.Constraint(), installed, lg.Reset)
+		fmt.Printf("  %s\n", c.Hint())
+	}
+	return ok
+}
+
+func init() {
+	rootCmd.AddCommand(toolsCmd)
+	toolsCmd.AddCommand(toolsInstallCmd)
+}
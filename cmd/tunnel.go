@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/script"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/afomera/spin/internal/tunnel"
+	"github.com/spf13/cobra"
+)
+
+// tunnelCmd represents the tunnel command
+var tunnelCmd = &cobra.Command{
+	Use:   "tunnel",
+	Short: "Manage SSH tunnels to remote services",
+	Long: `Manage the SSH port forwards of the "tunnels" section in spin.config.json,
+like a tunnel to a staging database or a shared Kafka cluster.
+
+spin up opens every tunnel as a process named tunnel-<name>, which shows up
+in spin ps and spin logs and stops with spin down. The process checks the
+forward every health_interval and reconnects when ssh exits or the forward
+stops accepting connections. ssh never prompts, so the server must accept a
+key from identity_file or the SSH agent.
+
+Example:
+  spin tunnel list                  # Show tunnels and whether they are up
+  spin tunnel start staging-db      # Open a tunnel
+  spin tunnel stop                  # Close all tunnels`,
+}
+
+// tunnelListCmd represents the tunnel list command
+var tunnelListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the tunnels and whether they are up",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadTunnelConfig()
+		manager := process.GetManager(cfg)
+		for _, name := range tunnelNames(cfg) {
+			t := cfg.Tunnels[name]
+			status := lg.Yellow + "stopped"
+			if _, err := manager.FindProcess(tunnel.ProcessName(name)); err == nil {
+				status = lg.Red + "reconnecting"
+				if tunnel.Healthy(t) {
+					status = lg.Green + "up"
+				}
+			}
+			fmt.Printf("%-20s localhost:%-6d -> %s:%d via %s  %s%s\n", name, t.LocalPort, t.GetRemoteHost(), t.RemotePort, t.Host, status, lg.Reset)
+		}
+	},
+}
+
+// tunnelStartCmd represents the tunnel start command
+var tunnelStartCmd = &cobra.Command{
+	Use:   "start [name...]",
+	Short: "Open tunnels, all of them by default",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadTunnelConfig()
+		names := selectTunnels(cfg, args)
+		startTunnels(cfg, process.GetManager(cfg), names, os.Environ(), ".")
+	},
+}
+
+// tunnelStopCmd represents the tunnel stop command
+var tunnelStopCmd = &cobra.Command{
+	Use:   "stop [name...]",
+	Short: "Close tunnels, all of them by default",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadTunnelConfig()
+		manager := process.GetManager(cfg)
+		for _, name := range selectTunnels(cfg, args) {
+			processName := tunnel.ProcessName(name)
+			if _, err := manager.FindProcess(processName); err != nil {
+				continue
+			}
+			if err := manager.StopProcess(cfg.Name, processName); err != nil {
+				fmt.Printf("%sError stopping %s: %v%s\n", lg.Red, processName, err, lg.Reset)
+				continue
+			}
+			lg.Printf("%sStopped %s%s\n", lg.Green, processName, lg.Reset)
+		}
+	},
+}
+
+// tunnelRunCmd keeps a tunnel open, it is what tunnel processes run
+var tunnelRunCmd = &cobra.Command{
+	Use:    "run <name>",
+	Short:  "Keep a tunnel open in the foreground",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadTunnelConfig()
+		t, ok := cfg.Tunnels[args[0]]
+		if !ok {
+			fmt.Printf("%sUnknown tunnel %s%s\n", lg.Red, args[0], lg.Reset)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := tunnel.Run(ctx, args[0], t, os.Stdout); err != nil {
+			fmt.Printf("%sError: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+	},
+}
+
+// loadTunnelConfig loads the project config, exiting when it has no tunnels
+func loadTunnelConfig() *config.Config {
+	cfg, err := config.LoadProject(filepath.Join(".", "spin.config.json"))
+	if err != nil {
+		spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+	}
+	if len(cfg.Tunnels) == 0 {
+		fmt.Printf("%sNo tunnels configured in spin.config.json%s\n", lg.Yellow, lg.Reset)
+		os.Exit(0)
+	}
+	return cfg
+}
+
+// tunnelNames returns the names of the configured tunnels, sorted
+func tunnelNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Tunnels))
+	for name := range cfg.Tunnels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectTunnels returns the tunnels named in args, or all of them
+func selectTunnels(cfg *config.Config, args []string) []string {
+	if len(args) == 0 {
+		return tunnelNames(cfg)
+	}
+	for _, name := range args {
+		if _, ok := cfg.Tunnels[name]; !ok {
+			fmt.Printf("%sUnknown tunnel %s, configured tunnels: %v%s\n", lg.Red, name, tunnelNames(cfg), lg.Reset)
+			os.Exit(1)
+		}
+	}
+	return args
+}
+
+// startTunnels starts a spin tunnel run process for each tunnel that isn't
+// running yet
+func startTunnels(cfg *config.Config, manager *process.Manager, names []string, env []string, workDir string) {
+	exe, err := os.Executable()
+	if err != nil {
+		lg.Warnf("not opening tunnels: %v", err)
+		return
+	}
+	for _, name := range names {
+		processName := tunnel.ProcessName(name)
+		if _, err := manager.FindProcess(processName); err == nil {
+			fmt.Printf("%s-> %s is already running%s\n", lg.Yellow, processName, lg.Reset)
+			continue
+		}
+		lg.Printf("%s-> Starting %s: spin tunnel run %s%s\n", lg.Blue, processName, name, lg.Reset)
+		if err := manager.StartProcess(cfg.Name, processName, exe, []string{"tunnel", "run", script.ShellQuote(name)}, env, workDir); err != nil {
+			lg.Warnf("not opening tunnel %s: %v", name, err)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(tunnelCmd)
+	tunnelCmd.AddCommand(tunnelListCmd)
+	tunnelCmd.AddCommand(tunnelStartCmd)
+	tunnelCmd.AddCommand(tunnelStopCmd)
+	tunnelCmd.AddCommand(tunnelRunCmd)
+}
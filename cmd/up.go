@@ -1,17 +1,33 @@
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/credentials"
+	"github.com/afomera/spin/internal/detector"
+	"github.com/afomera/spin/internal/initjob"
 	lg "github.com/afomera/spin/internal/logger"
 	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/procfile"
+	"github.com/afomera/spin/internal/registry"
+	"github.com/afomera/spin/internal/script"
 	"github.com/afomera/spin/internal/service"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/setup"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/afomera/spin/internal/tools"
 	"github.com/spf13/cobra"
 )
 
@@ -23,10 +39,62 @@ var upCmd = &cobra.Command{
 It reads the spin.config.json file, sets up environment variables,
 and executes the start script.
 
+In a monorepo, the "apps" of spin.config.json each have their own
+spin.config.json, Procfile and services. Their processes are started together,
+named after the app like api-web, and services they share are started once.
+
+Projects coming from foreman or overmind keep their setup: the procfile, port
+and formation of .foreman or .overmind.env are used, and the variables of .env
+are passed to processes. A formation like web=1,worker=2 runs two instances of
+worker, named worker.1 and worker.2, and leaves out processes with a count of
+0. processes.formation in spin.config.json sets the counts the same way, and
+--scale changes the count of single processes. Instances get their own PORT.
+With a port base, from --port, .foreman or processes.port_base, each process
+gets a PORT: 5000 for the first process, 5100 for the second and so on, and
+consecutive ports for instances.
+
+When spin.config.json has a dev_container, processes run inside it instead of
+on the host: the container is started from its image or built from its
+Dockerfile, the project is mounted into it and services are reachable by their
+names over the spin network. bundle install and migrations run in it too. Pass
+--on-host to run processes on the host anyway.
+
+Before starting processes, dependencies are installed and databases
+migrated, each only when its inputs changed since it last succeeded, which is
+recorded in .spin/state/up.json:
+  Ruby      bundle install, and rails db:migrate in Rails apps
+  Node.js   npm, yarn, pnpm or bun install
+  Go        go mod download
+  Elixir    mix deps.get, and mix ecto.migrate with priv/repo/migrations
+A failed migration is reported without stopping spin up. --skip-deps and
+--skip-migrations skip them altogether.
+
+Processes and migrations wait for the services they need to be healthy, not
+only started, so they don't race a database that is still booting. A process
+needs the services listed for it under processes.services, or else those of
+dependencies.services. Each service is waited for up to its ready_timeout, the
+start period of its health check or a minute, after which the process starts
+anyway with a warning. Services without a health check are ready once they
+run. Processes with a ready_timeout under processes.settings, like the dev
+servers spin init finds, are waited for until they listen on their port
+before the next ones start. --no-wait starts processes right away.
+
+Only one spin up starts a project at a time: it holds a lock in ~/.spin/locks
+while it runs, and a second one exits with the PID of the first. --takeover
+stops the first one instead and adopts the tmux sessions and containers it
+started. Locks of spin ups that died are replaced.
+
 Example:
-  spin up myapp`,
+  spin up myapp
+  spin up --formation all=1,worker=2   # Run two workers
+  spin up --scale worker=3             # Run three workers, the rest as configured
+  spin up --port 3000                  # Give processes a PORT from 3000
+  spin up --takeover                   # Replace a spin up that is stuck
+  spin up --no-wait                    # Don't wait for services to be healthy`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		upStarted = time.Now()
+
 		// If no app name is provided, use current directory
 		appPath := "."
 		if len(args) > 0 {
@@ -35,151 +103,294 @@ Example:
 
 		// Load configuration
 		configPath := filepath.Join(appPath, "spin.config.json")
-		cfg, err := config.LoadConfig(configPath)
+		cfg, err := config.LoadProject(configPath)
 		if err != nil {
-			fmt.Printf("%sError loading configuration: %v%s\n", lg.Red, err, lg.Reset)
-			os.Exit(1)
-		}
-
-		// Initialize service manager and required services
-		svcManager := service.NewServiceManager()
-		if len(cfg.Dependencies.Services) > 0 {
-			fmt.Printf("%sChecking required services...%s\n", lg.Blue, lg.Reset)
-			for _, serviceName := range cfg.Dependencies.Services {
-				svc, err := service.CreateService(serviceName, cfg)
-				if err != nil {
-					fmt.Printf("%sError creating service %s: %v%s\n", lg.Red, serviceName, err, lg.Reset)
-					os.Exit(1)
-				}
-				svcManager.RegisterService(svc)
-
-				if !svc.IsRunning() {
-					fmt.Printf("Starting %s%s%s...\n", lg.Cyan, serviceName, lg.Reset)
-					if err := svcManager.StartService(serviceName); err != nil {
-						fmt.Printf("%sError starting service %s: %v%s\n", lg.Red, serviceName, err, lg.Reset)
-						os.Exit(1)
-					}
-				} else {
-					fmt.Printf("%sService %s%s%s is already running%s\n", lg.Green, lg.Cyan, serviceName, lg.Green, lg.Reset)
-				}
-			}
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
 		}
 
-		// Set up environment variables
-		envVars := cfg.GetEnvVars("development")
-		env := os.Environ() // Get existing environment
-		for key, value := range envVars {
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
+		// Only one spin up starts a project at a time
+		takeover, _ := cmd.Flags().GetBool("takeover")
+		lock, err := lockProject(cfg, appPath, takeover)
+		if err != nil {
+			var locked *process.LockedError
+			if errors.As(err, &locked) {
+				failUp(cfg, spinerr.Wrap(spinerr.Process, "can't start "+cfg.Name, err).
+					WithFix("Wait for it to finish, or run spin up --takeover to stop it and adopt what it started"))
+			}
+			failUp(cfg, err)
 		}
+		defer lock.Release()
 
-		// Get process manager
-		processManager := process.GetManager(cfg)
+		// Resolve the processes to start from the Procfile and process groups
+		only, _ := cmd.Flags().GetStringSlice("only")
+		except, _ := cmd.Flags().GetStringSlice("except")
+		entries, err := procfile.Resolve(cfg, appPath, procfile.Selection{Only: only, Except: except})
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				failUp(cfg, spinerr.Wrap(spinerr.Config, "failed to read processes", err))
+			}
+			failUp(cfg, spinerr.Wrap(spinerr.Config, "could not find "+cfg.GetProcfilePath(), err).
+				WithFix(`Generate one with 'spin procfile generate', or set its path in spin.config.json like "processes": {"procfile": "Procfile"}`))
+		}
+		if len(entries) == 0 && (len(only) > 0 || len(except) > 0) {
+			failUp(cfg, spinerr.New(spinerr.Config, "no processes selected"))
+		}
 
-		// Run bundle install if Gemfile exists
-		if _, err := os.Stat(filepath.Join(appPath, "Gemfile")); err == nil {
-			fmt.Printf("%sRunning bundle install...%s\n", lg.Blue, lg.Reset)
-			bundleCmd := exec.Command("bundle", "install")
-			bundleCmd.Dir = appPath
-			bundleCmd.Stdout = os.Stdout
-			bundleCmd.Stderr = os.Stderr
-			if err := bundleCmd.Run(); err != nil {
-				fmt.Printf("%sError running bundle install: %v%s\n", lg.Red, err, lg.Reset)
-				os.Exit(1)
+		// Run the number of instances of each process the formation asks for
+		formation, err := upFormation(cmd, cfg)
+		if err != nil {
+			failUp(cfg, err)
+		}
+		portBase, _ := cmd.Flags().GetInt("port")
+		if portBase == 0 {
+			portBase = cfg.PortBase()
+		}
+		// Ports follow the order of all processes, whichever are selected
+		all, err := procfile.Resolve(cfg, appPath, procfile.Selection{})
+		if err != nil {
+			all = entries
+		}
+		var ports map[string]int
+		if portBase > 0 {
+			ports = procfile.Ports(all, formation, portBase, cfg.PortStep())
+		} else {
+			// Instances can't share a port, they get one even without a port base
+			ports = make(map[string]int)
+			for name, port := range procfile.Ports(all, formation, config.DefaultPortBase, cfg.PortStep()) {
+				if config.InstanceNumber(name) > 0 {
+					ports[name] = port
+				}
 			}
+		}
+		if entries, err = procfile.Scale(entries, formation); err != nil {
+			failUp(cfg, err)
+		}
 
-			// Run database migrations
-			fmt.Printf("%sRunning database migrations...%s\n", lg.Blue, lg.Reset)
-			migrateCmd := exec.Command("bundle", "exec", "rails", "db:migrate")
-			migrateCmd.Dir = appPath
-			migrateCmd.Stdout = os.Stdout
-			migrateCmd.Stderr = os.Stderr
-			if err := migrateCmd.Run(); err != nil {
-				fmt.Printf("%sError running migrations: %v%s\n", lg.Red, err, lg.Reset)
-				os.Exit(1)
+		// The dev container brings the project's toolchain
+		onHost, _ := cmd.Flags().GetBool("on-host")
+		useDevContainer := cfg.DevContainer != nil && !onHost
+
+		// Make sure the versions of Ruby, Node and Go the project asks for are installed
+		if skip, _ := cmd.Flags().GetBool("skip-tools-check"); !skip && !useDevContainer {
+			if ok := checkDependencies(cfg, appPath); !checkTools(appPath) || !ok {
+				failUp(cfg, spinerr.New(spinerr.Execution, "missing tool versions").
+					WithFix("Run 'spin tools install' to install the missing versions, or pass --skip-tools-check"))
 			}
 		}
 
-		fmt.Printf("%sStarting development environment for %s%s%s...%s\n", lg.Blue, lg.Cyan, cfg.Name, lg.Blue, lg.Reset)
+		// Start required services, and those processes ask for
+		startServices(cfg, entryServices(cfg, entries))
+		noWait, _ := cmd.Flags().GetBool("no-wait")
+		gate := newServiceGate(cfg, noWait)
 
-		// Get the Procfile path from config
-		procfilePath := filepath.Join(appPath, cfg.GetProcfilePath())
+		// Run one-time bootstrap jobs that haven't completed yet
+		if len(cfg.Init) > 0 {
+			if rerun, _ := cmd.Flags().GetBool("rerun-init"); rerun {
+				if err := initjob.Reset(cfg.Name); err != nil {
+					failUp(cfg, spinerr.Wrap(spinerr.Execution, "failed to reset init jobs", err))
+				}
+			}
+			if err := initjob.Run(cfg, appPath); err != nil {
+				failUp(cfg, spinerr.Wrap(spinerr.Execution, "failed to run init jobs", err))
+			}
+		}
 
-		// Parse and start processes from Procfile
-		procfile, err := os.Open(procfilePath)
-		if err != nil {
-			fmt.Printf("%sError: Could not find %s: %v%s\n", lg.Red, cfg.GetProcfilePath(), err, lg.Reset)
-			fmt.Printf("%sEnsure %s exists or configure a custom path in spin.config.json:%s\n", lg.Yellow, cfg.GetProcfilePath(), lg.Reset)
-			fmt.Println(`{
-		"processes": {
-		  "procfile": "your-procfile-name"
+		// Run the pre_up hooks, like installing dependencies
+		if err := runLifecycleHooks(cfg, "pre_up", appPath); err != nil {
+			failUp(cfg, err)
 		}
-}`)
-			os.Exit(1)
+		if err := runAppHooks(cfg, "pre_up", appPath); err != nil {
+			failUp(cfg, err)
+		}
+
+		// Set up environment variables, with the project's Ruby first on the PATH
+		var dev *devContainer
+		env := processEnv(cfg, appPath)
+		if useDevContainer {
+			dev = startDevContainer(cfg, appPath)
+		} else {
+			env = rubyEnv(env, appPath, true)
+		}
+
+		// Get process manager and bring its store in line with what is running
+		processManager := process.GetManager(cfg)
+		reconcileProcesses(processManager)
+
+		// Install dependencies and migrate databases, each only when its
+		// inputs changed since it last succeeded
+		runPreflight(cmd, appPath, dev, env, gate)
+
+		// Processes written like "web: docker:" run the app image, which is
+		// built the first time
+		if usesAppImage(entries) {
+			ensureAppImage(cfg, appPath)
 		}
-		defer procfile.Close()
 
-		fmt.Printf("\n%sStarting processes from %s%s\n", lg.Blue, cfg.GetProcfilePath(), lg.Reset)
+		lg.Printf("%sStarting development environment for %s%s%s...%s\n", lg.Blue, lg.Cyan, cfg.Name, lg.Blue, lg.Reset)
+
+		lg.Printf("\n%sStarting processes from %s%s\n", lg.Blue, cfg.GetProcfilePath(), lg.Reset)
 
-		scanner := bufio.NewScanner(procfile)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "" || strings.HasPrefix(line, "#") {
+		for _, entry := range entries {
+			command, args := procfile.SplitCommand(entry.Command)
+			if command == "" {
 				continue
 			}
 
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) != 2 {
+			// Processes left running by a previous spin up keep running
+			if _, err := processManager.FindProcess(entry.Name); err == nil {
+				fmt.Printf("%s-> %s is already running%s\n", lg.Yellow, entry.Name, lg.Reset)
 				continue
 			}
 
-			procName := strings.TrimSpace(parts[0])
-			procCommand := strings.TrimSpace(parts[1])
+			// Log the process we're about to start
+			processCmd := command
+			if len(args) > 0 {
+				processCmd += " " + strings.Join(args, " ")
+			}
+			gate.wait(entry.Name, cfg.ProcessServices(entry.Name))
+			lg.Printf("%s-> Starting %s: %s%s\n", lg.Blue, entry.Name, processCmd, lg.Reset)
 
-			// Special handling for npm-related commands to preserve colons and other special characters
-			var command string
-			var args []string
+			// Processes of apps run in the app's directory with its env
+			entryEnv, workDir := env, appPath
+			if entry.Dir != "" {
+				workDir = filepath.Join(appPath, entry.Dir)
+				if dev == nil {
+					entryEnv = rubyEnv(env, workDir, false)
+				}
+			}
+			if len(entry.Env) > 0 {
+				entryEnv = append([]string{}, entryEnv...)
+				for key, value := range entry.Env {
+					entryEnv = append(entryEnv, fmt.Sprintf("%s=%s", key, value))
+				}
+			}
+			// Like foreman, processes learn their port and instance name
+			if port, ok := ports[entry.Name]; ok {
+				entryEnv = append(entryEnv[:len(entryEnv):len(entryEnv)], fmt.Sprintf("PORT=%d", port), "PS="+entry.Name)
+			}
 
-			if strings.HasPrefix(procCommand, "yarn ") ||
-				strings.HasPrefix(procCommand, "npm ") ||
-				strings.HasPrefix(procCommand, "npx ") {
-				// For npm-related commands, keep the command intact
-				parts := strings.SplitN(procCommand, " ", 2)
-				command = parts[0] // yarn, npm, or npx
-				if len(parts) > 1 {
-					// Keep the rest as a single argument to preserve colons and other special characters
-					args = []string{parts[1]}
+			// Processes in containers get their variables from the command line
+			containerEnv := make(map[string]string)
+			for key, value := range entry.Env {
+				containerEnv[key] = value
+			}
+			port, hasPort := ports[entry.Name]
+			if hasPort {
+				containerEnv["PORT"] = strconv.Itoa(port)
+				containerEnv["PS"] = entry.Name
+			}
+			for key, value := range cfg.GetProcessEnv(entry.Name) {
+				containerEnv[key] = value
+			}
+
+			if imageCommand, ok := procfile.ImageCommand(entry.Command); ok {
+				// The process runs in a container of the app image
+				imageEnv := developmentEnv(cfg, appPath)
+				for key, value := range containerEnv {
+					imageEnv[key] = value
 				}
-			} else {
-				// For other commands, split normally
-				cmdParts := strings.Fields(procCommand)
-				if len(cmdParts) == 0 {
-					continue
+				if !hasPort {
+					port = 0
+				}
+				command, args = imageRunArgs(cfg, entry.Name, imageEnv, port, imageCommand)
+			} else if dev != nil {
+				// In the dev container, the process is started with docker exec
+				command, args = dev.execArgs(entry.Dir, containerEnv, entry.Command)
+			}
+
+			if err := processManager.StartProcess(cfg.Name, entry.Name, command, args, entryEnv, workDir); err != nil {
+				failUp(cfg, spinerr.Wrap(spinerr.Process, "failed to start "+entry.Name, err))
+			}
+
+			// Dev servers are waited for until they serve
+			if settings, ok := cfg.GetProcessSettings(entry.Name); ok && settings.ReadyWithin() > 0 && !noWait {
+				readyPort := settings.Port
+				if readyPort == 0 {
+					readyPort = port
 				}
-				command = cmdParts[0]
-				if len(cmdParts) > 1 {
-					args = cmdParts[1:]
+				if readyPort != 0 {
+					waitForPort(entry.Name, readyPort, settings.ReadyWithin())
 				}
 			}
+		}
 
-			// Log the process we're about to start
-			processCmd := command
-			if len(args) > 0 {
-				processCmd += " " + strings.Join(args, " ")
+		// Restart processes on file changes when they ask for it
+		if _, err := processManager.FindProcess(watcherProcessName); err == nil {
+			fmt.Printf("%s-> %s is already running%s\n", lg.Yellow, watcherProcessName, lg.Reset)
+		} else if needsWatcher(cfg, entries) {
+			exe, err := os.Executable()
+			if err != nil {
+				lg.Warnf("not starting the file watcher: %v", err)
+			} else {
+				lg.Printf("%s-> Starting %s: spin watch%s\n", lg.Blue, watcherProcessName, lg.Reset)
+				if err := processManager.StartProcess(cfg.Name, watcherProcessName, exe, []string{"watch"}, env, appPath); err != nil {
+					failUp(cfg, spinerr.Wrap(spinerr.Process, "failed to start the file watcher", err))
+				}
+			}
+		}
+
+		// Open the SSH tunnels to remote services
+		if len(cfg.Tunnels) > 0 {
+			startTunnels(cfg, processManager, tunnelNames(cfg), env, appPath)
+		}
+
+		// Record usage history for spin stats --history and the dashboard
+		if _, err := processManager.FindProcess(metricsProcessName); err == nil {
+			fmt.Printf("%s-> %s is already running%s\n", lg.Yellow, metricsProcessName, lg.Reset)
+		} else if exe, err := os.Executable(); err != nil {
+			lg.Warnf("not recording usage history: %v", err)
+		} else {
+			lg.Printf("%s-> Starting %s: spin stats --collect%s\n", lg.Blue, metricsProcessName, lg.Reset)
+			if err := processManager.StartProcess(cfg.Name, metricsProcessName, exe, []string{"stats", "--collect"}, env, appPath); err != nil {
+				lg.Warnf("not recording usage history: %v", err)
+			}
+		}
+
+		// Publish crash and health events, which also drive notifications
+		if _, err := processManager.FindProcess(monitorProcessName); err == nil {
+			fmt.Printf("%s-> %s is already running%s\n", lg.Yellow, monitorProcessName, lg.Reset)
+		} else if exe, err := os.Executable(); err != nil {
+			lg.Warnf("not starting the crash monitor: %v", err)
+		} else {
+			lg.Printf("%s-> Starting %s: spin monitor%s\n", lg.Blue, monitorProcessName, lg.Reset)
+			if err := processManager.StartProcess(cfg.Name, monitorProcessName, exe, []string{"monitor"}, env, appPath); err != nil {
+				lg.Warnf("not starting the crash monitor: %v", err)
+			}
+		}
+
+		// Let editors and tools control the environment over its socket
+		if _, err := processManager.FindProcess(apiProcessName); err == nil {
+			fmt.Printf("%s-> %s is already running%s\n", lg.Yellow, apiProcessName, lg.Reset)
+		} else if exe, err := os.Executable(); err != nil {
+			lg.Warnf("not starting the control API: %v", err)
+		} else {
+			lg.Printf("%s-> Starting %s: spin api%s\n", lg.Blue, apiProcessName, lg.Reset)
+			if err := processManager.StartProcess(cfg.Name, apiProcessName, exe, []string{"api"}, env, appPath); err != nil {
+				lg.Warnf("not starting the control API: %v", err)
 			}
-			fmt.Printf("%s-> Starting %s: %s%s\n", lg.Blue, procName, processCmd, lg.Reset)
+		}
 
-			if err := processManager.StartProcess(cfg.Name, procName, command, args, env, appPath); err != nil {
-				fmt.Printf("%sError starting process %s: %v%s\n", lg.Red, procName, err, lg.Reset)
-				os.Exit(1)
+		// Let spin list and spin open find the project from anywhere
+		if dir, err := filepath.Abs(appPath); err == nil {
+			entry := registry.Entry{Name: cfg.Name, Dir: dir, Port: webPort(entries, ports), StartedAt: time.Now()}
+			if err := registry.Register(entry); err != nil {
+				lg.Warnf("failed to register %s: %v", cfg.Name, err)
 			}
 		}
 
-		if err := scanner.Err(); err != nil {
-			fmt.Printf("%sError reading %s: %v%s\n", lg.Red, cfg.GetProcfilePath(), err, lg.Reset)
-			os.Exit(1)
+		if err := runLifecycleHooks(cfg, "post_up", appPath); err != nil {
+			lg.Warnf("%v", err)
+		}
+		if err := runAppHooks(cfg, "post_up", appPath); err != nil {
+			lg.Warnf("%v", err)
+		}
+
+		// Record how long it took for spin report
+		if err := script.RecordTiming(cfg.Name, "up", upStarted, nil); err != nil {
+			lg.Warnf("not recording how long spin up took: %v", err)
 		}
 
-		fmt.Printf("%sAll processes started successfully!%s\n", lg.Green, lg.Reset)
+		lg.Printf("%sAll processes started successfully!%s\n", lg.Green, lg.Reset)
 		// BELOW THIS LINE IS COMMENTED OUT FOR NOW
 		// fmt.Printf("\n%sPress Ctrl+C to stop all processes%s\n", lg.Yellow, lg.Reset)
 
@@ -198,6 +409,574 @@ Example:
 	},
 }
 
+// lockProject takes the lock of the project, stopping the spin up holding it
+// when taking over
+func lockProject(cfg *config.Config, appPath string, takeover bool) (*process.ProjectLock, error) {
+	dir, err := filepath.Abs(appPath)
+	if err != nil {
+		return nil, err
+	}
+	if takeover {
+		return process.TakeoverLock(cfg.Name, dir)
+	}
+	return process.AcquireLock(cfg.Name, dir)
+}
+
+// upFormation returns the number of instances to run of each process: the
+// formation of .foreman, overridden by processes.formation, then replaced by
+// --formation and overridden per process by --scale
+func upFormation(cmd *cobra.Command, cfg *config.Config) (procfile.Formation, error) {
+	formation := make(procfile.Formation)
+	if cfg.Foreman != nil && cfg.Foreman.Formation != "" {
+		parsed, err := procfile.ParseFormation(cfg.Foreman.Formation)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.Foreman.Source, err)
+		}
+		formation = parsed
+	}
+	if cfg.Processes != nil {
+		for name, count := range cfg.Processes.Formation {
+			formation[name] = count
+		}
+	}
+
+	if flag, _ := cmd.Flags().GetString("formation"); flag != "" {
+		parsed, err := procfile.ParseFormation(flag)
+		if err != nil {
+			return nil, err
+		}
+		formation = parsed
+	}
+	scale, _ := cmd.Flags().GetStringSlice("scale")
+	parsed, err := procfile.ParseFormation(strings.Join(scale, ","))
+	if err != nil {
+		return nil, err
+	}
+	for name, count := range parsed {
+		formation[name] = count
+	}
+	return formation, nil
+}
+
+// startServices starts the given services, Docker services as a dependency
+// graph and the others one by one. It exits on failure.
+func startServices(cfg *config.Config, serviceNames []string) {
+	if len(serviceNames) == 0 {
+		return
+	}
+	lg.Printf("%sChecking required services...%s\n", lg.Blue, lg.Reset)
+	trackServices(cfg)
+
+	// Docker services are started as a dependency graph
+	var dockerServices []string
+	for _, serviceName := range serviceNames {
+		if _, ok := cfg.Services[serviceName]; ok {
+			dockerServices = append(dockerServices, serviceName)
+		}
+	}
+	if len(dockerServices) > 0 {
+		dockerManager, err := docker.NewServiceManager("./data")
+		if err != nil {
+			failUp(cfg, spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
+		}
+		if err := dockerManager.StartServices(cfg.Services, dockerServices); err != nil {
+			failUp(cfg, spinerr.Wrap(spinerr.Service, "failed to start services", err))
+		}
+		useSharedServices(cfg, dockerManager, dockerServices)
+	}
+
+	svcManager := service.NewServiceManager()
+	for _, serviceName := range serviceNames {
+		if _, ok := cfg.Services[serviceName]; ok {
+			continue
+		}
+		svc, err := service.CreateService(serviceName, cfg)
+		if err != nil {
+			failUp(cfg, spinerr.Wrap(spinerr.Service, "failed to create service "+serviceName, err))
+		}
+		svcManager.RegisterService(svc)
+
+		if !svc.IsRunning() {
+			lg.Printf("Starting %s%s%s...\n", lg.Cyan, serviceName, lg.Reset)
+			if err := svcManager.StartService(serviceName); err != nil {
+				failUp(cfg, spinerr.Wrap(spinerr.Service, "failed to start "+serviceName, err))
+			}
+		} else {
+			lg.Printf("%sService %s%s%s is already running%s\n", lg.Green, lg.Cyan, serviceName, lg.Green, lg.Reset)
+		}
+	}
+}
+
+// useSharedServices records that the project uses the shared services it
+// started, including their dependencies, and creates its databases in them
+func useSharedServices(cfg *config.Config, dockerManager *docker.ServiceManager, names []string) {
+	levels, err := docker.ResolveStartOrder(cfg.Services, names)
+	if err != nil {
+		return
+	}
+	for _, level := range levels {
+		for _, name := range level {
+			if !cfg.Services[name].Shared {
+				continue
+			}
+			if err := dockerManager.UseShared(name, cfg.Services[name], cfg.Name); err != nil {
+				failUp(cfg, spinerr.Wrap(spinerr.Service, "failed to set up shared service "+name, err))
+			}
+			lg.Printf("%sUsing shared service %s%s%s%s\n", lg.Green, lg.Cyan, name, lg.Green, lg.Reset)
+		}
+	}
+}
+
+// needsWatcher checks if any of the started processes declares watch globs
+func needsWatcher(cfg *config.Config, entries []procfile.Entry) bool {
+	if cfg.Processes == nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.Name == watcherProcessName {
+			// The Procfile already uses the name
+			return false
+		}
+	}
+	for _, entry := range entries {
+		if settings, _ := cfg.GetProcessSettings(entry.Name); len(settings.Watch) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runLifecycleHooks runs the hooks of spin.config.json for a hook point,
+// pre_up, post_up, pre_down or post_down
+func runLifecycleHooks(cfg *config.Config, point string, appPath string) error {
+	if len(cfg.Hooks.Steps(point)) == 0 && len(cfg.Hooks.WebhooksFor(point)) == 0 {
+		return nil
+	}
+
+	manager := script.NewManager()
+	if err := script.LoadAndRegisterScripts(manager, filepath.Join(appPath, "spin.config.json")); err != nil {
+		return fmt.Errorf("failed to load scripts: %w", err)
+	}
+	return manager.RunHook(cfg.Hooks, script.HookEvent{Hook: point, App: cfg.Name}, script.LifecycleOptions{
+		App:         cfg.Name,
+		WorkDir:     appPath,
+		Env:         withMasterKey(cfg, appPath, cfg.GetEnvVars("development")),
+		MaxParallel: cfg.Hooks.MaxParallel,
+	})
+}
+
+// withMasterKey returns env with the Rails master key of the project in
+// appPath, warning when it is missing
+func withMasterKey(cfg *config.Config, appPath string, env map[string]string) map[string]string {
+	withKey, err := credentials.WithMasterKey(cfg.Name, appPath, env)
+	warnMasterKey(err)
+	return withKey
+}
+
+// entryServices returns the services of dependencies.services and those
+// the processes of entries ask for under processes.services, each once
+func entryServices(cfg *config.Config, entries []procfile.Entry) []string {
+	names := append([]string{}, cfg.Dependencies.Services...)
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, entry := range entries {
+		for _, name := range cfg.ProcessServices(entry.Name) {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// serviceGate holds processes back until the Docker services they need are
+// healthy, not only started, so they don't race a database that is still
+// booting. Each service is waited for once.
+type serviceGate struct {
+	cfg     *config.Config
+	dm      *docker.ServiceManager
+	checked map[string]bool
+	off     bool // Set by --no-wait
+}
+
+func newServiceGate(cfg *config.Config, noWait bool) *serviceGate {
+	return &serviceGate{cfg: cfg, checked: make(map[string]bool), off: noWait}
+}
+
+// wait blocks until the Docker services among names are healthy. When one
+// isn't within its ready_timeout, the process is started anyway with a warning.
+func (g *serviceGate) wait(who string, names []string) {
+	if g.off {
+		return
+	}
+	for _, name := range names {
+		svc, ok := g.cfg.Services[name]
+		if !ok || svc == nil || g.checked[name] {
+			continue
+		}
+		g.checked[name] = true
+		if g.dm == nil {
+			dm, err := docker.NewServiceManager("./data")
+			if err != nil {
+				lg.Warnf("not waiting for services: %v", err)
+				g.off = true
+				return
+			}
+			g.dm = dm
+		}
+
+		// Services without a health check are ready once they run
+		if status, err := g.dm.HealthStatus(name); err == nil && (status == "" || status == "healthy") {
+			continue
+		}
+		timeout := svc.ReadyWithin()
+		lg.Printf("%s-> Waiting up to %s for %s%s%s to be healthy before starting %s%s\n", lg.Blue, timeout, lg.Cyan, name, lg.Blue, who, lg.Reset)
+		if err := g.dm.WaitForHealthy(name, timeout); err != nil {
+			lg.Warnf("%s isn't healthy: %v, starting %s anyway", name, err, who)
+		}
+	}
+}
+
+// waitForPort waits until a process accepts connections on its port on
+// localhost. When it doesn't within timeout, spin up carries on with a
+// warning.
+func waitForPort(name string, port int, timeout time.Duration) {
+	lg.Printf("%s-> Waiting up to %s for %s%s%s to listen on port %d%s\n", lg.Blue, timeout, lg.Cyan, name, lg.Blue, port, lg.Reset)
+	address := fmt.Sprintf("localhost:%d", port)
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if conn, err := net.DialTimeout("tcp", address, time.Second); err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	lg.Warnf("%s isn't listening on port %d after %s, carrying on", name, port, timeout)
+}
+
+// runPreflight runs the preflight tasks of the project that aren't up to
+// date, in the dev container when there is one. Failed dependencies stop
+// spin up, failed migrations only warn and run again with the next spin up.
+func runPreflight(cmd *cobra.Command, appPath string, dev *devContainer, env []string, gate *serviceGate) {
+	skipDeps, _ := cmd.Flags().GetBool("skip-deps")
+	skipMigrations, _ := cmd.Flags().GetBool("skip-migrations")
+	for _, task := range detector.DetectPreflight(appPath) {
+		if (skipDeps && task.Kind == detector.PreflightDeps) || (skipMigrations && task.Kind == detector.PreflightMigrations) {
+			continue
+		}
+		needed, err := setup.NeedsRun(appPath, task)
+		if err != nil {
+			lg.Warnf("failed to check if %s is needed: %v", task.Name, err)
+		} else if !needed {
+			lg.Printf("%s%s is up to date%s\n", lg.Green, task.Name, lg.Reset)
+			continue
+		}
+
+		// Migrations need the databases to accept connections
+		if task.Kind == detector.PreflightMigrations {
+			gate.wait(task.Name, gate.cfg.Dependencies.Services)
+		}
+		lg.Printf("%sRunning %s...%s\n", lg.Blue, task.Name, lg.Reset)
+		args := strings.Fields(task.Command)
+		c := exec.Command(args[0], args[1:]...)
+		if dev != nil {
+			c = dev.command("", nil, args[0], args[1:]...)
+		}
+		c.Dir = appPath
+		c.Env = env
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			if task.Required() {
+				failUp(gate.cfg, spinerr.Wrap(spinerr.Execution, "failed to run "+task.Name, err).
+					WithFix("Fix it and run spin up again, or pass --skip-deps"))
+			}
+			lg.Warnf("%s failed: %v", task.Name, err)
+			fmt.Printf("%sFix it and run '%s', spin up tries again next time%s\n", lg.Yellow, task.Command, lg.Reset)
+			continue
+		}
+		if err := setup.RecordRun(appPath, task); err != nil {
+			lg.Warnf("failed to record %s: %v", task.Name, err)
+		}
+	}
+}
+
+// runAppHooks runs the hooks of the apps of a monorepo in their directories
+func runAppHooks(cfg *config.Config, point string, appPath string) error {
+	for _, app := range cfg.Apps {
+		appCfg, err := config.LoadApp(appPath, app)
+		if err != nil {
+			return err
+		}
+		if err := runLifecycleHooks(appCfg, point, filepath.Join(appPath, app.Path)); err != nil {
+			return fmt.Errorf("app %s: %w", app.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// upStarted is when the running spin up started, zero in other commands
+var upStarted time.Time
+
+// failUp exits with err, recording how long spin up took to fail for spin
+// report. Helpers other commands share exit through it too, which records
+// nothing outside of spin up.
+func failUp(cfg *config.Config, err error) {
+	if !upStarted.IsZero() {
+		if recordErr := script.RecordTiming(cfg.Name, "up", upStarted, err); recordErr != nil {
+			lg.Warnf("not recording how long spin up took: %v", recordErr)
+		}
+	}
+	spinerr.Exit(err)
+}
+
+// processEnv returns the environment processes of the app at appPath are
+// started with
+func processEnv(cfg *config.Config, appPath string) []string {
+	env := os.Environ() // Get existing environment
+	// The same variables spin env prints
+	for key, value := range projectEnv(cfg, "development") {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	if key := railsMasterKey(cfg, appPath); key != "" {
+		env = append(env, credentials.EnvVar+"="+key)
+	}
+	return env
+}
+
+// developmentEnv returns the variables of the development env of the app at
+// appPath, the ones processes in containers get
+func developmentEnv(cfg *config.Config, appPath string) map[string]string {
+	env := make(map[string]string)
+	if cfg.Foreman != nil {
+		for key, value := range cfg.Foreman.Env {
+			env[key] = value
+		}
+	}
+	for key, value := range cfg.GetEnvVars("development") {
+		env[key] = value
+	}
+	if key := railsMasterKey(cfg, appPath); key != "" {
+		env[credentials.EnvVar] = key
+	}
+	return env
+}
+
+// masterKeyWarned keeps spin from warning about a missing master key for
+// every process it starts
+var masterKeyWarned bool
+
+// railsMasterKey returns the master key to give the processes of the Rails
+// app at appPath with encrypted credentials, warning once when there is none
+func railsMasterKey(cfg *config.Config, appPath string) string {
+	env := cfg.GetEnvVars("development")
+	if cfg.Foreman != nil && cfg.Foreman.Env[credentials.EnvVar] != "" {
+		return ""
+	}
+	key, err := credentials.MasterKey(cfg.Name, appPath, env)
+	warnMasterKey(err)
+	return key
+}
+
+// warnMasterKey warns once that the master key couldn't be found
+func warnMasterKey(err error) {
+	if err == nil || masterKeyWarned {
+		return
+	}
+	masterKeyWarned = true
+	if errors.Is(err, credentials.ErrMissingKey) {
+		lg.Warnf("Rails credentials can't be decrypted: %v\nSet the key with 'spin config set-master-key'", err)
+		return
+	}
+	lg.Warnf("Rails credentials can't be decrypted: %v", err)
+}
+
+// usesAppImage checks if any of the processes runs the app image
+func usesAppImage(entries []procfile.Entry) bool {
+	for _, entry := range entries {
+		if _, ok := procfile.ImageCommand(entry.Command); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureAppImage builds the app image unless it exists and connects services
+// to the network its containers reach them on. It exits on failure.
+func ensureAppImage(cfg *config.Config, appPath string) {
+	dm, err := docker.NewServiceManager("./data")
+	if err != nil {
+		failUp(cfg, spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
+	}
+	if err := dm.ConnectServices(sortedServiceNames(cfg)); err != nil {
+		failUp(cfg, err)
+	}
+	if dm.ImageExists(cfg.GetImage()) {
+		return
+	}
+
+	opts, err := appBuildOptions(cfg, appPath)
+	if err != nil {
+		failUp(cfg, err)
+	}
+	lg.Printf("%sBuilding %s, run 'spin build' to rebuild it...%s\n", lg.Blue, opts.Tag, lg.Reset)
+	if err := docker.BuildImage(opts); err != nil {
+		failUp(cfg, err)
+	}
+}
+
+// imageRunArgs returns the command and arguments the process manager starts to
+// run a process in a container of the app image, with its default command
+// when commandLine is empty. A container left over by a previous run is
+// removed first, and port is published on localhost unless it is 0.
+func imageRunArgs(cfg *config.Config, name string, env map[string]string, port int, commandLine string) (string, []string) {
+	container := "spin_" + docker.ProcessContainerName(cfg.Name, name)
+	args := []string{
+		"docker", "run", "--rm", "-it", "--init",
+		"--name", container,
+		"--label", "spin.app=" + cfg.Name,
+		"--label", docker.ProcessLabel + "=" + name,
+		"--network", docker.NetworkName,
+	}
+	if port > 0 {
+		args = append(args, "-p", fmt.Sprintf("127.0.0.1:%d:%d", port, port))
+	}
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-e", key+"="+env[key])
+	}
+	args = append(args, cfg.GetImage())
+	if commandLine != "" {
+		args = append(args, "sh", "-c", commandLine)
+	}
+
+	for i, arg := range args {
+		args[i] = script.ShellQuote(arg)
+	}
+	run := fmt.Sprintf("docker rm -f %s >/dev/null 2>&1; exec %s", script.ShellQuote(container), strings.Join(args, " "))
+	return "sh", []string{"-c", script.ShellQuote(run)}
+}
+
+// devContainer runs the commands of spin up in the project's dev container
+type devContainer struct {
+	name    string            // Name of the container
+	workDir string            // Where the project is mounted in the container
+	env     map[string]string // Variables every command gets
+}
+
+// startDevContainer starts the dev container of the project, or reuses the
+// running one. It exits on failure.
+func startDevContainer(cfg *config.Config, appPath string) *devContainer {
+	dm, err := docker.NewServiceManager("./data")
+	if err != nil {
+		failUp(cfg, spinerr.Wrap(spinerr.Docker, "failed to connect to Docker", err))
+	}
+
+	lg.Printf("%sStarting dev container...%s\n", lg.Blue, lg.Reset)
+	name, err := dm.StartDevContainer(cfg.Name, appPath, cfg.DevContainer)
+	if err != nil {
+		failUp(cfg, spinerr.Wrap(spinerr.Docker, "failed to start the dev container", err))
+	}
+	if err := dm.ConnectServices(sortedServiceNames(cfg)); err != nil {
+		failUp(cfg, spinerr.Wrap(spinerr.Docker, "failed to connect services to the dev container", err))
+	}
+	lg.Printf("%sProcesses run in %s%s%s, services are reachable by their names%s\n", lg.Green, lg.Cyan, name, lg.Green, lg.Reset)
+
+	// The container gets the project's development env, its own env wins
+	env := developmentEnv(cfg, appPath)
+	for key, value := range cfg.DevContainer.Env {
+		env[key] = value
+	}
+	return &devContainer{name: name, workDir: cfg.DevContainer.GetWorkDir(), env: env}
+}
+
+// dockerExecArgs returns the arguments of docker exec running in dir, a
+// directory relative to the project, with the variables of the container
+// and env
+func (d *devContainer) dockerExecArgs(dir string, env map[string]string, tty bool) []string {
+	args := []string{"exec"}
+	if tty {
+		// A terminal lets processes be debugged, and ends them when the
+		// process manager stops docker exec
+		args = append(args, "-it")
+	}
+	args = append(args, "-w", path.Join(d.workDir, filepath.ToSlash(dir)))
+
+	merged := make(map[string]string)
+	for key, value := range d.env {
+		merged[key] = value
+	}
+	for key, value := range env {
+		merged[key] = value
+	}
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-e", key+"="+merged[key])
+	}
+	return append(args, d.name)
+}
+
+// command returns a command running name with args in the container
+func (d *devContainer) command(dir string, env map[string]string, name string, args ...string) *exec.Cmd {
+	return exec.Command("docker", append(append(d.dockerExecArgs(dir, env, false), name), args...)...)
+}
+
+// execArgs returns the command and arguments the process manager starts to
+// run a Procfile command in the container. They are joined into a shell
+// command, so the arguments are quoted.
+func (d *devContainer) execArgs(dir string, env map[string]string, commandLine string) (string, []string) {
+	args := d.dockerExecArgs(dir, env, true)
+	for i, arg := range args {
+		args[i] = script.ShellQuote(arg)
+	}
+	return "docker", append(args, "sh", "-c", script.ShellQuote(commandLine))
+}
+
+// rubyEnv puts the Ruby the project in dir asks for in front of the PATH of
+// env when a version manager like rbenv, rvm, asdf or mise has it installed,
+// so bundle exec uses it whatever Ruby the login shell defaults to. When
+// report is set it tells which Ruby is used, or warns that it is missing.
+func rubyEnv(env []string, dir string, report bool) []string {
+	ruby, err := tools.RubyEnv(dir)
+	if err != nil {
+		if report {
+			lg.Warnf("%v", err)
+		}
+		return env
+	}
+	if ruby == nil {
+		return env
+	}
+	if report {
+		lg.Printf("%sUsing Ruby %s from %s (%s)%s\n", lg.Blue, ruby.Version, ruby.Manager, ruby.Source, lg.Reset)
+	}
+	return ruby.Apply(env)
+}
+
 func init() {
 	rootCmd.AddCommand(upCmd)
+	upCmd.Flags().Bool("skip-tools-check", false, "Start even if installed tool versions don't match the project")
+	upCmd.Flags().Bool("rerun-init", false, "Run init jobs again even if they already completed")
+	upCmd.Flags().Bool("skip-deps", false, "Don't install dependencies, even when they changed")
+	upCmd.Flags().Bool("skip-migrations", false, "Don't run migrations, even when they changed")
+	upCmd.Flags().Bool("no-wait", false, "Start processes without waiting for their services to be healthy")
+	upCmd.Flags().StringSlice("only", nil, "Only start these process groups or processes")
+	upCmd.Flags().StringSlice("except", nil, "Start everything except these process groups or processes")
+	upCmd.MarkFlagsMutuallyExclusive("only", "except")
+	upCmd.Flags().StringP("formation", "m", "", "Number of instances of each process, like all=1,worker=2")
+	upCmd.Flags().StringSlice("scale", nil, "Run several instances of a process, like worker=3")
+	upCmd.Flags().Bool("takeover", false, "Stop another spin up of the project and adopt the processes it started")
+	upCmd.Flags().Bool("on-host", false, "Run processes on the host even when the project has a dev_container")
+	upCmd.Flags().IntP("port", "p", 0, "Give each process a PORT, counting from this one in steps of processes.port_step")
 }
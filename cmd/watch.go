@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/afomera/spin/internal/config"
+	lg "github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/afomera/spin/internal/watcher"
+	"github.com/spf13/cobra"
+)
+
+// watcherProcessName is the name spin up runs the file watcher under
+const watcherProcessName = "spin-watcher"
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Restart processes when their files change",
+	Long: `Watch the project for file changes and restart processes whose "watch"
+globs match a changed file. Changes are debounced so a burst of writes only
+causes one restart.
+
+spin up starts the watcher automatically when a running process declares watch
+globs under "processes.settings" in spin.config.json.
+
+Example:
+  spin watch
+  spin watch --debounce 2s`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadProject("spin.config.json")
+		if err != nil {
+			spinerr.Exit(spinerr.Wrap(spinerr.Config, "failed to load configuration", err))
+		}
+
+		names := watchedProcesses(cfg)
+		if len(names) == 0 {
+			fmt.Printf("%sNo processes declare watch globs%s\n", lg.Yellow, lg.Reset)
+			return
+		}
+
+		manager := process.GetManager(cfg)
+		var targets []watcher.Target
+		for _, name := range names {
+			name := name
+			targets = append(targets, watcher.Target{
+				Name:     name,
+				Patterns: cfg.Processes.Settings[name].Watch,
+				OnChange: func(paths []string) {
+					fmt.Printf("%s%s changed, restarting %s%s%s\n", lg.Blue, strings.Join(dedupe(paths), ", "), lg.Cyan, name, lg.Reset)
+					// Scaled processes restart all of their instances
+					instances := process.Instances(manager.ListProcesses(), name)
+					if len(instances) == 0 {
+						instances = []string{name}
+					}
+					for _, instance := range instances {
+						if err := manager.RestartProcess(cfg.Name, instance); err != nil {
+							fmt.Printf("%sError restarting %s: %v%s\n", lg.Red, instance, err, lg.Reset)
+						}
+					}
+				},
+			})
+			fmt.Printf("Watching %s%s%s: %s\n", lg.Cyan, name, lg.Reset, strings.Join(cfg.Processes.Settings[name].Watch, ", "))
+		}
+
+		debounce, _ := cmd.Flags().GetDuration("debounce")
+		w, err := watcher.New(".", targets, debounce)
+		if err != nil {
+			fmt.Printf("%sError starting file watcher: %v%s\n", lg.Red, err, lg.Reset)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		w.Run(ctx)
+	},
+}
+
+// watchedProcesses returns the processes that declare watch globs
+func watchedProcesses(cfg *config.Config) []string {
+	if cfg.Processes == nil {
+		return nil
+	}
+
+	var names []string
+	for name, settings := range cfg.Processes.Settings {
+		if len(settings.Watch) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dedupe removes repeated paths while keeping their order
+func dedupe(paths []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().Duration("debounce", watcher.DefaultDebounce, "How long to wait for more changes before restarting")
+}
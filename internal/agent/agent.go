@@ -0,0 +1,187 @@
+// Package agent installs the user service that restores spin environments on
+// login, a launchd agent on macOS and a systemd user unit on Linux
+package agent
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Label names the launchd agent and the systemd unit
+const Label = "dev.spin.agent"
+
+// unitName is the file name of the systemd user unit
+const unitName = "spin-agent.service"
+
+// Path returns the file the service is defined in
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", Label+".plist"), nil
+	case "linux":
+		return filepath.Join(home, ".config", "systemd", "user", unitName), nil
+	}
+	return "", fmt.Errorf("starting on login is only supported on macOS and Linux")
+}
+
+// LogPath returns the file the agent writes its output to
+func LogPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".spin", "agent.log"), nil
+}
+
+// Installed checks if the service is defined
+func Installed() bool {
+	path, err := Path()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// Install defines the service, running exe agent run on login, and enables
+// it. Services start with a bare environment, so the PATH of the current
+// shell is passed on for the tools processes need.
+func Install(exe string) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	logPath, err := LogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return err
+	}
+
+	var unit string
+	if runtime.GOOS == "darwin" {
+		unit = launchdPlist(exe, os.Getenv("PATH"), logPath)
+	} else {
+		unit = systemdUnit(exe, os.Getenv("PATH"), logPath)
+	}
+	if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "darwin" {
+		// Loading an agent that is loaded already fails, so it is reloaded
+		exec.Command("launchctl", "unload", path).Run()
+		return run("launchctl", "load", "-w", path)
+	}
+	if err := run("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	return run("systemctl", "--user", "enable", unitName)
+}
+
+// Uninstall disables the service and removes its definition
+func Uninstall() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	if runtime.GOOS == "darwin" {
+		exec.Command("launchctl", "unload", "-w", path).Run()
+	} else {
+		exec.Command("systemctl", "--user", "disable", unitName).Run()
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	if runtime.GOOS == "linux" {
+		exec.Command("systemctl", "--user", "daemon-reload").Run()
+	}
+	return nil
+}
+
+// launchdPlist returns the launchd agent. Processes spin up starts outlive
+// the agent, so they are not killed with its process group.
+func launchdPlist(exe string, path string, logPath string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>%s</string>
+  <key>ProgramArguments</key>
+  <array>
+    <string>%s</string>
+    <string>agent</string>
+    <string>run</string>
+  </array>
+  <key>EnvironmentVariables</key>
+  <dict>
+    <key>PATH</key>
+    <string>%s</string>
+  </dict>
+  <key>RunAtLoad</key>
+  <true/>
+  <key>AbandonProcessGroup</key>
+  <true/>
+  <key>StandardOutPath</key>
+  <string>%s</string>
+  <key>StandardErrorPath</key>
+  <string>%s</string>
+</dict>
+</plist>
+`, Label, html.EscapeString(exe), html.EscapeString(path), html.EscapeString(logPath), html.EscapeString(logPath))
+}
+
+// systemdUnit returns the systemd user unit. Processes spin up starts
+// outlive the agent, so only the agent itself is stopped with the unit.
+func systemdUnit(exe string, path string, logPath string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Restore spin environments
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+KillMode=process
+Environment=%s
+ExecStart=%s agent run
+StandardOutput=append:%s
+StandardError=append:%s
+
+[Install]
+WantedBy=default.target
+`, systemdQuote("PATH="+path), systemdQuote(exe), logPath, logPath)
+}
+
+// systemdQuote quotes a word of a unit file that may contain spaces
+func systemdQuote(s string) string {
+	if !strings.ContainsAny(s, " \t\"\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// run runs a command, returning its output with the error when it fails
+func run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/events"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/service/docker"
+)
+
+// defaultLines is the number of log lines or events returned when the
+// request doesn't ask for a number
+const defaultLines = 100
+
+// maxTail is how much of the end of a log file is read for the last lines
+const maxTail = 1024 * 1024
+
+// Process is the state of a process as returned by the API
+type Process struct {
+	Name          string    `json:"name"`
+	App           string    `json:"app"`
+	Status        string    `json:"status"`
+	PID           int       `json:"pid"`
+	Backend       string    `json:"backend,omitempty"`
+	Command       string    `json:"command,omitempty"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	MemoryUsage   uint64    `json:"memory_usage"`
+	MemoryPercent float64   `json:"memory_percent"`
+	StartedAt     time.Time `json:"started_at"`
+	LogFile       string    `json:"log_file"`
+	URL           string    `json:"url,omitempty"` // Public URL, when shared with spin share
+}
+
+// Service is the state of a Docker service as returned by the API
+type Service struct {
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	Running bool   `json:"running"`
+	Health  string `json:"health,omitempty"` // Empty for services without a health check
+}
+
+// Server serves the control API of one app on a Unix socket
+type Server struct {
+	Config  *config.Config
+	Manager *process.Manager
+	Start   func(name string) error // Starts a process defined in the Procfile
+}
+
+// SocketPath returns the location of the control socket of an app
+func SocketPath(app string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".spin", "sockets", process.SanitizeAppName(app)+".sock"), nil
+}
+
+// Serve listens on the control socket until ctx is cancelled. It fails when
+// another spin is already serving the socket.
+func (s *Server) Serve(ctx context.Context) error {
+	socket, err := SocketPath(s.Config.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return fmt.Errorf("failed to create the socket directory: %w", err)
+	}
+
+	// A socket nobody answers on is left over from a killed server
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return fmt.Errorf("another spin is already serving %s", socket)
+	}
+	os.Remove(socket)
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	defer os.Remove(socket)
+
+	// The API controls processes, only the user may connect
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict %s: %w", socket, err)
+	}
+
+	server := &http.Server{Handler: s.routes()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Handler returns the handler of all API endpoints, for serving the API
+// somewhere other than the control socket
+func (s *Server) Handler() http.Handler {
+	return s.routes()
+}
+
+// routes returns the handler of all API endpoints
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/processes", s.handleProcesses)
+	mux.HandleFunc("/v1/processes/", s.handleProcess)
+	mux.HandleFunc("/v1/services", s.handleServices)
+	mux.HandleFunc("/v1/events", s.handleEvents)
+	return mux
+}
+
+// handleProcesses lists the processes of the app
+//
+//	GET /v1/processes
+func (s *Server) handleProcesses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not allowed", r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.processes())
+}
+
+// handleProcess serves a single process
+//
+//	GET  /v1/processes/{name}
+//	GET  /v1/processes/{name}/logs?lines=100
+//	POST /v1/processes/{name}/start
+//	POST /v1/processes/{name}/stop
+//	POST /v1/processes/{name}/restart
+func (s *Server) handleProcess(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/processes/"), "/")
+	name := parts[0]
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	if name == "" || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no such endpoint %s", r.URL.Path))
+		return
+	}
+
+	want := http.MethodPost
+	if action == "" || action == "logs" {
+		want = http.MethodGet
+	}
+	if r.Method != want {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not allowed, use %s", r.Method, want))
+		return
+	}
+
+	switch action {
+	case "":
+		for _, p := range s.processes() {
+			if p.Name == name {
+				writeJSON(w, http.StatusOK, p)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Errorf("process %s is not running", name))
+	case "logs":
+		lines := intParam(r, "lines", defaultLines)
+		output, err := TailLines(LogFile(s.Config.Name, name), lines)
+		if err != nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no logs for %s: %w", name, err))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string][]string{"lines": output})
+	case "start":
+		if _, err := s.Manager.FindProcess(name); err == nil {
+			writeError(w, http.StatusConflict, fmt.Errorf("process %s is already running", name))
+			return
+		}
+		if err := s.Start(name); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		s.writeProcess(w, name)
+	case "stop":
+		if err := s.Manager.StopProcess(s.Config.Name, name); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "restart":
+		if err := s.Manager.RestartProcess(s.Config.Name, name); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		s.writeProcess(w, name)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown action %s", action))
+	}
+}
+
+// writeProcess responds with the state of a process that was just changed
+func (s *Server) writeProcess(w http.ResponseWriter, name string) {
+	for _, p := range s.processes() {
+		if p.Name == name {
+			writeJSON(w, http.StatusOK, p)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, Process{Name: name, App: s.Config.Name, Status: string(process.StatusStarting)})
+}
+
+// handleServices lists the Docker services of the app
+//
+//	GET /v1/services
+func (s *Server) handleServices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not allowed", r.Method))
+		return
+	}
+
+	services := []Service{}
+	if len(s.Config.Services) == 0 {
+		writeJSON(w, http.StatusOK, services)
+		return
+	}
+
+	dm, err := docker.NewServiceManager("")
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	defer dm.Client().Close()
+
+	names := make([]string, 0, len(s.Config.Services))
+	for name := range s.Config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		svc := Service{Name: name, Image: s.Config.Services[name].Image, Running: dm.IsRunning(name)}
+		if svc.Running {
+			svc.Health, _ = dm.HealthStatus(name)
+		}
+		services = append(services, svc)
+	}
+	writeJSON(w, http.StatusOK, services)
+}
+
+// handleEvents returns the latest events of the app
+//
+//	GET /v1/events?lines=100
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not allowed", r.Method))
+		return
+	}
+
+	recent, err := events.Recent(intParam(r, "lines", defaultLines), events.Filter{App: s.Config.Name})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if recent == nil {
+		recent = []events.Event{}
+	}
+	writeJSON(w, http.StatusOK, recent)
+}
+
+// processes returns the running processes of the app, sorted by name
+func (s *Server) processes() []Process {
+	result := []Process{}
+	for _, p := range s.Manager.ListProcesses() {
+		if p.Type == process.ProcessTypeDocker || p.AppName != s.Config.Name {
+			continue
+		}
+
+		proc := Process{
+			Name:          p.Name,
+			App:           p.AppName,
+			Status:        string(p.Status),
+			Backend:       p.Backend,
+			CPUPercent:    p.CPUPercent,
+			MemoryUsage:   p.MemoryUsage,
+			MemoryPercent: p.MemoryPercent,
+			StartedAt:     p.StartedAt,
+			LogFile:       LogFile(p.AppName, p.Name),
+		}
+		if p.Command != nil && p.Command.Process != nil {
+			proc.PID = p.Command.Process.Pid
+		}
+		if info, err := s.Manager.Store().GetProcess(p.Name); err == nil {
+			proc.Command = info.CommandLine
+			proc.URL = info.URL
+		}
+		result = append(result, proc)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// LogFile returns the output file of a process
+func LogFile(appName string, name string) string {
+	path, _ := process.LogPath(appName, name)
+	return path
+}
+
+// TailLines returns the last n lines of a file
+func TailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := int64(0)
+	if info.Size() > maxTail {
+		offset = info.Size() - maxTail
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if offset > 0 && len(lines) > 0 {
+		// The first line was cut off
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i, line := range lines {
+		// Output of processes in a pseudo-terminal ends in \r\n
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		lines = []string{}
+	}
+	return lines, nil
+}
+
+// intParam returns a positive integer query parameter or def
+func intParam(r *http.Request, name string, def int) int {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
+
+// writeJSON writes v as the JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,175 @@
+// Package ci turns the environment of spin.config.json into CI pipelines, so
+// tests run in CI against the same services, variables and commands as
+// locally
+package ci
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/detector"
+	"github.com/afomera/spin/internal/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats are the CI systems pipelines are exported for
+var Formats = []string{"github", "gitlab"}
+
+// Pipeline is what a CI job needs to test a project
+type Pipeline struct {
+	Services       []Service           // Service containers, in the order of dependencies.services
+	Env            map[string]string   // Variables of the job
+	Tools          []tools.Requirement // Ruby, Node and Go versions the project asks for
+	PackageManager string              // Node package manager, when the project has a package.json
+	Bundler        bool                // The project has a Gemfile
+	Setup          []Command           // Commands run before the tests
+	Test           []Command           // The test script with its hooks
+}
+
+// Service is a service container of a CI job
+type Service struct {
+	Name        string
+	Image       string
+	Port        int
+	Env         map[string]string
+	HealthCheck *config.HealthCheckConfig
+}
+
+// Command is a named step of a CI job
+type Command struct {
+	Name string
+	Run  string
+}
+
+// NewPipeline derives the pipeline of the project in dir from its config:
+// its Docker services, the test env (overridden by the ci env when there is
+// one), its setup tasks or setup script, and its test script
+func NewPipeline(cfg *config.Config, dir string) *Pipeline {
+	p := &Pipeline{Env: make(map[string]string)}
+
+	for _, name := range cfg.Dependencies.Services {
+		svcCfg, ok := cfg.Services[name]
+		if !ok {
+			svcCfg = config.GetDefaultDockerConfig(name)
+		}
+		if svcCfg == nil {
+			// Services like sqlite3 don't run in a container
+			continue
+		}
+		p.Services = append(p.Services, Service{
+			Name:        name,
+			Image:       svcCfg.Image,
+			Port:        svcCfg.Port,
+			Env:         svcCfg.Environment,
+			HealthCheck: svcCfg.HealthCheck,
+		})
+	}
+
+	for _, env := range []string{"test", "ci"} {
+		for key, value := range cfg.GetEnvVars(env) {
+			p.Env[key] = value
+		}
+	}
+
+	p.Tools = tools.Requirements(dir)
+	if _, err := os.Stat(filepath.Join(dir, "Gemfile")); err == nil {
+		p.Bundler = true
+	}
+	if _, err := os.Stat(filepath.Join(dir, "package.json")); err == nil {
+		p.PackageManager = cfg.PackageManager(dir)
+		if p.PackageManager == "" {
+			p.PackageManager = "npm"
+		}
+	}
+
+	if len(cfg.Setup) > 0 {
+		for _, task := range cfg.Setup {
+			p.Setup = append(p.Setup, Command{Name: task.Name, Run: task.Command})
+		}
+	} else if setup, ok := cfg.Scripts["setup"]; ok {
+		p.Setup = scriptCommands("setup", setup)
+	}
+	if test, ok := cfg.Scripts["test"]; ok {
+		p.Test = scriptCommands("test", test)
+		for key, value := range test.Env {
+			p.Env[key] = value
+		}
+	}
+	return p
+}
+
+// scriptCommands returns the commands of a script and its hooks
+func scriptCommands(name string, s config.Script) []Command {
+	var commands []Command
+	if s.Hooks.Pre != nil {
+		commands = append(commands, Command{Name: hookName(s.Hooks.Pre, "Before "+name), Run: s.Hooks.Pre.Command})
+	}
+	description := s.Description
+	if description == "" {
+		description = "Run " + name
+	}
+	commands = append(commands, Command{Name: description, Run: s.Command})
+	if s.Hooks.Post != nil {
+		commands = append(commands, Command{Name: hookName(s.Hooks.Post, "After "+name), Run: s.Hooks.Post.Command})
+	}
+	return commands
+}
+
+// hookName names the step of a hook after its description
+func hookName(hook *config.Hook, fallback string) string {
+	if hook.Description != "" {
+		return hook.Description
+	}
+	return fallback
+}
+
+// Export renders the pipeline of the project in dir for a CI system
+func Export(cfg *config.Config, dir string, format string) ([]byte, error) {
+	p := NewPipeline(cfg, dir)
+	var doc interface{}
+	switch format {
+	case "github":
+		doc = p.github()
+	case "gitlab":
+		doc = p.gitlab()
+	default:
+		return nil, fmt.Errorf("unknown format %q, use %s", format, strings.Join(Formats, " or "))
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// requirement returns the version of a tool the project asks for
+func (p *Pipeline) requirement(tool string) (tools.Requirement, bool) {
+	for _, r := range p.Tools {
+		if r.Tool == tool {
+			return r, true
+		}
+	}
+	return tools.Requirement{}, false
+}
+
+// installCommands returns the commands installing the project's packages
+func (p *Pipeline) installCommands(bundler bool) []Command {
+	var commands []Command
+	if bundler && p.Bundler {
+		commands = append(commands, Command{Name: "Install gems", Run: "bundle install"})
+	}
+	if p.PackageManager != "" {
+		commands = append(commands, Command{Name: "Install packages", Run: detector.InstallCommand(p.PackageManager)})
+	}
+	return commands
+}
@@ -0,0 +1,111 @@
+package ci
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// githubWorkflow is a GitHub Actions workflow with a single test job
+type githubWorkflow struct {
+	Name string               `yaml:"name"`
+	On   map[string]struct{}  `yaml:"on"`
+	Jobs map[string]githubJob `yaml:"jobs"`
+}
+
+type githubJob struct {
+	RunsOn   string                   `yaml:"runs-on"`
+	Services map[string]githubService `yaml:"services,omitempty"`
+	Env      map[string]string        `yaml:"env,omitempty"`
+	Steps    []githubStep             `yaml:"steps"`
+}
+
+type githubService struct {
+	Image   string            `yaml:"image"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	Ports   []string          `yaml:"ports,omitempty"`
+	Options string            `yaml:"options,omitempty"`
+}
+
+type githubStep struct {
+	Name string                 `yaml:"name,omitempty"`
+	Uses string                 `yaml:"uses,omitempty"`
+	With map[string]interface{} `yaml:"with,omitempty"`
+	Run  string                 `yaml:"run,omitempty"`
+}
+
+// github renders the pipeline as a GitHub Actions workflow. Service
+// containers publish their ports, so the job reaches them on localhost like
+// processes do locally.
+func (p *Pipeline) github() githubWorkflow {
+	job := githubJob{RunsOn: "ubuntu-latest", Env: p.Env}
+
+	if len(p.Services) > 0 {
+		job.Services = make(map[string]githubService)
+	}
+	for _, svc := range p.Services {
+		gs := githubService{Image: svc.Image, Env: svc.Env}
+		if svc.Port != 0 {
+			gs.Ports = []string{fmt.Sprintf("%d:%d", svc.Port, svc.Port)}
+		}
+		// Host checks have no counterpart in service containers of GitHub
+		if hc := svc.HealthCheck; hc != nil && len(hc.Command) > 0 && !hc.OnHost() {
+			options := []string{"--health-cmd " + strconv.Quote(strings.Join(hc.Command, " "))}
+			if hc.Interval != "" {
+				options = append(options, "--health-interval "+hc.Interval)
+			}
+			if hc.Timeout != "" {
+				options = append(options, "--health-timeout "+hc.Timeout)
+			}
+			if hc.Retries != 0 {
+				options = append(options, fmt.Sprintf("--health-retries %d", hc.Retries))
+			}
+			if hc.StartPeriod != "" {
+				options = append(options, "--health-start-period "+hc.StartPeriod)
+			}
+			gs.Options = strings.Join(options, " ")
+		}
+		job.Services[svc.Name] = gs
+	}
+
+	job.Steps = append(job.Steps, githubStep{Uses: "actions/checkout@v4"})
+	// The setup actions read the versions from the same files spin does
+	if _, ok := p.requirement("ruby"); ok || p.Bundler {
+		with := map[string]interface{}{}
+		if p.Bundler {
+			with["bundler-cache"] = true
+		}
+		job.Steps = append(job.Steps, githubStep{Uses: "ruby/setup-ruby@v1", With: with})
+	}
+	if p.PackageManager == "pnpm" {
+		job.Steps = append(job.Steps, githubStep{Uses: "pnpm/action-setup@v4"})
+	}
+	if p.PackageManager == "bun" {
+		job.Steps = append(job.Steps, githubStep{Uses: "oven-sh/setup-bun@v2"})
+	} else if r, ok := p.requirement("node"); ok || p.PackageManager != "" {
+		with := map[string]interface{}{}
+		if ok {
+			with["node-version-file"] = r.Source
+		}
+		if p.PackageManager != "" {
+			with["cache"] = p.PackageManager
+		}
+		job.Steps = append(job.Steps, githubStep{Uses: "actions/setup-node@v4", With: with})
+	}
+	if r, ok := p.requirement("go"); ok {
+		job.Steps = append(job.Steps, githubStep{Uses: "actions/setup-go@v5", With: map[string]interface{}{"go-version-file": r.Source}})
+	}
+
+	// setup-ruby installs the gems
+	for _, commands := range [][]Command{p.installCommands(false), p.Setup, p.Test} {
+		for _, c := range commands {
+			job.Steps = append(job.Steps, githubStep{Name: c.Name, Run: c.Run})
+		}
+	}
+
+	return githubWorkflow{
+		Name: "CI",
+		On:   map[string]struct{}{"push": {}, "pull_request": {}},
+		Jobs: map[string]githubJob{"test": job},
+	}
+}
@@ -0,0 +1,71 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gitlabJob is a GitLab CI job, the pipeline is a single test job
+type gitlabJob struct {
+	Image     string            `yaml:"image"`
+	Services  []gitlabService   `yaml:"services,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Script    []string          `yaml:"script"`
+}
+
+type gitlabService struct {
+	Name      string            `yaml:"name"`
+	Alias     string            `yaml:"alias"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+}
+
+// gitlab renders the pipeline as a .gitlab-ci.yml. GitLab services are
+// reached by their alias rather than on localhost, so addresses of services
+// in the variables are rewritten to their names.
+func (p *Pipeline) gitlab() map[string]gitlabJob {
+	job := gitlabJob{Image: p.image(), Variables: make(map[string]string)}
+
+	var replacements []string
+	for _, svc := range p.Services {
+		job.Services = append(job.Services, gitlabService{Name: svc.Image, Alias: svc.Name, Variables: svc.Env})
+		if svc.Port != 0 {
+			for _, host := range []string{"localhost", "127.0.0.1"} {
+				replacements = append(replacements, fmt.Sprintf("%s:%d", host, svc.Port), fmt.Sprintf("%s:%d", svc.Name, svc.Port))
+			}
+		}
+	}
+	replacer := strings.NewReplacer(replacements...)
+	for key, value := range p.Env {
+		job.Variables[key] = replacer.Replace(value)
+	}
+
+	for _, commands := range [][]Command{p.installCommands(true), p.Setup, p.Test} {
+		for _, c := range commands {
+			job.Script = append(job.Script, c.Run)
+		}
+	}
+	return map[string]gitlabJob{"test": job}
+}
+
+// image returns the image of the job: Ruby for projects with a Gemfile,
+// otherwise Node or Go, in the version the project asks for
+func (p *Pipeline) image() string {
+	candidates := []struct {
+		tool  string
+		image string
+		used  bool
+	}{
+		{"ruby", "ruby", p.Bundler},
+		{"node", "node", p.PackageManager != ""},
+		{"go", "golang", false},
+	}
+	for _, c := range candidates {
+		if r, ok := p.requirement(c.tool); ok {
+			return c.image + ":" + r.Version
+		}
+		if c.used {
+			return c.image
+		}
+	}
+	return "alpine"
+}
@@ -0,0 +1,364 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// Resource kinds reported by Find
+const (
+	KindContainer = "container"
+	KindImage     = "image"
+	KindNetwork   = "network"
+	KindProcess   = "process entry"
+	KindLog       = "log file"
+	KindSession   = "tmux session"
+	KindVolume    = "volume"
+)
+
+// Resource is a leftover piece of the spin footprint that can be removed
+type Resource struct {
+	Kind   string
+	Name   string
+	Reason string
+	Size   int64 // Bytes freed by removing the resource, when known
+	remove func() error
+}
+
+// Remove deletes the resource
+func (r Resource) Remove() error {
+	return r.remove()
+}
+
+// Options controls what is considered garbage
+type Options struct {
+	LogAge  time.Duration // Log files not written for this long are removed
+	Volumes bool          // Only unused service volumes, which hold data and are otherwise kept
+}
+
+// Find collects every resource that can be removed, or only the unused
+// volumes with opts.Volumes. Sources that can't be inspected, such as a
+// stopped Docker daemon, are returned as warnings and don't prevent the
+// others from being collected.
+func Find(opts Options) ([]Resource, []error) {
+	var resources []Resource
+	var warnings []error
+
+	manager := process.GetManager(nil)
+	manager.SetQuiet(true)
+	store := manager.Store()
+	entries, err := store.Entries()
+	if err != nil {
+		warnings = append(warnings, fmt.Errorf("failed to read process store: %w", err))
+		entries = make(map[string]process.ProcessInfo)
+	}
+
+	var cli *client.Client
+	dm, err := docker.NewServiceManager("")
+	if err != nil {
+		warnings = append(warnings, err)
+	} else if _, err := dm.Client().Ping(context.Background()); err != nil {
+		warnings = append(warnings, fmt.Errorf("skipping Docker resources, daemon is not reachable: %w", err))
+	} else {
+		cli = dm.Client()
+	}
+
+	if opts.Volumes {
+		if cli == nil {
+			return nil, warnings
+		}
+		volumes, err := findVolumes(dm)
+		if err != nil {
+			warnings = append(warnings, err)
+		}
+		return volumes, warnings
+	}
+
+	if cli != nil {
+		ctx := context.Background()
+		for _, find := range []func(context.Context, *client.Client) ([]Resource, error){findContainers, findImages, findNetworks} {
+			found, err := find(ctx, cli)
+			if err != nil {
+				warnings = append(warnings, err)
+			}
+			resources = append(resources, found...)
+		}
+	}
+
+	resources = append(resources, findProcessEntries(store, entries, cli)...)
+	resources = append(resources, findSessions(entries)...)
+
+	logs, err := findLogs(entries, opts.LogAge)
+	if err != nil {
+		warnings = append(warnings, err)
+	}
+	resources = append(resources, logs...)
+
+	return resources, warnings
+}
+
+// findContainers returns spin containers that are no longer running
+func findContainers(ctx context.Context, cli *client.Client) ([]Resource, error) {
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var resources []Resource
+	for _, c := range containers {
+		if len(c.Names) == 0 || !strings.HasPrefix(c.Names[0], "/spin_") {
+			continue
+		}
+		if c.State != "exited" && c.State != "created" && c.State != "dead" {
+			continue
+		}
+
+		id := c.ID
+		resources = append(resources, Resource{
+			Kind:   KindContainer,
+			Name:   strings.TrimPrefix(c.Names[0], "/"),
+			Reason: c.Status,
+			Size:   c.SizeRw,
+			remove: func() error {
+				// Volumes are kept, they hold service data
+				return cli.ContainerRemove(ctx, id, types.ContainerRemoveOptions{})
+			},
+		})
+	}
+	return resources, nil
+}
+
+// findImages returns dangling images left behind by spin service images
+// after their tag moved to a newer pull
+func findImages(ctx context.Context, cli *client.Client) ([]Resource, error) {
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	repos := make(map[string]bool)
+	for _, c := range containers {
+		if len(c.Names) > 0 && strings.HasPrefix(c.Names[0], "/spin_") {
+			repos[imageRepo(c.Image)] = true
+		}
+	}
+
+	images, err := cli.ImageList(ctx, types.ImageListOptions{Filters: filters.NewArgs(filters.Arg("dangling", "true"))})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	var resources []Resource
+	for _, image := range images {
+		for _, digest := range image.RepoDigests {
+			repo := strings.SplitN(digest, "@", 2)[0]
+			if !repos[repo] {
+				continue
+			}
+
+			id := image.ID
+			resources = append(resources, Resource{
+				Kind:   KindImage,
+				Name:   fmt.Sprintf("%s (%s)", repo, shortID(id)),
+				Reason: "dangling",
+				Size:   image.Size,
+				remove: func() error {
+					_, err := cli.ImageRemove(ctx, id, types.ImageRemoveOptions{PruneChildren: true})
+					return err
+				},
+			})
+			break
+		}
+	}
+	return resources, nil
+}
+
+// findNetworks returns spin networks without any attached containers
+func findNetworks(ctx context.Context, cli *client.Client) ([]Resource, error) {
+	networks, err := cli.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	var resources []Resource
+	for _, network := range networks {
+		if !strings.HasPrefix(network.Name, "spin") {
+			continue
+		}
+
+		// Listing doesn't include attached containers, inspecting does
+		details, err := cli.NetworkInspect(ctx, network.ID, types.NetworkInspectOptions{})
+		if err != nil || len(details.Containers) > 0 {
+			continue
+		}
+
+		id := network.ID
+		resources = append(resources, Resource{
+			Kind:   KindNetwork,
+			Name:   network.Name,
+			Reason: "no containers attached",
+			remove: func() error {
+				return cli.NetworkRemove(ctx, id)
+			},
+		})
+	}
+	return resources, nil
+}
+
+// findVolumes returns spin volumes no container uses. Backups of major
+// upgrades are left alone.
+func findVolumes(dm *docker.ServiceManager) ([]Resource, error) {
+	unused, err := dm.UnusedVolumes()
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, name := range unused {
+		name := name
+		resources = append(resources, Resource{
+			Kind:   KindVolume,
+			Name:   name,
+			Reason: "not used by any container",
+			remove: func() error {
+				return dm.Client().VolumeRemove(context.Background(), name, false)
+			},
+		})
+	}
+	return resources, nil
+}
+
+// findProcessEntries returns process store entries whose process or
+// container no longer exists. Docker entries are only checked when the
+// daemon is reachable.
+func findProcessEntries(store *process.Store, entries map[string]process.ProcessInfo, cli *client.Client) []Resource {
+	var resources []Resource
+	for key, info := range entries {
+		var reason string
+		switch info.Type {
+		case process.ProcessTypeDocker:
+			if cli == nil || info.ContainerID == "" {
+				continue
+			}
+			if _, err := cli.ContainerInspect(context.Background(), info.ContainerID); !client.IsErrNotFound(err) {
+				continue
+			}
+			reason = "container no longer exists"
+		default:
+			if process.IsAlive(info.Pid) {
+				continue
+			}
+			reason = fmt.Sprintf("process %d is not running", info.Pid)
+		}
+
+		key := key
+		resources = append(resources, Resource{
+			Kind:   KindProcess,
+			Name:   key,
+			Reason: reason,
+			remove: func() error {
+				return store.RemoveEntries([]string{key})
+			},
+		})
+	}
+	return resources
+}
+
+// findSessions returns spin tmux sessions the process store doesn't know about
+func findSessions(entries map[string]process.ProcessInfo) []Resource {
+	tracked := make(map[string]bool)
+	for _, info := range entries {
+		tracked[process.SessionName(info.AppName, info.Name)] = true
+	}
+
+	var resources []Resource
+	for _, session := range process.ListSessions() {
+		if tracked[session] {
+			continue
+		}
+
+		session := session
+		resources = append(resources, Resource{
+			Kind:   KindSession,
+			Name:   session,
+			Reason: "not tracked by spin",
+			remove: func() error {
+				return process.KillSession(session)
+			},
+		})
+	}
+	return resources
+}
+
+// findLogs returns process log files that haven't been written for maxAge.
+// Logs of processes that are still running are always kept.
+func findLogs(entries map[string]process.ProcessInfo, maxAge time.Duration) ([]Resource, error) {
+	outputDir, err := process.OutputDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+	cutoff := time.Now().Add(-maxAge)
+	err = filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".log") || info.ModTime().After(cutoff) {
+			return nil
+		}
+
+		// Logs live at output/<app>/<process>.log
+		rel, _ := filepath.Rel(outputDir, path)
+		key := strings.TrimSuffix(strings.ReplaceAll(rel, string(filepath.Separator), "-"), ".log")
+		if entry, ok := entries[key]; ok && process.IsAlive(entry.Pid) {
+			return nil
+		}
+
+		resources = append(resources, Resource{
+			Kind:   KindLog,
+			Name:   path,
+			Reason: fmt.Sprintf("last written %s", info.ModTime().Format("2006-01-02")),
+			Size:   info.Size(),
+			remove: func() error {
+				return os.Remove(path)
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		return resources, fmt.Errorf("failed to scan log files: %w", err)
+	}
+	return resources, nil
+}
+
+// imageRepo strips the tag or digest from an image reference
+func imageRepo(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// shortID returns the abbreviated form of an image ID
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
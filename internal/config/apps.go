@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// App is an application of a monorepo, a directory with its own
+// spin.config.json, services, scripts and Procfile
+type App struct {
+	Name string `json:"name,omitempty"` // Prefix of its process names, the base name of Path when empty
+	Path string `json:"path"`           // Directory relative to the root spin.config.json
+}
+
+// GetName returns the name the processes of the app are namespaced with
+func (a App) GetName() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return filepath.Base(filepath.Clean(a.Path))
+}
+
+// ProcessName returns the name a process of the app runs as, like api-web
+func (a App) ProcessName(name string) string {
+	return a.GetName() + "-" + name
+}
+
+// LoadApp loads the spin.config.json of an app of the project in root
+func LoadApp(root string, app App) (*Config, error) {
+	if app.Path == "" {
+		return nil, fmt.Errorf("app %s has no path", app.GetName())
+	}
+	cfg, err := Load(filepath.Join(root, app.Path, "spin.config.json"))
+	if err != nil {
+		return nil, fmt.Errorf("app %s: %w", app.GetName(), err)
+	}
+	return cfg, nil
+}
+
+// LoadProject loads a spin.config.json and merges the apps it declares into
+// it. Services the apps share are only listed once and the settings of their
+// processes are added under namespaced names. The result is meant for running
+// the project, saving it would write the apps into the root config.
+func LoadProject(path string) (*Config, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckApps(filepath.Dir(path), cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.mergeApps(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// CheckApps refuses apps whose configs would include themselves: apps in the
+// directory of the project in root or above it, and apps declaring each other
+func CheckApps(root string, cfg *Config) error {
+	return checkApps(root, cfg, make(map[string]bool))
+}
+
+// checkApps checks the apps of the config in root, visiting holds the
+// directories of the apps declaring it
+func checkApps(root string, cfg *Config, visiting map[string]bool) error {
+	dir, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	visiting[dir] = true
+	defer delete(visiting, dir)
+
+	for _, app := range cfg.Apps {
+		if app.Path == "" {
+			return fmt.Errorf("app %s has no path", app.GetName())
+		}
+		appDir, err := filepath.Abs(filepath.Join(root, app.Path))
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(appDir, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("app %s: path %s is the directory of the project or one above it", app.GetName(), app.Path)
+		}
+		if visiting[appDir] {
+			return fmt.Errorf("app %s: path %s leads back to an app declaring it", app.GetName(), app.Path)
+		}
+
+		appCfg, err := LoadApp(root, app)
+		if err != nil {
+			return err
+		}
+		if err := checkApps(filepath.Join(root, app.Path), appCfg, visiting); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeApps merges the services, tools and process settings of the apps
+func (c *Config) mergeApps(root string) error {
+	names := make(map[string]bool)
+	for _, app := range c.Apps {
+		if names[app.GetName()] {
+			return fmt.Errorf("app %s is declared twice", app.GetName())
+		}
+		names[app.GetName()] = true
+
+		appCfg, err := LoadApp(root, app)
+		if err != nil {
+			return err
+		}
+		if err := appCfg.mergeApps(filepath.Join(root, app.Path)); err != nil {
+			return err
+		}
+
+		c.Dependencies.Services = appendMissing(c.Dependencies.Services, appCfg.Dependencies.Services)
+		c.Dependencies.Tools = appendMissing(c.Dependencies.Tools, appCfg.Dependencies.Tools)
+		for name, svc := range appCfg.Services {
+			// The first definition of a shared service wins
+			if _, ok := c.Services[name]; ok {
+				continue
+			}
+			if c.Services == nil {
+				c.Services = make(map[string]*DockerServiceConfig)
+			}
+			c.Services[name] = svc
+		}
+
+		if appCfg.Processes == nil || len(appCfg.Processes.Settings) == 0 {
+			continue
+		}
+		if c.Processes == nil {
+			c.Processes = &ProcessConfig{}
+		}
+		if c.Processes.Settings == nil {
+			c.Processes.Settings = make(map[string]ProcessSettings)
+		}
+		for name, settings := range appCfg.Processes.Settings {
+			// Watch globs are relative to the app, those without a slash
+			// match file names in any of its directories
+			watch := make([]string, 0, len(settings.Watch))
+			for _, pattern := range settings.Watch {
+				if !strings.Contains(pattern, "/") {
+					pattern = "**/" + pattern
+				}
+				watch = append(watch, filepath.ToSlash(filepath.Join(app.Path, pattern)))
+			}
+			settings.Watch = watch
+			c.Processes.Settings[app.ProcessName(name)] = settings
+		}
+	}
+	return nil
+}
+
+// appendMissing appends the values that aren't in list yet
+func appendMissing(list []string, values []string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/afomera/spin/internal/detector"
 )
@@ -20,14 +22,77 @@ type Config struct {
 	Env          map[string]EnvMap               `json:"env"`
 	Processes    *ProcessConfig                  `json:"processes,omitempty"`
 	Rails        *RailsConfig                    `json:"rails,omitempty"`
+	Node         *NodeConfig                     `json:"node,omitempty"`
 	Services     map[string]*DockerServiceConfig `json:"services,omitempty"`
+	Init         []InitJob                       `json:"init,omitempty"`
+	Hooks        *LifecycleHooks                 `json:"hooks,omitempty"`
+	Setup        []SetupTask                     `json:"setup,omitempty"`
+	Packages     *SystemPackages                 `json:"system_packages,omitempty"`
+	Apps         []App                           `json:"apps,omitempty"`          // Apps of a monorepo started together by spin up
+	DevContainer *DevContainerConfig             `json:"dev_container,omitempty"` // Container the processes run in instead of the host
+	Build        *BuildConfig                    `json:"build,omitempty"`         // How spin build builds the app image
+	Tunnels      map[string]*TunnelConfig        `json:"tunnels,omitempty"`       // SSH port forwards to remote services, kept open by spin up
+
+	DisabledServices []string `json:"disabled_services,omitempty"` // Services to leave out, usually set in spin.config.local.json
+
+	Foreman *ForemanSettings `json:"-"` // Settings of the project's .foreman or .overmind.env
+}
+
+// SystemPackages lists the packages of the operating system spin bootstrap
+// installs, per package manager
+type SystemPackages struct {
+	Brew []string `json:"brew,omitempty"`
+	Apt  []string `json:"apt,omitempty"`
+	Dnf  []string `json:"dnf,omitempty"`
+}
+
+// For returns the packages to install with a package manager
+func (p *SystemPackages) For(manager string) []string {
+	if p == nil {
+		return nil
+	}
+	switch manager {
+	case "brew":
+		return p.Brew
+	case "apt":
+		return p.Apt
+	case "dnf":
+		return p.Dnf
+	}
+	return nil
+}
+
+// SetupTask is a step of spin setup that is skipped while it is up to date
+type SetupTask struct {
+	Name    string   `json:"name"`
+	Command string   `json:"command"`           // Run through the shell
+	Inputs  []string `json:"inputs,omitempty"`  // Files or globs whose contents decide whether the task runs again
+	Creates string   `json:"creates,omitempty"` // The task is done while this file exists
+}
+
+// InitJob is a bootstrap task that runs once per environment, either as a
+// one-shot container (when Image is set) or as a command on the host
+type InitJob struct {
+	Name    string            `json:"name"`
+	Image   string            `json:"image,omitempty"`   // Run in a short-lived container using this image
+	Command []string          `json:"command,omitempty"` // Command to run
+	Env     map[string]string `json:"env,omitempty"`
+	Service string            `json:"service,omitempty"` // Service that must be running, containers share its network
 }
 
 type Script struct {
-	Command     string            `json:"command"`
-	Description string            `json:"description,omitempty"`
-	Env         map[string]string `json:"env,omitempty"`
-	Hooks       Hooks             `json:"hooks,omitempty"`
+	Command     string                 `json:"command"`
+	Description string                 `json:"description,omitempty"`
+	Interpreter string                 `json:"interpreter,omitempty"`
+	Env         map[string]string      `json:"env,omitempty"`
+	Params      map[string]ScriptParam `json:"params,omitempty"`
+	Hooks       Hooks                  `json:"hooks,omitempty"`
+}
+
+type ScriptParam struct {
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
 }
 
 type Hooks struct {
@@ -42,8 +107,9 @@ type Hook struct {
 }
 
 type Repository struct {
-	Organization string `json:"organization"`
+	Organization string `json:"organization"` // Organization, user or GitLab group, like group/subgroup
 	Name         string `json:"name"`
+	Host         string `json:"host,omitempty"` // Git host, github.com when empty
 }
 
 type Dependencies struct {
@@ -54,7 +120,129 @@ type Dependencies struct {
 type EnvMap map[string]string
 
 type ProcessConfig struct {
-	Procfile string `json:"procfile"`
+	Procfile string                     `json:"procfile"`
+	Groups   map[string]ProcessGroup    `json:"groups,omitempty"`    // Named subsets for spin up --only/--except
+	Services map[string][]string        `json:"services,omitempty"`  // Services each process depends on, started by spin run
+	Settings map[string]ProcessSettings `json:"settings,omitempty"`  // Per-process overrides keyed by process name
+	PortBase int                        `json:"port_base,omitempty"` // Give each process a PORT, counting from this one
+	PortStep int                        `json:"port_step,omitempty"` // Ports between processes, 100 by default
+
+	Formation map[string]int `json:"formation,omitempty"` // Instances to run of each process, like {"worker": 2}
+}
+
+// ProcessSettings holds overrides for a single process
+type ProcessSettings struct {
+	Env          map[string]string `json:"env,omitempty"`           // Merged on top of the development env
+	Port         int               `json:"port,omitempty"`          // Exported to the process as PORT
+	Watch        []string          `json:"watch,omitempty"`         // Globs of files that restart the process when changed
+	StopSignal   string            `json:"stop_signal,omitempty"`   // Signal sent to stop the process (SIGTERM or SIGINT)
+	StopTimeout  string            `json:"stop_timeout,omitempty"`  // Time to exit before the process is killed (e.g., "10s")
+	ReadyTimeout string            `json:"ready_timeout,omitempty"` // Time spin up waits for the process to listen on its port (e.g., "30s")
+}
+
+// DevServerReadyTimeout is how long spin up waits for the dev servers spin
+// init finds to listen on their port
+const DevServerReadyTimeout = "30s"
+
+// ReadyWithin returns how long spin up waits for the process to listen on
+// its port before starting the next ones, 0 when it doesn't wait
+func (s ProcessSettings) ReadyWithin() time.Duration {
+	d, err := time.ParseDuration(s.ReadyTimeout)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// ProcessGroup selects processes from the main Procfile or from its own Procfile
+type ProcessGroup struct {
+	Processes []string `json:"processes,omitempty"` // Process names, all processes of the Procfile when empty
+	Procfile  string   `json:"procfile,omitempty"`  // Procfile to read instead of the main one
+}
+
+// DevContainerConfig is a container the processes of the Procfile run in, so
+// everyone works with the same toolchain. The project is mounted into it and
+// services are reachable by their names over the spin network.
+type DevContainerConfig struct {
+	Image      string            `json:"image,omitempty"`      // Image to run, like ruby:3.3
+	Dockerfile string            `json:"dockerfile,omitempty"` // Dockerfile to build the image from instead
+	Context    string            `json:"context,omitempty"`    // Build context of the Dockerfile, the project by default
+	WorkDir    string            `json:"workdir,omitempty"`    // Where the project is mounted, /app by default
+	User       string            `json:"user,omitempty"`       // User commands run as, like 1000:1000
+	Env        map[string]string `json:"env,omitempty"`        // Variables of every process, like DATABASE_HOST=postgresql
+	Ports      []int             `json:"ports,omitempty"`      // Ports of processes published on localhost
+}
+
+// BuildConfig describes how the image of the app is built by spin build and
+// run by docker: processes of the Procfile
+type BuildConfig struct {
+	Image      string            `json:"image,omitempty"`      // Tag of the image, spin-<name> by default
+	Dockerfile string            `json:"dockerfile,omitempty"` // Dockerfile, Dockerfile by default
+	Context    string            `json:"context,omitempty"`    // Build context, the project by default
+	Target     string            `json:"target,omitempty"`     // Stage of a multi-stage Dockerfile to build
+	Args       map[string]string `json:"args,omitempty"`       // Build arguments
+	CacheFrom  []string          `json:"cache_from,omitempty"` // Images to use as cache sources
+}
+
+// GetImage returns the tag the app image is built as
+func (c *Config) GetImage() string {
+	if c.Build != nil && c.Build.Image != "" {
+		return c.Build.Image
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '-'
+		}
+	}, strings.ToLower(c.Name))
+	if name == "" {
+		name = "app"
+	}
+	return "spin-" + name
+}
+
+// TunnelConfig is an SSH port forward from localhost to a service reachable
+// from an SSH server, like a staging database behind a bastion host
+type TunnelConfig struct {
+	Host           string `json:"host"`                      // SSH server, or a Host of ~/.ssh/config
+	User           string `json:"user,omitempty"`            // SSH user, from ~/.ssh/config by default
+	Port           int    `json:"port,omitempty"`            // SSH port, from ~/.ssh/config by default
+	IdentityFile   string `json:"identity_file,omitempty"`   // Private key, the SSH agent's keys by default
+	LocalPort      int    `json:"local_port"`                // Port opened on localhost
+	RemoteHost     string `json:"remote_host,omitempty"`     // Host the server connects to, the server itself by default
+	RemotePort     int    `json:"remote_port"`               // Port of the remote service
+	HealthInterval string `json:"health_interval,omitempty"` // Time between checks of the forward (e.g., "10s")
+}
+
+// GetRemoteHost returns the host the SSH server forwards connections to
+func (t *TunnelConfig) GetRemoteHost() string {
+	if t.RemoteHost != "" {
+		return t.RemoteHost
+	}
+	return "localhost"
+}
+
+// GetHealthInterval returns the time between checks of the forward
+func (t *TunnelConfig) GetHealthInterval() time.Duration {
+	if d, err := time.ParseDuration(t.HealthInterval); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Second
+}
+
+// GetWorkDir returns where the project is mounted in the dev container
+func (d *DevContainerConfig) GetWorkDir() string {
+	if d.WorkDir != "" {
+		return d.WorkDir
+	}
+	return "/app"
+}
+
+// NodeConfig represents Node.js-specific configuration
+type NodeConfig struct {
+	PackageManager string `json:"package_manager,omitempty"` // npm, yarn, pnpm or bun
 }
 
 // RailsConfig represents Rails-specific configuration
@@ -70,13 +258,14 @@ type RailsConfig struct {
 		Settings map[string]string `json:"settings"`
 	} `json:"database"`
 	Services struct {
-		Redis         bool `json:"redis"`
-		Sidekiq       bool `json:"sidekiq,omitempty"`
-		DelayedJob    bool `json:"delayed_job,omitempty"`
-		GoodJob       bool `json:"good_job,omitempty"`
-		Elasticsearch bool `json:"elasticsearch,omitempty"`
-		Memcached     bool `json:"memcached,omitempty"`
-		ActionCable   bool `json:"action_cable,omitempty"`
+		Redis         bool   `json:"redis"`
+		Sidekiq       bool   `json:"sidekiq,omitempty"`
+		DelayedJob    bool   `json:"delayed_job,omitempty"`
+		GoodJob       bool   `json:"good_job,omitempty"`
+		Elasticsearch bool   `json:"elasticsearch,omitempty"`
+		Memcached     bool   `json:"memcached,omitempty"`
+		ActionCable   bool   `json:"action_cable,omitempty"`
+		AnyCable      string `json:"anycable,omitempty"` // grpc or http, how anycable-go calls the app
 	} `json:"services"`
 	Assets struct {
 		Pipeline string `json:"pipeline,omitempty"` // sprockets, webpacker, propshaft
@@ -105,11 +294,77 @@ func (c *Config) GetEnvVars(env string) map[string]string {
 	return make(map[string]string)
 }
 
+// GetProcessEnv returns the environment overrides of a single process
+func (c *Config) GetProcessEnv(name string) map[string]string {
+	env := make(map[string]string)
+	if c.Processes == nil {
+		return env
+	}
+
+	settings, ok := c.GetProcessSettings(name)
+	if !ok {
+		return env
+	}
+	for key, value := range settings.Env {
+		env[key] = value
+	}
+	if settings.Port != 0 {
+		// Instances get consecutive ports, like foreman
+		port := settings.Port
+		if n := InstanceNumber(name); n > 1 {
+			port += n - 1
+		}
+		env["PORT"] = strconv.Itoa(port)
+	}
+	return env
+}
+
+// ProcessServices returns the services a process needs: those listed for it
+// or its process type under processes.services, or else those of
+// dependencies.services
+func (c *Config) ProcessServices(name string) []string {
+	if c.Processes != nil {
+		if services, ok := c.Processes.Services[name]; ok {
+			return services
+		}
+		if services, ok := c.Processes.Services[ProcessType(name)]; ok {
+			return services
+		}
+	}
+	return c.Dependencies.Services
+}
+
+// GetProcessSettings returns the settings of a process. Instances of a
+// process, like worker.2, share the settings of worker.
+func (c *Config) GetProcessSettings(name string) (ProcessSettings, bool) {
+	if c.Processes == nil {
+		return ProcessSettings{}, false
+	}
+	if settings, ok := c.Processes.Settings[name]; ok {
+		return settings, true
+	}
+	settings, ok := c.Processes.Settings[ProcessType(name)]
+	return settings, ok
+}
+
+// PackageManager returns the Node.js package manager of the project at path,
+// the configured one or the one detected from its lockfile. It returns an
+// empty string for projects without package.json.
+func (c *Config) PackageManager(path string) string {
+	if c.Node != nil && c.Node.PackageManager != "" {
+		return c.Node.PackageManager
+	}
+	return detector.DetectPackageManager(path)
+}
+
 // GetProcfilePath returns the path to the Procfile
 func (c *Config) GetProcfilePath() string {
 	if c.Processes != nil && c.Processes.Procfile != "" {
 		return c.Processes.Procfile
 	}
+	if c.Foreman != nil && c.Foreman.Procfile != "" {
+		return c.Foreman.Procfile
+	}
 	return "Procfile.dev"
 }
 
@@ -130,8 +385,31 @@ func (c *Config) Save(path string) error {
 	return os.WriteFile(path, data, 0644)
 }
 
-// Load reads configuration from a file
+// Load reads configuration from a file, merges spin.config.local.json over it
+// and replaces the variables in its services and scripts
 func Load(path string) (*Config, error) {
+	data, err := ReadMerged(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	config.disableServices()
+	if err := config.interpolate(); err != nil {
+		return nil, err
+	}
+	if config.Foreman, err = LoadForeman(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// LoadRaw reads configuration from a file as written, without the local
+// overrides, for editing and saving it back
+func LoadRaw(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -141,6 +419,10 @@ func Load(path string) (*Config, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	// Commands add services to what they load
+	if config.Services == nil {
+		config.Services = make(map[string]*DockerServiceConfig)
+	}
 
 	return &config, nil
 }
@@ -161,14 +443,22 @@ func (r *Repository) GetFullName() string {
 	return r.Organization + "/" + r.Name
 }
 
+// GetHost returns the git host of the repository, github.com when not set
+func (r *Repository) GetHost() string {
+	if r.Host == "" {
+		return "github.com"
+	}
+	return r.Host
+}
+
 // GetHTTPSCloneURL returns the HTTPS clone URL for the repository
 func (r *Repository) GetHTTPSCloneURL() string {
-	return fmt.Sprintf("https://github.com/%s/%s.git", r.Organization, r.Name)
+	return fmt.Sprintf("https://%s/%s/%s.git", r.GetHost(), r.Organization, r.Name)
 }
 
 // GetSSHCloneURL returns the SSH clone URL for the repository
 func (r *Repository) GetSSHCloneURL() string {
-	return fmt.Sprintf("git@github.com:%s/%s.git", r.Organization, r.Name)
+	return fmt.Sprintf("git@%s:%s/%s.git", r.GetHost(), r.Organization, r.Name)
 }
 
 // GetCloneURL returns the preferred clone URL based on the user's configuration
@@ -179,16 +469,17 @@ func (r *Repository) GetCloneURL(preferSSH bool) string {
 	return r.GetHTTPSCloneURL()
 }
 
-// ParseRepositoryString parses a repository string in the format "org/name"
+// ParseRepositoryString parses a repository string in the format "org/name".
+// GitLab repositories in subgroups are given as "group/subgroup/name".
 func ParseRepositoryString(s string) (*Repository, error) {
-	parts := strings.Split(s, "/")
-	if len(parts) != 2 {
+	i := strings.LastIndex(s, "/")
+	if i <= 0 || i == len(s)-1 || strings.HasPrefix(s, "/") || strings.Contains(s, "//") {
 		return nil, fmt.Errorf("invalid repository format: %s (expected org/name)", s)
 	}
 
 	return &Repository{
-		Organization: parts[0],
-		Name:         parts[1],
+		Organization: s[:i],
+		Name:         s[i+1:],
 	}, nil
 }
 
@@ -262,13 +553,14 @@ func DetectProjectType(path string) (*Config, error) {
 					Settings: railsConfig.Database.Settings,
 				},
 				Services: struct {
-					Redis         bool `json:"redis"`
-					Sidekiq       bool `json:"sidekiq,omitempty"`
-					DelayedJob    bool `json:"delayed_job,omitempty"`
-					GoodJob       bool `json:"good_job,omitempty"`
-					Elasticsearch bool `json:"elasticsearch,omitempty"`
-					Memcached     bool `json:"memcached,omitempty"`
-					ActionCable   bool `json:"action_cable,omitempty"`
+					Redis         bool   `json:"redis"`
+					Sidekiq       bool   `json:"sidekiq,omitempty"`
+					DelayedJob    bool   `json:"delayed_job,omitempty"`
+					GoodJob       bool   `json:"good_job,omitempty"`
+					Elasticsearch bool   `json:"elasticsearch,omitempty"`
+					Memcached     bool   `json:"memcached,omitempty"`
+					ActionCable   bool   `json:"action_cable,omitempty"`
+					AnyCable      string `json:"anycable,omitempty"` // grpc or http, how anycable-go calls the app
 				}{
 					Redis:         railsConfig.Services.Redis,
 					Sidekiq:       railsConfig.Services.Sidekiq,
@@ -277,6 +569,7 @@ func DetectProjectType(path string) (*Config, error) {
 					Elasticsearch: railsConfig.Services.Elasticsearch,
 					Memcached:     railsConfig.Services.Memcached,
 					ActionCable:   railsConfig.Services.ActionCable,
+					AnyCable:      railsConfig.Services.AnyCable,
 				},
 				Assets: struct {
 					Pipeline string `json:"pipeline,omitempty"`
@@ -297,11 +590,8 @@ func DetectProjectType(path string) (*Config, error) {
 		services := make(map[string]*DockerServiceConfig)
 
 		// Add database service if detected
-		switch railsConfig.Database.Type {
-		case "postgresql":
-			services["postgresql"] = GetDefaultDockerConfig("postgresql")
-		case "mysql":
-			services["mysql"] = GetDefaultDockerConfig("mysql")
+		if db := railsConfig.DatabaseService(); db != "" {
+			services[db] = GetDefaultDockerConfig(db)
 		}
 
 		// Add detected services
@@ -315,13 +605,42 @@ func DetectProjectType(path string) (*Config, error) {
 			services["memcached"] = GetDefaultDockerConfig("memcached")
 		}
 
-		// Add background job services
-		if railsConfig.Services.Sidekiq {
-			if _, exists := services["redis"]; !exists {
-				services["redis"] = GetDefaultDockerConfig("redis")
+		// The job worker and AnyCable depend on the services they queue and
+		// broadcast through, so spin run starts them
+		processes := railsConfig.AnyCableProcesses(path)
+		if worker, ok := railsConfig.JobWorker(path); ok {
+			processes = append(processes, worker)
+		}
+		for _, process := range processes {
+			for _, name := range process.Services {
+				if _, exists := services[name]; !exists {
+					services[name] = GetDefaultDockerConfig(name)
+				}
+			}
+			if cfg.Processes.Services == nil {
+				cfg.Processes.Services = make(map[string][]string)
+			}
+			cfg.Processes.Services[process.Name] = process.Services
+		}
+
+		// The app and anycable-go find each other through the development
+		// env, at the ports Redis and the web process listen on
+		if redis, ok := services["redis"]; ok {
+			webPort := 3000
+			if settings, ok := cfg.GetProcessSettings("web"); ok && settings.Port != 0 {
+				webPort = settings.Port
+			}
+			for key, value := range railsConfig.AnyCableEnv(redis.PublishedPort(), webPort) {
+				cfg.Env["development"][key] = value
 			}
 		}
 
+		// The bundler's dev server is given its port, so spin knows where
+		// it listens and waits for it
+		if devServer, ok := detector.DetectDevServer(path); ok && devServer.Command != "" {
+			cfg.Processes.Settings = map[string]ProcessSettings{devServer.Name: {Port: devServer.Port, ReadyTimeout: DevServerReadyTimeout}}
+		}
+
 		cfg.Services = services
 
 		// Update dependencies based on detected services
@@ -354,6 +673,16 @@ func DetectProjectType(path string) (*Config, error) {
 			}
 		}
 
+		// Rails apps with a package.json bundle JavaScript with a package
+		// manager, and get its scripts next to their own
+		if manager := detector.DetectPackageManager(path); manager != "" {
+			cfg.Node = &NodeConfig{PackageManager: manager}
+			if nodeConfig, err := detector.DetectNode(path); err == nil {
+				addPackageScripts(cfg, nodeConfig)
+			}
+		}
+		cfg.Build = detectBuild(path)
+
 		return cfg, nil
 	}
 
@@ -368,6 +697,21 @@ func DetectProjectType(path string) (*Config, error) {
 				Tools:    append([]string{"node"}, nodeConfig.DevTools...),
 			},
 			Scripts: make(map[string]Script),
+			Node:    &NodeConfig{PackageManager: nodeConfig.PackageManager},
+		}
+
+		// The web process runs the dev server of the framework, whose port
+		// is known from its script or config
+		for _, process := range nodeConfig.DevProcesses(path) {
+			if process.Name == "web" && process.Port > 0 {
+				cfg.Processes = &ProcessConfig{
+					Procfile: "Procfile.dev",
+					Settings: map[string]ProcessSettings{"web": {Port: process.Port, ReadyTimeout: DevServerReadyTimeout}},
+				}
+			}
+		}
+		if nodeConfig.PackageManager != "npm" {
+			cfg.Dependencies.Tools = append(cfg.Dependencies.Tools, nodeConfig.PackageManager)
 		}
 
 		// Add services based on detected Node.js services
@@ -403,16 +747,43 @@ func DetectProjectType(path string) (*Config, error) {
 			cfg.Dependencies.Services = append(cfg.Dependencies.Services, serviceName)
 		}
 
-		// Convert package.json scripts to our Script format
-		for _, scriptName := range nodeConfig.Scripts {
-			cfg.Scripts[scriptName] = Script{
-				Command:     fmt.Sprintf("npm run %s", scriptName),
-				Description: fmt.Sprintf("Run npm script: %s", scriptName),
-			}
-		}
+		addPackageScripts(cfg, nodeConfig)
+		cfg.Build = detectBuild(path)
 
 		return cfg, nil
 	}
 
 	return nil, fmt.Errorf("unable to detect project type")
 }
+
+// addPackageScripts adds a script running each package.json script with the
+// project's package manager, keeping the scripts cfg already has
+func addPackageScripts(cfg *Config, nodeConfig *detector.NodeConfig) {
+	for _, name := range nodeConfig.Scripts {
+		if _, ok := cfg.Scripts[name]; ok {
+			continue
+		}
+		cfg.Scripts[name] = Script{
+			Command:     detector.RunScriptCommand(nodeConfig.PackageManager, name, ""),
+			Description: fmt.Sprintf("Run %s script: %s", nodeConfig.PackageManager, name),
+		}
+	}
+}
+
+// detectBuild returns the build settings of the app image from its Dockerfile
+// or compose file, or nil when it has neither
+func detectBuild(path string) *BuildConfig {
+	build := detector.DetectDockerBuild(path)
+	if build == nil {
+		return nil
+	}
+	cfg := &BuildConfig{Target: build.Target, Args: build.Args}
+	// Leave out the defaults
+	if build.Dockerfile != "Dockerfile" {
+		cfg.Dockerfile = build.Dockerfile
+	}
+	if build.Context != "." {
+		cfg.Context = build.Context
+	}
+	return cfg
+}
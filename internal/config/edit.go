@@ -0,0 +1,395 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/detector"
+)
+
+// object is a JSON object that keeps the order of its keys, so editing a
+// value doesn't reorder the rest of the file
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// MarshalJSON writes the object with its keys in their original order
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := marshalPlain(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := marshalPlain(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// set adds or replaces a key, new keys go last
+func (o *object) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// marshalPlain encodes a value without escaping HTML characters like &
+func marshalPlain(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// decodeOrdered parses JSON keeping the order of object keys and numbers as
+// they are written
+func decodeOrdered(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return value, nil
+}
+
+// decodeValue reads the next value from a decoder
+func decodeValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := &object{values: make(map[string]interface{})}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key.(string), value)
+		}
+		_, err := decoder.Token()
+		return obj, err
+	case json.Delim('['):
+		list := make([]interface{}, 0)
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := decoder.Token()
+		return list, err
+	}
+	return token, nil
+}
+
+// encodeOrdered formats a value parsed by decodeOrdered the way Save does
+func encodeOrdered(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// setPath sets the value at a path of object keys and list indexes, creating
+// missing objects on the way
+func setPath(root interface{}, parts []string, value interface{}) error {
+	current := root
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch v := current.(type) {
+		case *object:
+			if last {
+				v.set(part, value)
+				return nil
+			}
+			next, ok := v.values[part]
+			if !ok || next == nil {
+				next = &object{values: make(map[string]interface{})}
+				v.set(part, next)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(v) {
+				return fmt.Errorf("%s has no item %s", strings.Join(parts[:i], "."), part)
+			}
+			if last {
+				v[index] = value
+				return nil
+			}
+			current = v[index]
+		default:
+			return fmt.Errorf("%s is not an object", strings.Join(parts[:i], "."))
+		}
+	}
+	return nil
+}
+
+// ParseValue reads a value given on the command line: JSON like 6380, true or
+// ["redis"] when it parses, a string otherwise
+func ParseValue(s string) interface{} {
+	value, err := decodeOrdered([]byte(s))
+	if err != nil {
+		return s
+	}
+	return value
+}
+
+// GetValue returns the value of a dotted key like services.redis.port, with
+// spin.config.local.json merged over the config, as written
+func GetValue(path string, key string) (interface{}, error) {
+	data, err := ReadMerged(path)
+	if err != nil {
+		return nil, err
+	}
+	var merged interface{}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+
+	var parts []string
+	if key != "" {
+		parts = strings.Split(key, ".")
+	}
+	value, ok := lookup(merged, parts)
+	if !ok {
+		return nil, fmt.Errorf("%s is not set", key)
+	}
+	return value, nil
+}
+
+// SetValue sets a dotted key in a config file, or in its
+// spin.config.local.json when local is set, and returns the contents of the
+// file before and after. The change is only written when the config is still
+// valid with it.
+func SetValue(path string, key string, value interface{}, local bool) ([]byte, []byte, error) {
+	if key == "" {
+		return nil, nil, fmt.Errorf("no key given")
+	}
+
+	target := path
+	if local {
+		target = LocalPath(path)
+	}
+	before, err := os.ReadFile(target)
+	if local && os.IsNotExist(err) {
+		before, err = []byte("{}"), nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := decodeOrdered(before)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", target, err)
+	}
+	if err := setPath(doc, strings.Split(key, "."), value); err != nil {
+		return nil, nil, err
+	}
+	after, err := encodeOrdered(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.HasSuffix(before, []byte("\n")) {
+		after = bytes.TrimRight(after, "\n")
+	}
+
+	if err := validate(path, target, after); err != nil {
+		return nil, nil, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if err := os.WriteFile(target, after, 0644); err != nil {
+		return nil, nil, err
+	}
+	if local && string(before) == "{}" {
+		before = nil
+	}
+	return before, after, nil
+}
+
+// validate checks that the file at target is valid with the given contents,
+// on its own and merged with the other file
+func validate(path string, target string, contents []byte) error {
+	if err := CheckUnknownKeys(contents); err != nil {
+		return err
+	}
+	if target == path {
+		if err := validateConfig(contents); err != nil {
+			return err
+		}
+	}
+
+	read := func(file string) (interface{}, error) {
+		data, err := os.ReadFile(file)
+		if file == target {
+			data, err = contents, nil
+		}
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		return value, json.Unmarshal(data, &value)
+	}
+
+	base, err := read(path)
+	if err != nil {
+		return err
+	}
+	local, err := read(LocalPath(path))
+	if err != nil {
+		return err
+	}
+	merged := base
+	if local != nil {
+		merged = mergeValues(base, local)
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	return validateConfig(data)
+}
+
+// validateConfig checks that a config only has known keys, values of the
+// right types and variables that can be replaced
+func validateConfig(data []byte) error {
+	// Unknown keys are typos that would silently do nothing
+	if err := CheckUnknownKeys(data); err != nil {
+		return err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	for name, service := range config.Services {
+		if service == nil {
+			continue
+		}
+		if service.IdleTimeout != "" {
+			if d, err := time.ParseDuration(service.IdleTimeout); err != nil || d <= 0 {
+				return fmt.Errorf("service %s: idle_timeout must be a duration like \"30m\", got %q", name, service.IdleTimeout)
+			}
+		}
+		if service.ReadyTimeout != "" {
+			if d, err := time.ParseDuration(service.ReadyTimeout); err != nil || d <= 0 {
+				return fmt.Errorf("service %s: ready_timeout must be a duration like \"2m\", got %q", name, service.ReadyTimeout)
+			}
+		}
+	}
+	if config.Processes != nil {
+		for name, settings := range config.Processes.Settings {
+			if settings.ReadyTimeout != "" {
+				if d, err := time.ParseDuration(settings.ReadyTimeout); err != nil || d <= 0 {
+					return fmt.Errorf("process %s: ready_timeout must be a duration like \"30s\", got %q", name, settings.ReadyTimeout)
+				}
+			}
+		}
+		for name, count := range config.Processes.Formation {
+			if count < 0 {
+				return fmt.Errorf("processes.formation: %s must run 0 or more instances, got %d", name, count)
+			}
+		}
+	}
+	if config.DevContainer != nil && (config.DevContainer.Image == "") == (config.DevContainer.Dockerfile == "") {
+		return fmt.Errorf("dev_container needs either an image or a dockerfile")
+	}
+	for name, tunnel := range config.Tunnels {
+		switch {
+		case tunnel == nil || tunnel.Host == "":
+			return fmt.Errorf("tunnel %s: host is required", name)
+		case tunnel.LocalPort <= 0 || tunnel.RemotePort <= 0:
+			return fmt.Errorf("tunnel %s: local_port and remote_port are required", name)
+		case tunnel.HealthInterval != "":
+			if d, err := time.ParseDuration(tunnel.HealthInterval); err != nil || d <= 0 {
+				return fmt.Errorf("tunnel %s: health_interval must be a duration like \"10s\", got %q", name, tunnel.HealthInterval)
+			}
+		}
+	}
+	if config.Hooks != nil {
+		for i, webhook := range config.Hooks.Webhooks {
+			if !strings.HasPrefix(webhook.URL, "http://") && !strings.HasPrefix(webhook.URL, "https://") {
+				return fmt.Errorf("hooks.webhooks[%d]: url must be an http or https URL, got %q", i, webhook.URL)
+			}
+			for _, point := range webhook.On {
+				if !IsHookPoint(point) {
+					return fmt.Errorf("hooks.webhooks[%d]: unknown hook point %q, use %s", i, point, strings.Join(HookPoints, ", "))
+				}
+			}
+		}
+	}
+	if config.Node != nil && config.Node.PackageManager != "" && !detector.IsPackageManager(config.Node.PackageManager) {
+		return fmt.Errorf("node.package_manager must be one of %s, got %q", strings.Join(detector.PackageManagers, ", "), config.Node.PackageManager)
+	}
+	return config.interpolate()
+}
+
+// CheckUnknownKeys reports the first key of a config that spin doesn't know,
+// including keys of services
+func CheckUnknownKeys(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&Config{}); err != nil {
+		return fmt.Errorf("%s", strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	// Services decode themselves, which doesn't check their keys
+	var raw struct {
+		Services map[string]json.RawMessage `json:"services"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	type plain DockerServiceConfig
+	for name, data := range raw.Services {
+		var service struct {
+			plain
+			Port json.RawMessage `json:"port"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&service); err != nil {
+			return fmt.Errorf("service %s: %s", name, strings.TrimPrefix(err.Error(), "json: "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Default PORT allocation of foreman and overmind: each process gets a block
+// of ports starting at 5000, one port per instance
+const (
+	DefaultPortBase = 5000
+	DefaultPortStep = 100
+)
+
+// ForemanSettings are the settings of a project's .foreman or .overmind.env,
+// read so projects moving from foreman or overmind keep working
+type ForemanSettings struct {
+	Source    string            // File the settings were read from
+	Procfile  string            // Procfile to read, like foreman's procfile: Procfile.dev
+	PortBase  int               // PORT of the first process
+	PortStep  int               // Ports between processes
+	Formation string            // Instances of each process, like web=1,worker=2
+	Env       map[string]string // Variables of the env files foreman and overmind load, .env by default
+}
+
+// LoadForeman reads the .foreman or .overmind.env of the project in dir. It
+// returns nil when the project has neither.
+func LoadForeman(dir string) (*ForemanSettings, error) {
+	settings := &ForemanSettings{PortBase: DefaultPortBase, PortStep: DefaultPortStep, Env: make(map[string]string)}
+	envFiles := []string{".env"}
+	overmindEnv := make(map[string]string)
+
+	path := filepath.Join(dir, ".foreman")
+	values, err := readKeyValues(path, ":")
+	if os.IsNotExist(err) {
+		path = filepath.Join(dir, ".overmind.env")
+		values, err = readKeyValues(path, "=")
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// overmind names its settings like its environment variables and
+		// passes the other variables to processes
+		renamed := make(map[string]string)
+		for key, value := range values {
+			if name, ok := strings.CutPrefix(key, "OVERMIND_"); ok {
+				renamed[strings.ToLower(name)] = value
+			} else {
+				overmindEnv[strings.TrimPrefix(key, "export ")] = value
+			}
+		}
+		values = renamed
+	} else if err != nil {
+		return nil, err
+	}
+	settings.Source = filepath.Base(path)
+
+	for key, value := range values {
+		switch key {
+		case "procfile":
+			settings.Procfile = value
+		case "port":
+			if settings.PortBase, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("%s: port must be a number, got %q", settings.Source, value)
+			}
+		case "port_step":
+			if settings.PortStep, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("%s: port_step must be a number, got %q", settings.Source, value)
+			}
+		case "formation", "concurrency":
+			settings.Formation = value
+		case "env":
+			envFiles = strings.Split(value, ",")
+		}
+	}
+
+	// Like foreman, later env files override earlier ones
+	for _, file := range envFiles {
+		env, err := readKeyValues(filepath.Join(dir, strings.TrimSpace(file)), "=")
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range env {
+			settings.Env[strings.TrimPrefix(key, "export ")] = value
+		}
+	}
+	for key, value := range overmindEnv {
+		settings.Env[key] = value
+	}
+	return settings, nil
+}
+
+// readKeyValues reads a file of key and value lines, like .foreman's
+// "port: 5000" or .env's "PORT=5000", skipping comments and blank lines
+func readKeyValues(path string, separator string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, separator)
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, scanner.Err()
+}
+
+// PortBase returns the PORT given to the first process, from
+// processes.port_base or .foreman, or 0 when processes don't get a PORT
+func (c *Config) PortBase() int {
+	if c.Processes != nil && c.Processes.PortBase != 0 {
+		return c.Processes.PortBase
+	}
+	if c.Foreman != nil {
+		return c.Foreman.PortBase
+	}
+	return 0
+}
+
+// PortStep returns the number of ports between processes
+func (c *Config) PortStep() int {
+	if c.Processes != nil && c.Processes.PortStep != 0 {
+		return c.Processes.PortStep
+	}
+	if c.Foreman != nil && c.Foreman.PortStep != 0 {
+		return c.Foreman.PortStep
+	}
+	return DefaultPortStep
+}
+
+// ProcessType returns the Procfile entry an instance of a process runs, like
+// worker for worker.2
+func ProcessType(name string) string {
+	base, instance, ok := strings.Cut(name, ".")
+	if !ok {
+		return name
+	}
+	if _, err := strconv.Atoi(instance); err != nil {
+		return name
+	}
+	return base
+}
+
+// InstanceNumber returns the number of an instance of a process, like 2 for
+// worker.2, or 0 for processes running once
+func InstanceNumber(name string) int {
+	if _, instance, ok := strings.Cut(name, "."); ok {
+		if n, err := strconv.Atoi(instance); err == nil {
+			return n
+		}
+	}
+	return 0
+}
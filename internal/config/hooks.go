@@ -0,0 +1,123 @@
+package config
+
+import "encoding/json"
+
+// LifecycleHooks are run by spin up and spin down. Every hook point is a list
+// of steps run one after another.
+type LifecycleHooks struct {
+	PreUp              []HookStep    `json:"pre_up,omitempty"`               // After services started, before processes start
+	PostUp             []HookStep    `json:"post_up,omitempty"`              // After all processes started
+	PreDown            []HookStep    `json:"pre_down,omitempty"`             // Before anything is stopped
+	PostDown           []HookStep    `json:"post_down,omitempty"`            // After all processes stopped
+	OnProcessCrash     []HookStep    `json:"on_process_crash,omitempty"`     // When a process exits without being stopped
+	OnServiceUnhealthy []HookStep    `json:"on_service_unhealthy,omitempty"` // When the health check of a service starts failing
+	Webhooks           []HookWebhook `json:"webhooks,omitempty"`             // URLs hook points are posted to
+	MaxParallel        int           `json:"max_parallel,omitempty"`         // Commands of a step run at once, all of them when 0
+}
+
+// HookPoints lists the hook points in the order they are documented
+var HookPoints = []string{"pre_up", "post_up", "pre_down", "post_down", "on_process_crash", "on_service_unhealthy"}
+
+// IsHookPoint checks if name is a hook point
+func IsHookPoint(name string) bool {
+	for _, point := range HookPoints {
+		if point == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HookWebhook is a URL a JSON payload is posted to at hook points, like a
+// Slack incoming webhook
+type HookWebhook struct {
+	URL     string            `json:"url"`
+	On      []string          `json:"on,omitempty"`      // Hook points posted, all of them when empty
+	Headers map[string]string `json:"headers,omitempty"` // Extra headers, like Authorization
+}
+
+// Matches checks if the webhook is posted at a hook point
+func (w HookWebhook) Matches(point string) bool {
+	if len(w.On) == 0 {
+		return true
+	}
+	for _, on := range w.On {
+		if on == point {
+			return true
+		}
+	}
+	return false
+}
+
+// HookStep is a script name or command, or a list of them that run
+// concurrently, like ["bundle install", "yarn install"]
+type HookStep []string
+
+// UnmarshalJSON accepts a single string as well as a list
+func (s *HookStep) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = HookStep{single}
+		return nil
+	}
+
+	var group []string
+	if err := json.Unmarshal(data, &group); err != nil {
+		return err
+	}
+	*s = group
+	return nil
+}
+
+// MarshalJSON writes a step with a single command as a string
+func (s HookStep) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0])
+	}
+	return json.Marshal([]string(s))
+}
+
+// Steps returns the steps of a hook point
+func (h *LifecycleHooks) Steps(point string) [][]string {
+	if h == nil {
+		return nil
+	}
+
+	var steps []HookStep
+	switch point {
+	case "pre_up":
+		steps = h.PreUp
+	case "post_up":
+		steps = h.PostUp
+	case "pre_down":
+		steps = h.PreDown
+	case "post_down":
+		steps = h.PostDown
+	case "on_process_crash":
+		steps = h.OnProcessCrash
+	case "on_service_unhealthy":
+		steps = h.OnServiceUnhealthy
+	}
+
+	result := make([][]string, 0, len(steps))
+	for _, step := range steps {
+		if len(step) > 0 {
+			result = append(result, step)
+		}
+	}
+	return result
+}
+
+// WebhooksFor returns the webhooks posted at a hook point
+func (h *LifecycleHooks) WebhooksFor(point string) []HookWebhook {
+	if h == nil {
+		return nil
+	}
+	var webhooks []HookWebhook
+	for _, w := range h.Webhooks {
+		if w.URL != "" && w.Matches(point) {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// variablePattern matches ${PORT}, ${PROJECT_NAME} and ${env:VAR}, the last
+// optionally with a default like ${env:VAR:-value}
+var variablePattern = regexp.MustCompile(`\$\{(PORT|PROJECT_NAME|env:([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?)\}`)
+
+// Vars are the values variables in the config are replaced with. An empty
+// Port leaves ${PORT} in place, for the shell to expand in commands.
+type Vars struct {
+	Port        string
+	ProjectName string
+}
+
+// Interpolate replaces the variables in s. ${env:VAR} is read from the
+// environment, and is empty when the variable isn't set and has no default.
+// Other ${...} expressions are left alone.
+func Interpolate(s string, vars Vars) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variablePattern.FindStringSubmatch(match)
+		switch {
+		case groups[1] == "PORT":
+			if vars.Port == "" {
+				return match
+			}
+			return vars.Port
+		case groups[1] == "PROJECT_NAME":
+			return vars.ProjectName
+		default:
+			if value, ok := os.LookupEnv(groups[2]); ok && value != "" {
+				return value
+			}
+			return groups[4]
+		}
+	})
+}
+
+// interpolateSlice replaces the variables in every element of a list
+func interpolateSlice(values []string, vars Vars) []string {
+	for i, value := range values {
+		values[i] = Interpolate(value, vars)
+	}
+	return values
+}
+
+// interpolate replaces the variables in the services and scripts of the
+// config. In a service ${PORT} is the service's port.
+func (c *Config) interpolate() error {
+	vars := Vars{ProjectName: c.Name}
+
+	for name, script := range c.Scripts {
+		script.Command = Interpolate(script.Command, vars)
+		for key, value := range script.Env {
+			script.Env[key] = Interpolate(value, vars)
+		}
+		for _, hook := range []*Hook{script.Hooks.Pre, script.Hooks.Post} {
+			if hook != nil {
+				hook.Command = Interpolate(hook.Command, vars)
+			}
+		}
+		c.Scripts[name] = script
+	}
+
+	for name, svc := range c.Services {
+		if svc == nil {
+			continue
+		}
+		if svc.PortTemplate != "" {
+			port, err := strconv.Atoi(strings.TrimSpace(Interpolate(svc.PortTemplate, vars)))
+			if err != nil {
+				return fmt.Errorf("service %s: port %q isn't a number", name, svc.PortTemplate)
+			}
+			svc.Port, svc.PortTemplate = port, ""
+		}
+
+		serviceVars := vars
+		serviceVars.Port = strconv.Itoa(svc.Port)
+		svc.Image = Interpolate(svc.Image, serviceVars)
+		for key, value := range svc.Environment {
+			svc.Environment[key] = Interpolate(value, serviceVars)
+		}
+		volumes := make(map[string]string, len(svc.Volumes))
+		for key, target := range svc.Volumes {
+			volumes[Interpolate(key, serviceVars)] = Interpolate(target, serviceVars)
+		}
+		if svc.Volumes != nil {
+			svc.Volumes = volumes
+		}
+		svc.Command = interpolateSlice(svc.Command, serviceVars)
+		svc.Entrypoint = interpolateSlice(svc.Entrypoint, serviceVars)
+		if svc.HealthCheck != nil {
+			svc.HealthCheck.Command = interpolateSlice(svc.HealthCheck.Command, serviceVars)
+			if svc.HealthCheck.HTTP != nil {
+				svc.HealthCheck.HTTP.URL = Interpolate(svc.HealthCheck.HTTP.URL, serviceVars)
+			}
+		}
+		if svc.Hooks != nil {
+			for _, command := range append(svc.Hooks.PostStart, svc.Hooks.PreStop...) {
+				interpolateSlice(command, serviceVars)
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON reads a service, whose port may be a number or a string with
+// variables like "${env:PG_PORT:-5432}"
+func (c *DockerServiceConfig) UnmarshalJSON(data []byte) error {
+	type plain DockerServiceConfig
+	aux := struct {
+		*plain
+		Port json.RawMessage `json:"port"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	c.Port, c.PortTemplate = 0, ""
+	if len(aux.Port) == 0 || string(aux.Port) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(aux.Port, &c.Port); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(aux.Port, &c.PortTemplate); err != nil {
+		return fmt.Errorf("port must be a number or a string")
+	}
+	return nil
+}
+
+// MarshalJSON writes a service, keeping a port that was given as a string
+func (c DockerServiceConfig) MarshalJSON() ([]byte, error) {
+	type plain DockerServiceConfig
+	if c.PortTemplate == "" {
+		return json.Marshal(plain(c))
+	}
+	return json.Marshal(struct {
+		plain
+		Port string `json:"port"`
+	}{plain(c), c.PortTemplate})
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LocalFile is the untracked file whose settings override spin.config.json,
+// for ports, env, disabled services and personal scripts
+const LocalFile = "spin.config.local.json"
+
+// LocalPath returns the path of the local overrides of a config file
+func LocalPath(path string) string {
+	return filepath.Join(filepath.Dir(path), LocalFile)
+}
+
+// ReadMerged reads a config file with the local overrides next to it merged
+// over it
+func ReadMerged(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	local, err := os.ReadFile(LocalPath(path))
+	if os.IsNotExist(err) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var base, override interface{}
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(local, &override); err != nil {
+		return nil, fmt.Errorf("%s: %w", LocalFile, err)
+	}
+	return json.Marshal(mergeValues(base, override))
+}
+
+// mergeValues merges override into base: objects are merged key by key, null
+// removes a key and any other value replaces the one in base
+func mergeValues(base interface{}, override interface{}) interface{} {
+	baseObject, ok := base.(map[string]interface{})
+	overrideObject, isObject := override.(map[string]interface{})
+	if !ok || !isObject {
+		return override
+	}
+	for key, value := range overrideObject {
+		if value == nil {
+			delete(baseObject, key)
+			continue
+		}
+		baseObject[key] = mergeValues(baseObject[key], value)
+	}
+	return baseObject
+}
+
+// disableServices leaves out the services listed in disabled_services
+func (c *Config) disableServices() {
+	for _, name := range c.DisabledServices {
+		delete(c.Services, name)
+		services := c.Dependencies.Services[:0]
+		for _, service := range c.Dependencies.Services {
+			if service != name {
+				services = append(services, service)
+			}
+		}
+		c.Dependencies.Services = services
+	}
+}
+
+// Setting is a single value of the config and the file it comes from
+type Setting struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`               // JSON encoded, empty when removed
+	Source    string `json:"source"`              // spin.config.json or spin.config.local.json
+	Overrides string `json:"overrides,omitempty"` // JSON encoded value of spin.config.json the local file replaces
+	Removed   bool   `json:"removed,omitempty"`   // Removed by a null in the local file
+}
+
+// Explain returns the settings under a dotted key like services.redis.port,
+// or all settings when key is empty, with the file each of them comes from.
+// Values are shown as written, before variables are replaced.
+func Explain(path string, key string) ([]Setting, error) {
+	var base, local interface{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, err
+	}
+	if data, err := os.ReadFile(LocalPath(path)); err == nil {
+		if err := json.Unmarshal(data, &local); err != nil {
+			return nil, fmt.Errorf("%s: %w", LocalFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var parts []string
+	if key != "" {
+		parts = strings.Split(key, ".")
+	}
+
+	var merged interface{}
+	if data, err := ReadMerged(path); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+
+	var settings []Setting
+	value, found := lookup(merged, parts)
+	if found {
+		settings = explainValue(value, parts, base, local)
+	}
+	// Keys removed by the local file are no longer in the merged config
+	if localValue, ok := lookup(local, parts); ok {
+		settings = append(settings, removedKeys(localValue, parts, base)...)
+	}
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("%s is not set", key)
+	}
+	return settings, nil
+}
+
+// explainValue lists the leaves of a merged value with their source
+func explainValue(value interface{}, parts []string, base interface{}, local interface{}) []Setting {
+	if object, ok := value.(map[string]interface{}); ok && len(object) > 0 {
+		var settings []Setting
+		for _, key := range sortedKeys(object) {
+			settings = append(settings, explainValue(object[key], append(parts[:len(parts):len(parts)], key), base, local)...)
+		}
+		return settings
+	}
+
+	setting := Setting{Key: strings.Join(parts, "."), Value: encode(value), Source: "spin.config.json"}
+	if _, ok := lookup(local, parts); ok {
+		setting.Source = LocalFile
+		if baseValue, ok := lookup(base, parts); ok {
+			setting.Overrides = encode(baseValue)
+		}
+	}
+	return []Setting{setting}
+}
+
+// removedKeys lists the keys the local file removes with null
+func removedKeys(value interface{}, parts []string, base interface{}) []Setting {
+	if value == nil {
+		baseValue, ok := lookup(base, parts)
+		if !ok {
+			return nil
+		}
+		return []Setting{{Key: strings.Join(parts, "."), Source: LocalFile, Overrides: encode(baseValue), Removed: true}}
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var settings []Setting
+	for _, key := range sortedKeys(object) {
+		settings = append(settings, removedKeys(object[key], append(parts[:len(parts):len(parts)], key), base)...)
+	}
+	return settings
+}
+
+// lookup finds the value at a path of object keys and list indexes
+func lookup(value interface{}, parts []string) (interface{}, bool) {
+	if value == nil && len(parts) > 0 {
+		return nil, false
+	}
+	for _, part := range parts {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[part]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// sortedKeys returns the keys of an object in order
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// encode formats a value as compact JSON
+func encode(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
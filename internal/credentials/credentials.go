@@ -0,0 +1,133 @@
+// Package credentials finds the key Rails decrypts config/credentials.yml.enc
+// with, so processes and scripts get it without a config/master.key
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/afomera/spin/internal/userconfig"
+)
+
+// EnvVar is the variable Rails reads the master key from
+const EnvVar = "RAILS_MASTER_KEY"
+
+// ErrMissingKey is returned when a project has encrypted credentials and no
+// key Rails can decrypt them with
+var ErrMissingKey = errors.New("no master key")
+
+// Credentials are the encrypted credentials of a Rails project
+type Credentials struct {
+	File    string // Like config/credentials.yml.enc, relative to the project
+	KeyFile string // Key file Rails reads when RAILS_MASTER_KEY isn't set
+}
+
+// Detect returns the credentials Rails reads in an env of the project in dir,
+// preferring config/credentials/<env>.yml.enc over config/credentials.yml.enc
+func Detect(dir string, env string) (Credentials, bool) {
+	candidates := []Credentials{
+		{
+			File:    filepath.Join("config", "credentials", env+".yml.enc"),
+			KeyFile: filepath.Join("config", "credentials", env+".key"),
+		},
+		{
+			File:    filepath.Join("config", "credentials.yml.enc"),
+			KeyFile: filepath.Join("config", "master.key"),
+		},
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, c.File)); err == nil {
+			return c, true
+		}
+	}
+	return Credentials{}, false
+}
+
+// MasterKey returns the master key to give the processes of app in dir. It
+// is empty when Rails finds a key without it: the project has no encrypted
+// credentials, their key file exists, or env or the environment of spin
+// already set RAILS_MASTER_KEY. Otherwise the key is read from the
+// railsMasterKeys of the user configuration, and ErrMissingKey is returned
+// when it isn't there.
+func MasterKey(app string, dir string, env map[string]string) (string, error) {
+	creds, ok := Detect(dir, "development")
+	if !ok {
+		return "", nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, creds.KeyFile)); err == nil {
+		return "", nil
+	}
+	if env[EnvVar] != "" || os.Getenv(EnvVar) != "" {
+		return "", nil
+	}
+
+	userCfg, err := userconfig.Load()
+	if err != nil {
+		return "", err
+	}
+	ref := userCfg.RailsMasterKeys[app]
+	if ref == "" {
+		return "", fmt.Errorf("%w: %s is encrypted, set %s or add %s", ErrMissingKey, creds.File, EnvVar, creds.KeyFile)
+	}
+	return Resolve(ref)
+}
+
+// WithMasterKey returns env with the master key MasterKey finds added, as a
+// copy so the caller's map is left alone. On errors env is returned as is.
+func WithMasterKey(app string, dir string, env map[string]string) (map[string]string, error) {
+	key, err := MasterKey(app, dir, env)
+	if err != nil || key == "" {
+		return env, err
+	}
+	withKey := make(map[string]string, len(env)+1)
+	for k, v := range env {
+		withKey[k] = v
+	}
+	withKey[EnvVar] = key
+	return withKey, nil
+}
+
+// resolved caches secrets read from providers, which may prompt to unlock
+var (
+	resolvedMu sync.Mutex
+	resolved   = make(map[string]string)
+)
+
+// Resolve returns the secret a value of the user configuration refers to:
+//   - op://vault/item/field is read with the 1Password CLI
+//   - cmd:<command> is the output of a command run through the shell
+//   - anything else is the secret itself
+func Resolve(ref string) (string, error) {
+	var cmd *exec.Cmd
+	switch {
+	case strings.HasPrefix(ref, "op://"):
+		cmd = exec.Command("op", "read", "--no-newline", ref)
+	case strings.HasPrefix(ref, "cmd:"):
+		cmd = exec.Command("sh", "-c", strings.TrimPrefix(ref, "cmd:"))
+	default:
+		return ref, nil
+	}
+
+	resolvedMu.Lock()
+	defer resolvedMu.Unlock()
+	if secret, ok := resolved[ref]; ok {
+		return secret, nil
+	}
+
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the master key with %s: %w", cmd.Args[0], err)
+	}
+	secret := strings.TrimSpace(string(out))
+	if secret == "" {
+		return "", fmt.Errorf("%s printed an empty master key", cmd.Args[0])
+	}
+	resolved[ref] = secret
+	return secret, nil
+}
@@ -0,0 +1,234 @@
+package dashboard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/script"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// combinedHistory is the number of lines shown from every log when the
+// combined view is opened
+const combinedHistory = 20
+
+// startCombinedLogs opens the combined logs view and starts following the
+// logs of all processes
+func (m *Model) startCombinedLogs() tea.Cmd {
+	m.ViewMode = CombinedLogsMode
+	m.CombinedBuffer = nil
+	m.combinedStop = make(chan struct{})
+	m.combinedTailing = make(map[string]bool)
+	m.CombinedChan = make(chan CombinedLine, 256)
+	if m.HiddenLogs == nil {
+		m.HiddenLogs = make(map[string]bool)
+	}
+
+	m.followNewLogs()
+	m.updateCombinedView()
+	return m.readCombinedCmd()
+}
+
+// stopCombinedLogs stops following the logs and returns to the details view
+func (m *Model) stopCombinedLogs() {
+	if m.combinedStop != nil {
+		close(m.combinedStop)
+		m.combinedStop = nil
+	}
+	m.ViewMode = DetailsMode
+	m.CombinedBuffer = nil
+}
+
+// followNewLogs starts following the logs of processes that aren't followed yet
+func (m *Model) followNewLogs() {
+	for _, p := range m.Processes {
+		if m.combinedTailing[p.Name] {
+			continue
+		}
+		m.combinedTailing[p.Name] = true
+		path, err := process.LogPath(p.AppName, p.Name)
+		if err != nil {
+			m.ErrorMsg = fmt.Sprintf("Error getting the log file of %s: %v", p.Name, err)
+			continue
+		}
+		go tailLog(path, p.Name, m.CombinedChan, m.combinedStop)
+	}
+
+	// Scripts run from the dashboard or the command line log next to the
+	// processes, their lines are already prefixed with the script name
+	if !m.combinedTailing[script.OutputLogName] {
+		if path, err := script.OutputPath(m.ProjectName); err == nil {
+			m.combinedTailing[script.OutputLogName] = true
+			go tailLog(path, script.OutputLogName, m.CombinedChan, m.combinedStop)
+		}
+	}
+}
+
+// readCombinedCmd returns a command that waits for the next combined log line
+func (m *Model) readCombinedCmd() tea.Cmd {
+	lines, stop := m.CombinedChan, m.combinedStop
+	return func() tea.Msg {
+		select {
+		case line := <-lines:
+			return CombinedLogMsg(line)
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// handleCombinedLogMsg adds a line to the combined view
+func (m *Model) handleCombinedLogMsg(msg CombinedLogMsg) (*Model, tea.Cmd) {
+	if m.ViewMode != CombinedLogsMode {
+		return m, nil
+	}
+
+	m.CombinedBuffer = append(m.CombinedBuffer, CombinedLine(msg))
+	if limit := m.scrollback(); len(m.CombinedBuffer) > limit {
+		m.CombinedBuffer = m.CombinedBuffer[len(m.CombinedBuffer)-limit:]
+	}
+
+	if !m.HiddenLogs[msg.Name] {
+		m.updateCombinedView()
+	}
+	return m, m.readCombinedCmd()
+}
+
+// toggleCombinedProcess shows or hides the lines of the selected process
+func (m *Model) toggleCombinedProcess() {
+	if m.Cursor >= len(m.Processes) {
+		return
+	}
+	name := m.Processes[m.Cursor].Name
+	m.HiddenLogs[name] = !m.HiddenLogs[name]
+	m.updateProcessView()
+	m.updateCombinedView()
+}
+
+// updateCombinedView renders the lines of all processes that aren't hidden
+func (m *Model) updateCombinedView() {
+	width := len(script.OutputLogName)
+	for _, p := range m.Processes {
+		if len(p.Name) > width {
+			width = len(p.Name)
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range m.CombinedBuffer {
+		if m.HiddenLogs[line.Name] {
+			continue
+		}
+		text := line.Text
+		switch lineLevel(text) {
+		case LevelError:
+			text = ErrorLineStyle.Render(text)
+		case LevelWarn:
+			text = WarnLineStyle.Render(text)
+		}
+		b.WriteString(fmt.Sprintf("%s%-*s%s │ %s\n", logger.GetColorForService(line.Name), width, line.Name, logger.Reset, text))
+	}
+
+	atBottom := m.DetailsView.AtBottom()
+	m.DetailsView.SetContent(b.String())
+	if atBottom && m.Follow {
+		m.DetailsView.GotoBottom()
+	}
+}
+
+// tailLog sends the last lines of a log file and then every line appended to
+// it until stop is closed
+func tailLog(path string, name string, out chan<- CombinedLine, stop <-chan struct{}) {
+	send := func(text string) bool {
+		select {
+		case out <- CombinedLine{Name: name, Text: strings.TrimRight(text, "\r\n")}:
+			return true
+		case <-stop:
+			return false
+		}
+	}
+
+	var file *os.File
+	for file == nil {
+		// The log is created when the process starts
+		f, err := os.Open(path)
+		if err == nil {
+			file = f
+			break
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(time.Second):
+		}
+	}
+	defer file.Close()
+
+	for _, line := range lastLines(file, combinedHistory) {
+		if !send(line) {
+			return
+		}
+	}
+	followFile(file, stop, send)
+}
+
+// followFile sends every line appended to a file from its current position
+// until stop is closed or send returns false
+func followFile(file *os.File, stop <-chan struct{}, send func(string) bool) {
+	reader := bufio.NewReader(file)
+	partial := ""
+	for {
+		text, err := reader.ReadString('\n')
+		partial += text
+		if err == nil {
+			if !send(strings.TrimRight(partial, "\r\n")) {
+				return
+			}
+			partial = ""
+			continue
+		}
+		if err != io.EOF {
+			return
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// lastLines returns up to n complete lines from the end of a file and leaves
+// the file positioned at its end
+func lastLines(file *os.File, n int) []string {
+	end, err := file.Seek(0, io.SeekEnd)
+	if err != nil || end == 0 {
+		return nil
+	}
+
+	start := end - 64*1024
+	if start < 0 {
+		start = 0
+	}
+	buf := make([]byte, end-start)
+	if _, err := file.ReadAt(buf, start); err != nil && err != io.EOF {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(string(buf), "\n"), "\n")
+	if start > 0 && len(lines) > 0 {
+		// The first line was cut off
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
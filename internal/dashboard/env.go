@@ -0,0 +1,78 @@
+package dashboard
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/afomera/spin/internal/process"
+)
+
+// updateEnvView renders the environment the selected process was started
+// with, grouped by where the variables came from
+func (m *Model) updateEnvView(proc *process.Process) {
+	var b strings.Builder
+	b.WriteString(HeaderStyle.Render(fmt.Sprintf("Environment: %s/%s", proc.AppName, proc.Name)) + "\n")
+	b.WriteString(InfoStyle.Render("Press 'e' to return to details • secrets are masked") + "\n")
+
+	vars, err := process.LoadEnv(proc.AppName, proc.Name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			b.WriteString("\nThe environment wasn't recorded, restart the process with spin up to record it\n")
+		} else {
+			b.WriteString("\n" + ErrorStyle.Render(fmt.Sprintf("Error reading environment: %v", err)) + "\n")
+		}
+		m.DetailsView.SetContent(b.String())
+		return
+	}
+
+	set := make(map[string]bool, len(vars))
+	var fromSpin, fromShell []process.EnvVar
+	for _, v := range vars {
+		set[v.Key] = true
+		if v.Source == process.EnvSourceShell {
+			fromShell = append(fromShell, v)
+		} else {
+			fromSpin = append(fromSpin, v)
+		}
+	}
+
+	b.WriteString("\n" + HeaderStyle.Render("Set by spin.config.json") + "\n")
+	if len(fromSpin) == 0 {
+		b.WriteString("None\n")
+	}
+	for _, v := range fromSpin {
+		b.WriteString(formatEnvVar(v, ""))
+	}
+
+	// dotenv doesn't override variables that are already set, which is a
+	// common reason for an app seeing a different value than its .env file
+	if info, err := m.Manager.Store().GetProcess(proc.Name); err == nil && info.WorkDir != "" {
+		if dotenv := process.LoadDotenv(info.WorkDir); len(dotenv) > 0 {
+			b.WriteString("\n" + HeaderStyle.Render(".env files") + "\n")
+			for _, v := range dotenv {
+				note := ""
+				if set[v.Key] {
+					note = StoppedStyle.Render(" (ignored by dotenv, already set)")
+				}
+				b.WriteString(formatEnvVar(v, note))
+			}
+		}
+	}
+
+	b.WriteString("\n" + HeaderStyle.Render(fmt.Sprintf("Inherited from the shell (%d)", len(fromShell))) + "\n")
+	for _, v := range fromShell {
+		b.WriteString(formatEnvVar(v, ""))
+	}
+
+	m.DetailsView.SetContent(b.String())
+}
+
+// formatEnvVar renders a variable with its value masked and its source
+func formatEnvVar(v process.EnvVar, note string) string {
+	source := ""
+	if v.Source != process.EnvSourceShell {
+		source = HelpStyle.Render(" [" + v.Source + "]")
+	}
+	return fmt.Sprintf("%s=%s%s%s\n", InfoStyle.Render(v.Key), process.MaskValue(v.Key, v.Value), source, note)
+}
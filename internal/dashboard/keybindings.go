@@ -7,13 +7,21 @@ type KeyMap struct {
 	Up          key.Binding
 	Down        key.Binding
 	Tab         key.Binding
+	Start       key.Binding
 	Restart     key.Binding
 	Stop        key.Binding
 	Debug       key.Binding
 	Logs        key.Binding
+	Env         key.Binding
+	Combined    key.Binding
+	ToggleLog   key.Binding
+	Follow      key.Binding
+	Scripts     key.Binding
 	PageUp      key.Binding
 	PageDown    key.Binding
 	Search      key.Binding
+	NextError   key.Binding
+	PrevError   key.Binding
 	Escape      key.Binding
 	Quit        key.Binding
 	ToggleInput key.Binding
@@ -29,10 +37,12 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Tab},
-		{k.PageUp, k.PageDown},
-		{k.Restart, k.Stop},
-		{k.Debug, k.Logs},
-		{k.Search},
+		{k.PageUp, k.PageDown, k.Follow},
+		{k.Start, k.Restart, k.Stop},
+		{k.Debug, k.Logs, k.Env},
+		{k.Combined, k.ToggleLog},
+		{k.Scripts, k.Enter},
+		{k.Search, k.NextError, k.PrevError},
 		{k.Quit},
 	}
 }
@@ -60,6 +70,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("pgdown"),
 			key.WithHelp("pgdn", "page down"),
 		),
+		Start: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "start service"),
+		),
 		Restart: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("r", "restart"),
@@ -69,17 +83,45 @@ func DefaultKeyMap() KeyMap {
 			key.WithHelp("s", "stop"),
 		),
 		Debug: key.NewBinding(
-			key.WithKeys("d"),
-			key.WithHelp("d", "debug"),
+			key.WithKeys("a", "d"),
+			key.WithHelp("a", "attach"),
 		),
 		Logs: key.NewBinding(
 			key.WithKeys("l"),
 			key.WithHelp("l", "toggle logs"),
 		),
+		Env: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "environment"),
+		),
+		Combined: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "combined logs"),
+		),
+		ToggleLog: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "toggle process in combined logs"),
+		),
+		Scripts: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "scripts tab"),
+		),
+		Follow: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "follow logs"),
+		),
 		Search: key.NewBinding(
 			key.WithKeys("/"),
 			key.WithHelp("/", "search logs"),
 		),
+		NextError: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "next error"),
+		),
+		PrevError: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "previous error"),
+		),
 		Escape: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "exit search/input"),
@@ -94,7 +136,7 @@ func DefaultKeyMap() KeyMap {
 		),
 		Enter: key.NewBinding(
 			key.WithKeys("enter"),
-			key.WithHelp("enter", "execute command"),
+			key.WithHelp("enter", "run script/command"),
 		),
 	}
 }
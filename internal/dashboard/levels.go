@@ -0,0 +1,202 @@
+package dashboard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/afomera/spin/internal/process"
+)
+
+// LogLevel is the severity of a log line
+type LogLevel int
+
+const (
+	LevelNone LogLevel = iota
+	LevelWarn
+	LevelError
+)
+
+var (
+	// Uppercase level words like Rails' "ERROR -- :" or "[WARN]", and
+	// lowercase levels in logfmt or JSON logs
+	errorPattern = regexp.MustCompile(`\b(ERROR|FATAL|PANIC|CRITICAL)\b|(?i)level"?[=:]\s*"?(error|fatal|panic|critical)\b`)
+	warnPattern  = regexp.MustCompile(`\b(WARN|WARNING)\b|(?i)level"?[=:]\s*"?(warn|warning)\b`)
+
+	// Frames of Ruby, Node, Go and Python stack traces
+	stackPattern = regexp.MustCompile(`^\s+(at |from |in |File ")|^\s+\S+\.(rb|js|ts|go|py):\d+|^Traceback |^goroutine \d+ `)
+)
+
+// lineLevel returns the level of a single log line
+func lineLevel(line string) LogLevel {
+	switch {
+	case errorPattern.MatchString(line):
+		return LevelError
+	case warnPattern.MatchString(line):
+		return LevelWarn
+	}
+	return LevelNone
+}
+
+// isStackFrame reports whether a line is part of a stack trace
+func isStackFrame(line string) bool {
+	return stackPattern.MatchString(line)
+}
+
+// renderLogLines colorizes warnings and errors, including the stack traces
+// that follow an error, and returns the indexes of the lines an error starts on
+func renderLogLines(lines []string) (string, []int) {
+	var (
+		b       strings.Builder
+		errors  []int
+		inTrace bool
+	)
+	for i, line := range lines {
+		level := lineLevel(line)
+		if level == LevelNone && inTrace && isStackFrame(line) {
+			level = LevelError
+		} else {
+			inTrace = level == LevelError
+			if level == LevelError {
+				errors = append(errors, i)
+			}
+		}
+
+		switch level {
+		case LevelError:
+			b.WriteString(ErrorLineStyle.Render(line))
+		case LevelWarn:
+			b.WriteString(WarnLineStyle.Render(line))
+		default:
+			b.WriteString(LogStyle.Render(line))
+		}
+		if i < len(lines)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), errors
+}
+
+// nextError returns the first error index after line, wrapping around
+func nextError(index []int, line int) (int, bool) {
+	if len(index) == 0 {
+		return 0, false
+	}
+	for _, i := range index {
+		if i > line {
+			return i, true
+		}
+	}
+	return index[0], true
+}
+
+// prevError returns the last error index before line, wrapping around
+func prevError(index []int, line int) (int, bool) {
+	if len(index) == 0 {
+		return 0, false
+	}
+	for i := len(index) - 1; i >= 0; i-- {
+		if index[i] < line {
+			return index[i], true
+		}
+	}
+	return index[len(index)-1], true
+}
+
+// jumpToError scrolls the logs to the next or previous error
+func (m *Model) jumpToError(forward bool) {
+	if m.Search.Active && m.Search.Term != "" {
+		m.ErrorMsg = "Clear the search to jump between errors"
+		return
+	}
+
+	jump := prevError
+	if forward {
+		jump = nextError
+	}
+	line, ok := jump(m.ErrorIndex, m.DetailsView.YOffset)
+	if !ok {
+		m.ErrorMsg = "No errors in the logs"
+		return
+	}
+
+	// Reading an error pauses following so new lines don't scroll it away
+	m.Follow = false
+	m.DetailsView.SetYOffset(line)
+	for i, l := range m.ErrorIndex {
+		if l == line {
+			m.ErrorMsg = fmt.Sprintf("Error %d of %d", i+1, len(m.ErrorIndex))
+			break
+		}
+	}
+}
+
+// errorCounter counts the errors in the logs of processes, reading only what
+// was appended since the last count
+type errorCounter struct {
+	offsets map[string]int64
+	counts  map[string]int
+}
+
+func newErrorCounter() *errorCounter {
+	return &errorCounter{
+		offsets: make(map[string]int64),
+		counts:  make(map[string]int),
+	}
+}
+
+// update counts the new errors in the logs of the given processes
+func (c *errorCounter) update(processes []*process.Process) {
+	for _, p := range processes {
+		path, err := process.LogPath(p.AppName, p.Name)
+		if err != nil {
+			return
+		}
+		c.scan(p.Name, path)
+	}
+}
+
+// scan counts the errors in the part of a log after the last offset
+func (c *errorCounter) scan(name string, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
+	offset := c.offsets[name]
+	if info.Size() < offset {
+		// The log was truncated when the process restarted
+		offset = 0
+		c.counts[name] = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// Leave partial lines for the next scan
+			break
+		}
+		offset += int64(len(line))
+		if lineLevel(line) == LevelError {
+			c.counts[name]++
+		}
+	}
+	c.offsets[name] = offset
+}
+
+// count returns the number of errors logged by a process
+func (c *errorCounter) count(name string) int {
+	return c.counts[name]
+}
@@ -1,19 +1,21 @@
 package dashboard
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/events"
+	"github.com/afomera/spin/internal/metrics"
 	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/service/docker"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -52,6 +54,36 @@ func executeCommand(command string) tea.Cmd {
 	}
 }
 
+// spinCommand runs spin itself with the given arguments and returns its
+// output, used for actions that print progress
+func spinCommand(args ...string) tea.Cmd {
+	return func() tea.Msg {
+		command := "spin " + strings.Join(args, " ")
+		exe, err := os.Executable()
+		if err != nil {
+			return CommandMsg{Command: command, Output: err.Error(), Error: err}
+		}
+
+		output, err := exec.Command(exe, args...).CombinedOutput()
+		if err != nil && len(output) == 0 {
+			output = []byte(err.Error())
+		}
+		return CommandMsg{Command: command, Output: string(output), Error: err}
+	}
+}
+
+// attachProcess suspends the dashboard and attaches the terminal to a
+// process like spin debug does, until the user detaches
+func attachProcess(name string) tea.Cmd {
+	exe, err := os.Executable()
+	if err != nil {
+		return func() tea.Msg { return AttachMsg{Name: name, Error: err} }
+	}
+	return tea.ExecProcess(exec.Command(exe, "debug", name), func(err error) tea.Msg {
+		return AttachMsg{Name: name, Error: err}
+	})
+}
+
 // New creates a new dashboard model with the given configuration
 func New(cfg *config.Config) (*Model, error) {
 	manager := process.GetManager(cfg)
@@ -78,11 +110,34 @@ func New(cfg *config.Config) (*Model, error) {
 		projectName = name
 	}
 
+	// Show the latest events and keep following the stream for the lifetime
+	// of the dashboard
+	filter := events.Filter{App: projectName}
+	recent, err := events.Recent(DefaultConfig().MaxEvents, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error reading events: %v", err)
+	}
+	eventChan := make(chan events.Event, 16)
+	go events.Follow(context.Background(), filter, func(e events.Event) {
+		eventChan <- e
+	})
+
+	// Services are listed without Docker, just without their state
+	dm, err := docker.NewServiceManager("")
+	if err != nil {
+		dm = nil
+	}
+
 	return &Model{
+		Config:      cfg,
+		Docker:      dm,
+		Events:      recent,
+		EventChan:   eventChan,
+		errorCounts: newErrorCounter(),
+		Follow:      true,
 		Help:        help.New(),
 		Manager:     manager,
 		ViewMode:    DetailsMode,
-		LogBuffer:   make([]string, 0, DefaultConfig().MaxLogBuffer),
 		Input:       ti,
 		InputActive: false,
 		ProjectName: projectName,
@@ -95,6 +150,7 @@ func (m *Model) Init() tea.Cmd {
 		tea.EnterAltScreen,
 		m.tickCmd(),
 		m.readLogsCmd(),
+		m.readEventsCmd(),
 	)
 }
 
@@ -107,61 +163,95 @@ func (m *Model) tickCmd() tea.Cmd {
 
 // readLogsCmd returns a command that reads from the log channel
 func (m *Model) readLogsCmd() tea.Cmd {
+	lines, stop := m.LogChan, m.logStop
 	return func() tea.Msg {
-		if m.LogChan == nil {
+		if lines == nil {
+			return nil
+		}
+		select {
+		case line := <-lines:
+			return LogMsg(line)
+		case <-stop:
 			return nil
 		}
-		msg := <-m.LogChan
-		return LogMsg(msg)
 	}
 }
 
-// startLogReader starts reading logs for the specified process
-func (m *Model) startLogReader(processName string) error {
-	// Close existing log file if any
-	if m.LogFile != nil {
-		m.LogFile.Close()
-		m.LogFile = nil
+// readEventsCmd returns a command that waits for the next event
+func (m *Model) readEventsCmd() tea.Cmd {
+	return func() tea.Msg {
+		return EventMsg(<-m.EventChan)
 	}
+}
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("error getting home directory: %v", err)
-	}
+// startLogReader loads the end of the log of the specified process into the
+// buffer and starts following it
+func (m *Model) startLogReader(processName string) error {
+	m.stopLogReader()
 
 	proc := m.Processes[m.Cursor]
-	logPath := filepath.Join(home, ".spin", "output", process.SanitizeAppName(proc.AppName), fmt.Sprintf("%s.log", processName))
+	logPath, err := process.LogPath(proc.AppName, processName)
+	if err != nil {
+		return fmt.Errorf("error getting the log file: %v", err)
+	}
 	file, err := os.Open(logPath)
 	if err != nil {
 		return fmt.Errorf("error opening log file: %v", err)
 	}
 	m.LogFile = file
 
-	if m.LogChan == nil {
-		m.LogChan = make(chan string)
-	}
+	lines := make(chan string, 256)
+	stop := make(chan struct{})
+	m.LogChan = lines
+	m.logStop = stop
 
-	m.LogBuffer = nil
+	m.LogBuffer = lastLines(file, m.scrollback())
+	m.ErrorIndex = nil
 
 	go func() {
 		defer file.Close()
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			m.LogChan <- scanner.Text()
-		}
-		// Keep watching for new content
-		for {
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				m.LogChan <- scanner.Text()
+		followFile(file, stop, func(line string) bool {
+			select {
+			case lines <- line:
+				return true
+			case <-stop:
+				return false
 			}
-			time.Sleep(100 * time.Millisecond)
-		}
+		})
 	}()
 
 	return nil
 }
 
+// stopLogReader stops following the log of the process shown in the logs view
+func (m *Model) stopLogReader() {
+	if m.logStop != nil {
+		close(m.logStop)
+		m.logStop = nil
+	}
+	m.LogChan = nil
+	m.LogFile = nil
+}
+
+// scrollback returns how many lines of logs are kept
+func (m *Model) scrollback() int {
+	if m.Scrollback > 0 {
+		return m.Scrollback
+	}
+	return DefaultConfig().MaxLogBuffer
+}
+
+// toggleFollow turns scrolling to new log lines on or off
+func (m *Model) toggleFollow() {
+	m.Follow = !m.Follow
+	if m.Follow {
+		m.DetailsView.GotoBottom()
+		m.ErrorMsg = "Following new lines"
+	} else {
+		m.ErrorMsg = "Paused following, press 'f' to resume"
+	}
+}
+
 // handleKeyMsg handles keyboard input messages
 func (m *Model) handleKeyMsg(msg tea.KeyMsg) (*Model, tea.Cmd) {
 	// Handle input mode
@@ -227,6 +317,12 @@ func (m *Model) handleSearchMode(msg tea.KeyMsg) (*Model, tea.Cmd) {
 func (m *Model) handleRegularKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 	keys := DefaultKeyMap()
 
+	if m.ActiveTab == ScriptsTab {
+		if cmd, handled := m.handleScriptKeys(msg); handled {
+			return m, cmd
+		}
+	}
+
 	switch {
 	case key.Matches(msg, keys.Quit):
 		m.Quitting = true
@@ -239,6 +335,16 @@ func (m *Model) handleRegularKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 			m.ErrorMsg = "Search mode: Type to filter logs, ESC to exit"
 		}
 
+	case key.Matches(msg, keys.NextError):
+		if m.ViewMode == LogsMode {
+			m.jumpToError(true)
+		}
+
+	case key.Matches(msg, keys.PrevError):
+		if m.ViewMode == LogsMode {
+			m.jumpToError(false)
+		}
+
 	case key.Matches(msg, keys.Tab):
 		if m.ActivePanel == ProcessList {
 			m.ActivePanel = ProcessDetails
@@ -258,7 +364,7 @@ func (m *Model) handleRegularKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 
 	case key.Matches(msg, keys.Down):
 		if m.ActivePanel == ProcessList {
-			if m.Cursor < len(m.Processes)-1 {
+			if m.Cursor < len(m.Processes)+len(m.Services)-1 {
 				m.Cursor++
 				m.updateDetailsView()
 			}
@@ -282,17 +388,58 @@ func (m *Model) handleRegularKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 			if err := m.Manager.StopProcess(proc.AppName, proc.Name); err != nil {
 				m.ErrorMsg = fmt.Sprintf("Error stopping process: %v", err)
 			}
+		} else if svc := m.selectedService(); svc != nil {
+			return m, spinCommand("services", "stop", svc.Name)
 		}
 
-	case key.Matches(msg, keys.Debug):
+	case key.Matches(msg, keys.Start):
+		if svc := m.selectedService(); svc != nil {
+			if svc.Running {
+				m.ErrorMsg = fmt.Sprintf("Service %s is already running", svc.Name)
+				return m, nil
+			}
+			return m, spinCommand("services", "start", svc.Name)
+		}
+
+	case key.Matches(msg, keys.Restart):
 		if len(m.Processes) > 0 && m.Cursor < len(m.Processes) {
 			proc := m.Processes[m.Cursor]
-			if err := m.Manager.DebugProcess(m.ProjectName, proc.Name); err != nil {
-				m.ErrorMsg = fmt.Sprintf("Error debugging process: %v", err)
+			if err := m.Manager.RestartProcess(proc.AppName, proc.Name); err != nil {
+				m.ErrorMsg = fmt.Sprintf("Error restarting process: %v", err)
 			}
+		} else if svc := m.selectedService(); svc != nil {
+			return m, spinCommand("services", "restart", svc.Name)
+		}
+
+	case key.Matches(msg, keys.Debug):
+		if len(m.Processes) > 0 && m.Cursor < len(m.Processes) {
+			return m, attachProcess(m.Processes[m.Cursor].Name)
+		}
+
+	case key.Matches(msg, keys.Scripts):
+		m.toggleScriptsTab()
+
+	case key.Matches(msg, keys.Combined):
+		if m.ViewMode == CombinedLogsMode {
+			m.stopCombinedLogs()
+			m.updateProcessView()
+			m.updateDetailsView()
+			return m, nil
+		}
+		cmd := m.startCombinedLogs()
+		m.updateProcessView()
+		return m, cmd
+
+	case key.Matches(msg, keys.ToggleLog):
+		if m.ViewMode == CombinedLogsMode {
+			m.toggleCombinedProcess()
 		}
 
 	case key.Matches(msg, keys.Logs):
+		if m.ViewMode == CombinedLogsMode {
+			m.stopCombinedLogs()
+			m.updateProcessView()
+		}
 		if len(m.Processes) > 0 && m.Cursor < len(m.Processes) {
 			if m.ViewMode == DetailsMode {
 				m.ViewMode = LogsMode
@@ -300,11 +447,40 @@ func (m *Model) handleRegularKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 				if err := m.startLogReader(proc.Name); err != nil {
 					m.ErrorMsg = fmt.Sprintf("Error reading logs: %v", err)
 					m.ViewMode = DetailsMode
+					m.updateDetailsView()
+					return m, nil
 				}
-			} else {
+				m.filterLogs()
+				if m.Follow {
+					m.DetailsView.GotoBottom()
+				}
+				return m, m.readLogsCmd()
+			}
+			m.stopLogReader()
+			m.ViewMode = DetailsMode
+			m.updateDetailsView()
+		}
+
+	case key.Matches(msg, keys.Follow):
+		if m.ViewMode == LogsMode || m.ViewMode == CombinedLogsMode {
+			m.toggleFollow()
+		}
+
+	case key.Matches(msg, keys.Env):
+		if m.ViewMode == CombinedLogsMode {
+			m.stopCombinedLogs()
+			m.updateProcessView()
+		}
+		m.stopLogReader()
+		if len(m.Processes) > 0 && m.Cursor < len(m.Processes) {
+			if m.ViewMode == EnvMode {
 				m.ViewMode = DetailsMode
+			} else {
+				m.ViewMode = EnvMode
+				m.Search.Active = false
 			}
 			m.updateDetailsView()
+			m.DetailsView.GotoTop()
 		}
 
 	case key.Matches(msg, keys.ToggleInput):
@@ -330,7 +506,9 @@ func (m *Model) handleRegularKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 // filterLogs applies the current search term to the log buffer
 func (m *Model) filterLogs() {
 	if !m.Search.Active || m.Search.Term == "" {
-		m.DetailsView.SetContent(strings.Join(m.LogBuffer, "\n"))
+		var content string
+		content, m.ErrorIndex = renderLogLines(m.LogBuffer)
+		m.DetailsView.SetContent(content)
 		return
 	}
 
@@ -351,7 +529,8 @@ func (m *Model) filterLogs() {
 	}
 
 	if len(filtered) > 0 {
-		m.DetailsView.SetContent(strings.Join(filtered, "\n"))
+		content, _ := renderLogLines(filtered)
+		m.DetailsView.SetContent(content)
 	} else {
 		m.DetailsView.SetContent("No matches found for: " + m.Search.Term)
 	}
@@ -375,6 +554,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.OutputBuffer = append(m.OutputBuffer, output)
 		return m, nil
 
+	case AttachMsg:
+		if msg.Error != nil {
+			m.ErrorMsg = fmt.Sprintf("Error attaching to %s: %v", msg.Name, msg.Error)
+		}
+		return m, nil
+
 	case tea.KeyMsg:
 		model, cmd := m.handleKeyMsg(msg)
 		return model, cmd
@@ -384,27 +569,66 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case TickMsg:
 		m.LastUpdate = time.Time(msg)
-		processes := m.Manager.ListProcesses()
+		// Containers of services are listed with the services
+		var processes, containers []*process.Process
+		for _, p := range m.Manager.ListProcesses() {
+			if p.Type == process.ProcessTypeDocker {
+				containers = append(containers, p)
+			} else {
+				processes = append(processes, p)
+			}
+		}
 
-		// Sort processes by name
+		// Sort processes by name, instances like worker.2 by number
 		sort.Slice(processes, func(i, j int) bool {
-			return processes[i].Name < processes[j].Name
+			ti, tj := config.ProcessType(processes[i].Name), config.ProcessType(processes[j].Name)
+			if ti != tj {
+				return ti < tj
+			}
+			return config.InstanceNumber(processes[i].Name) < config.InstanceNumber(processes[j].Name)
 		})
 
 		m.Processes = processes
+		m.loadURLs()
+		m.errorCounts.update(processes)
+		m.refreshServices(containers)
+		if last := len(m.Processes) + len(m.Services) - 1; m.Cursor > last && last >= 0 {
+			m.Cursor = last
+		}
 		m.updateProcessView()
 		if m.ViewMode == DetailsMode {
 			m.updateDetailsView()
 		}
+		if m.ViewMode == CombinedLogsMode {
+			m.followNewLogs()
+		}
 		// Force rerender every second
 		return m, tea.Batch(
 			m.tickCmd(),
-			m.readLogsCmd(),
 			func() tea.Msg { return tea.WindowSizeMsg{Width: m.Width, Height: m.Height} },
 		)
 
 	case LogMsg:
 		return m.handleLogMsg(msg)
+
+	case CombinedLogMsg:
+		return m.handleCombinedLogMsg(msg)
+
+	case ScriptOutputMsg:
+		return m.handleScriptOutputMsg(msg)
+
+	case ScriptDoneMsg:
+		return m.handleScriptDoneMsg(msg)
+
+	case EventMsg:
+		m.Events = append(m.Events, events.Event(msg))
+		if len(m.Events) > DefaultConfig().MaxEvents {
+			m.Events = m.Events[len(m.Events)-DefaultConfig().MaxEvents:]
+		}
+		if m.ViewMode == DetailsMode {
+			m.updateDetailsView()
+		}
+		return m, m.readEventsCmd()
 	}
 
 	// Handle viewport updates
@@ -416,6 +640,21 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// loadURLs reads the public URLs of the processes from the process store,
+// so rendering doesn't read it
+func (m *Model) loadURLs() {
+	entries, err := m.Manager.Store().Entries()
+	if err != nil {
+		return
+	}
+	m.URLs = make(map[string]string)
+	for _, p := range m.Processes {
+		if info, ok := entries[process.EntryKey(m.Config.Name, p.Name)]; ok && info.URL != "" {
+			m.URLs[p.Name] = info.URL
+		}
+	}
+}
+
 // handleWindowResize handles window resize events
 func (m *Model) handleWindowResize(msg tea.WindowSizeMsg) (*Model, tea.Cmd) {
 	m.Width = msg.Width
@@ -446,28 +685,51 @@ func (m *Model) handleWindowResize(msg tea.WindowSizeMsg) (*Model, tea.Cmd) {
 // handleLogMsg handles new log messages
 func (m *Model) handleLogMsg(msg LogMsg) (*Model, tea.Cmd) {
 	if m.ViewMode == LogsMode {
-		logLine := LogStyle.Render(string(msg))
-		m.LogBuffer = append(m.LogBuffer, logLine)
+		offset := m.DetailsView.YOffset
+		m.LogBuffer = append(m.LogBuffer, string(msg))
+		if limit := m.scrollback(); len(m.LogBuffer) > limit {
+			dropped := len(m.LogBuffer) - limit
+			m.LogBuffer = m.LogBuffer[dropped:]
+			offset -= dropped
+			if offset < 0 {
+				offset = 0
+			}
+		}
 
-		if m.Search.Active {
-			m.filterLogs()
+		// Keep the lines the user is reading in place while not following
+		m.filterLogs()
+		if m.Follow {
+			m.DetailsView.GotoBottom()
 		} else {
-			var content strings.Builder
-			content.WriteString(m.DetailsView.View())
-			content.WriteString("\n")
-			content.WriteString(logLine)
-			m.DetailsView.SetContent(content.String())
+			m.DetailsView.SetYOffset(offset)
 		}
-		m.DetailsView.GotoBottom()
 	}
 	return m, m.readLogsCmd()
 }
 
 // updateProcessView updates the process list view
 func (m *Model) updateProcessView() {
+	if m.ActiveTab == ScriptsTab {
+		m.updateScriptsView()
+		return
+	}
+
 	var b strings.Builder
 
+	// Scaled processes get a line summing up their instances, which are
+	// sorted next to each other
+	groups := make(map[string]process.InstanceGroup)
+	for _, group := range process.GroupInstances(m.Processes) {
+		groups[group.Type] = group
+	}
+	lastGroup := ""
+
 	for i, p := range m.Processes {
+		if group, ok := groups[config.ProcessType(p.Name)]; ok && config.InstanceNumber(p.Name) > 0 && group.Type != lastGroup {
+			lastGroup = group.Type
+			b.WriteString(InfoStyle.Render(fmt.Sprintf("  %s/%s ×%d, %d running", p.AppName, group.Type, len(group.Instances), group.Running)) + "\n")
+		}
+
 		cursor := " "
 		if m.Cursor == i {
 			cursor = ">"
@@ -494,12 +756,17 @@ func (m *Model) updateProcessView() {
 
 		// Format process line with resource usage
 		// First line with name and status
+		statusText := statusStyle.Render(string(p.Status))
+		if m.ViewMode == CombinedLogsMode && m.HiddenLogs[p.Name] {
+			// The panel is too narrow for both
+			statusText = StoppedStyle.Render("muted")
+		}
 		processLine := fmt.Sprintf("%s %s/%s %s %s",
 			cursor,
 			p.AppName,
 			p.Name,
 			statusEmoji,
-			statusStyle.Render(string(p.Status)),
+			statusText,
 		)
 		processLine = fmt.Sprintf("%-25s\n", processLine) // Pad to 25 chars
 
@@ -508,6 +775,9 @@ func (m *Model) updateProcessView() {
 			p.CPUPercent,
 			p.MemoryPercent,
 		)
+		if errs := m.errorCounts.count(p.Name); errs > 0 {
+			resourceLine += " " + ErrorBadgeStyle.Render(fmt.Sprintf("✗%d", errs))
+		}
 		processLine += fmt.Sprintf("%-25s", resourceLine) // Pad to 25 chars
 
 		if i == m.Cursor {
@@ -523,21 +793,79 @@ func (m *Model) updateProcessView() {
 		b.WriteString("No processes running\n")
 	}
 
+	if len(m.Services) > 0 {
+		b.WriteString("\n" + HeaderStyle.Render("Services") + "\n")
+	}
+	for i, svc := range m.Services {
+		index := len(m.Processes) + i
+		cursor := " "
+		if m.Cursor == index {
+			cursor = ">"
+		}
+
+		statusEmoji, status, statusStyle := "🔴", "stopped", StoppedStyle
+		switch {
+		case svc.Running && svc.Health == "unhealthy":
+			statusEmoji, status, statusStyle = "🟠", "unhealthy", ErrorStyle
+		case svc.Running && svc.Health == "starting":
+			statusEmoji, status, statusStyle = "🟡", "starting", StartingStyle
+		case svc.Running:
+			statusEmoji, status, statusStyle = "🟢", "running", RunningStyle
+		case m.Docker == nil:
+			statusEmoji, status = "⚪", "unknown"
+		}
+
+		serviceLine := fmt.Sprintf("%-25s\n", fmt.Sprintf("%s %s %s %s", cursor, svc.Name, statusEmoji, statusStyle.Render(status)))
+		resourceLine := fmt.Sprintf("  port %d", svc.Port)
+		if svc.Tracked {
+			resourceLine += fmt.Sprintf(" CPU: %.1f%% MEM: %.1f%%", svc.CPUPercent, svc.MemoryPercent)
+		}
+		serviceLine += fmt.Sprintf("%-25s", resourceLine)
+
+		if index == m.Cursor {
+			serviceLine = SelectedProcessStyle.Render(serviceLine)
+		} else {
+			serviceLine = ProcessItemStyle.Render(serviceLine)
+		}
+		b.WriteString(serviceLine)
+	}
+
 	m.ProcessView.SetContent(b.String())
 }
 
 // updateDetailsView updates the details/logs view
 func (m *Model) updateDetailsView() {
+	if m.ActiveTab == ScriptsTab {
+		m.updateScriptDetails()
+		return
+	}
+
 	var b strings.Builder
 
 	if len(m.Processes) > 0 && m.Cursor < len(m.Processes) {
 		proc := m.Processes[m.Cursor]
 
+		if m.ViewMode == EnvMode {
+			m.updateEnvView(proc)
+			return
+		}
+
 		if m.ViewMode == DetailsMode {
 			b.WriteString(HeaderStyle.Render("Process Details") + "\n")
 			b.WriteString(fmt.Sprintf("App: %s\n", SelectedProcessStyle.Render(proc.AppName)))
 			b.WriteString(fmt.Sprintf("Process: %s\n", SelectedProcessStyle.Render(proc.Name)))
 			b.WriteString(fmt.Sprintf("Status: %s\n", RunningStyle.Render(string(proc.Status))))
+			if url := m.URLs[proc.Name]; url != "" {
+				b.WriteString(fmt.Sprintf("Public URL: %s\n", SelectedProcessStyle.Render(url)))
+			}
+			for _, group := range process.GroupInstances(m.Processes) {
+				if group.Type == config.ProcessType(proc.Name) && config.InstanceNumber(proc.Name) > 0 {
+					b.WriteString(fmt.Sprintf("Instances: %d of %d running (%s)\n", group.Running, len(group.Instances), strings.Join(group.Instances, ", ")))
+				}
+			}
+			if !proc.StartedAt.IsZero() {
+				b.WriteString(fmt.Sprintf("Uptime: %s (started %s)\n", process.FormatUptime(proc.Uptime()), proc.StartedAt.Format("Jan 2 15:04:05")))
+			}
 			b.WriteString(fmt.Sprintf("Debug Mode: %s\n", StoppedStyle.Render("Disabled")))
 
 			b.WriteString("\n" + HeaderStyle.Render("Resource Usage") + "\n")
@@ -548,12 +876,31 @@ func (m *Model) updateDetailsView() {
 			))
 			b.WriteString(fmt.Sprintf("Last Updated: %s\n", proc.LastUpdated.Format("15:04:05")))
 
+			// Usage over the last minutes, recorded by the metrics process
+			if ring, err := metrics.ProcessRing(process.SanitizeAppName(proc.AppName), proc.Name); err == nil {
+				if samples, err := ring.Samples(time.Now().Add(-10 * time.Minute)); err == nil && len(samples) > 1 {
+					b.WriteString(fmt.Sprintf("CPU (10m):    %s\n", metrics.Sparkline(metrics.CPUValues(samples), 30)))
+					b.WriteString(fmt.Sprintf("Memory (10m): %s\n", metrics.Sparkline(metrics.MemoryValues(samples), 30)))
+				}
+			}
+
+			if recent := m.processEvents(proc.Name, 5); len(recent) > 0 {
+				b.WriteString("\n" + HeaderStyle.Render("Recent Events") + "\n")
+				for _, e := range recent {
+					line := fmt.Sprintf("%s %s", e.Time.Format("15:04:05"), e.Type)
+					if e.Message != "" {
+						line += " " + e.Message
+					}
+					b.WriteString(line + "\n")
+				}
+			}
+
 			if proc.OutputFile != "" {
 				b.WriteString("\n" + HeaderStyle.Render("Log Information") + "\n")
 				b.WriteString(fmt.Sprintf("Log File: ~/.spin/output/%s/%s.log\n", process.SanitizeAppName(proc.AppName), proc.Name))
 			}
 
-			b.WriteString("\n" + InfoStyle.Render("Press 'l' to view logs"))
+			b.WriteString("\n" + InfoStyle.Render("Press 'l' to view logs, 'e' to view the environment"))
 		} else {
 			// Logs view
 			b.WriteString(HeaderStyle.Render(fmt.Sprintf("Logs: %s/%s", proc.AppName, proc.Name)) + "\n")
@@ -561,14 +908,97 @@ func (m *Model) updateDetailsView() {
 			b.WriteString(InfoStyle.Render(" • "))
 			b.WriteString(InfoStyle.Render("Press '/' to search logs"))
 			b.WriteString(InfoStyle.Render(" • "))
+			b.WriteString(InfoStyle.Render("Press 'n'/'p' to jump between errors"))
+			b.WriteString(InfoStyle.Render(" • "))
 			b.WriteString(InfoStyle.Render("Use ↑/↓, PgUp/PgDn to scroll\n"))
 			if m.Search.Active {
 				b.WriteString(fmt.Sprintf("\nSearch: %s\n", m.Search.Term))
 			}
 		}
+	} else if svc := m.selectedService(); svc != nil {
+		b.WriteString(HeaderStyle.Render("Service Details") + "\n")
+		b.WriteString(fmt.Sprintf("Service: %s\n", SelectedProcessStyle.Render(svc.Name)))
+		b.WriteString(fmt.Sprintf("Image: %s\n", svc.Image))
+		b.WriteString(fmt.Sprintf("Port: %d\n", svc.Port))
+		switch {
+		case m.Docker == nil:
+			b.WriteString(fmt.Sprintf("Status: %s\n", StoppedStyle.Render("Docker is not available")))
+		case svc.Running:
+			b.WriteString(fmt.Sprintf("Status: %s\n", RunningStyle.Render("running")))
+		default:
+			b.WriteString(fmt.Sprintf("Status: %s\n", StoppedStyle.Render("stopped")))
+		}
+		if svc.Health != "" {
+			b.WriteString(fmt.Sprintf("Health: %s\n", svc.Health))
+		}
+		if svc.Tracked {
+			b.WriteString(fmt.Sprintf("CPU: %.1f%%\n", svc.CPUPercent))
+			b.WriteString(fmt.Sprintf("Memory: %.1f%%\n", svc.MemoryPercent))
+		}
+
+		if recent := m.processEvents(svc.Name, 5); len(recent) > 0 {
+			b.WriteString("\n" + HeaderStyle.Render("Recent Events") + "\n")
+			for _, e := range recent {
+				b.WriteString(fmt.Sprintf("%s %s\n", e.Time.Format("15:04:05"), e.Type))
+			}
+		}
+
+		b.WriteString("\n" + InfoStyle.Render("Press 'S' to start, 's' to stop, 'r' to restart"))
 	} else {
 		b.WriteString("Select a process to view details")
 	}
 
 	m.DetailsView.SetContent(b.String())
 }
+
+// processEvents returns the last n events of a process, oldest first
+func (m *Model) processEvents(name string, n int) []events.Event {
+	var result []events.Event
+	for i := len(m.Events) - 1; i >= 0 && len(result) < n; i-- {
+		if m.Events[i].Name == name {
+			result = append([]events.Event{m.Events[i]}, result...)
+		}
+	}
+	return result
+}
+
+// selectedService returns the service under the cursor, if any
+func (m *Model) selectedService() *ServiceState {
+	index := m.Cursor - len(m.Processes)
+	if index < 0 || index >= len(m.Services) {
+		return nil
+	}
+	return &m.Services[index]
+}
+
+// refreshServices reloads the state of the project's Docker services, with
+// the usage of the tracked containers
+func (m *Model) refreshServices(containers []*process.Process) {
+	usage := make(map[string]*process.Process)
+	for _, p := range containers {
+		usage[p.Name] = p
+	}
+
+	names := make([]string, 0, len(m.Config.Services))
+	for name := range m.Config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	services := make([]ServiceState, 0, len(names))
+	for _, name := range names {
+		cfg := m.Config.Services[name]
+		svc := ServiceState{Name: name, Image: cfg.Image, Port: cfg.Port}
+		if m.Docker != nil && m.Docker.IsRunning(name) {
+			svc.Running = true
+			svc.Health, _ = m.Docker.HealthStatus(name)
+			if p, ok := usage[name]; ok {
+				svc.Tracked = true
+				svc.CPUPercent = p.CPUPercent
+				svc.MemoryPercent = p.MemoryPercent
+			}
+		}
+		services = append(services, svc)
+	}
+	m.Services = services
+}
@@ -0,0 +1,317 @@
+package dashboard
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/afomera/spin/internal/events"
+	"github.com/afomera/spin/internal/script"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadScripts reads the scripts of the project, sorted by name
+func (m *Model) loadScripts() {
+	manager := script.NewManager()
+	if err := script.LoadAndRegisterScripts(manager, script.DefaultConfigPath()); err != nil {
+		m.ErrorMsg = fmt.Sprintf("Error loading scripts: %v", err)
+		m.Scripts = nil
+		return
+	}
+	m.scriptManager = manager
+
+	scripts := manager.List()
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].Name < scripts[j].Name
+	})
+	m.Scripts = scripts
+	if m.ScriptCursor >= len(scripts) {
+		m.ScriptCursor = 0
+	}
+}
+
+// toggleScriptsTab switches between the processes and the scripts tab
+func (m *Model) toggleScriptsTab() {
+	if m.ActiveTab == ScriptsTab {
+		m.ActiveTab = ProcessesTab
+		m.updateProcessView()
+		m.updateDetailsView()
+		return
+	}
+
+	if m.ViewMode == CombinedLogsMode {
+		m.stopCombinedLogs()
+	}
+	m.stopLogReader()
+	m.ViewMode = DetailsMode
+	m.Search.Active = false
+	m.ActiveTab = ScriptsTab
+	m.loadScripts()
+	m.updateProcessView()
+	m.updateDetailsView()
+}
+
+// handleScriptKeys handles the keys of the scripts tab, reporting whether
+// the key was meant for it
+func (m *Model) handleScriptKeys(msg tea.KeyMsg) (tea.Cmd, bool) {
+	keys := DefaultKeyMap()
+
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.ActivePanel != ProcessList {
+			return nil, false
+		}
+		if m.ScriptCursor > 0 {
+			m.ScriptCursor--
+			m.updateProcessView()
+			m.updateDetailsView()
+		}
+		return nil, true
+
+	case key.Matches(msg, keys.Down):
+		if m.ActivePanel != ProcessList {
+			return nil, false
+		}
+		if m.ScriptCursor < len(m.Scripts)-1 {
+			m.ScriptCursor++
+			m.updateProcessView()
+			m.updateDetailsView()
+		}
+		return nil, true
+
+	case key.Matches(msg, keys.Enter):
+		return m.runSelectedScript(), true
+
+	case key.Matches(msg, keys.Quit), key.Matches(msg, keys.Tab), key.Matches(msg, keys.Scripts),
+		key.Matches(msg, keys.PageUp), key.Matches(msg, keys.PageDown),
+		key.Matches(msg, keys.ToggleInput), key.Matches(msg, keys.Escape):
+		return nil, false
+	}
+
+	// Process keys don't apply to scripts
+	return nil, true
+}
+
+// runSelectedScript runs the script under the cursor, streaming its output
+// into the details view
+func (m *Model) runSelectedScript() tea.Cmd {
+	if m.ScriptCursor >= len(m.Scripts) {
+		return nil
+	}
+	if m.ScriptRunning != "" {
+		m.ErrorMsg = fmt.Sprintf("Script %s is still running", m.ScriptRunning)
+		return nil
+	}
+
+	s := m.Scripts[m.ScriptCursor]
+	m.ScriptRunning = s.Name
+	m.ScriptOutput = nil
+	m.ErrorMsg = ""
+	out := make(chan tea.Msg, 256)
+	m.scriptChan = out
+
+	manager := m.scriptManager
+	app := m.ProjectName
+	go func() {
+		writer := &lineWriter{send: func(line string) { out <- ScriptOutputMsg{Text: line} }}
+		entry, opts := script.StartRun(app, s.Name, &script.RunOptions{
+			Stdout: writer,
+			Progress: func(step script.Step) {
+				writer.Flush()
+				out <- ScriptOutputMsg{Text: formatStep(step), Step: true}
+			},
+		})
+
+		err := manager.Run(s.Name, opts)
+		writer.Flush()
+		entry.Finish(err)
+
+		event := events.Event{Type: events.ScriptRun, App: app, Name: s.Name}
+		if err != nil {
+			event.Type = events.ScriptFailed
+			event.Message = err.Error()
+		}
+		events.Publish(event)
+
+		out <- ScriptDoneMsg{Name: s.Name, Error: err}
+	}()
+
+	m.updateDetailsView()
+	return m.readScriptCmd()
+}
+
+// readScriptCmd returns a command that waits for the next output of the
+// running script
+func (m *Model) readScriptCmd() tea.Cmd {
+	out := m.scriptChan
+	return func() tea.Msg {
+		return <-out
+	}
+}
+
+// handleScriptOutputMsg adds a line of output of the running script
+func (m *Model) handleScriptOutputMsg(msg ScriptOutputMsg) (*Model, tea.Cmd) {
+	line := LogStyle.Render(msg.Text)
+	if msg.Step {
+		line = HeaderStyle.Render(msg.Text)
+	}
+	m.ScriptOutput = append(m.ScriptOutput, line)
+	if limit := DefaultConfig().MaxLogBuffer; len(m.ScriptOutput) > limit {
+		m.ScriptOutput = m.ScriptOutput[len(m.ScriptOutput)-limit:]
+	}
+
+	if m.ActiveTab == ScriptsTab {
+		m.updateDetailsView()
+		m.DetailsView.GotoBottom()
+	}
+	return m, m.readScriptCmd()
+}
+
+// handleScriptDoneMsg records the end of a script run
+func (m *Model) handleScriptDoneMsg(msg ScriptDoneMsg) (*Model, tea.Cmd) {
+	m.ScriptRunning = ""
+	m.scriptChan = nil
+	if msg.Error != nil {
+		m.ScriptOutput = append(m.ScriptOutput, ErrorStyle.Render(fmt.Sprintf("✗ %s failed: %v", msg.Name, msg.Error)))
+	} else {
+		m.ScriptOutput = append(m.ScriptOutput, RunningStyle.Render(fmt.Sprintf("✓ %s finished", msg.Name)))
+	}
+
+	if m.ActiveTab == ScriptsTab {
+		m.updateDetailsView()
+		m.DetailsView.GotoBottom()
+	}
+	return m, nil
+}
+
+// formatStep describes the start or end of a hook or the script
+func formatStep(step script.Step) string {
+	name := step.Name
+	if name == "pre" || name == "post" {
+		name += " hook"
+	}
+	if step.Description != "" {
+		name += " (" + step.Description + ")"
+	}
+
+	switch {
+	case !step.Done:
+		return "▶ " + name
+	case step.Err != nil:
+		return fmt.Sprintf("✗ %s: %v", name, step.Err)
+	}
+	return "✓ " + name
+}
+
+// updateScriptsView renders the list of scripts in the left panel
+func (m *Model) updateScriptsView() {
+	var b strings.Builder
+
+	for i, s := range m.Scripts {
+		cursor := " "
+		if m.ScriptCursor == i {
+			cursor = ">"
+		}
+
+		line := fmt.Sprintf("%s %s", cursor, s.Name)
+		if s.Name == m.ScriptRunning {
+			line += " " + StartingStyle.Render("running")
+		}
+		line = fmt.Sprintf("%-25s\n", line)
+		desc := s.Description
+		if len(desc) > 22 {
+			desc = desc[:21] + "…"
+		}
+		line += fmt.Sprintf("%-25s", "  "+desc)
+
+		if i == m.ScriptCursor {
+			line = SelectedProcessStyle.Render(line)
+		} else {
+			line = ProcessItemStyle.Render(line)
+		}
+		b.WriteString(line)
+	}
+
+	if len(m.Scripts) == 0 {
+		b.WriteString("No scripts in spin.config.json\n")
+	}
+
+	m.ProcessView.SetContent(b.String())
+}
+
+// updateScriptDetails renders the selected script and the output of the
+// last run in the details view
+func (m *Model) updateScriptDetails() {
+	var b strings.Builder
+
+	if m.ScriptCursor < len(m.Scripts) {
+		s := m.Scripts[m.ScriptCursor]
+		b.WriteString(HeaderStyle.Render("Script Details") + "\n")
+		b.WriteString(fmt.Sprintf("Script: %s\n", SelectedProcessStyle.Render(s.Name)))
+		if s.Description != "" {
+			b.WriteString(fmt.Sprintf("Description: %s\n", s.Description))
+		}
+		b.WriteString(fmt.Sprintf("Command: %s\n", s.Command))
+		if s.Interpreter != "" {
+			b.WriteString(fmt.Sprintf("Interpreter: %s\n", s.Interpreter))
+		}
+		if params := s.ParamNames(); len(params) > 0 {
+			b.WriteString(fmt.Sprintf("Parameters: %s\n", strings.Join(params, ", ")))
+		}
+		for _, hookType := range []string{"pre", "post"} {
+			if hook := s.Hooks[hookType]; hook != nil {
+				b.WriteString(fmt.Sprintf("%s hook: %s\n", strings.ToUpper(hookType[:1])+hookType[1:], hook.Command))
+			}
+		}
+		b.WriteString("\n" + InfoStyle.Render("Press 'enter' to run"))
+	} else {
+		b.WriteString("Select a script to view details")
+	}
+
+	if len(m.ScriptOutput) > 0 {
+		b.WriteString("\n\n" + HeaderStyle.Render("Output") + "\n")
+		b.WriteString(strings.Join(m.ScriptOutput, "\n"))
+	}
+
+	m.DetailsView.SetContent(b.String())
+}
+
+// lineWriter splits what's written to it into lines
+type lineWriter struct {
+	mu      sync.Mutex
+	partial bytes.Buffer
+	send    func(string)
+}
+
+// Write implements io.Writer
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial.Write(p)
+	for {
+		line, err := w.partial.ReadString('\n')
+		if err != nil {
+			// Keep the incomplete line for the next write
+			w.partial.Reset()
+			w.partial.WriteString(line)
+			break
+		}
+		w.send(strings.TrimRight(line, "\r\n"))
+	}
+	return len(p), nil
+}
+
+// Flush sends a trailing line without a newline
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.partial.Len() > 0 {
+		w.send(w.partial.String())
+		w.partial.Reset()
+	}
+}
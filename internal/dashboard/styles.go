@@ -35,6 +35,17 @@ var (
 	ErrorStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("9"))
 
+	// Log line styles by level
+	WarnLineStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("3"))
+
+	ErrorLineStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")).
+			Bold(true)
+
+	ErrorBadgeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9"))
+
 	InfoStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("4"))
 
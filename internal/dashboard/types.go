@@ -4,10 +4,15 @@ import (
 	"os"
 	"time"
 
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/events"
 	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/script"
+	"github.com/afomera/spin/internal/service/docker"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // Panel represents different UI panels in the dashboard
@@ -18,6 +23,14 @@ const (
 	ProcessDetails
 )
 
+// Tab represents what is listed in the left panel
+type Tab int
+
+const (
+	ProcessesTab Tab = iota // Processes and services
+	ScriptsTab
+)
+
 // ViewMode represents different view modes for the details panel
 type ViewMode int
 
@@ -25,6 +38,8 @@ const (
 	DetailsMode ViewMode = iota
 	LogsMode
 	SearchMode
+	CombinedLogsMode // Logs of all processes interleaved
+	EnvMode          // Environment the process was started with
 )
 
 // SearchState holds the current search configuration
@@ -40,6 +55,21 @@ type Model struct {
 	Processes []*process.Process
 	Cursor    int
 	Manager   *process.Manager
+	URLs      map[string]string // Public URLs of processes shared with spin share, loaded every tick
+
+	// Docker services of the project, listed below the processes. The cursor
+	// moves on to them after the last process.
+	Config   *config.Config
+	Services []ServiceState
+	Docker   *docker.ServiceManager // nil when Docker isn't reachable
+
+	// Scripts of the project, listed in the scripts tab
+	Scripts       []*script.Script
+	ScriptCursor  int
+	ScriptRunning string   // Name of the script being run, if any
+	ScriptOutput  []string // Output of the last script run
+	scriptManager *script.Manager
+	scriptChan    chan tea.Msg
 
 	// UI components
 	Help        help.Model
@@ -54,6 +84,7 @@ type Model struct {
 
 	// View state
 	ActivePanel   Panel
+	ActiveTab     Tab
 	ViewMode      ViewMode
 	Quitting      bool
 	InputActive   bool
@@ -66,8 +97,38 @@ type Model struct {
 	LogChan      chan string
 	LogFile      *os.File
 	LogBuffer    []string
+	Scrollback   int  // Lines of logs kept, MaxLogBuffer when zero
+	Follow       bool // Scroll to new log lines as they arrive
+	logStop      chan struct{}
 	OutputBuffer []string
 	Search       SearchState
+	ErrorIndex   []int         // Lines of LogBuffer an error starts on
+	errorCounts  *errorCounter // Errors logged by every process
+
+	// Combined logs of all processes
+	CombinedBuffer  []CombinedLine
+	CombinedChan    chan CombinedLine
+	HiddenLogs      map[string]bool // Processes toggled off in the combined view
+	combinedStop    chan struct{}   // Closed to stop following the logs
+	combinedTailing map[string]bool // Processes whose log is being followed
+
+	// Events of the project, newest last
+	Events    []events.Event
+	EventChan chan events.Event
+}
+
+// ServiceState is the state of a Docker service shown in the dashboard
+type ServiceState struct {
+	Name    string
+	Image   string
+	Port    int
+	Running bool
+	Health  string // Empty for services without a health check
+
+	// Usage of the container, when spin tracks it
+	Tracked       bool
+	CPUPercent    float64
+	MemoryPercent float64
 }
 
 // TickMsg is sent when we should update process information
@@ -76,11 +137,43 @@ type TickMsg time.Time
 // LogMsg is sent when new log content is available
 type LogMsg string
 
+// CombinedLine is a line of a process' log in the combined logs view
+type CombinedLine struct {
+	Name string
+	Text string
+}
+
+// CombinedLogMsg is sent when a process in the combined logs view logs a line
+type CombinedLogMsg CombinedLine
+
+// EventMsg is sent when an event of the project is published
+type EventMsg events.Event
+
+// ScriptOutputMsg is sent when the running script writes a line or one of
+// its steps starts or finishes
+type ScriptOutputMsg struct {
+	Text string
+	Step bool
+}
+
+// ScriptDoneMsg is sent when a script run finishes
+type ScriptDoneMsg struct {
+	Name  string
+	Error error
+}
+
+// AttachMsg is sent when the user detaches from a process
+type AttachMsg struct {
+	Name  string
+	Error error
+}
+
 // Config holds the dashboard configuration
 type Config struct {
 	// Add any dashboard-specific configuration options here
 	RefreshInterval time.Duration
 	MaxLogBuffer    int
+	MaxEvents       int
 }
 
 // DefaultConfig returns a Config with default values
@@ -88,5 +181,6 @@ func DefaultConfig() Config {
 	return Config{
 		RefreshInterval: time.Second,
 		MaxLogBuffer:    1000,
+		MaxEvents:       50,
 	}
 }
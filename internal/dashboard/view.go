@@ -30,7 +30,12 @@ func (m *Model) View() string {
 	// Left panel with processes
 	leftPanel := lipgloss.JoinVertical(
 		lipgloss.Left,
-		HeaderStyle.Render("Processes"),
+		HeaderStyle.Render(func() string {
+			if m.ActiveTab == ScriptsTab {
+				return "Scripts"
+			}
+			return "Processes"
+		}()),
 		ProcessBoxStyle.Render(m.ProcessView.View()),
 	)
 
@@ -38,10 +43,15 @@ func (m *Model) View() string {
 	rightPanel := lipgloss.JoinVertical(
 		lipgloss.Left,
 		HeaderStyle.Render(func() string {
-			if m.ViewMode == DetailsMode {
+			switch m.ViewMode {
+			case DetailsMode:
 				return "Details"
+			case CombinedLogsMode:
+				return "Combined Logs" + m.followStatus()
+			case EnvMode:
+				return "Environment"
 			}
-			return "Logs"
+			return "Logs" + m.followStatus()
 		}()),
 		LogBoxStyle.
 			Copy().
@@ -105,3 +115,11 @@ func (m *Model) View() string {
 		inputPanel,
 	)
 }
+
+// followStatus describes whether the logs view scrolls to new lines
+func (m *Model) followStatus() string {
+	if m.Follow {
+		return " (following)"
+	}
+	return " (paused)"
+}
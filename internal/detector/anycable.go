@@ -0,0 +1,64 @@
+package detector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Ports of anycable-go and of the gRPC server of the app
+const (
+	AnyCablePort    = 8080
+	AnyCableRPCPort = 50051
+)
+
+// AnyCableProcesses returns the processes running AnyCable for the app at
+// path: anycable-go, which takes the WebSocket connections, and the RPC
+// server of the app unless anycable-go calls the app over HTTP
+func (r *RailsConfig) AnyCableProcesses(path string) []Process {
+	if r.Services.AnyCable == "" {
+		return nil
+	}
+
+	// anycable-rails installs anycable-go as bin/anycable-go
+	command := "anycable-go"
+	if _, err := os.Stat(filepath.Join(path, "bin", "anycable-go")); err == nil {
+		command = "bin/anycable-go"
+	}
+	processes := []Process{{
+		Name:     "ws",
+		Command:  fmt.Sprintf("%s --port %d", command, AnyCablePort),
+		Services: []string{"redis"},
+	}}
+
+	if r.Services.AnyCable == "grpc" {
+		rpc := Process{Name: "rpc", Command: "bundle exec anycable"}
+		if db := r.DatabaseService(); db != "" {
+			rpc.Services = append(rpc.Services, db)
+		}
+		processes = append(processes, rpc)
+	}
+	return processes
+}
+
+// AnyCableEnv returns the variables that connect anycable-go, the app and
+// the browser: where anycable-go calls the app, the Redis both broadcast
+// through, and the URL the app tells the browser to open cable connections to.
+// redisPort is the port Redis is published on and webPort that of the app.
+func (r *RailsConfig) AnyCableEnv(redisPort int, webPort int) map[string]string {
+	if r.Services.AnyCable == "" {
+		return nil
+	}
+
+	cableURL := fmt.Sprintf("ws://localhost:%d/cable", AnyCablePort)
+	env := map[string]string{
+		"ANYCABLE_REDIS_URL":     fmt.Sprintf("redis://localhost:%d/0", redisPort),
+		"ANYCABLE_WEBSOCKET_URL": cableURL,
+		"CABLE_URL":              cableURL,
+		"ANYCABLE_RPC_HOST":      fmt.Sprintf("localhost:%d", AnyCableRPCPort),
+	}
+	if r.Services.AnyCable == "http" {
+		env["ANYCABLE_RPC_HOST"] = fmt.Sprintf("http://localhost:%d/_anycable", webPort)
+	}
+	return env
+}
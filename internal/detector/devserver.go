@@ -0,0 +1,116 @@
+package detector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DevServer is the dev server of a JavaScript bundler, which serves assets
+// while they are edited and pushes changes to the browser over a WebSocket
+type DevServer struct {
+	Name    string // Process name, vite or webpack
+	Command string // Empty when the project's own scripts start it
+	Port    int    // Port the server and its hot module replacement listen on
+}
+
+// Default ports of the dev servers
+const (
+	viteRubyPort   = 3036
+	vitePort       = 5173
+	webpackerPort  = 3035
+	webpackDevPort = 8080
+)
+
+// Ports set in vite.config.js and webpack.config.js, like server: { port: 5174 }
+var (
+	viteConfigPortPattern    = regexp.MustCompile(`server\s*:\s*\{[^}]*?port\s*:\s*(\d+)`)
+	webpackConfigPortPattern = regexp.MustCompile(`devServer\s*:\s*\{[^}]*?port\s*:\s*(\d+)`)
+)
+
+// DetectDevServer finds the bundler dev server of the project at path: Vite
+// Ruby's bin/vite dev, the webpack dev server of Shakapacker or Webpacker,
+// or Vite and webpack configured on their own, which package.json scripts
+// start. Its port is read from the bundler's configuration.
+func DetectDevServer(path string) (DevServer, bool) {
+	if exists(path, "bin", "vite") {
+		return DevServer{Name: "vite", Command: "bin/vite dev", Port: viteRubyDevPort(path)}, true
+	}
+	for _, binstub := range []string{"shakapacker-dev-server", "webpacker-dev-server", "webpack-dev-server"} {
+		if exists(path, "bin", binstub) {
+			return DevServer{Name: "webpack", Command: "bin/" + binstub, Port: webpackerDevPort(path)}, true
+		}
+	}
+
+	for _, name := range []string{"vite.config.ts", "vite.config.js", "vite.config.mts", "vite.config.mjs"} {
+		if data, err := os.ReadFile(filepath.Join(path, name)); err == nil {
+			return DevServer{Name: "vite", Port: configPort(data, viteConfigPortPattern, vitePort)}, true
+		}
+	}
+	for _, name := range []string{"webpack.config.js", "webpack.config.ts"} {
+		if data, err := os.ReadFile(filepath.Join(path, name)); err == nil && webpackConfigPortPattern.Match(data) {
+			return DevServer{Name: "webpack", Port: configPort(data, webpackConfigPortPattern, webpackDevPort)}, true
+		}
+	}
+	return DevServer{}, false
+}
+
+// viteRubyDevPort reads the port of the development dev server from
+// config/vite.json
+func viteRubyDevPort(path string) int {
+	var cfg map[string]struct {
+		Port int `json:"port"`
+	}
+	data, err := os.ReadFile(filepath.Join(path, "config", "vite.json"))
+	if err != nil || json.Unmarshal(data, &cfg) != nil {
+		return viteRubyPort
+	}
+	for _, env := range []string{"development", "all"} {
+		if cfg[env].Port != 0 {
+			return cfg[env].Port
+		}
+	}
+	return viteRubyPort
+}
+
+// webpackerDevPort reads the port of the development dev server from
+// config/shakapacker.yml or config/webpacker.yml
+func webpackerDevPort(path string) int {
+	for _, name := range []string{"shakapacker.yml", "webpacker.yml"} {
+		var cfg struct {
+			Development struct {
+				DevServer struct {
+					Port int `yaml:"port"`
+				} `yaml:"dev_server"`
+			} `yaml:"development"`
+		}
+		data, err := os.ReadFile(filepath.Join(path, "config", name))
+		if err != nil || yaml.Unmarshal(data, &cfg) != nil {
+			continue
+		}
+		if cfg.Development.DevServer.Port != 0 {
+			return cfg.Development.DevServer.Port
+		}
+	}
+	return webpackerPort
+}
+
+// configPort returns the port a JavaScript config sets, or fallback
+func configPort(data []byte, pattern *regexp.Regexp, fallback int) int {
+	if match := pattern.FindSubmatch(data); match != nil {
+		if port, err := strconv.Atoi(string(match[1])); err == nil {
+			return port
+		}
+	}
+	return fallback
+}
+
+// exists checks if a file exists under path
+func exists(path string, elem ...string) bool {
+	_, err := os.Stat(filepath.Join(append([]string{path}, elem...)...))
+	return err == nil
+}
@@ -0,0 +1,130 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DockerBuild describes how the image of an application is built
+type DockerBuild struct {
+	Source     string            `json:"source"`     // File the build was found in, a Dockerfile or compose file
+	Dockerfile string            `json:"dockerfile"` // Dockerfile relative to the project
+	Context    string            `json:"context"`    // Build context relative to the project
+	Target     string            `json:"target"`     // Stage of a multi-stage Dockerfile to build
+	Args       map[string]string `json:"args"`       // Build arguments
+}
+
+// composeFiles are the names docker compose looks for, in order
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+// composeBuild is the build section of a compose service, which is either a
+// context path or an object
+type composeBuild struct {
+	Context    string      `yaml:"context"`
+	Dockerfile string      `yaml:"dockerfile"`
+	Target     string      `yaml:"target"`
+	Args       composeArgs `yaml:"args"`
+}
+
+// composeArgs are build arguments, written as a map or a list of KEY=value
+type composeArgs map[string]string
+
+// UnmarshalYAML accepts both forms of build arguments
+func (a *composeArgs) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return node.Decode((*map[string]string)(a))
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*a = make(composeArgs)
+	for _, pair := range list {
+		key, value, _ := strings.Cut(pair, "=")
+		(*a)[key] = value
+	}
+	return nil
+}
+
+// UnmarshalYAML accepts the short form build: ./path
+func (b *composeBuild) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		b.Context = node.Value
+		return nil
+	}
+	type plain composeBuild
+	return node.Decode((*plain)(b))
+}
+
+// DetectDockerBuild finds how the application at path builds its image: from
+// the build section of a compose file, preferring the web or app service, or
+// from a Dockerfile at the root. It returns nil when there is neither.
+func DetectDockerBuild(path string) *DockerBuild {
+	for _, name := range composeFiles {
+		data, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			continue
+		}
+		var compose struct {
+			Services map[string]struct {
+				Build *composeBuild `yaml:"build"`
+			} `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			continue
+		}
+
+		names := make([]string, 0, len(compose.Services))
+		for service, def := range compose.Services {
+			if def.Build != nil {
+				names = append(names, service)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return servicePreference(names[i]) < servicePreference(names[j]) ||
+				(servicePreference(names[i]) == servicePreference(names[j]) && names[i] < names[j])
+		})
+
+		build := compose.Services[names[0]].Build
+		context := build.Context
+		if context == "" {
+			context = "."
+		}
+		dockerfile := build.Dockerfile
+		if dockerfile == "" {
+			dockerfile = "Dockerfile"
+		}
+		return &DockerBuild{
+			Source:     name,
+			Dockerfile: filepath.Join(context, dockerfile),
+			Context:    filepath.Clean(context),
+			Target:     build.Target,
+			Args:       map[string]string(build.Args),
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(path, "Dockerfile")); err == nil {
+		return &DockerBuild{Source: "Dockerfile", Dockerfile: "Dockerfile", Context: "."}
+	}
+	return nil
+}
+
+// servicePreference ranks compose services, the application usually is web
+// or app
+func servicePreference(name string) int {
+	switch name {
+	case "web":
+		return 0
+	case "app":
+		return 1
+	default:
+		return 2
+	}
+}
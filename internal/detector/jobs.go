@@ -0,0 +1,53 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Process is a process a Rails app needs next to its server
+type Process struct {
+	Name     string
+	Command  string
+	Services []string // Services the process needs, named like those of spin.config.json
+	Port     int      // Port the process listens on, 0 when it isn't known
+}
+
+// DatabaseService returns the name of the service the database of the app
+// runs in, like postgresql for the postgis adapter, or "" when spin doesn't
+// run it
+func (r *RailsConfig) DatabaseService() string {
+	switch r.Database.Type {
+	case "postgresql", "postgis":
+		return "postgresql"
+	case "mysql", "mysql2", "trilogy":
+		return "mysql"
+	}
+	return ""
+}
+
+// JobWorker returns the worker of the job backend the app at path uses:
+// Sidekiq, which queues in Redis and reads its queues from
+// config/sidekiq.yml, or GoodJob and Delayed Job, which queue in the database
+func (r *RailsConfig) JobWorker(path string) (Process, bool) {
+	var worker Process
+	switch {
+	case r.Services.Sidekiq:
+		worker = Process{Name: "worker", Command: "bundle exec sidekiq", Services: []string{"redis"}}
+		if _, err := os.Stat(filepath.Join(path, "config", "sidekiq.yml")); err == nil {
+			worker.Command += " -C config/sidekiq.yml"
+		}
+	case r.Services.GoodJob:
+		worker = Process{Name: "worker", Command: "bundle exec good_job start"}
+	case r.Services.DelayedJob:
+		worker = Process{Name: "worker", Command: "bin/rails jobs:work"}
+	default:
+		return Process{}, false
+	}
+
+	// Jobs are loaded with the app, so workers need its database too
+	if db := r.DatabaseService(); db != "" {
+		worker.Services = append(worker.Services, db)
+	}
+	return worker, true
+}
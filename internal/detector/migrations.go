@@ -0,0 +1,64 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// schemaVersionPattern matches the version in db/schema.rb, like
+// ActiveRecord::Schema[7.1].define(version: 2024_03_01_120000)
+var schemaVersionPattern = regexp.MustCompile(`define\(version:\s*([0-9_]+)\)`)
+
+// structureVersionPattern matches the versions inserted into
+// schema_migrations at the end of db/structure.sql
+var structureVersionPattern = regexp.MustCompile(`\('(\d{14})'\)`)
+
+// migrationPattern matches migration files like 20240301120000_create_users.rb
+var migrationPattern = regexp.MustCompile(`^(\d{14})_.+\.rb$`)
+
+// PendingMigrations returns the migrations of a Rails app in path that are
+// newer than its schema, oldest first. The schema only records the version
+// of the latest migration, so a missed older migration isn't reported.
+func PendingMigrations(path string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(path, "db", "migrate"))
+	if err != nil {
+		return nil, err
+	}
+
+	version := schemaVersion(path)
+	var pending []string
+	for _, entry := range entries {
+		match := migrationPattern.FindStringSubmatch(entry.Name())
+		if match == nil || match[1] <= version {
+			continue
+		}
+		pending = append(pending, strings.TrimSuffix(entry.Name(), ".rb"))
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
+
+// schemaVersion returns the version of the latest migration in the schema,
+// or an empty string if the app has no schema yet
+func schemaVersion(path string) string {
+	if data, err := os.ReadFile(filepath.Join(path, "db", "schema.rb")); err == nil {
+		if match := schemaVersionPattern.FindSubmatch(data); match != nil {
+			return strings.ReplaceAll(string(match[1]), "_", "")
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(path, "db", "structure.sql"))
+	if err != nil {
+		return ""
+	}
+	version := ""
+	for _, match := range structureVersionPattern.FindAllSubmatch(data, -1) {
+		if v := string(match[1]); v > version {
+			version = v
+		}
+	}
+	return version
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // NodeConfig holds Node.js-specific configuration
@@ -17,6 +19,8 @@ type NodeConfig struct {
 	Services    NodeServicesConfig `json:"services"`    // Detected services
 	Scripts     []string           `json:"scripts"`     // Available npm scripts
 	DevTools    []string           `json:"devTools"`    // Development tools (eslint, prettier, etc.)
+
+	PackageManager string `json:"packageManager"` // npm, yarn, pnpm or bun
 }
 
 // PackageJSONInfo represents the relevant parts of package.json
@@ -25,6 +29,8 @@ type PackageJSONInfo struct {
 	Version         string            `json:"version"`
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
+	Scripts         map[string]string `json:"scripts"`
+	PackageManager  string            `json:"packageManager"` // Corepack's field, like "pnpm@9.1.0"
 	Engines         struct {
 		Node string `json:"node"`
 		NPM  string `json:"npm"`
@@ -105,6 +111,9 @@ func DetectNode(path string) (*NodeConfig, error) {
 	// Detect development tools
 	config.DevTools = detectDevTools(config.PackageJSON)
 
+	// Detect the package manager
+	config.PackageManager = detectPackageManager(path, config.PackageJSON)
+
 	return config, nil
 }
 
@@ -117,6 +126,90 @@ func parsePackageJSON(path string, info *PackageJSONInfo) error {
 	return json.Unmarshal(data, info)
 }
 
+// PackageManagers are the Node.js package managers spin knows how to run
+var PackageManagers = []string{"npm", "yarn", "pnpm", "bun"}
+
+// lockfiles map the lockfile of each package manager to it, in the order they
+// are looked for
+var lockfiles = []struct {
+	name    string
+	manager string
+}{
+	{"yarn.lock", "yarn"},
+	{"pnpm-lock.yaml", "pnpm"},
+	{"bun.lockb", "bun"},
+	{"bun.lock", "bun"},
+	{"package-lock.json", "npm"},
+	{"npm-shrinkwrap.json", "npm"},
+}
+
+// DetectPackageManager returns the package manager of the Node.js project at
+// path, or an empty string when it has no package.json
+func DetectPackageManager(path string) string {
+	var info PackageJSONInfo
+	if err := parsePackageJSON(filepath.Join(path, "package.json"), &info); err != nil {
+		return ""
+	}
+	return detectPackageManager(path, info)
+}
+
+// detectPackageManager picks the package manager from the packageManager
+// field of package.json, then from the lockfile, falling back to npm
+func detectPackageManager(path string, pkgInfo PackageJSONInfo) string {
+	if name, _, _ := strings.Cut(pkgInfo.PackageManager, "@"); IsPackageManager(name) {
+		return name
+	}
+
+	for _, lockfile := range lockfiles {
+		if _, err := os.Stat(filepath.Join(path, lockfile.name)); err == nil {
+			return lockfile.manager
+		}
+	}
+	return "npm"
+}
+
+// IsPackageManager reports whether name is a package manager spin knows
+func IsPackageManager(name string) bool {
+	for _, manager := range PackageManagers {
+		if manager == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RunScriptCommand returns the command running a package.json script with a
+// package manager, passing args through to the script
+func RunScriptCommand(manager, script, args string) string {
+	var command string
+	switch manager {
+	case "yarn", "pnpm":
+		command = manager + " " + script
+	case "bun":
+		command = "bun run " + script
+	default:
+		command = "npm run " + script
+		if args != "" {
+			// npm needs a separator to pass arguments through to the script
+			command += " --"
+		}
+	}
+
+	if args != "" {
+		command += " " + args
+	}
+	return command
+}
+
+// InstallCommand returns the command installing the packages of a project
+// with a package manager
+func InstallCommand(manager string) string {
+	if !IsPackageManager(manager) {
+		manager = "npm"
+	}
+	return manager + " install"
+}
+
 func detectNodeVersion(path string, pkgInfo PackageJSONInfo) (string, error) {
 	// Check .nvmrc first
 	if data, err := os.ReadFile(filepath.Join(path, ".nvmrc")); err == nil {
@@ -280,7 +373,10 @@ func detectNodeServices(pkgInfo PackageJSONInfo) NodeServicesConfig {
 
 func getScripts(pkgInfo PackageJSONInfo) []string {
 	scripts := []string{}
-	// This would be populated from package.json scripts
+	for name := range pkgInfo.Scripts {
+		scripts = append(scripts, name)
+	}
+	sort.Strings(scripts)
 	return scripts
 }
 
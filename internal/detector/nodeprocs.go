@@ -0,0 +1,143 @@
+package detector
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// devServerCommand is a command of a package.json script that starts a dev
+// server, with the port it listens on by default
+type devServerCommand struct {
+	pattern *regexp.Regexp
+	port    int
+}
+
+// devServerCommands are the dev servers of common frameworks and bundlers
+var devServerCommands = []devServerCommand{
+	{regexp.MustCompile(`\bnext dev\b`), 3000},
+	{regexp.MustCompile(`\bastro dev\b`), 4321},
+	{regexp.MustCompile(`\bnux[ti] dev\b`), 3000},
+	{regexp.MustCompile(`\bremix (vite:)?dev\b`), 5173},
+	{regexp.MustCompile(`\bsvelte-kit dev\b`), 5173},
+	{regexp.MustCompile(`\bgatsby develop\b`), 8000},
+	{regexp.MustCompile(`\breact-scripts start\b`), 3000},
+	{regexp.MustCompile(`\bwebpack(-dev-server| serve)\b`), 8080},
+	{regexp.MustCompile(`\bvite( dev| serve)?(\s+-|\s*$|\s*&)`), 5173},
+	{regexp.MustCompile(`\b(nodemon|ts-node-dev|tsx watch|node --watch)\b`), 0},
+}
+
+// watchFlagPattern matches commands that rebuild on changes, like
+// tsc --watch or tailwindcss -w
+var watchFlagPattern = regexp.MustCompile(`(^|\s)(--watch|-w)(\s|=|$)`)
+
+// scriptNamePattern matches characters process names can't have
+var scriptNamePattern = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// DevProcesses returns the processes of the Node.js project at path, read
+// from the commands of its package.json scripts: the dev server as web,
+// preferring the dev script, then start, and every script that rebuilds on
+// changes, like watch:css, under its own name. Without a dev server, start or
+// dev runs as web. Tests in watch mode are left out.
+func (n *NodeConfig) DevProcesses(path string) []Process {
+	scripts := n.PackageJSON.Scripts
+	names := make([]string, 0, len(scripts))
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	web, port := "", 0
+	for _, name := range append([]string{"dev", "start"}, names...) {
+		if p, explicit, ok := devServerPort(scripts[name]); ok {
+			web, port = name, p
+			// Vite and webpack read the port from their config otherwise
+			if devServer, ok := DetectDevServer(path); ok && !explicit && devServer.Command == "" && strings.Contains(scripts[name], devServer.Name) {
+				port = devServer.Port
+			}
+			break
+		}
+	}
+	if web == "" {
+		for _, name := range []string{"dev", "start"} {
+			if _, ok := scripts[name]; ok {
+				web = name
+				break
+			}
+		}
+	}
+
+	var processes []Process
+	taken := make(map[string]bool)
+	if web != "" {
+		processes = append(processes, Process{Name: "web", Command: RunScriptCommand(n.PackageManager, web, ""), Port: port})
+		taken["web"] = true
+	}
+	for _, name := range names {
+		if name == web || !isWatchScript(name, scripts[name]) || calledByScript(scripts[web], name) {
+			continue
+		}
+		process := watchProcessName(name)
+		if taken[process] {
+			continue
+		}
+		taken[process] = true
+		processes = append(processes, Process{Name: process, Command: RunScriptCommand(n.PackageManager, name, "")})
+	}
+	return processes
+}
+
+// devServerPort checks if a script command starts a dev server, returning
+// the port it listens on: the one it passes with --port or -p, which is
+// explicit, or the server's default. The port is 0 when it isn't known.
+func devServerPort(command string) (port int, explicit bool, ok bool) {
+	for _, server := range devServerCommands {
+		if !server.pattern.MatchString(command) {
+			continue
+		}
+		if match := scriptPortPattern.FindStringSubmatch(command); match != nil {
+			if port, err := strconv.Atoi(match[1]); err == nil {
+				return port, true, true
+			}
+		}
+		return server.port, false, true
+	}
+	return 0, false, false
+}
+
+// scriptPortPattern matches the port a script command passes
+var scriptPortPattern = regexp.MustCompile(`(?:--port[= ]|-p )(\d+)`)
+
+// testRunnerPattern matches commands running tests, which have watch modes
+// too
+var testRunnerPattern = regexp.MustCompile(`\b(jest|vitest|mocha|ava|playwright|cypress)\b`)
+
+// isWatchScript checks if a script rebuilds on changes. Scripts running
+// several others, like concurrently, are left to their parts.
+func isWatchScript(name string, command string) bool {
+	if strings.HasPrefix(name, "test") || testRunnerPattern.MatchString(command) {
+		return false
+	}
+	if strings.Contains(command, "concurrently") || strings.Contains(command, "npm-run-all") || strings.Contains(command, "run-p ") {
+		return false
+	}
+	return watchFlagPattern.MatchString(command) || name == "watch" || strings.HasPrefix(name, "watch:") || strings.HasSuffix(name, ":watch")
+}
+
+// calledByScript checks if a command runs another script, like a dev script
+// running watch:css alongside the server
+func calledByScript(command string, script string) bool {
+	return regexp.MustCompile(`(^|[\s"'])` + regexp.QuoteMeta(script) + `($|[\s"'&;])`).MatchString(command)
+}
+
+// watchProcessName turns a script name into a process name, like css for
+// watch:css and build-css for build:css
+func watchProcessName(script string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(script, "watch:"), ":watch")
+	name = strings.Trim(scriptNamePattern.ReplaceAllString(name, "-"), "-")
+	if name == "" || name == "web" {
+		return "watch"
+	}
+	return name
+}
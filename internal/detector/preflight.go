@@ -0,0 +1,129 @@
+package detector
+
+// Kinds of preflight tasks, which spin up --skip-deps and --skip-migrations
+// leave out
+const (
+	PreflightDeps       = "deps"
+	PreflightMigrations = "migrations"
+)
+
+// PreflightTask is a task spin up runs before starting processes, like
+// installing dependencies, only when its inputs changed since it last
+// succeeded
+type PreflightTask struct {
+	Name    string   // Like bundle install, also the key its state is kept under
+	Command string   // Command to run, split on spaces
+	Kind    string   // PreflightDeps or PreflightMigrations
+	Inputs  []string // Globs of the files the task depends on, relative to the project
+}
+
+// Required reports whether spin up stops when the task fails. Failed
+// migrations are left for the developer, dependencies are needed to start.
+func (t PreflightTask) Required() bool {
+	return t.Kind == PreflightDeps
+}
+
+// PreflightDetector finds the preflight tasks of one kind of project
+type PreflightDetector interface {
+	Preflight(path string) []PreflightTask
+}
+
+// preflightDetectors are the detectors DetectPreflight asks, in the order
+// their tasks run
+var preflightDetectors = []PreflightDetector{
+	rubyPreflight{},
+	nodePreflight{},
+	goPreflight{},
+	elixirPreflight{},
+}
+
+// DetectPreflight returns the preflight tasks of the project in path. A
+// project can have several, like a Rails app bundling its JavaScript, which
+// installs its gems and its packages.
+func DetectPreflight(path string) []PreflightTask {
+	var tasks []PreflightTask
+	for _, d := range preflightDetectors {
+		tasks = append(tasks, d.Preflight(path)...)
+	}
+	return tasks
+}
+
+// rubyPreflight installs gems, and migrates the database of Rails apps
+type rubyPreflight struct{}
+
+func (rubyPreflight) Preflight(path string) []PreflightTask {
+	if !exists(path, "Gemfile") {
+		return nil
+	}
+	tasks := []PreflightTask{{
+		Name:    "bundle install",
+		Command: "bundle install",
+		Kind:    PreflightDeps,
+		Inputs:  []string{"Gemfile", "Gemfile.lock"},
+	}}
+	if hasRailsGem(path) || exists(path, "db", "migrate") {
+		tasks = append(tasks, PreflightTask{
+			Name:    "rails db:migrate",
+			Command: "bundle exec rails db:migrate",
+			Kind:    PreflightMigrations,
+			Inputs:  []string{"db/migrate/*.rb", "db/schema.rb", "db/structure.sql"},
+		})
+	}
+	return tasks
+}
+
+// nodePreflight installs packages with the project's package manager
+type nodePreflight struct{}
+
+func (nodePreflight) Preflight(path string) []PreflightTask {
+	if !exists(path, "package.json") {
+		return nil
+	}
+	command := InstallCommand(DetectPackageManager(path))
+	return []PreflightTask{{
+		Name:    command,
+		Command: command,
+		Kind:    PreflightDeps,
+		Inputs:  []string{"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "bun.lockb", "bun.lock"},
+	}}
+}
+
+// goPreflight downloads the modules of go.mod
+type goPreflight struct{}
+
+func (goPreflight) Preflight(path string) []PreflightTask {
+	if !exists(path, "go.mod") {
+		return nil
+	}
+	return []PreflightTask{{
+		Name:    "go mod download",
+		Command: "go mod download",
+		Kind:    PreflightDeps,
+		Inputs:  []string{"go.mod", "go.sum"},
+	}}
+}
+
+// elixirPreflight fetches the deps of mix.exs, and migrates the Ecto repos
+// of apps with migrations, like Phoenix apps
+type elixirPreflight struct{}
+
+func (elixirPreflight) Preflight(path string) []PreflightTask {
+	if !exists(path, "mix.exs") {
+		return nil
+	}
+	tasks := []PreflightTask{{
+		Name:    "mix deps.get",
+		Command: "mix deps.get",
+		Kind:    PreflightDeps,
+		Inputs:  []string{"mix.exs", "mix.lock"},
+	}}
+	if exists(path, "priv", "repo", "migrations") {
+		tasks = append(tasks, PreflightTask{
+			Name:    "mix ecto.migrate",
+			Command: "mix ecto.migrate",
+			Kind:    PreflightMigrations,
+			Inputs:  []string{"priv/repo/migrations/*.exs"},
+		})
+	}
+	return tasks
+}
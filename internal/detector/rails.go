@@ -24,19 +24,21 @@ type RailsConfig struct {
 
 // ServicesConfig holds information about detected services
 type ServicesConfig struct {
-	Redis         bool `json:"redis,omitempty"`
-	Sidekiq       bool `json:"sidekiq,omitempty"`
-	DelayedJob    bool `json:"delayed_job,omitempty"`
-	GoodJob       bool `json:"good_job,omitempty"`
-	Elasticsearch bool `json:"elasticsearch,omitempty"`
-	Memcached     bool `json:"memcached,omitempty"`
-	ActionCable   bool `json:"action_cable,omitempty"`
+	Redis         bool   `json:"redis,omitempty"`
+	Sidekiq       bool   `json:"sidekiq,omitempty"`
+	DelayedJob    bool   `json:"delayed_job,omitempty"`
+	GoodJob       bool   `json:"good_job,omitempty"`
+	Elasticsearch bool   `json:"elasticsearch,omitempty"`
+	Memcached     bool   `json:"memcached,omitempty"`
+	ActionCable   bool   `json:"action_cable,omitempty"`
+	AnyCable      string `json:"anycable,omitempty"` // grpc or http, how anycable-go calls the app
 }
 
 // AssetConfig holds information about asset pipeline and JavaScript bundler
 type AssetConfig struct {
-	Pipeline string `json:"pipeline"` // sprockets, webpacker, propshaft
-	Bundler  string `json:"bundler"`  // esbuild, rollup, webpack
+	Pipeline string `json:"pipeline"`      // sprockets, webpacker, propshaft
+	Bundler  string `json:"bundler"`       // esbuild, rollup, webpack, vite
+	CSS      string `json:"css,omitempty"` // tailwindcss, dartsass, cssbundling
 }
 
 // TestingConfig holds information about testing frameworks
@@ -193,6 +195,18 @@ func detectServices(path string) (ServicesConfig, error) {
 		services.Memcached = true
 	}
 
+	// Check for AnyCable, whose anycable-go broadcasts through Redis. Apps
+	// with only anycable-rails-core are called over HTTP instead of gRPC.
+	if hasGem("anycable-rails") || hasGem("anycable") {
+		services.AnyCable = "grpc"
+	} else if hasGem("anycable-rails-core") {
+		services.AnyCable = "http"
+	}
+	if services.AnyCable != "" {
+		services.ActionCable = true
+		services.Redis = true
+	}
+
 	// Check for ActionCable
 	cablePath := filepath.Join(path, "config", "cable.yml")
 	if _, err := os.Stat(cablePath); err == nil {
@@ -221,7 +235,7 @@ func detectAssetConfig(path string) (AssetConfig, error) {
 		if err == nil {
 			content := string(data)
 			switch {
-			case strings.Contains(content, "\"@rails/webpacker\""):
+			case strings.Contains(content, "\"@rails/webpacker\""), strings.Contains(content, "\"shakapacker\""):
 				config.Bundler = "webpack"
 			case strings.Contains(content, "\"esbuild\""):
 				config.Bundler = "esbuild"
@@ -231,6 +245,24 @@ func detectAssetConfig(path string) (AssetConfig, error) {
 		}
 	}
 
+	// Vite Ruby ships a binstub that runs the dev server
+	if _, err := os.Stat(filepath.Join(path, "bin", "vite")); err == nil {
+		config.Bundler = "vite"
+	}
+
+	// Check for a CSS watcher
+	if data, err := os.ReadFile(filepath.Join(path, "Gemfile")); err == nil {
+		content := string(data)
+		switch {
+		case strings.Contains(content, "tailwindcss-rails"):
+			config.CSS = "tailwindcss"
+		case strings.Contains(content, "dartsass-rails"):
+			config.CSS = "dartsass"
+		case strings.Contains(content, "cssbundling-rails"):
+			config.CSS = "cssbundling"
+		}
+	}
+
 	return config, nil
 }
 
@@ -0,0 +1,507 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/cleanup"
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/credentials"
+	"github.com/afomera/spin/internal/detector"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/service"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/tools"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Groups checks are reported under, in the order they run
+const (
+	GroupSystem   = "System"
+	GroupProject  = "Project"
+	GroupTools    = "Tools"
+	GroupPorts    = "Ports"
+	GroupServices = "Services"
+	GroupDocker   = "Docker"
+)
+
+// minFreeDisk is the free disk space below which Docker is reported as low on space
+const minFreeDisk = 5 * 1024 * 1024 * 1024
+
+// Check is the result of a single check with how to fix what it found
+type Check struct {
+	ID      string `json:"id"` // Stable identifier, like tools.ruby or service.redis
+	Group   string `json:"group"`
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"remediation,omitempty"` // How to fix the problem
+
+	apply func() error // Safe remediation run by spin doctor --fix
+}
+
+// MarshalJSON adds whether spin doctor --fix can fix the problem
+func (c Check) MarshalJSON() ([]byte, error) {
+	type check Check
+	return json.Marshal(struct {
+		check
+		Fixable bool `json:"fixable"`
+	}{check(c), c.Fixable()})
+}
+
+// Failed reports whether any of the checks failed
+func Failed(checks []Check) bool {
+	for _, check := range checks {
+		if check.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Fixable reports whether spin doctor --fix can fix the problem
+func (c Check) Fixable() bool {
+	return c.Status != StatusOK && c.apply != nil
+}
+
+// Apply runs the remediation of the check
+func (c Check) Apply() error {
+	if c.apply == nil {
+		return fmt.Errorf("%s can't be fixed automatically", c.Name)
+	}
+	return c.apply()
+}
+
+// Run checks the system and, when dir holds a spin.config.json, the project
+func Run(dir string) []Check {
+	checks := systemChecks()
+
+	dm, dockerErr := dockerManager()
+	if dockerErr != nil {
+		checks = append(checks, Check{
+			ID:      "docker",
+			Group:   GroupSystem,
+			Name:    "docker",
+			Status:  StatusWarn,
+			Message: dockerErr.Error(),
+			Fix:     "Install Docker, or start Docker Desktop to use docker features",
+		})
+	} else {
+		checks = append(checks, Check{ID: "docker", Group: GroupSystem, Name: "docker", Status: StatusOK, Message: "running"})
+	}
+
+	configPath := filepath.Join(dir, "spin.config.json")
+	if !config.Exists(configPath) {
+		return checks
+	}
+
+	cfg, configChecks := checkConfig(dir, configPath)
+	checks = append(checks, configChecks...)
+	checks = append(checks, checkTools(dir)...)
+	if cfg == nil {
+		return checks
+	}
+
+	checks = append(checks, checkDependencies(cfg, dir)...)
+	checks = append(checks, checkCredentials(cfg, dir)...)
+	checks = append(checks, checkPorts(cfg, dm)...)
+	if dm != nil {
+		checks = append(checks, checkServices(cfg, dm)...)
+		checks = append(checks, checkVolumes(dm)...)
+		checks = append(checks, checkDisk(dm)...)
+	}
+	return checks
+}
+
+// dockerManager connects to the Docker daemon
+func dockerManager() (*docker.ServiceManager, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil, fmt.Errorf("not found")
+	}
+	dm, err := docker.NewServiceManager("./data")
+	if err != nil {
+		return nil, fmt.Errorf("installed but not running")
+	}
+	if _, err := dm.Client().Ping(context.Background()); err != nil {
+		return nil, fmt.Errorf("installed but not running")
+	}
+	return dm, nil
+}
+
+// systemChecks checks the programs spin relies on
+func systemChecks() []Check {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return []Check{{
+			ID:      "tmux",
+			Group:   GroupSystem,
+			Name:    "tmux",
+			Status:  StatusWarn,
+			Message: "not found",
+			Fix:     "Install tmux or run 'spin config set-backend native'",
+		}}
+	}
+	return []Check{{ID: "tmux", Group: GroupSystem, Name: "tmux", Status: StatusOK, Message: "installed"}}
+}
+
+// checkConfig validates spin.config.json and the files it points to
+func checkConfig(dir string, configPath string) (*config.Config, []Check) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, []Check{{ID: "config", Group: GroupProject, Name: "spin.config.json", Status: StatusFail, Message: err.Error()}}
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, []Check{{
+			ID:      "config",
+			Group:   GroupProject,
+			Name:    "spin.config.json",
+			Status:  StatusFail,
+			Message: err.Error(),
+			Fix:     "Fix the syntax of spin.config.json",
+		}}
+	}
+
+	var checks []Check
+
+	// Unknown keys are usually typos that silently do nothing
+	if err := config.CheckUnknownKeys(data); err != nil {
+		checks = append(checks, Check{
+			ID:      "config",
+			Group:   GroupProject,
+			Name:    "spin.config.json",
+			Status:  StatusWarn,
+			Message: err.Error(),
+			Fix:     "Remove or correct the key, it is ignored",
+		})
+	} else {
+		checks = append(checks, Check{ID: "config", Group: GroupProject, Name: "spin.config.json", Status: StatusOK, Message: "valid"})
+	}
+	checks = append(checks, checkLocalConfig(dir)...)
+
+	for _, name := range cfg.Dependencies.Services {
+		if _, err := service.CreateService(name, cfg); err != nil {
+			checks = append(checks, Check{
+				ID:      "config.services." + name,
+				Group:   GroupProject,
+				Name:    name,
+				Status:  StatusFail,
+				Message: fmt.Sprintf("dependencies.services lists %s, which isn't configured", name),
+				Fix:     "Add it with 'spin services add' or remove it from dependencies.services",
+			})
+		}
+	}
+
+	procfile := filepath.Join(dir, cfg.GetProcfilePath())
+	if _, err := os.Stat(procfile); err != nil {
+		checks = append(checks, Check{
+			ID:      "procfile",
+			Group:   GroupProject,
+			Name:    cfg.GetProcfilePath(),
+			Status:  StatusFail,
+			Message: "not found",
+			Fix:     "Generate one with 'spin procfile generate'",
+		})
+	} else {
+		checks = append(checks, Check{ID: "procfile", Group: GroupProject, Name: cfg.GetProcfilePath(), Status: StatusOK, Message: "found"})
+	}
+
+	return cfg, checks
+}
+
+// checkLocalConfig checks spin.config.local.json, which is personal and
+// shouldn't be committed
+func checkLocalConfig(dir string) []Check {
+	data, err := os.ReadFile(filepath.Join(dir, config.LocalFile))
+	if err != nil {
+		return nil
+	}
+
+	check := Check{ID: "config.local", Group: GroupProject, Name: config.LocalFile, Status: StatusOK, Message: "valid"}
+	if err := config.CheckUnknownKeys(data); err != nil {
+		check.Status = StatusWarn
+		check.Message = err.Error()
+		check.Fix = "Remove or correct the key, it is ignored"
+	}
+
+	tracked := exec.Command("git", "ls-files", "--error-unmatch", config.LocalFile)
+	tracked.Dir = dir
+	if tracked.Run() == nil {
+		check.Status = StatusWarn
+		check.Message = "committed to git, but meant for personal overrides"
+		check.Fix = fmt.Sprintf("Run 'git rm --cached %s' and add it to .gitignore", config.LocalFile)
+	}
+	return []Check{check}
+}
+
+// checkCredentials checks that Rails can decrypt the credentials of the project
+func checkCredentials(cfg *config.Config, dir string) []Check {
+	creds, ok := credentials.Detect(dir, "development")
+	if !ok {
+		return nil
+	}
+
+	env := make(map[string]string)
+	if cfg.Foreman != nil {
+		for key, value := range cfg.Foreman.Env {
+			env[key] = value
+		}
+	}
+	for key, value := range cfg.GetEnvVars("development") {
+		env[key] = value
+	}
+
+	check := Check{ID: "credentials", Group: GroupProject, Name: creds.File, Status: StatusOK, Message: "master key found"}
+	if _, err := credentials.MasterKey(cfg.Name, dir, env); err != nil {
+		check.Status = StatusWarn
+		check.Message = err.Error()
+		check.Fix = fmt.Sprintf("Ask a teammate for the key and run 'spin config set-master-key', or put it in %s", creds.KeyFile)
+	}
+	return []Check{check}
+}
+
+// checkTools checks the installed versions of the tools the project asks for
+func checkTools(dir string) []Check {
+	var checks []Check
+	for _, c := range tools.CheckAll(dir) {
+		check := Check{
+			ID:      "tools." + c.Tool,
+			Group:   GroupTools,
+			Name:    c.Tool,
+			Status:  StatusOK,
+			Message: fmt.Sprintf("%s satisfies %s from %s", c.Installed, c.Constraint(), c.Source),
+		}
+		if !c.OK {
+			installed := c.Installed
+			if installed == "" {
+				installed = "not installed"
+			}
+			check.Status = StatusFail
+			check.Message = fmt.Sprintf("%s requires %s, found %s", c.Source, c.Constraint(), installed)
+			check.Fix = c.Hint()
+			if tools.Manager(c.Tool) != "" {
+				requirement := c.Requirement
+				check.apply = func() error { return tools.Install(dir, requirement) }
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkDependencies checks that the tools of dependencies.tools are installed
+// and meet their version constraints
+func checkDependencies(cfg *config.Config, dir string) []Check {
+	deps, err := tools.CheckDependencies(dir, cfg.Dependencies.Tools)
+	if err != nil {
+		return []Check{{ID: "config.tools", Group: GroupProject, Name: "dependencies.tools", Status: StatusFail, Message: err.Error()}}
+	}
+
+	var checks []Check
+	for _, c := range deps {
+		check := Check{
+			ID:      "dependency." + c.Name,
+			Group:   GroupTools,
+			Name:    c.Dependency.String(),
+			Status:  StatusOK,
+			Message: c.Path,
+		}
+		switch {
+		case c.Pending:
+			check.Status = StatusWarn
+			check.Message = "not installed yet, it comes with the project's packages"
+			check.Fix = fmt.Sprintf("Install the project's packages with '%s'", detector.InstallCommand(cfg.PackageManager(dir)))
+		case c.Path == "":
+			check.Status = StatusFail
+			check.Message = "not installed"
+			check.Fix = c.Hint()
+		case !c.OK:
+			check.Status = StatusFail
+			check.Message = fmt.Sprintf("found %s at %s", c.Installed, c.Path)
+			check.Fix = c.Hint()
+		case c.Installed != "":
+			check.Message = fmt.Sprintf("%s at %s", c.Installed, c.Path)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkPorts reports ports of services and processes taken by other programs
+func checkPorts(cfg *config.Config, dm *docker.ServiceManager) []Check {
+	var checks []Check
+
+	for _, name := range sortedServices(cfg) {
+		port := cfg.Services[name].PublishedPort()
+		if port == 0 || (dm != nil && dm.IsRunning(name)) {
+			continue
+		}
+		checks = append(checks, portCheck(fmt.Sprintf("service %s", name), port, fmt.Sprintf("services.%s.port", name)))
+	}
+
+	if cfg.Processes != nil && len(cfg.Processes.Settings) > 0 {
+		manager := process.GetManager(cfg)
+		manager.SetQuiet(true)
+
+		names := make([]string, 0, len(cfg.Processes.Settings))
+		for name := range cfg.Processes.Settings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			port := cfg.Processes.Settings[name].Port
+			if port == 0 {
+				continue
+			}
+			if _, err := manager.FindProcess(name); err == nil {
+				continue
+			}
+			checks = append(checks, portCheck(fmt.Sprintf("process %s", name), port, fmt.Sprintf("processes.settings.%s.port", name)))
+		}
+	}
+
+	return checks
+}
+
+// portCheck checks that a port is free for its owner to listen on
+func portCheck(owner string, port int, key string) Check {
+	check := Check{
+		ID:      fmt.Sprintf("port.%d", port),
+		Group:   GroupPorts,
+		Name:    fmt.Sprintf("%d", port),
+		Status:  StatusOK,
+		Message: fmt.Sprintf("free for %s", owner),
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("used by another program, %s can't start", owner)
+		check.Fix = fmt.Sprintf("Stop the program listening on %d, or change %s", port, key)
+		return check
+	}
+	listener.Close()
+	return check
+}
+
+// checkServices reports services that are stopped or unhealthy
+func checkServices(cfg *config.Config, dm *docker.ServiceManager) []Check {
+	var checks []Check
+	for _, name := range sortedServices(cfg) {
+		name, svc := name, cfg.Services[name]
+		check := Check{ID: "service." + name, Group: GroupServices, Name: name, Status: StatusOK, Message: "running"}
+
+		if !dm.IsRunning(name) {
+			check.Status = StatusWarn
+			check.Message = "not running"
+			check.Fix = fmt.Sprintf("Start it with 'spin services start %s'", name)
+			check.apply = func() error { return dm.StartServices(cfg.Services, []string{name}) }
+			checks = append(checks, check)
+			continue
+		}
+
+		switch health, _ := dm.HealthStatus(name); health {
+		case "unhealthy":
+			check.Status = StatusFail
+			check.Message = "unhealthy"
+			check.Fix = fmt.Sprintf("Check 'spin services logs %s' and 'spin services doctor %s', or restart it", name, name)
+			check.apply = func() error {
+				if err := dm.StopService(name, svc); err != nil {
+					return err
+				}
+				return dm.StartService(name, svc)
+			}
+		case "healthy":
+			check.Message = "running and healthy"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkVolumes reports spin volumes no container uses. Removing them deletes
+// data, so they are never removed by --fix.
+func checkVolumes(dm *docker.ServiceManager) []Check {
+	unused, err := dm.UnusedVolumes()
+	if err != nil {
+		return []Check{{ID: "volumes", Group: GroupDocker, Name: "volumes", Status: StatusWarn, Message: err.Error()}}
+	}
+	if len(unused) == 0 {
+		return []Check{{ID: "volumes", Group: GroupDocker, Name: "volumes", Status: StatusOK, Message: "no stale volumes"}}
+	}
+	return []Check{{
+		ID:      "volumes",
+		Group:   GroupDocker,
+		Name:    "volumes",
+		Status:  StatusWarn,
+		Message: fmt.Sprintf("%d stale volume(s): %s", len(unused), strings.Join(unused, ", ")),
+		Fix:     "Remove them with 'spin services cleanup volumes' once you no longer need their data",
+	}}
+}
+
+// checkDisk reports when the disk Docker stores its data on is running out
+// of space. Docker Desktop keeps its data in a virtual machine, the home
+// directory is checked then.
+func checkDisk(dm *docker.ServiceManager) []Check {
+	path, _ := os.UserHomeDir()
+	if info, err := dm.Client().Info(context.Background()); err == nil {
+		if _, err := os.Stat(info.DockerRootDir); err == nil {
+			path = info.DockerRootDir
+		}
+	}
+
+	usage, err := disk.Usage(path)
+	if err != nil {
+		return []Check{{ID: "disk", Group: GroupDocker, Name: "disk space", Status: StatusWarn, Message: fmt.Sprintf("failed to read free space of %s: %v", path, err)}}
+	}
+
+	free := int64(usage.Free)
+	check := Check{ID: "disk", Group: GroupDocker, Name: "disk space", Status: StatusOK, Message: fmt.Sprintf("%s free", docker.FormatBytes(free))}
+	if free >= minFreeDisk {
+		return []Check{check}
+	}
+
+	check.Status = StatusWarn
+	check.Message = fmt.Sprintf("only %s free on %s", docker.FormatBytes(free), path)
+	check.Fix = "Run 'spin cleanup' to remove leftover containers, images and logs"
+	check.apply = func() error {
+		resources, _ := cleanup.Find(cleanup.Options{LogAge: 7 * 24 * time.Hour})
+		var errs []string
+		for _, r := range resources {
+			if err := r.Remove(); err != nil {
+				errs = append(errs, fmt.Sprintf("%s %s: %v", r.Kind, r.Name, err))
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to remove %s", strings.Join(errs, ", "))
+		}
+		return nil
+	}
+	return []Check{check}
+}
+
+// sortedServices returns the names of the Docker services of the project
+func sortedServices(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Services))
+	for name := range cfg.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
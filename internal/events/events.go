@@ -0,0 +1,236 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Type identifies what happened
+type Type string
+
+// Events published by spin
+const (
+	ProcessStarted   Type = "process.started"
+	ProcessStopped   Type = "process.stopped"
+	ProcessRestarted Type = "process.restarted"
+	ProcessCrashed   Type = "process.crashed" // Exited without being stopped
+	ServiceHealthy   Type = "service.healthy"
+	ServiceUnhealthy Type = "service.unhealthy"
+	ServiceIdle      Type = "service.idle" // Stopped after its idle_timeout without traffic
+	ScriptRun        Type = "script.run"
+	ScriptFailed     Type = "script.failed"
+)
+
+// Types lists every event type in the order they are documented
+var Types = []Type{
+	ProcessStarted, ProcessStopped, ProcessRestarted, ProcessCrashed,
+	ServiceHealthy, ServiceUnhealthy, ServiceIdle, ScriptRun, ScriptFailed,
+}
+
+// maxSize is the size at which the event log is rotated to events.jsonl.1
+const maxSize = 5 * 1024 * 1024
+
+// followInterval is how often Follow checks the log for new events
+const followInterval = 500 * time.Millisecond
+
+// Event is a single entry of the event stream
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    Type      `json:"type"`
+	App     string    `json:"app,omitempty"`  // Project the event belongs to
+	Name    string    `json:"name,omitempty"` // Process, service or script
+	Message string    `json:"message,omitempty"`
+}
+
+// Filter selects events, empty fields match everything
+type Filter struct {
+	App   string
+	Name  string
+	Types []Type
+	Since time.Time
+}
+
+// Match reports whether e is selected by the filter
+func (f Filter) Match(e Event) bool {
+	if f.App != "" && e.App != f.App {
+		return false
+	}
+	if f.Name != "" && e.Name != f.Name {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if e.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// mu serializes publishing within a process, appends of a single line are
+// atomic enough between processes
+var mu sync.Mutex
+
+// Path returns the location of the event log
+func Path() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".spin", "events.jsonl"), nil
+}
+
+// Publish appends an event to the event log, the time defaults to now
+func Publish(e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create spin directory: %w", err)
+	}
+
+	// Keep one old log around once the current one gets large
+	if info, err := os.Stat(path); err == nil && info.Size() > maxSize {
+		os.Rename(path, path+".1")
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Recent returns the last n events matching the filter, oldest first
+func Recent(n int, filter Filter) ([]Event, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var result []Event
+	_, err = readEvents(f, func(e Event) {
+		if !filter.Match(e) {
+			return
+		}
+		result = append(result, e)
+		if n > 0 && len(result) > n {
+			result = result[1:]
+		}
+	})
+	return result, err
+}
+
+// Follow calls fn for every event matching the filter that is published
+// after Follow was called, until ctx is cancelled
+func Follow(ctx context.Context, filter Filter, fn func(Event)) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	// Start at the current end of the log
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.Size() < offset {
+			// The log was rotated, read the new one from the start
+			offset = 0
+		}
+		if info.Size() == offset {
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err == nil {
+			n, _ := readEvents(f, func(e Event) {
+				if filter.Match(e) {
+					fn(e)
+				}
+			})
+			offset += n
+		}
+		f.Close()
+	}
+}
+
+// readEvents calls fn for every complete line of r and returns the number of
+// bytes consumed. Lines that aren't valid events are skipped.
+func readEvents(r io.Reader, fn func(Event)) (int64, error) {
+	reader := bufio.NewReader(r)
+	var consumed int64
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// A partial line is still being written, read it next time
+			if err == io.EOF {
+				return consumed, nil
+			}
+			return consumed, err
+		}
+		consumed += int64(len(line))
+
+		var e Event
+		if json.Unmarshal([]byte(strings.TrimSpace(line)), &e) == nil {
+			fn(e)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package filelock keeps processes from changing the same files at once
+package filelock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Lock is an exclusive lock on a file, held until it is released. The lock
+// goes away with the process holding it, so it is never left stale.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire waits until it holds the lock of the file at path, which is
+// created if needed
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return &Lock{file: file}, nil
+}
+
+// Release gives up the lock
+func (l *Lock) Release() error {
+	unlock(l.file)
+	return l.file.Close()
+}
@@ -0,0 +1,102 @@
+package forge
+
+import (
+	"encoding/base64"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Git hosting providers spin can fetch from
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	Bitbucket = "bitbucket"
+)
+
+// DefaultHost is the host repositories are fetched from when none is configured
+const DefaultHost = "github.com"
+
+// tokenVariables are the environment variables a provider's access token is
+// read from, in order of preference
+var tokenVariables = map[string][]string{
+	GitHub:    {"GH_TOKEN", "GITHUB_TOKEN"},
+	GitLab:    {"GITLAB_TOKEN"},
+	Bitbucket: {"BITBUCKET_TOKEN"},
+}
+
+// basicUsers are the user names access tokens are sent with over HTTPS
+var basicUsers = map[string]string{
+	GitHub:    "x-access-token",
+	GitLab:    "oauth2",
+	Bitbucket: "x-token-auth",
+}
+
+// Provider guesses the provider of a host from its name, falling back to
+// GitHub, e.g. for GitHub Enterprise servers
+func Provider(host string) string {
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return GitLab
+	case strings.Contains(host, "bitbucket"):
+		return Bitbucket
+	default:
+		return GitHub
+	}
+}
+
+// Token returns the access token for a provider from the environment or,
+// for GitHub, from the gh CLI. It returns an empty string when there is none.
+func Token(provider string, host string) string {
+	for _, name := range tokenVariables[provider] {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+
+	if provider == GitHub {
+		if _, err := exec.LookPath("gh"); err == nil {
+			if output, err := exec.Command("gh", "auth", "token", "--hostname", host).Output(); err == nil {
+				return strings.TrimSpace(string(output))
+			}
+		}
+	}
+	return ""
+}
+
+// AuthEnv returns environment variables configuring git to authenticate
+// HTTPS requests to a host with the provider's access token, or nil when
+// there is no token. The token is passed in the environment of a single git
+// command, so it isn't stored in .git/config or shown in the process list.
+func AuthEnv(provider string, host string) []string {
+	token := Token(provider, host)
+	if token == "" {
+		return nil
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(basicUsers[provider] + ":" + token))
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.https://" + host + "/.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + credentials,
+	}
+}
+
+// WebURL returns the page of a repository, named like org/name, on a host
+func WebURL(host string, fullName string) string {
+	return "https://" + host + "/" + fullName
+}
+
+// CompareURL returns the page opening a pull request, or merge request on
+// GitLab, from branch into base
+func CompareURL(provider string, host string, fullName string, base string, branch string) string {
+	switch provider {
+	case GitLab:
+		query := url.Values{"merge_request[source_branch]": {branch}, "merge_request[target_branch]": {base}}
+		return WebURL(host, fullName) + "/-/merge_requests/new?" + query.Encode()
+	case Bitbucket:
+		query := url.Values{"source": {branch}, "dest": {base}}
+		return WebURL(host, fullName) + "/pull-requests/new?" + query.Encode()
+	}
+	return WebURL(host, fullName) + "/compare/" + base + "..." + branch + "?expand=1"
+}
@@ -0,0 +1,166 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Repository is a repository of an organization on a git host
+type Repository struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
+}
+
+// maxConcurrentRequests limits the requests made at once to the API
+const maxConcurrentRequests = 8
+
+// httpClient is used for requests to the API of git hosts
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// GitHubClient talks to the API of github.com or a GitHub Enterprise server
+type GitHubClient struct {
+	host  string
+	token string
+}
+
+// NewGitHubClient creates a client for the API of a GitHub host, using the
+// token from the environment or the gh CLI when there is one
+func NewGitHubClient(host string) *GitHubClient {
+	return &GitHubClient{host: host, token: Token(GitHub, host)}
+}
+
+// apiURL returns the URL of an API endpoint
+func (c *GitHubClient) apiURL(path string) string {
+	if c.host == DefaultHost {
+		return "https://api.github.com" + path
+	}
+	return "https://" + c.host + "/api/v3" + path
+}
+
+// get requests an API endpoint and decodes its JSON response into v. It
+// returns the status code of the response.
+func (c *GitHubClient) get(path string, v interface{}) (int, error) {
+	req, err := http.NewRequest(http.MethodGet, c.apiURL(path), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach %s: %w", c.host, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return resp.StatusCode, nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return resp.StatusCode, fmt.Errorf("%s denied access (%s), set GH_TOKEN or log in with 'gh auth login'", c.host, resp.Status)
+	case resp.StatusCode >= 300:
+		return resp.StatusCode, fmt.Errorf("%s returned %s", c.host, resp.Status)
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// ListRepositories returns the repositories of an organization or user that
+// aren't archived, sorted by name
+func (c *GitHubClient) ListRepositories(owner string) ([]Repository, error) {
+	repos, found, err := c.listPages("/orgs/" + url.PathEscape(owner) + "/repos")
+	if err == nil && !found {
+		// Not an organization, try a user
+		repos, found, err = c.listPages("/users/" + url.PathEscape(owner) + "/repos")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("organization or user %s not found on %s", owner, c.host)
+	}
+
+	var active []Repository
+	for _, repo := range repos {
+		if !repo.Archived {
+			active = append(active, repo)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Name < active[j].Name })
+	return active, nil
+}
+
+// listPages requests every page of a list of repositories. It reports
+// whether the list exists.
+func (c *GitHubClient) listPages(path string) ([]Repository, bool, error) {
+	var repos []Repository
+	for page := 1; ; page++ {
+		var batch []Repository
+		status, err := c.get(fmt.Sprintf("%s?per_page=100&page=%d", path, page), &batch)
+		if err != nil || status == http.StatusNotFound {
+			return nil, false, err
+		}
+		repos = append(repos, batch...)
+		if len(batch) < 100 {
+			return repos, true, nil
+		}
+	}
+}
+
+// HasFile reports whether the default branch of a repository has a file
+func (c *GitHubClient) HasFile(owner string, repo string, path string) (bool, error) {
+	status, err := c.get(fmt.Sprintf("/repos/%s/%s/contents/%s", url.PathEscape(owner), url.PathEscape(repo), path), nil)
+	if err != nil {
+		return false, err
+	}
+	return status != http.StatusNotFound, nil
+}
+
+// SpinRepositories returns the repositories of an organization or user that
+// have a spin.config.json
+func (c *GitHubClient) SpinRepositories(owner string) ([]Repository, error) {
+	repos, err := c.ListRepositories(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]bool, len(repos))
+	errs := make([]error, len(repos))
+	slots := make(chan struct{}, maxConcurrentRequests)
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			found[i], errs[i] = c.HasFile(owner, name, "spin.config.json")
+		}(i, repo.Name)
+	}
+	wg.Wait()
+
+	var spinRepos []Repository
+	for i, repo := range repos {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if found[i] {
+			spinRepos = append(spinRepos, repo)
+		}
+	}
+	return spinRepos, nil
+}
@@ -0,0 +1,185 @@
+package initjob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/service/docker"
+)
+
+// Record describes a completed init job
+type Record struct {
+	Hash        string    `json:"hash"` // Hash of the job definition when it ran
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// statePath returns the file that tracks completed init jobs for an app
+func statePath(appName string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".spin", "init", process.SanitizeAppName(appName)+".json"), nil
+}
+
+// loadState reads the completed init jobs for an app
+func loadState(appName string) (map[string]Record, error) {
+	path, err := statePath(appName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]Record), nil
+		}
+		return nil, err
+	}
+
+	state := make(map[string]Record)
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse init state: %w", err)
+	}
+	return state, nil
+}
+
+// saveState writes the completed init jobs for an app
+func saveState(appName string, state map[string]Record) error {
+	path, err := statePath(appName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// hashJob fingerprints a job so edited jobs run again
+func hashJob(job config.InitJob) string {
+	data, _ := json.Marshal(job)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Pending returns the jobs that have not completed with their current definition
+func Pending(appName string, jobs []config.InitJob) ([]config.InitJob, error) {
+	state, err := loadState(appName)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []config.InitJob
+	for _, job := range jobs {
+		if record, ok := state[job.Name]; ok && record.Hash == hashJob(job) {
+			continue
+		}
+		pending = append(pending, job)
+	}
+	return pending, nil
+}
+
+// Reset forgets completed jobs so they run again on the next `spin up`
+func Reset(appName string) error {
+	path, err := statePath(appName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Run executes all pending init jobs of a project in order. Each job is
+// recorded as soon as it succeeds, so a failure only re-runs what is left.
+func Run(cfg *config.Config, appPath string) error {
+	pending, err := Pending(cfg.Name, cfg.Init)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	state, err := loadState(cfg.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range pending {
+		fmt.Printf("Running init job %s...\n", job.Name)
+		if err := runJob(cfg, appPath, job); err != nil {
+			return fmt.Errorf("init job %s failed: %w", job.Name, err)
+		}
+
+		state[job.Name] = Record{Hash: hashJob(job), CompletedAt: time.Now()}
+		if err := saveState(cfg.Name, state); err != nil {
+			return fmt.Errorf("failed to record init job %s: %w", job.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// runJob runs a single init job in a container or on the host
+func runJob(cfg *config.Config, appPath string, job config.InitJob) error {
+	if job.Image != "" || job.Service != "" {
+		manager, err := docker.NewServiceManager("")
+		if err != nil {
+			return fmt.Errorf("failed to create Docker manager: %w", err)
+		}
+		defer manager.Client().Close()
+
+		// Make sure the service the job talks to is up
+		if _, ok := cfg.Services[job.Service]; ok {
+			if err := manager.StartServices(cfg.Services, []string{job.Service}); err != nil {
+				return err
+			}
+		}
+
+		if job.Image == "" {
+			return runHostCommand(appPath, job)
+		}
+		return manager.RunOneShot(docker.OneShotOptions{
+			Name:    fmt.Sprintf("init_%s_%s", process.SanitizeAppName(cfg.Name), job.Name),
+			Image:   job.Image,
+			Command: job.Command,
+			Env:     job.Env,
+			Service: job.Service,
+		})
+	}
+
+	return runHostCommand(appPath, job)
+}
+
+// runHostCommand runs an init job command on the host in the project directory
+func runHostCommand(appPath string, job config.InitJob) error {
+	if len(job.Command) == 0 {
+		return fmt.Errorf("either image or command is required")
+	}
+
+	cmd := exec.Command(job.Command[0], job.Command[1:]...)
+	cmd.Dir = appPath
+	cmd.Env = os.Environ()
+	for k, v := range job.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -1,14 +1,19 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
+	"time"
+
+	"golang.org/x/term"
 )
 
-// Colors for different log types
-const (
+// Colors for different log types, empty when color is off
+var (
 	Reset  = "\033[0m"
 	Red    = "\033[31m"
 	Green  = "\033[32m"
@@ -18,30 +23,154 @@ const (
 	Cyan   = "\033[36m"
 )
 
+// Output is plain when NO_COLOR is set (see https://no-color.org) or stdout
+// isn't a terminal, like when it is piped to a file or a CI log
+func init() {
+	if os.Getenv("NO_COLOR") != "" || !term.IsTerminal(int(os.Stdout.Fd())) {
+		DisableColor()
+	}
+}
+
+// DisableColor turns colored output off, for --no-color. It has to be called
+// before anything is printed.
+func DisableColor() {
+	Reset, Red, Green, Yellow, Blue, Purple, Cyan = "", "", "", "", "", "", ""
+}
+
+// Level is how important a log message is
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the name of the level, as written in JSON records
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	default:
+		return "error"
+	}
+}
+
+// Formats of log output
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// allModules turns on debug output of every module
+const allModules = "all"
+
 var (
-	verbose bool
+	verbose map[string]bool // Modules with debug output, allModules for all of them
+	quiet   bool
+	format  = FormatText
 	mu      sync.Mutex
+
+	// Log messages go to stderr, leaving stdout for results
+	logOutput io.Writer = os.Stderr
 )
 
-// SetVerbose enables or disables verbose output
+// SetVerbose enables or disables debug output of every module
 func SetVerbose(v bool) {
+	if v {
+		SetVerboseModules(allModules)
+	} else {
+		SetVerboseModules("")
+	}
+}
+
+// SetVerboseModules enables debug output of a comma separated list of
+// modules, like "docker,process", or of all of them with "all"
+func SetVerboseModules(spec string) {
 	mu.Lock()
-	verbose = v
-	mu.Unlock()
+	defer mu.Unlock()
+	verbose = make(map[string]bool)
+	for _, module := range strings.Split(spec, ",") {
+		if module = strings.TrimSpace(module); module != "" {
+			verbose[module] = true
+		}
+	}
 }
 
-// IsVerbose returns whether verbose output is enabled
+// IsVerbose returns whether debug output of any module is enabled
 func IsVerbose() bool {
 	mu.Lock()
 	defer mu.Unlock()
-	return verbose
+	return len(verbose) > 0
+}
+
+// IsVerboseFor returns whether debug output of a module is enabled
+func IsVerboseFor(module string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return verbose[allModules] || verbose[module]
+}
+
+// SetFormat sets the format of log output, FormatText or FormatJSON. JSON
+// output is one record per line on stderr, without colors.
+func SetFormat(f string) error {
+	switch f {
+	case FormatText:
+	case FormatJSON:
+		DisableColor()
+	default:
+		return fmt.Errorf("unknown log format %q, use %s or %s", f, FormatText, FormatJSON)
+	}
+	mu.Lock()
+	format = f
+	mu.Unlock()
+	return nil
+}
+
+// IsJSON returns whether log output is JSON
+func IsJSON() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return format == FormatJSON
+}
+
+// SetQuiet enables or disables quiet mode, which leaves out progress and
+// success messages so only results, warnings and errors are printed
+func SetQuiet(q bool) {
+	mu.Lock()
+	quiet = q
+	mu.Unlock()
+}
+
+// IsQuiet returns whether quiet mode is enabled
+func IsQuiet() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return quiet
+}
+
+// Printf writes a progress or success message unless quiet mode is enabled.
+// In JSON mode it is written as an info record instead.
+func Printf(format string, args ...interface{}) {
+	if IsQuiet() {
+		return
+	}
+	if IsJSON() {
+		write(LevelInfo, "", fmt.Sprintf(format, args...))
+		return
+	}
+	fmt.Printf(format, args...)
 }
 
 // Debug writes a debug message if verbose mode is enabled
 func Debug(format string, args ...interface{}) {
 	if IsVerbose() {
-		prefix := fmt.Sprintf("%s[debug]%s ", Yellow, Reset)
-		fmt.Printf(prefix+format, args...)
+		write(LevelDebug, "", fmt.Sprintf(format, args...))
 	}
 }
 
@@ -50,12 +179,106 @@ func Debugf(format string, args ...interface{}) {
 	Debug(format, args...)
 }
 
+// Warnf writes a warning, which is printed in quiet mode too
+func Warnf(format string, args ...interface{}) {
+	write(LevelWarn, "", fmt.Sprintf(format, args...))
+}
+
+// Errorf writes an error that doesn't stop spin
+func Errorf(format string, args ...interface{}) {
+	write(LevelError, "", fmt.Sprintf(format, args...))
+}
+
+// Logger writes the messages of one module, whose debug output can be
+// enabled on its own with --verbose=<module>
+type Logger struct {
+	module string
+}
+
+// For returns the logger of a module, like "docker" or "process"
+func For(module string) *Logger {
+	return &Logger{module: module}
+}
+
+// Debugf writes a debug message if verbose mode is enabled for the module
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	if IsVerboseFor(l.module) {
+		write(LevelDebug, l.module, fmt.Sprintf(format, args...))
+	}
+}
+
+// Infof writes a progress message unless quiet mode is enabled
+func (l *Logger) Infof(format string, args ...interface{}) {
+	if !IsQuiet() {
+		write(LevelInfo, l.module, fmt.Sprintf(format, args...))
+	}
+}
+
+// Warnf writes a warning of the module
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	write(LevelWarn, l.module, fmt.Sprintf(format, args...))
+}
+
+// Errorf writes an error of the module that doesn't stop spin
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	write(LevelError, l.module, fmt.Sprintf(format, args...))
+}
+
+// record is a log message in JSON mode
+type record struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Module  string `json:"module,omitempty"`
+	Message string `json:"msg"`
+}
+
+// write writes a message to the log output, as a line of text or a JSON
+// record. Debug lines have a timestamp, so slow steps stand out.
+func write(level Level, module string, message string) {
+	message = strings.TrimRight(message, "\n")
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+	now := time.Now()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if format == FormatJSON {
+		data, err := json.Marshal(record{
+			Time:    now.Format(time.RFC3339Nano),
+			Level:   level.String(),
+			Module:  module,
+			Message: strings.TrimSpace(message),
+		})
+		if err == nil {
+			fmt.Fprintf(logOutput, "%s\n", data)
+		}
+		return
+	}
+
+	if module != "" {
+		message = module + ": " + message
+	}
+	switch level {
+	case LevelDebug:
+		fmt.Fprintf(logOutput, "%s%s [debug]%s %s\n", Yellow, now.Format("15:04:05.000"), Reset, message)
+	case LevelInfo:
+		fmt.Println(message)
+	case LevelWarn:
+		fmt.Fprintf(logOutput, "%sWarning: %s%s\n", Yellow, message, Reset)
+	case LevelError:
+		fmt.Fprintf(logOutput, "%sError: %s%s\n", Red, message, Reset)
+	}
+}
+
 // PrefixedWriter wraps an io.Writer to prefix each line with a colored tag
 type PrefixedWriter struct {
 	name   string
 	color  string
 	writer io.Writer
 	mu     sync.Mutex
+
+	midLine bool // The last write didn't end with a newline
 }
 
 // NewPrefixedWriter creates a new PrefixedWriter
@@ -73,7 +296,18 @@ func (w *PrefixedWriter) Write(p []byte) (n int, err error) {
 	defer w.mu.Unlock()
 
 	prefix := fmt.Sprintf("%s[%s]%s ", w.color, w.name, Reset)
-	if _, err := fmt.Fprintf(w.writer, prefix+"%s", string(p)); err != nil {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(string(p), "\n") {
+		if line == "" {
+			continue
+		}
+		if !w.midLine {
+			b.WriteString(prefix)
+		}
+		b.WriteString(line)
+		w.midLine = !strings.HasSuffix(line, "\n")
+	}
+	if _, err := io.WriteString(w.writer, b.String()); err != nil {
 		return 0, err
 	}
 
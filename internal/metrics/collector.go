@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/service/docker"
+)
+
+// debugLog writes the debug messages of the collector, --verbose=metrics
+var debugLog = logger.For("metrics")
+
+// Collect records the resource usage of the app's processes and services
+// every interval until ctx is cancelled
+func Collect(ctx context.Context, cfg *config.Config, interval time.Duration) {
+	manager := process.GetManager(cfg)
+	manager.SetQuiet(true)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		collectOnce(manager, cfg)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectOnce takes one sample of every running process and service
+func collectOnce(manager *process.Manager, cfg *config.Config) {
+	now := time.Now()
+
+	// Listing processes refreshes their usage
+	for _, p := range manager.ListProcesses() {
+		if p.Type == process.ProcessTypeDocker {
+			continue
+		}
+		ring, err := ProcessRing(process.SanitizeAppName(p.AppName), p.Name)
+		if err != nil {
+			debugLog.Debugf("%v\n", err)
+			continue
+		}
+		sample := Sample{Time: now, CPUPercent: p.CPUPercent, MemoryUsage: p.MemoryUsage, MemoryPercent: p.MemoryPercent}
+		if err := ring.Append(sample); err != nil {
+			debugLog.Debugf("failed to record metrics for %s: %v\n", p.Name, err)
+		}
+	}
+
+	if len(cfg.Services) == 0 {
+		return
+	}
+	dm, err := docker.NewServiceManager("")
+	if err != nil {
+		debugLog.Debugf("%v\n", err)
+		return
+	}
+	defer dm.Client().Close()
+
+	names := make([]string, 0, len(cfg.Services))
+	for name := range cfg.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !dm.IsRunning(name) {
+			continue
+		}
+		cpu, memory, err := dm.ServiceUsage(name)
+		if err != nil {
+			debugLog.Debugf("%v\n", err)
+			continue
+		}
+		ring, err := ServiceRing(name)
+		if err != nil {
+			debugLog.Debugf("%v\n", err)
+			return
+		}
+		if err := ring.Append(Sample{Time: now, CPUPercent: cpu, MemoryUsage: memory}); err != nil {
+			debugLog.Debugf("failed to record metrics for %s: %v\n", name, err)
+		}
+	}
+}
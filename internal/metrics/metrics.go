@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultInterval is how often the collector samples resource usage
+const DefaultInterval = 5 * time.Second
+
+// sparkLevels are the bars of a sparkline from low to high
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Dir returns the directory metrics are stored in
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".spin", "metrics"), nil
+}
+
+// ProcessRing returns the ring file of a process, appName must be sanitized
+func ProcessRing(appName, name string) (*Ring, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return OpenRing(filepath.Join(dir, appName, name+".ring"), DefaultCapacity), nil
+}
+
+// ProcessNames returns the processes of an app that have a ring file
+func ProcessNames(appName string) ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, appName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".ring") {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".ring"))
+		}
+	}
+	return names, nil
+}
+
+// ServiceRing returns the ring file of a service
+func ServiceRing(name string) (*Ring, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return OpenRing(filepath.Join(dir, "services", name+".ring"), DefaultCapacity), nil
+}
+
+// Summary describes a series of samples
+type Summary struct {
+	Last       Sample
+	AvgCPU     float64
+	MaxCPU     float64
+	MaxMemory  uint64
+	MemoryDiff int64 // Memory change from the first to the last sample, in bytes
+}
+
+// Summarize returns statistics for a non-empty series of samples
+func Summarize(samples []Sample) Summary {
+	s := Summary{Last: samples[len(samples)-1]}
+	var total float64
+	for _, sample := range samples {
+		total += sample.CPUPercent
+		if sample.CPUPercent > s.MaxCPU {
+			s.MaxCPU = sample.CPUPercent
+		}
+		if sample.MemoryUsage > s.MaxMemory {
+			s.MaxMemory = sample.MemoryUsage
+		}
+	}
+	s.AvgCPU = total / float64(len(samples))
+	s.MemoryDiff = int64(s.Last.MemoryUsage) - int64(samples[0].MemoryUsage)
+	return s
+}
+
+// CPUValues returns the CPU usage of each sample
+func CPUValues(samples []Sample) []float64 {
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.CPUPercent
+	}
+	return values
+}
+
+// MemoryValues returns the memory usage of each sample
+func MemoryValues(samples []Sample) []float64 {
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = float64(s.MemoryUsage)
+	}
+	return values
+}
+
+// Sparkline renders values as a line of bars at most width characters wide.
+// Values are averaged into buckets when there are more than width of them.
+func Sparkline(values []float64, width int) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+
+	if len(values) > width {
+		buckets := make([]float64, width)
+		for i := range buckets {
+			start := i * len(values) / width
+			end := (i + 1) * len(values) / width
+			var sum float64
+			for _, v := range values[start:end] {
+				sum += v
+			}
+			buckets[i] = sum / float64(end-start)
+		}
+		values = buckets
+	}
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if max > min {
+			level = int((v - min) / (max - min) * float64(len(sparkLevels)-1))
+		}
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCapacity is the number of samples a ring file holds, two hours at
+// the default interval
+const DefaultCapacity = 1440
+
+// Ring file layout: a header with the capacity, the slot the next sample is
+// written to and the number of samples stored, followed by capacity fixed
+// size records. Old samples are overwritten once the file is full.
+const (
+	headerSize = 12
+	recordSize = 32
+)
+
+// Sample is a single resource usage measurement
+type Sample struct {
+	Time          time.Time
+	CPUPercent    float64
+	MemoryUsage   uint64 // in bytes
+	MemoryPercent float64
+}
+
+// header is the first part of a ring file
+type header struct {
+	Capacity uint32
+	Next     uint32
+	Count    uint32
+}
+
+// Ring is a fixed size file of samples for one process or service
+type Ring struct {
+	path     string
+	capacity uint32
+}
+
+// OpenRing returns the ring file at path, it is created on the first Append
+func OpenRing(path string, capacity int) *Ring {
+	return &Ring{path: path, capacity: uint32(capacity)}
+}
+
+// Append writes a sample, overwriting the oldest one when the ring is full
+func (r *Ring) Append(s Sample) error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open metrics file: %w", err)
+	}
+	defer f.Close()
+
+	h, err := readHeader(f)
+	if err != nil || h.Capacity == 0 {
+		// New or unreadable file, start over
+		h = header{Capacity: r.capacity}
+	}
+
+	record := make([]byte, recordSize)
+	binary.LittleEndian.PutUint64(record[0:], uint64(s.Time.UnixNano()))
+	binary.LittleEndian.PutUint64(record[8:], math.Float64bits(s.CPUPercent))
+	binary.LittleEndian.PutUint64(record[16:], s.MemoryUsage)
+	binary.LittleEndian.PutUint64(record[24:], math.Float64bits(s.MemoryPercent))
+	if _, err := f.WriteAt(record, headerSize+int64(h.Next)*recordSize); err != nil {
+		return fmt.Errorf("failed to write sample: %w", err)
+	}
+
+	h.Next = (h.Next + 1) % h.Capacity
+	if h.Count < h.Capacity {
+		h.Count++
+	}
+	return writeHeader(f, h)
+}
+
+// Samples returns the stored samples taken at or after since, oldest first
+func (r *Ring) Samples(since time.Time) ([]Sample, error) {
+	f, err := os.Open(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	h, err := readHeader(f)
+	if err != nil {
+		return nil, err
+	}
+	if h.Count == 0 {
+		return nil, nil
+	}
+
+	data := make([]byte, int(h.Capacity)*recordSize)
+	if _, err := f.ReadAt(data, headerSize); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read samples: %w", err)
+	}
+
+	// The oldest sample is at Next once the ring has wrapped around
+	start := uint32(0)
+	if h.Count == h.Capacity {
+		start = h.Next
+	}
+
+	samples := make([]Sample, 0, h.Count)
+	for i := uint32(0); i < h.Count; i++ {
+		record := data[int((start+i)%h.Capacity)*recordSize:][:recordSize]
+		s := Sample{
+			Time:          time.Unix(0, int64(binary.LittleEndian.Uint64(record[0:]))),
+			CPUPercent:    math.Float64frombits(binary.LittleEndian.Uint64(record[8:])),
+			MemoryUsage:   binary.LittleEndian.Uint64(record[16:]),
+			MemoryPercent: math.Float64frombits(binary.LittleEndian.Uint64(record[24:])),
+		}
+		if !s.Time.Before(since) {
+			samples = append(samples, s)
+		}
+	}
+	return samples, nil
+}
+
+// readHeader reads the header of a ring file
+func readHeader(f *os.File) (header, error) {
+	var h header
+	buf := make([]byte, headerSize)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		return h, err
+	}
+	h.Capacity = binary.LittleEndian.Uint32(buf[0:])
+	h.Next = binary.LittleEndian.Uint32(buf[4:])
+	h.Count = binary.LittleEndian.Uint32(buf[8:])
+	if (h.Capacity > 0 && h.Next >= h.Capacity) || h.Count > h.Capacity {
+		return h, fmt.Errorf("corrupt metrics file %s", f.Name())
+	}
+	return h, nil
+}
+
+// writeHeader writes the header of a ring file
+func writeHeader(f *os.File, h header) error {
+	buf := make([]byte, headerSize)
+	binary.LittleEndian.PutUint32(buf[0:], h.Capacity)
+	binary.LittleEndian.PutUint32(buf[4:], h.Next)
+	binary.LittleEndian.PutUint32(buf[8:], h.Count)
+	_, err := f.WriteAt(buf, 0)
+	return err
+}
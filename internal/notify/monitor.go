@@ -0,0 +1,369 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/events"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+	"github.com/afomera/spin/internal/procfile"
+	"github.com/afomera/spin/internal/script"
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/userconfig"
+)
+
+// debugLog writes the debug messages of the monitor, --verbose=notify
+var debugLog = logger.For("notify")
+
+// DefaultInterval is the time between two checks of the monitor
+const DefaultInterval = 5 * time.Second
+
+// missedChecks is how many checks in a row a command has to be gone before
+// it counts as crashed, so a restart in progress isn't reported
+const missedChecks = 3
+
+// crashHookCooldown is how long the on_process_crash hook of a process waits
+// before it runs again, so a process in a restart loop doesn't flood it
+const crashHookCooldown = time.Minute
+
+// monitor remembers what the previous checks saw
+type monitor struct {
+	cfg     *config.Config
+	self    string         // Name the monitor itself runs under
+	missed  map[string]int // Checks in a row a tmux process' command was gone
+	crashed map[string]bool
+	health  map[string]string // Last health status of each service
+	idle    *docker.IdleTracker
+
+	mu          sync.Mutex
+	idleStopped map[string]bool // Services stopped for being idle, started again on demand
+}
+
+// Monitor publishes events for tmux processes of the app whose command exits
+// unexpectedly and services whose health changes, and sends notifications for
+// the crash and health events of the app until ctx is cancelled. Natively
+// supervised processes publish their own exit. self is the process name the
+// monitor runs under, it is left out.
+//
+// Services with an idle_timeout are stopped once they go that long without
+// network traffic, and started again when a process that needs them starts.
+func Monitor(ctx context.Context, cfg *config.Config, self string, interval time.Duration) {
+	go notifyEvents(ctx, cfg)
+
+	m := &monitor{
+		cfg:         cfg,
+		self:        self,
+		missed:      make(map[string]int),
+		crashed:     make(map[string]bool),
+		health:      make(map[string]string),
+		idle:        docker.NewIdleTracker(),
+		idleStopped: make(map[string]bool),
+	}
+	go m.startOnDemand(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.checkProcesses()
+		m.checkServices()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkProcesses notifies about tmux processes whose command has exited while
+// their session is still tracked. Stopped processes are removed from the
+// store and with it from the check.
+func (m *monitor) checkProcesses() {
+	manager := process.GetManager(m.cfg)
+	manager.SetQuiet(true)
+
+	entries, err := manager.Store().Entries()
+	if err != nil {
+		debugLog.Debugf("%v\n", err)
+		return
+	}
+
+	appName := process.SanitizeAppName(m.cfg.Name)
+	seen := make(map[string]bool)
+	for _, info := range entries {
+		if info.Type == process.ProcessTypeDocker || info.Backend == userconfig.BackendNative {
+			continue
+		}
+		if process.SanitizeAppName(info.AppName) != appName || info.Name == m.self {
+			continue
+		}
+		seen[info.Name] = true
+
+		if process.CommandRunning(info) {
+			m.missed[info.Name] = 0
+			m.crashed[info.Name] = false
+			continue
+		}
+
+		m.missed[info.Name]++
+		if m.missed[info.Name] >= missedChecks && !m.crashed[info.Name] {
+			m.crashed[info.Name] = true
+			publish(events.Event{Type: events.ProcessCrashed, App: m.cfg.Name, Name: info.Name})
+		}
+	}
+
+	for name := range m.missed {
+		if !seen[name] {
+			delete(m.missed, name)
+			delete(m.crashed, name)
+		}
+	}
+}
+
+// checkServices publishes an event when the health of a running service
+// changes
+func (m *monitor) checkServices() {
+	if len(m.cfg.Services) == 0 {
+		return
+	}
+
+	dm, err := docker.NewServiceManager("")
+	if err != nil {
+		debugLog.Debugf("%v\n", err)
+		return
+	}
+	defer dm.Client().Close()
+
+	for name := range m.cfg.Services {
+		if !dm.IsRunning(name) {
+			delete(m.health, name)
+			m.idle.Forget(name)
+			continue
+		}
+		if m.stopIfIdle(dm, name) {
+			delete(m.health, name)
+			continue
+		}
+		status, err := dm.HealthStatus(name)
+		if err != nil {
+			debugLog.Debugf("%v\n", err)
+			continue
+		}
+
+		previous := m.health[name]
+		m.health[name] = status
+		switch {
+		case status == "unhealthy" && previous != "unhealthy":
+			publish(events.Event{Type: events.ServiceUnhealthy, App: m.cfg.Name, Name: name})
+		case status == "healthy" && previous != "" && previous != "healthy":
+			publish(events.Event{Type: events.ServiceHealthy, App: m.cfg.Name, Name: name})
+		}
+	}
+}
+
+// stopIfIdle stops a service that has gone without network traffic for its
+// idle_timeout and reports whether it did. Shared services, which other
+// projects may use, and paused services are left alone, and so are services
+// with connected clients or running processes that need them: an app holding
+// idle pooled connections would break.
+func (m *monitor) stopIfIdle(dm *docker.ServiceManager, name string) bool {
+	service := m.cfg.Services[name]
+	timeout := service.IdleAfter()
+	if timeout == 0 || service.Shared || dm.IsPaused(name) {
+		m.idle.Forget(name)
+		return false
+	}
+
+	bytes, err := dm.NetworkBytes(name)
+	if err != nil {
+		debugLog.Debugf("%v\n", err)
+		return false
+	}
+	if m.idle.Observe(name, bytes, time.Now()) < timeout {
+		return false
+	}
+	if m.inUse(dm, name) {
+		m.idle.Forget(name)
+		return false
+	}
+
+	m.idle.Forget(name)
+	if err := dm.StopService(name, service); err != nil {
+		debugLog.Debugf("failed to stop idle service %s: %v\n", name, err)
+		return false
+	}
+	m.mu.Lock()
+	m.idleStopped[name] = true
+	m.mu.Unlock()
+
+	message := fmt.Sprintf("no network traffic for %s", timeout)
+	fmt.Printf("%sStopped %s: %s%s\n", logger.Yellow, name, message, logger.Reset)
+	publish(events.Event{Type: events.ServiceIdle, App: m.cfg.Name, Name: name, Message: message})
+	return true
+}
+
+// inUse reports whether clients are connected to a service or a running
+// process of the app needs it. Services whose connections can't be read
+// count as used.
+func (m *monitor) inUse(dm *docker.ServiceManager, name string) bool {
+	if port := m.cfg.Services[name].Port; port != 0 {
+		clients, err := dm.HasClients(name, port)
+		if err != nil {
+			debugLog.Debugf("not stopping %s, failed to read its connections: %v\n", name, err)
+			return true
+		}
+		if clients {
+			debugLog.Debugf("not stopping %s, clients are connected\n", name)
+			return true
+		}
+	}
+
+	manager := process.GetManager(m.cfg)
+	manager.SetQuiet(true)
+	for _, p := range manager.ListProcesses() {
+		if p.Type == process.ProcessTypeDocker || strings.HasPrefix(p.Name, procfile.ReservedPrefix) {
+			continue
+		}
+		for _, needed := range m.cfg.ProcessServices(p.Name) {
+			if needed == name {
+				debugLog.Debugf("not stopping %s, %s needs it\n", name, p.Name)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startOnDemand starts the idle services a process needs when it starts or
+// restarts, until ctx is cancelled. A process needs the services listed for
+// it under processes.services, or those of dependencies.services.
+func (m *monitor) startOnDemand(ctx context.Context) {
+	filter := events.Filter{
+		App:   m.cfg.Name,
+		Types: []events.Type{events.ProcessStarted, events.ProcessRestarted},
+	}
+
+	err := events.Follow(ctx, filter, func(e events.Event) {
+		needed := m.cfg.ProcessServices(e.Name)
+
+		m.mu.Lock()
+		var names []string
+		for _, name := range needed {
+			if m.idleStopped[name] {
+				names = append(names, name)
+			}
+		}
+		m.mu.Unlock()
+		if len(names) == 0 {
+			return
+		}
+
+		dm, err := docker.NewServiceManager("")
+		if err != nil {
+			debugLog.Debugf("%v\n", err)
+			return
+		}
+		defer dm.Client().Close()
+
+		fmt.Printf("%sStarting idle services for %s: %s%s\n", logger.Blue, e.Name, strings.Join(names, ", "), logger.Reset)
+		if err := dm.StartServices(m.cfg.Services, names); err != nil {
+			logger.Errorf("failed to start idle services: %v", err)
+			return
+		}
+		m.mu.Lock()
+		for _, name := range names {
+			delete(m.idleStopped, name)
+		}
+		m.mu.Unlock()
+	})
+	if err != nil {
+		debugLog.Debugf("failed to follow events: %v\n", err)
+	}
+}
+
+// publish records an event, failures are only logged
+func publish(e events.Event) {
+	if err := events.Publish(e); err != nil {
+		debugLog.Debugf("failed to publish event: %v\n", err)
+	}
+}
+
+// notifyEvents sends a notification for every crash and unhealthy service of
+// the app published to the event stream, and for services that recover. The
+// on_process_crash and on_service_unhealthy hooks of the app run for them too.
+func notifyEvents(ctx context.Context, cfg *config.Config) {
+	unhealthy := make(map[string]bool)
+	crashHooked := make(map[string]time.Time) // Last run of the crash hook of each process
+	filter := events.Filter{
+		App:   cfg.Name,
+		Types: []events.Type{events.ProcessCrashed, events.ServiceUnhealthy, events.ServiceHealthy},
+	}
+
+	err := events.Follow(ctx, filter, func(e events.Event) {
+		switch e.Type {
+		case events.ProcessCrashed:
+			message := fmt.Sprintf("%s of %s exited unexpectedly, see spin logs %s", e.Name, e.App, e.Name)
+			if e.Message != "" {
+				message = fmt.Sprintf("%s of %s exited unexpectedly (%s), see spin logs %s", e.Name, e.App, e.Message, e.Name)
+			}
+			send(fmt.Sprintf("spin: %s exited", e.Name), message)
+			if last, ok := crashHooked[e.Name]; ok && time.Since(last) < crashHookCooldown {
+				debugLog.Debugf("skipped the on_process_crash hook of %s, it ran %s ago\n", e.Name, time.Since(last).Round(time.Second))
+				break
+			}
+			crashHooked[e.Name] = time.Now()
+			go runHook(cfg, "on_process_crash", e)
+		case events.ServiceUnhealthy:
+			unhealthy[e.Name] = true
+			send(fmt.Sprintf("spin: %s is unhealthy", e.Name),
+				fmt.Sprintf("The health check of service %s is failing, see spin services logs %s", e.Name, e.Name))
+			go runHook(cfg, "on_service_unhealthy", e)
+		case events.ServiceHealthy:
+			if unhealthy[e.Name] {
+				delete(unhealthy, e.Name)
+				send(fmt.Sprintf("spin: %s is healthy", e.Name), fmt.Sprintf("Service %s is healthy again", e.Name))
+			}
+		}
+	})
+	if err != nil {
+		debugLog.Debugf("failed to follow events: %v\n", err)
+	}
+}
+
+// runHook runs the hook point of spin.config.json an event triggers,
+// failures are only logged
+func runHook(cfg *config.Config, point string, e events.Event) {
+	if len(cfg.Hooks.Steps(point)) == 0 && len(cfg.Hooks.WebhooksFor(point)) == 0 {
+		return
+	}
+	manager := script.NewManager()
+	if err := script.LoadAndRegisterScripts(manager, "spin.config.json"); err != nil {
+		logger.Warnf("%s hook: failed to load scripts: %v", point, err)
+		return
+	}
+	hookEvent := script.HookEvent{Hook: point, App: e.App, Name: e.Name, Message: e.Message, Time: e.Time}
+	err := manager.RunHook(cfg.Hooks, hookEvent, script.LifecycleOptions{
+		App:         cfg.Name,
+		WorkDir:     ".",
+		Env:         cfg.GetEnvVars("development"),
+		MaxParallel: cfg.Hooks.MaxParallel,
+	})
+	if err != nil {
+		logger.Warnf("%s hook: %v", point, err)
+	}
+}
+
+// send prints a notification to the monitor's log and delivers it, failures
+// are only logged
+func send(title string, message string) {
+	fmt.Printf("%s%s: %s%s\n", logger.Yellow, title, message, logger.Reset)
+	if err := Send(title, message); err != nil {
+		debugLog.Debugf("failed to send notification: %v\n", err)
+	}
+}
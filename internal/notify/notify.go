@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/userconfig"
+)
+
+// webhookTimeout bounds how long posting to the webhook may take
+const webhookTimeout = 5 * time.Second
+
+// Notification is a single event worth telling the user about
+type Notification struct {
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Send shows a desktop notification and posts it to the configured webhook,
+// if notifications are enabled in the user configuration
+func Send(title string, message string) error {
+	cfg, err := userconfig.Load()
+	if err != nil {
+		return err
+	}
+	if !cfg.Notifications {
+		return nil
+	}
+	return Deliver(cfg, Notification{Title: title, Message: message, Time: time.Now()})
+}
+
+// Deliver sends a notification regardless of whether notifications are
+// enabled, errors of the desktop and webhook notifiers are combined
+func Deliver(cfg *userconfig.Config, n Notification) error {
+	var errs []error
+	if err := desktop(n); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.NotificationWebhook != "" {
+		if err := webhook(cfg.NotificationWebhook, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// desktop shows a notification with the platform's notification tool
+func desktop(n Notification) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(n.Message), appleScriptString(n.Title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux":
+		cmd = exec.Command("notify-send", "--app-name=spin", n.Title, n.Message)
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if output = bytes.TrimSpace(output); len(output) > 0 {
+			return fmt.Errorf("failed to show desktop notification: %v: %s", err, output)
+		}
+		return fmt.Errorf("failed to show desktop notification: %w", err)
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// webhook posts a notification as JSON to url
+func webhook(url string, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
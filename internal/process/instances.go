@@ -0,0 +1,59 @@
+package process
+
+import (
+	"sort"
+
+	"github.com/afomera/spin/internal/config"
+)
+
+// InstanceGroup is a process scaled to several instances, like worker.1 and
+// worker.2 running worker
+type InstanceGroup struct {
+	Type      string   // Procfile entry the instances run
+	Instances []string // Names of the instances, sorted by number
+	Running   int      // Number of instances that are running
+}
+
+// GroupInstances returns the processes with several instances among
+// processes, sorted by type
+func GroupInstances(processes []*Process) []InstanceGroup {
+	groups := make(map[string]*InstanceGroup)
+	for _, p := range processes {
+		if p.Type == ProcessTypeDocker || config.InstanceNumber(p.Name) == 0 {
+			continue
+		}
+		processType := config.ProcessType(p.Name)
+		group, ok := groups[processType]
+		if !ok {
+			group = &InstanceGroup{Type: processType}
+			groups[processType] = group
+		}
+		group.Instances = append(group.Instances, p.Name)
+		if p.Status == StatusRunning {
+			group.Running++
+		}
+	}
+
+	result := make([]InstanceGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.Slice(group.Instances, func(i, j int) bool {
+			return config.InstanceNumber(group.Instances[i]) < config.InstanceNumber(group.Instances[j])
+		})
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Type < result[j].Type })
+	return result
+}
+
+// Instances returns the names of the processes running name, the process
+// itself or its instances when it is scaled
+func Instances(processes []*Process, name string) []string {
+	var names []string
+	for _, p := range processes {
+		if p.Name == name || (config.InstanceNumber(p.Name) > 0 && config.ProcessType(p.Name) == name) {
+			names = append(names, p.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
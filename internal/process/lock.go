@@ -0,0 +1,164 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/afomera/spin/internal/filelock"
+)
+
+// ProjectLock is held by the spin up starting a project, so that two of them
+// don't start the same tmux sessions and containers at once
+type ProjectLock struct {
+	path  string
+	owner LockOwner
+}
+
+// LockOwner is the process holding the lock of a project
+type LockOwner struct {
+	PID       int       `json:"pid"`
+	Dir       string    `json:"dir"`                  // Directory spin up was started for
+	StartedAt time.Time `json:"started_at,omitempty"` // Start of the process, which tells it apart from a later one reusing the PID
+}
+
+// alive checks if the owner still runs
+func (o LockOwner) alive() bool {
+	if !IsAlive(o.PID) {
+		return false
+	}
+	if o.StartedAt.IsZero() {
+		return true
+	}
+	started := processStartTime(o.PID)
+	if started.IsZero() {
+		return true
+	}
+	diff := started.Sub(o.StartedAt)
+	return diff > -time.Second && diff < time.Second
+}
+
+// LockedError is returned when a live process holds the lock of a project
+type LockedError struct {
+	Project string
+	Owner   LockOwner
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("spin up is already running for %s (PID %d in %s)", e.Project, e.Owner.PID, e.Owner.Dir)
+}
+
+// lockPath returns the lock file of a project
+func lockPath(project string) (string, error) {
+	spinDir, err := getSpinDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(spinDir, "locks", SanitizeAppName(project)+".lock"), nil
+}
+
+// AcquireLock takes the lock of a project for the current process. Locks of
+// processes that are gone are stale and replaced. A *LockedError is returned
+// when another process holds the lock.
+func AcquireLock(project string, dir string) (*ProjectLock, error) {
+	path, err := lockPath(project)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	// Stale locks are only checked and replaced by one process at a time, so
+	// two of them can't both remove a stale lock and take it
+	guard, err := filelock.Acquire(path + ".guard")
+	if err != nil {
+		return nil, err
+	}
+	defer guard.Release()
+
+	if current, err := readLock(path); err == nil && current.alive() {
+		return nil, &LockedError{Project: project, Owner: current}
+	} else if !os.IsNotExist(err) {
+		os.Remove(path)
+	}
+
+	owner := LockOwner{PID: os.Getpid(), Dir: dir, StartedAt: processStartTime(os.Getpid())}
+	data, err := json.Marshal(owner)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take the lock of %s: %w", project, err)
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return &ProjectLock{path: path, owner: owner}, nil
+}
+
+// TakeoverLock takes the lock of a project from the process holding it,
+// which is stopped first: asked to terminate, then killed if it is still
+// running after DefaultStopTimeout
+func TakeoverLock(project string, dir string) (*ProjectLock, error) {
+	path, err := lockPath(project)
+	if err != nil {
+		return nil, err
+	}
+	if current, err := readLock(path); err == nil && current.alive() && current.PID != os.Getpid() {
+		if err := stopLockOwner(current); err != nil {
+			return nil, err
+		}
+	}
+	return AcquireLock(project, dir)
+}
+
+// stopLockOwner ends the process holding a lock
+func stopLockOwner(owner LockOwner) error {
+	proc, err := os.FindProcess(owner.PID)
+	if err != nil {
+		return err
+	}
+	if err := proc.Signal(DefaultStopSignal); err != nil {
+		return proc.Kill()
+	}
+	deadline := time.Now().Add(DefaultStopTimeout)
+	for time.Now().Before(deadline) {
+		if !owner.alive() {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err := proc.Kill(); err != nil && owner.alive() {
+		return fmt.Errorf("failed to stop PID %d: %w", owner.PID, err)
+	}
+	return nil
+}
+
+// readLock reads the owner of a lock file
+func readLock(path string) (LockOwner, error) {
+	var owner LockOwner
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return owner, err
+	}
+	err = json.Unmarshal(data, &owner)
+	return owner, err
+}
+
+// Release gives up the lock, unless another process has taken it over
+func (l *ProjectLock) Release() error {
+	current, err := readLock(l.path)
+	if err != nil || current.PID != l.owner.PID {
+		return nil
+	}
+	return os.Remove(l.path)
+}
@@ -10,6 +10,8 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,9 +19,12 @@ import (
 	"time"
 
 	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/events"
 	"github.com/afomera/spin/internal/logger"
 	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/spinerr"
 	"github.com/afomera/spin/internal/tracker"
+	"github.com/afomera/spin/internal/userconfig"
 	"github.com/docker/docker/api/types"
 	psutil "github.com/shirou/gopsutil/v3/process"
 )
@@ -47,15 +52,40 @@ type Process struct {
 	IsDebug       bool   // Whether this is a debug session
 	OutputWriter  io.Writer
 	TmuxSession   string // Name of the tmux session
+	Backend       string // Backend supervising the process
 	CPUPercent    float64
 	MemoryUsage   uint64 // in bytes
 	MemoryPercent float64
 	LastUpdated   time.Time
+	StartedAt     time.Time // When the process was last started or restarted
 	Type          ProcessType
 	ContainerID   string // Docker container ID
 	Image         string // Docker image name
 }
 
+// Uptime returns how long the process has been running, zero when unknown
+func (p *Process) Uptime() time.Duration {
+	if p.StartedAt.IsZero() {
+		return 0
+	}
+	return time.Since(p.StartedAt)
+}
+
+// FormatUptime formats how long something has been running, e.g. 3h12m
+func FormatUptime(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+}
+
 // SanitizeAppName replaces characters that could cause issues in tmux session names
 func SanitizeAppName(name string) string {
 	// Replace dots with dashes
@@ -64,24 +94,52 @@ func SanitizeAppName(name string) string {
 	return name
 }
 
-// NewDockerProcess creates a new Docker process
-func NewDockerProcess(name string, containerID string, image string) *Process {
-	// Extract app name from process name (format: appname-processname)
-	parts := strings.SplitN(name, "-", 2)
-	appName := SanitizeAppName(parts[0])
-	processName := name
-	if len(parts) > 1 {
-		processName = parts[1]
+// SessionName returns the tmux session a process of an app runs in, like
+// spin-myapp-web. tmux doesn't allow dots in session names, so instances like
+// worker.2 use worker-2.
+func SessionName(appName string, name string) string {
+	return "spin-" + SanitizeAppName(appName) + "-" + SanitizeAppName(name)
+}
+
+// OutputDir returns the directory the output directories of apps are in
+func OutputDir() (string, error) {
+	spinDir, err := getSpinDir()
+	if err != nil {
+		return "", err
 	}
+	return filepath.Join(spinDir, "output"), nil
+}
 
+// LogDir returns the output directory of an app
+func LogDir(appName string) (string, error) {
+	dir, err := OutputDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SanitizeAppName(appName)), nil
+}
+
+// LogPath returns the output file of a process, in the output directory of
+// its app
+func LogPath(appName string, name string) (string, error) {
+	dir, err := LogDir(appName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".log"), nil
+}
+
+// NewDockerProcess creates a new Docker process for a service of an app
+func NewDockerProcess(appName string, name string, containerID string, image string) *Process {
 	return &Process{
-		Name:        processName,
+		Name:        name,
 		AppName:     appName,
 		Status:      StatusRunning,
 		Type:        ProcessTypeDocker,
 		ContainerID: containerID,
 		Image:       image,
 		LastUpdated: time.Now(),
+		StartedAt:   time.Now(),
 	}
 }
 
@@ -92,7 +150,8 @@ type Manager struct {
 	mu        sync.RWMutex
 	wg        sync.WaitGroup
 	store     *Store
-	quiet     bool // When true, suppress stdout/stderr output
+	quiet     bool   // When true, suppress stdout/stderr output
+	backend   string // Backend new processes are started with
 }
 
 var (
@@ -107,6 +166,7 @@ func GetManager(cfg *config.Config) *Manager {
 			processes: make(map[string]*Process),
 			config:    cfg,
 			quiet:     false, // Initialize quiet mode to false
+			backend:   backend(),
 		}
 		// Create store after manager is initialized
 		instance.store = NewStore(instance)
@@ -122,10 +182,25 @@ func (m *Manager) SetQuiet(quiet bool) {
 	m.quiet = quiet
 }
 
+// Store returns the persistent process store
+func (m *Manager) Store() *Store {
+	return m.store
+}
+
+// debugLog writes the debug messages of processes, --verbose=process
+var debugLog = logger.For("process")
+
 // debugf prints debug messages using the logger
 func (m *Manager) debugf(format string, args ...interface{}) {
 	if !m.quiet {
-		logger.Debugf(format, args...)
+		debugLog.Debugf(format, args...)
+	}
+}
+
+// publish records an event of a process, failures are only logged
+func (m *Manager) publish(t events.Type, appName string, name string, message string) {
+	if err := events.Publish(events.Event{Type: t, App: appName, Name: name, Message: message}); err != nil {
+		m.debugf("Warning: Failed to publish %s event: %v\n", t, err)
 	}
 }
 
@@ -164,49 +239,100 @@ func (m *Manager) FindProcess(name string) (*Process, error) {
 	process, exists := m.processes[name]
 	m.mu.RUnlock()
 	if exists {
-		m.debugf("Debug: Found process %s in memory\n", name)
+		m.debugf("Found process %s in memory\n", name)
 		return process, nil
 	}
 
 	// Then check the store
 	info, err := m.store.GetProcess(name)
 	if err != nil {
-		m.debugf("Debug: Process %s not found in store: %v\n", name)
+		m.debugf("Process %s not found in store: %v\n", name, err)
 		return nil, err
 	}
-	m.debugf("Debug: Found process %s in store (PID: %d)\n", name, info.Pid)
+	m.debugf("Found process %s in store (PID: %d)\n", name, info.Pid)
+
+	// Containers of services have no PID of their own
+	if info.Type == ProcessTypeDocker {
+		process = &Process{
+			Name:          info.Name,
+			AppName:       info.AppName,
+			Status:        info.Status,
+			Type:          ProcessTypeDocker,
+			ContainerID:   info.ContainerID,
+			Image:         info.Image,
+			CPUPercent:    info.CPUPercent,
+			MemoryUsage:   info.MemoryUsage,
+			MemoryPercent: info.MemoryPercent,
+			LastUpdated:   info.LastUpdated,
+			StartedAt:     info.StartedAt,
+		}
+
+		m.mu.Lock()
+		m.processes[name] = process
+		m.mu.Unlock()
+
+		return process, nil
+	}
 
 	// Try to find the process
 	proc, err := os.FindProcess(info.Pid)
 	if err != nil {
-		m.debugf("Debug: Failed to find process %s with PID %d: %v\n", name, info.Pid, err)
+		m.debugf("Failed to find process %s with PID %d: %v\n", name, info.Pid, err)
 		return nil, fmt.Errorf("failed to find process: %w", err)
 	}
 
 	// Check if process is still running
 	if err := proc.Signal(syscall.Signal(0)); err != nil {
-		m.debugf("Debug: Process %s (PID: %d) is not running: %v\n", name, info.Pid, err)
+		m.debugf("Process %s (PID: %d) is not running: %v\n", name, info.Pid, err)
 		// Remove from store since it's not running
 		m.store.RemoveProcess(name)
 		return nil, fmt.Errorf("process is not running: %w", err)
 	}
 
-	m.debugf("Debug: Process %s (PID: %d) is running\n", name, info.Pid)
+	m.debugf("Process %s (PID: %d) is running\n", name, info.Pid)
 
-	// Get spin directory for output file
-	spinDir, err := getSpinDir()
+	startedAt := info.StartedAt
+	if startedAt.IsZero() {
+		startedAt = processStartTime(info.Pid)
+	}
+
+	// Get the output file in the app's output directory
+	outputFile, err := LogPath(info.AppName, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spin directory: %w", err)
 	}
 
+	// Natively supervised processes are tracked by their supervisor's PID
+	if info.Backend == userconfig.BackendNative {
+		process = &Process{
+			Name:          info.Name,
+			AppName:       info.AppName,
+			Command:       &exec.Cmd{Process: proc},
+			Status:        info.Status,
+			OutputFile:    outputFile,
+			Backend:       userconfig.BackendNative,
+			CPUPercent:    info.CPUPercent,
+			MemoryUsage:   info.MemoryUsage,
+			MemoryPercent: info.MemoryPercent,
+			LastUpdated:   info.LastUpdated,
+			StartedAt:     startedAt,
+		}
+
+		m.mu.Lock()
+		m.processes[name] = process
+		m.mu.Unlock()
+
+		return process, nil
+	}
+
 	// Get tmux session name with sanitized app name prefix
-	sessionName := fmt.Sprintf("spin-%s-%s", SanitizeAppName(info.AppName), name)
+	sessionName := SessionName(info.AppName, name)
 
 	// Check if tmux session exists and get pane PID
 	listCmd := exec.Command("tmux", "list-panes", "-t", sessionName, "-F", "#{pane_pid}")
 	output, err := listCmd.Output()
 	if err != nil {
-		m.debugf("Debug: No tmux session for process %s\n", name)
+		m.debugf("No tmux session for process %s\n", name)
 		return nil, fmt.Errorf("process has no tmux session")
 	}
 
@@ -229,14 +355,16 @@ func (m *Manager) FindProcess(name string) (*Process, error) {
 		AppName:       info.AppName,
 		Command:       &exec.Cmd{Process: proc},
 		Status:        info.Status,
-		OutputFile:    filepath.Join(spinDir, "output", fmt.Sprintf("%s.log", name)),
+		OutputFile:    outputFile,
 		TmuxSession:   sessionName,
+		Backend:       userconfig.BackendTmux,
 		CPUPercent:    info.CPUPercent,
 		MemoryUsage:   info.MemoryUsage,
 		MemoryPercent: info.MemoryPercent,
 		LastUpdated:   info.LastUpdated,
+		StartedAt:     startedAt,
 	}
-	m.debugf("Debug: Found tmux session for process %s\n", name)
+	m.debugf("Found tmux session for process %s\n", name)
 
 	// Add to manager's processes map
 	m.mu.Lock()
@@ -251,20 +379,19 @@ func (m *Manager) StartProcess(appName string, name string, command string, args
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.debugf("Debug: Starting process %s: %s %v\n", name, command, args)
+	m.debugf("Starting process %s: %s %v\n", name, command, args)
 
 	if _, exists := m.processes[name]; exists {
-		return fmt.Errorf("process %s is already running", name)
+		return spinerr.New(spinerr.Process, fmt.Sprintf("process %s is already running", name)).
+			WithFix(fmt.Sprintf("Stop it first with 'spin down %s'", name))
 	}
 
 	// Get spin directory
-	spinDir, err := getSpinDir()
+	// Create app-specific output directory
+	outputDir, err := LogDir(appName)
 	if err != nil {
 		return fmt.Errorf("failed to create spin directory: %w", err)
 	}
-
-	// Create app-specific output directory
-	outputDir := filepath.Join(spinDir, "output", SanitizeAppName(appName))
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
@@ -275,10 +402,27 @@ func (m *Manager) StartProcess(appName string, name string, command string, args
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
 
+	// Combine command and args into a single string
+	fullCmd := command
+	if len(args) > 0 {
+		fullCmd += " " + strings.Join(args, " ")
+	}
+
+	env, overrides := m.mergeProcessEnv(name, env)
+	if err := m.saveEnv(appName, name, env); err != nil {
+		m.debugf("Warning: Failed to record environment of %s: %v\n", name, err)
+	}
+
+	if m.backend == userconfig.BackendNative {
+		f.Close()
+		return m.startNativeProcess(appName, name, fullCmd, env, workDir, outputFile, isDebugCommand(command, args))
+	}
+
 	// Ensure tmux is set up
 	if err := setupTmux(); err != nil {
 		f.Close()
-		return fmt.Errorf("failed to set up tmux: %w", err)
+		return spinerr.Wrap(spinerr.Process, "failed to set up tmux", err).
+			WithFix("Install tmux, or supervise processes without it with 'spin config set-backend native'")
 	}
 
 	// Get config path
@@ -289,23 +433,43 @@ func (m *Manager) StartProcess(appName string, name string, command string, args
 	}
 	configPath := filepath.Join(home, ".spin", "tmux.conf")
 
-	// Create a new tmux session for the process with sanitized app name prefix
-	sessionName := fmt.Sprintf("spin-%s-%s", SanitizeAppName(appName), name)
-	createCmd := exec.Command("tmux", "-f", configPath, "new-session", "-d", "-s", sessionName, "-c", workDir)
+	// Create a new tmux session for the process with sanitized app name prefix.
+	// Overrides are passed with -e since a running tmux server keeps its own
+	// environment.
+	sessionName := SessionName(appName, name)
+	createArgs := []string{"-f", configPath, "new-session", "-d", "-s", sessionName, "-c", workDir}
+	envFlag := tmuxSessionEnv()
+	if envFlag {
+		for _, pair := range overrides {
+			createArgs = append(createArgs, "-e", pair)
+		}
+	}
+
+	createCmd := exec.Command("tmux", createArgs...)
 	createCmd.Env = env
 	if err := createCmd.Run(); err != nil {
 		f.Close()
 		return fmt.Errorf("failed to create tmux session: %w", err)
 	}
 
-	var sendCmd *exec.Cmd
-
-	// Combine command and args into a single string
-	fullCmd := command
-	if len(args) > 0 {
-		fullCmd += " " + strings.Join(args, " ")
+	// tmux before 3.2 has no -e, the overrides are set on the session instead
+	// and its shell is restarted to pick them up
+	if !envFlag && len(overrides) > 0 {
+		for _, pair := range overrides {
+			key, value, _ := strings.Cut(pair, "=")
+			if err := exec.Command("tmux", "set-environment", "-t", sessionName, key, value).Run(); err != nil {
+				f.Close()
+				return fmt.Errorf("failed to set the environment of the tmux session: %w", err)
+			}
+		}
+		if err := exec.Command("tmux", "respawn-pane", "-k", "-t", sessionName, "-c", workDir).Run(); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to restart the tmux session: %w", err)
+		}
 	}
 
+	var sendCmd *exec.Cmd
+
 	// Send the full command at once
 	sendCmd = exec.Command("tmux", "-f", configPath, "send-keys", "-t", sessionName, fullCmd)
 	if err := sendCmd.Run(); err != nil {
@@ -347,10 +511,12 @@ func (m *Manager) StartProcess(appName string, name string, command string, args
 		OutputWriter:  outputWriter,
 		IsDebug:       isDebugCommand(command, args),
 		TmuxSession:   sessionName,
+		Backend:       userconfig.BackendTmux,
 		CPUPercent:    0,
 		MemoryUsage:   0,
 		MemoryPercent: 0,
 		LastUpdated:   time.Now(),
+		StartedAt:     time.Now(),
 	}
 
 	m.processes[name] = process
@@ -373,21 +539,193 @@ func (m *Manager) StartProcess(appName string, name string, command string, args
 
 	// Save process information to store
 	info := ProcessInfo{
-		Name:    name,
-		AppName: appName,
-		Pid:     pid,
-		Status:  StatusRunning,
-		WorkDir: workDir,
+		Name:        name,
+		AppName:     appName,
+		Pid:         pid,
+		Status:      StatusRunning,
+		WorkDir:     workDir,
+		CommandLine: fullCmd,
+		Backend:     userconfig.BackendTmux,
+		StartedAt:   process.StartedAt,
+	}
+
+	m.debugf("Saving process %s (PID: %d) to store\n", name, info.Pid)
+	if err := m.store.SaveProcess(info); err != nil {
+		m.debugf("Warning: Failed to save process info: %v\n", err)
+	}
+
+	m.publish(events.ProcessStarted, appName, name, fullCmd)
+	return nil
+}
+
+// mergeProcessEnv appends the per-process overrides from the config to env.
+// It returns the merged environment and the overrides as KEY=VALUE pairs.
+func (m *Manager) mergeProcessEnv(name string, env []string) ([]string, []string) {
+	if m.config == nil {
+		return env, nil
+	}
+
+	overrides := m.config.GetProcessEnv(name)
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, overrides[key]))
+	}
+
+	env = env[:len(env):len(env)] // Don't write into the caller's slice
+	return append(env, pairs...), pairs
+}
+
+// startNativeProcess starts a process under the native supervisor and tracks it
+func (m *Manager) startNativeProcess(appName string, name string, fullCmd string, env []string, workDir string, outputFile string, isDebug bool) error {
+	pid, err := m.startNative(appName, name, fullCmd, env, workDir, outputFile)
+	if err != nil {
+		return err
+	}
+
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find supervisor: %w", err)
+	}
+
+	m.processes[name] = &Process{
+		Name:        name,
+		AppName:     appName,
+		Command:     &exec.Cmd{Process: proc},
+		Status:      StatusRunning,
+		OutputFile:  outputFile,
+		IsDebug:     isDebug,
+		Backend:     userconfig.BackendNative,
+		LastUpdated: time.Now(),
+		StartedAt:   time.Now(),
+	}
+
+	info := ProcessInfo{
+		Name:        name,
+		AppName:     appName,
+		Pid:         pid,
+		Status:      StatusRunning,
+		WorkDir:     workDir,
+		CommandLine: fullCmd,
+		Backend:     userconfig.BackendNative,
+		StartedAt:   m.processes[name].StartedAt,
 	}
 
-	m.debugf("Debug: Saving process %s (PID: %d) to store\n", name, info.Pid)
+	m.debugf("Saving process %s (PID: %d) to store\n", name, info.Pid)
 	if err := m.store.SaveProcess(info); err != nil {
 		m.debugf("Warning: Failed to save process info: %v\n", err)
 	}
 
+	m.publish(events.ProcessStarted, appName, name, fullCmd)
 	return nil
 }
 
+// RestartProcess kills the command running in a process's tmux pane (or
+// under its supervisor) and starts it again, keeping its session and log file
+func (m *Manager) RestartProcess(appName string, name string) error {
+	info, err := m.store.GetProcess(name)
+	if err != nil {
+		return err
+	}
+	if info.CommandLine == "" {
+		return fmt.Errorf("process %s has no recorded command, restart it with spin up", name)
+	}
+
+	if info.Backend == userconfig.BackendNative {
+		if err := sendSupervisorRequest(appName, name, requestRestart); err != nil {
+			return err
+		}
+		info.LastUpdated = time.Now()
+		info.StartedAt = info.LastUpdated
+		if err := m.store.SaveProcess(info); err != nil {
+			return err
+		}
+
+		// Forget the cached process so the next lookup sees the new start time
+		m.mu.Lock()
+		delete(m.processes, name)
+		m.mu.Unlock()
+
+		m.publish(events.ProcessRestarted, appName, name, "")
+		return nil
+	}
+
+	sessionName := SessionName(appName, name)
+	if err := exec.Command("tmux", "respawn-pane", "-k", "-t", sessionName).Run(); err != nil {
+		return fmt.Errorf("failed to respawn tmux pane: %w", err)
+	}
+	if err := exec.Command("tmux", "send-keys", "-t", sessionName, info.CommandLine, "Enter").Run(); err != nil {
+		return fmt.Errorf("failed to send command to tmux session: %w", err)
+	}
+
+	// Keep capturing output, -o only opens the pipe if it was closed
+	outputFile, err := LogPath(appName, name)
+	if err != nil {
+		return err
+	}
+	pipeCmd := exec.Command("tmux", "pipe-pane", "-o", "-t", sessionName, fmt.Sprintf("while IFS= read -r line; do echo \"$line\" >> '%s'; echo \"$line\"; done", outputFile))
+	if err := pipeCmd.Run(); err != nil {
+		return fmt.Errorf("failed to pipe tmux output: %w", err)
+	}
+
+	// The pane runs a new shell, track its PID
+	pid, err := panePID(sessionName)
+	if err != nil {
+		return err
+	}
+
+	info.Pid = pid
+	info.Status = StatusRunning
+	info.LastUpdated = time.Now()
+	info.StartedAt = info.LastUpdated
+	if err := m.store.SaveProcess(info); err != nil {
+		m.debugf("Warning: Failed to save process info: %v\n", err)
+	}
+
+	// Forget the cached process so the next lookup sees the new PID
+	m.mu.Lock()
+	delete(m.processes, name)
+	m.mu.Unlock()
+
+	m.publish(events.ProcessRestarted, appName, name, "")
+	return nil
+}
+
+// tmuxVersionPattern matches the version tmux -V prints, like 3.2 in
+// "tmux 3.2a"
+var tmuxVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+var (
+	sessionEnvOnce sync.Once
+	sessionEnv     bool
+)
+
+// tmuxSessionEnv reports whether new-session takes -e to set the environment
+// of a session, which came with tmux 3.2. Builds without a version number,
+// like master, have it.
+func tmuxSessionEnv() bool {
+	sessionEnvOnce.Do(func() {
+		output, err := exec.Command("tmux", "-V").Output()
+		if err != nil {
+			return
+		}
+		match := tmuxVersionPattern.FindStringSubmatch(string(output))
+		if match == nil {
+			sessionEnv = true
+			return
+		}
+		major, _ := strconv.Atoi(match[1])
+		minor, _ := strconv.Atoi(match[2])
+		sessionEnv = major > 3 || major == 3 && minor >= 2
+	})
+	return sessionEnv
+}
+
 // setupTmux ensures tmux is available and configured
 func setupTmux() error {
 	// Check if tmux is available
@@ -419,8 +757,34 @@ bind-key C-d detach-client
 	return nil
 }
 
-// DebugProcess attaches to a process in debug mode using tmux
+// ListSessions returns the names of all tmux sessions created by spin
+func ListSessions() []string {
+	output, err := exec.Command("tmux", "list-sessions", "-F", "#{session_name}").Output()
+	if err != nil {
+		// tmux exits with an error when no server is running
+		return nil
+	}
+
+	var sessions []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if strings.HasPrefix(line, "spin-") {
+			sessions = append(sessions, line)
+		}
+	}
+	return sessions
+}
+
+// KillSession terminates a tmux session
+func KillSession(name string) error {
+	return exec.Command("tmux", "kill-session", "-t", name).Run()
+}
+
+// DebugProcess attaches to a process in debug mode using tmux or its supervisor
 func (m *Manager) DebugProcess(appName string, name string) error {
+	if info, err := m.store.GetProcess(name); err == nil && info.Backend == userconfig.BackendNative {
+		return m.attachNative(appName, name)
+	}
+
 	// Ensure tmux is set up
 	if err := setupTmux(); err != nil {
 		return fmt.Errorf("failed to set up tmux: %w", err)
@@ -434,7 +798,7 @@ func (m *Manager) DebugProcess(appName string, name string) error {
 	configPath := filepath.Join(home, ".spin", "tmux.conf")
 
 	// Get the session name with sanitized app name
-	sessionName := fmt.Sprintf("spin-%s-%s", SanitizeAppName(appName), name)
+	sessionName := SessionName(appName, name)
 
 	// Check if session exists
 	checkCmd := exec.Command("tmux", "has-session", "-t", sessionName)
@@ -468,12 +832,13 @@ func (m *Manager) StopProcess(appName string, name string) error {
 		return err
 	}
 
-	// Kill the tmux session
-	if process.TmuxSession != "" {
-		killCmd := exec.Command("tmux", "kill-session", "-t", process.TmuxSession)
-		if err := killCmd.Run(); err != nil {
-			m.debugf("Warning: Failed to kill tmux session: %v\n", err)
+	// Stop the supervisor or kill the tmux session
+	if process.Backend == userconfig.BackendNative {
+		if err := m.stopNative(process); err != nil {
+			m.debugf("Warning: Failed to stop supervisor: %v\n", err)
 		}
+	} else if process.TmuxSession != "" {
+		m.stopTmux(process)
 	}
 
 	// Close output writer if it's a file
@@ -494,9 +859,72 @@ func (m *Manager) StopProcess(appName string, name string) error {
 	delete(m.processes, name)
 	m.mu.Unlock()
 
+	m.publish(events.ProcessStopped, process.AppName, name, "")
 	return nil
 }
 
+// stopTmux sends the stop signal to the command running in a process's tmux
+// pane, waits for it to exit and then kills the session. Commands that don't
+// exit within the grace period go down with the session.
+func (m *Manager) stopTmux(process *Process) {
+	sig, timeout := m.stopSettings(process.Name)
+
+	if pid, err := panePID(process.TmuxSession); err == nil {
+		// The pane's shell is in the foreground when no command is running
+		if pgid, err := foregroundGroup(pid); err == nil && pgid > 0 && pgid != pid {
+			m.debugf("Sending %s to process %s (group %d)\n", SignalName(sig), process.Name, pgid)
+			if err := signalGroup(pgid, sig); err == nil {
+				deadline := time.Now().Add(timeout)
+				for groupAlive(pgid) && time.Now().Before(deadline) {
+					time.Sleep(100 * time.Millisecond)
+				}
+				if groupAlive(pgid) {
+					m.debugf("Process %s did not stop within %s, killing its session\n", process.Name, timeout)
+				}
+			}
+		}
+	}
+
+	killCmd := exec.Command("tmux", "kill-session", "-t", process.TmuxSession)
+	if err := killCmd.Run(); err != nil {
+		m.debugf("Warning: Failed to kill tmux session: %v\n", err)
+	}
+}
+
+// panePID returns the PID of the shell running in a tmux session's pane
+func panePID(sessionName string) (int, error) {
+	output, err := exec.Command("tmux", "list-panes", "-t", sessionName, "-F", "#{pane_pid}").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pane PID: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse pane PID: %w", err)
+	}
+	return pid, nil
+}
+
+// CommandRunning reports whether the command of a tracked process is still
+// running. A tmux pane keeps its shell after the command exits, so the
+// command is gone once the shell is back in the foreground. A native
+// supervisor exits together with its command.
+func CommandRunning(info ProcessInfo) bool {
+	if info.Backend == userconfig.BackendNative {
+		return IsAlive(info.Pid)
+	}
+
+	pid, err := panePID(SessionName(info.AppName, info.Name))
+	if err != nil {
+		return false
+	}
+	pgid, err := foregroundGroup(pid)
+	if err != nil {
+		// The foreground can't be told on this platform, trust the session
+		return true
+	}
+	return pgid != pid
+}
+
 // StopAll stops all running processes
 func (m *Manager) StopAll() {
 	m.mu.RLock()
@@ -536,6 +964,20 @@ func (m *Manager) GetProcessStatus(appName string, name string) (ProcessStatus,
 	return process.Status, nil
 }
 
+// processStartTime returns when the operating system started a process,
+// for processes recorded without a start time
+func processStartTime(pid int) time.Time {
+	proc, err := psutil.NewProcess(int32(pid))
+	if err != nil {
+		return time.Time{}
+	}
+	created, err := proc.CreateTime()
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(created)
+}
+
 // updateResourceUsage updates CPU and memory usage for a process
 func (m *Manager) updateResourceUsage(p *Process) error {
 	if p.Type == ProcessTypeDocker {
@@ -556,21 +998,45 @@ func (m *Manager) updateResourceUsage(p *Process) error {
 	if err != nil {
 		return fmt.Errorf("failed to get CPU usage: %w", err)
 	}
-	p.CPUPercent = cpuPercent
 
 	// Get memory info
 	memInfo, err := proc.MemoryInfo()
 	if err != nil {
 		return fmt.Errorf("failed to get memory info: %w", err)
 	}
-	p.MemoryUsage = memInfo.RSS // Resident Set Size
+	memoryUsage := memInfo.RSS // Resident Set Size
 
 	// Get memory percent
 	memPercent, err := proc.MemoryPercent()
 	if err != nil {
 		return fmt.Errorf("failed to get memory percent: %w", err)
 	}
-	p.MemoryPercent = float64(memPercent)
+	memoryPercent := float64(memPercent)
+
+	// The tracked PID is a shell or supervisor, the actual work happens in
+	// its children (puma workers, node processes), so add up the whole tree.
+	// Children can exit while walking the tree, those are skipped.
+	for _, child := range descendants(proc) {
+		childCPU, err := child.CPUPercent()
+		if err != nil {
+			continue
+		}
+		childMem, err := child.MemoryInfo()
+		if err != nil {
+			continue
+		}
+		childMemPercent, err := child.MemoryPercent()
+		if err != nil {
+			continue
+		}
+		cpuPercent += childCPU
+		memoryUsage += childMem.RSS
+		memoryPercent += float64(childMemPercent)
+	}
+
+	p.CPUPercent = cpuPercent
+	p.MemoryUsage = memoryUsage
+	p.MemoryPercent = memoryPercent
 
 	p.LastUpdated = time.Now()
 
@@ -589,9 +1055,42 @@ func (m *Manager) updateResourceUsage(p *Process) error {
 		ContainerID:   p.ContainerID,
 		Image:         p.Image,
 	}
+
+	// Keep what only the store knows, restarts and reconciling rely on it
+	if stored, err := m.store.GetProcess(p.Name); err == nil {
+		info.WorkDir = stored.WorkDir
+		info.CommandLine = stored.CommandLine
+		info.Backend = stored.Backend
+		info.StartedAt = stored.StartedAt
+		info.URL = stored.URL
+	}
 	return m.store.SaveProcess(info)
 }
 
+// descendants returns all children of a process, recursively
+func descendants(proc *psutil.Process) []*psutil.Process {
+	var result []*psutil.Process
+	seen := map[int32]bool{proc.Pid: true}
+	queue := []*psutil.Process{proc}
+	for len(queue) > 0 {
+		children, err := queue[0].Children()
+		queue = queue[1:]
+		if err != nil {
+			// gopsutil returns an error for processes without children
+			continue
+		}
+		for _, child := range children {
+			if seen[child.Pid] {
+				continue
+			}
+			seen[child.Pid] = true
+			result = append(result, child)
+			queue = append(queue, child)
+		}
+	}
+	return result
+}
+
 // updateDockerResourceUsage updates resource usage for a Docker container
 func (m *Manager) updateDockerResourceUsage(p *Process) error {
 	dockerManager, err := docker.NewServiceManager("")
@@ -642,7 +1141,12 @@ func (m *Manager) updateDockerResourceUsage(p *Process) error {
 	p.MemoryPercent = float64(v.MemoryStats.Usage) / float64(v.MemoryStats.Limit) * 100
 	p.LastUpdated = time.Now()
 
-	// Update store
+	// Update store, keeping the start time of containers tracked before it
+	// was recorded
+	startedAt := p.StartedAt
+	if stored, err := m.store.GetProcess(p.Name); err == nil && startedAt.IsZero() {
+		startedAt = stored.StartedAt
+	}
 	info := ProcessInfo{
 		Name:          p.Name,
 		AppName:       p.AppName,
@@ -651,6 +1155,7 @@ func (m *Manager) updateDockerResourceUsage(p *Process) error {
 		MemoryUsage:   p.MemoryUsage,
 		MemoryPercent: p.MemoryPercent,
 		LastUpdated:   p.LastUpdated,
+		StartedAt:     startedAt,
 		Type:          ProcessTypeDocker,
 		ContainerID:   p.ContainerID,
 		Image:         p.Image,
@@ -658,16 +1163,16 @@ func (m *Manager) updateDockerResourceUsage(p *Process) error {
 	return m.store.SaveProcess(info)
 }
 
-// ListProcesses returns a list of all processes
+// ListProcesses returns the processes of the manager's project
 func (m *Manager) ListProcesses() []*Process {
 	// Get processes from store
-	storeProcesses, err := m.store.ListProcesses()
+	storeProcesses, err := m.store.ListProcesses(m.appName())
 	if err != nil {
-		m.debugf("Debug: Error listing processes from store: %v\n", err)
+		m.debugf("Error listing processes from store: %v\n", err)
 		return nil
 	}
 
-	m.debugf("Debug: Found %d processes in store\n", len(storeProcesses))
+	m.debugf("Found %d processes in store\n", len(storeProcesses))
 
 	// Convert store processes to Process objects
 	processes := make([]*Process, 0, len(storeProcesses))
@@ -675,13 +1180,13 @@ func (m *Manager) ListProcesses() []*Process {
 		if process, err := m.FindProcess(info.Name); err == nil {
 			// Update resource usage
 			if err := m.updateResourceUsage(process); err != nil {
-				m.debugf("Debug: Failed to update resource usage for %s: %v\n", process.Name, err)
+				m.debugf("Failed to update resource usage for %s: %v\n", process.Name, err)
 			}
 			processes = append(processes, process)
 		}
 	}
 
-	m.debugf("Debug: Returning %d active processes\n", len(processes))
+	m.debugf("Returning %d active processes\n", len(processes))
 	return processes
 }
 
@@ -695,23 +1200,22 @@ func (m *Manager) StartDockerProcess(name string, containerID string, image stri
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.debugf("Debug: Starting Docker process %s (container: %s)\n", name, containerID)
+	m.debugf("Starting Docker process %s (container: %s)\n", name, containerID)
 
-	if _, exists := m.processes[name]; exists {
+	// A restarted service replaces the container tracked before
+	if existing, exists := m.processes[name]; exists && existing.Type != ProcessTypeDocker {
 		return fmt.Errorf("process %s is already running", name)
 	}
 
 	// Create a new Docker process
-	process := NewDockerProcess(name, containerID, image)
+	process := NewDockerProcess(m.appName(), name, containerID, image)
 
 	// Get spin directory for logs
-	spinDir, err := getSpinDir()
+	// Create app-specific output directory
+	outputDir, err := LogDir(process.AppName)
 	if err != nil {
 		return fmt.Errorf("failed to create spin directory: %w", err)
 	}
-
-	// Create app-specific output directory
-	outputDir := filepath.Join(spinDir, "output", SanitizeAppName(process.AppName))
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
@@ -747,12 +1251,31 @@ func (m *Manager) StartDockerProcess(name string, containerID string, image stri
 		ContainerID: containerID,
 		Image:       image,
 		LastUpdated: time.Now(),
+		StartedAt:   process.StartedAt,
 	}
 
-	m.debugf("Debug: Saving Docker process %s to store\n", name)
+	m.debugf("Saving Docker process %s to store\n", name)
 	if err := m.store.SaveProcess(info); err != nil {
 		m.debugf("Warning: Failed to save process info: %v\n", err)
 	}
 
 	return nil
 }
+
+// StopDockerProcess stops tracking the container of a service
+func (m *Manager) StopDockerProcess(name string) error {
+	m.mu.Lock()
+	delete(m.processes, name)
+	m.mu.Unlock()
+
+	m.debugf("Removing Docker process %s from store\n", name)
+	return m.store.RemoveProcess(name)
+}
+
+// appName returns the name of the app the manager was created for
+func (m *Manager) appName() string {
+	if m.config == nil {
+		return ""
+	}
+	return m.config.Name
+}
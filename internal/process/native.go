@@ -0,0 +1,194 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/afomera/spin/internal/userconfig"
+	"golang.org/x/term"
+)
+
+// detachKey detaches from a natively supervised process, like the Ctrl+D
+// prefix of spin's tmux config
+const detachKey = 0x04
+
+// supervisorSocket returns the control socket of a natively supervised process
+func supervisorSocket(appName string, name string) (string, error) {
+	spinDir, err := getSpinDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(spinDir, "run")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.sock", SanitizeAppName(appName), name)), nil
+}
+
+// startNative starts a process under a detached spin supervisor and returns
+// the supervisor's PID once it accepts connections
+func (m *Manager) startNative(appName string, name string, commandLine string, env []string, workDir string, outputFile string) (int, error) {
+	socket, err := supervisorSocket(appName, name)
+	if err != nil {
+		return 0, err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find spin executable: %w", err)
+	}
+
+	// Errors of the supervisor itself end up in the process log
+	log, err := os.OpenFile(outputFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer log.Close()
+
+	sig, timeout := m.stopSettings(name)
+	cmd := exec.Command(executable, "supervise",
+		"--app", appName,
+		"--name", name,
+		"--socket", socket,
+		"--log", outputFile,
+		"--stop-signal", SignalName(sig),
+		"--stop-timeout", timeout.String(),
+		"--", commandLine)
+	cmd.Dir = workDir
+	cmd.Env = env
+	cmd.Stdout = log
+	cmd.Stderr = log
+	cmd.SysProcAttr = detachedAttrs()
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start supervisor: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	// Wait for the control socket so the process can be attached right away
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case <-exited:
+			return 0, fmt.Errorf("supervisor exited, see %s", outputFile)
+		case <-deadline:
+			return 0, fmt.Errorf("supervisor did not start listening on %s", socket)
+		case <-time.After(50 * time.Millisecond):
+			if conn, err := net.Dial("unix", socket); err == nil {
+				conn.Close()
+				return cmd.Process.Pid, nil
+			}
+		}
+	}
+}
+
+// sendSupervisorRequest sends a single request to a supervisor
+func sendSupervisorRequest(appName string, name string, request string) error {
+	socket, err := supervisorSocket(appName, name)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return fmt.Errorf("failed to connect to supervisor of %s: %w", name, err)
+	}
+	defer conn.Close()
+
+	_, err = fmt.Fprintf(conn, "%s\n", request)
+	return err
+}
+
+// stopNative stops a supervised process and waits for its supervisor to exit
+func (m *Manager) stopNative(process *Process) error {
+	if err := sendSupervisorRequest(process.AppName, process.Name, requestStop); err != nil {
+		m.debugf("Warning: %v\n", err)
+	}
+
+	if process.Command == nil || process.Command.Process == nil {
+		return nil
+	}
+	pid := process.Command.Process.Pid
+
+	// The supervisor kills the command after its stop timeout, give it a little longer
+	_, timeout := m.stopSettings(process.Name)
+	deadline := time.Now().Add(timeout + 2*time.Second)
+	for IsAlive(pid) && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if IsAlive(pid) {
+		return process.Command.Process.Kill()
+	}
+	return nil
+}
+
+// attachNative connects the terminal to a supervised process until the
+// detach key is pressed or the process exits
+func (m *Manager) attachNative(appName string, name string) error {
+	socket, err := supervisorSocket(appName, name)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return fmt.Errorf("process %s is not running", name)
+	}
+	defer conn.Close()
+
+	rows, cols := 0, 0
+	if width, height, err := term.GetSize(int(os.Stdin.Fd())); err == nil {
+		rows, cols = height, width
+	}
+	if _, err := fmt.Fprintf(conn, "%s %d %d\n", requestAttach, rows, cols); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		io.Copy(os.Stdout, conn)
+		close(done)
+	}()
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if i := bytes.IndexByte(buf[:n], detachKey); i >= 0 {
+				conn.Write(buf[:i])
+				conn.Close()
+				return
+			}
+			if n > 0 {
+				if _, err := conn.Write(buf[:n]); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	<-done
+	return nil
+}
+
+// backend returns the process backend chosen in the user configuration
+func backend() string {
+	cfg, err := userconfig.Load()
+	if err != nil {
+		return userconfig.BackendTmux
+	}
+	return cfg.Backend()
+}
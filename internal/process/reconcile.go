@@ -0,0 +1,237 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/service/docker"
+	"github.com/afomera/spin/internal/userconfig"
+	"github.com/docker/docker/client"
+)
+
+// Actions Reconcile can take on the process store
+const (
+	FixAdopted  = "adopted"  // A running process was added to the store
+	FixRemoved  = "removed"  // An entry of a process that is gone was removed
+	FixUpdated  = "updated"  // An entry was corrected in place
+	FixRestored = "restored" // The store was unreadable and was recovered
+)
+
+// Fix is a single change Reconcile made to the process store
+type Fix struct {
+	Action string
+	Name   string // Store key of the process, <app>/<name>, or the store file
+	Reason string
+}
+
+// Reconcile brings the process store in line with what is actually running.
+// Entries whose tmux session, supervisor or container is gone are removed and
+// stale PIDs are corrected. Tmux sessions and service containers of the
+// configured app that aren't tracked are adopted. Sessions of other apps are
+// left alone since their app and process names can't be told apart. A
+// store that had to be recovered is reported first.
+func (m *Manager) Reconcile() ([]Fix, error) {
+	entries, err := m.store.Entries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process store: %w", err)
+	}
+
+	var fixes []Fix
+	if recovered := m.store.takeRecovery(); recovered != "" {
+		fixes = append(fixes, Fix{Action: FixRestored, Name: filepath.Base(m.store.path), Reason: recovered})
+	}
+
+	sessions := make(map[string]bool)
+	for _, session := range ListSessions() {
+		sessions[session] = true
+	}
+
+	// Docker is optional, containers are only reconciled when it's reachable
+	var cli *client.Client
+	if dm, err := docker.NewServiceManager(""); err == nil {
+		if _, err := dm.Client().Ping(context.Background()); err == nil {
+			cli = dm.Client()
+			defer cli.Close()
+		}
+	}
+
+	var removed []string
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		info := entries[key]
+		switch {
+		case info.Type == ProcessTypeDocker:
+			if cli == nil || info.ContainerID == "" {
+				continue
+			}
+			if _, err := cli.ContainerInspect(context.Background(), info.ContainerID); client.IsErrNotFound(err) {
+				removed = append(removed, key)
+				fixes = append(fixes, Fix{Action: FixRemoved, Name: key, Reason: "container no longer exists"})
+			}
+		case info.Backend == userconfig.BackendNative:
+			if !IsAlive(info.Pid) {
+				removed = append(removed, key)
+				fixes = append(fixes, Fix{Action: FixRemoved, Name: key, Reason: fmt.Sprintf("supervisor %d is not running", info.Pid)})
+			}
+		default:
+			session := SessionName(info.AppName, info.Name)
+			if !sessions[session] {
+				removed = append(removed, key)
+				fixes = append(fixes, Fix{Action: FixRemoved, Name: key, Reason: "tmux session is gone"})
+				continue
+			}
+			if IsAlive(info.Pid) {
+				continue
+			}
+			pid, err := panePID(session)
+			if err != nil {
+				continue
+			}
+			fixes = append(fixes, Fix{Action: FixUpdated, Name: key, Reason: fmt.Sprintf("PID %d replaced by pane PID %d", info.Pid, pid)})
+			info.Pid = pid
+			info.LastUpdated = time.Now()
+			if err := m.store.SaveProcess(info); err != nil {
+				return fixes, err
+			}
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := m.store.RemoveEntries(removed); err != nil {
+			return fixes, err
+		}
+		m.mu.Lock()
+		for _, key := range removed {
+			if entries[key].AppName == m.appName() {
+				delete(m.processes, entries[key].Name)
+			}
+		}
+		m.mu.Unlock()
+	}
+
+	if m.config == nil {
+		return fixes, nil
+	}
+
+	adopted, err := m.adoptSessions(entries, sessions)
+	fixes = append(fixes, adopted...)
+	if err != nil {
+		return fixes, err
+	}
+
+	if cli != nil {
+		adopted, err := m.adoptContainers(cli, entries)
+		fixes = append(fixes, adopted...)
+		if err != nil {
+			return fixes, err
+		}
+	}
+
+	return fixes, nil
+}
+
+// adoptSessions adds the untracked tmux sessions of the configured app to the store
+func (m *Manager) adoptSessions(entries map[string]ProcessInfo, sessions map[string]bool) ([]Fix, error) {
+	prefix := SessionName(m.config.Name, "")
+	tracked := make(map[string]bool)
+	for _, info := range entries {
+		tracked[SessionName(info.AppName, info.Name)] = true
+	}
+
+	names := make([]string, 0, len(sessions))
+	for session := range sessions {
+		names = append(names, session)
+	}
+	sort.Strings(names)
+
+	var fixes []Fix
+	for _, session := range names {
+		if !strings.HasPrefix(session, prefix) {
+			continue
+		}
+		if tracked[session] {
+			continue
+		}
+
+		pid, err := panePID(session)
+		if err != nil {
+			continue
+		}
+		workDir, _ := exec.Command("tmux", "display-message", "-p", "-t", session, "#{pane_current_path}").Output()
+
+		info := ProcessInfo{
+			Name:        strings.TrimPrefix(session, prefix),
+			AppName:     m.config.Name,
+			Pid:         pid,
+			Status:      StatusRunning,
+			WorkDir:     strings.TrimSpace(string(workDir)),
+			Backend:     userconfig.BackendTmux,
+			LastUpdated: time.Now(),
+			StartedAt:   processStartTime(pid),
+		}
+		if err := m.store.SaveProcess(info); err != nil {
+			return fixes, err
+		}
+		fixes = append(fixes, Fix{Action: FixAdopted, Name: EntryKey(info.AppName, info.Name), Reason: "untracked tmux session"})
+	}
+	return fixes, nil
+}
+
+// adoptContainers adds running containers of the configured services that
+// aren't tracked to the store
+func (m *Manager) adoptContainers(cli *client.Client, entries map[string]ProcessInfo) ([]Fix, error) {
+	tracked := make(map[string]bool)
+	for _, info := range entries {
+		if info.Type == ProcessTypeDocker {
+			tracked[info.ContainerID] = true
+		}
+	}
+
+	names := make([]string, 0, len(m.config.Services))
+	for name := range m.config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fixes []Fix
+	for _, name := range names {
+		container, err := cli.ContainerInspect(context.Background(), fmt.Sprintf("spin_%s", name))
+		if err != nil || container.State == nil || !container.State.Running || tracked[container.ID] {
+			continue
+		}
+
+		// Track the container like the service manager does when starting it
+		proc := NewDockerProcess(m.appName(), name, container.ID, container.Config.Image)
+		info := ProcessInfo{
+			Name:        proc.Name,
+			AppName:     proc.AppName,
+			Status:      StatusRunning,
+			Type:        ProcessTypeDocker,
+			ContainerID: container.ID,
+			Image:       proc.Image,
+			LastUpdated: time.Now(),
+		}
+		if started, err := time.Parse(time.RFC3339Nano, container.State.StartedAt); err == nil {
+			info.StartedAt = started
+		}
+		if err := m.store.SaveProcess(info); err != nil {
+			return fixes, err
+		}
+		fixes = append(fixes, Fix{
+			Action: FixAdopted,
+			Name:   EntryKey(info.AppName, info.Name),
+			Reason: "untracked service container",
+		})
+	}
+	return fixes, nil
+}
@@ -0,0 +1,71 @@
+package process
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Defaults for stopping a process gracefully
+const (
+	DefaultStopSignal  = syscall.SIGTERM
+	DefaultStopTimeout = 10 * time.Second
+)
+
+// stopSignals are the signals a process can be configured to stop with
+var stopSignals = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGHUP":  syscall.SIGHUP,
+}
+
+// ParseSignal converts a signal name like "SIGINT" or "int" to a signal
+func ParseSignal(name string) (syscall.Signal, error) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, ok := stopSignals[name]
+	if !ok {
+		return 0, fmt.Errorf("unsupported stop signal %q", name)
+	}
+	return sig, nil
+}
+
+// SignalName returns the name ParseSignal accepts for a signal
+func SignalName(sig syscall.Signal) string {
+	for name, s := range stopSignals {
+		if s == sig {
+			return name
+		}
+	}
+	return sig.String()
+}
+
+// stopSettings returns the signal and grace period to stop a process with,
+// falling back to the defaults when the config has none or invalid ones
+func (m *Manager) stopSettings(name string) (syscall.Signal, time.Duration) {
+	sig, timeout := DefaultStopSignal, DefaultStopTimeout
+	if m.config == nil || m.config.Processes == nil {
+		return sig, timeout
+	}
+
+	settings, _ := m.config.GetProcessSettings(name)
+	if settings.StopSignal != "" {
+		if s, err := ParseSignal(settings.StopSignal); err == nil {
+			sig = s
+		} else {
+			m.debugf("Warning: %v for process %s, using %s\n", err, name, SignalName(sig))
+		}
+	}
+	if settings.StopTimeout != "" {
+		if d, err := time.ParseDuration(settings.StopTimeout); err == nil && d >= 0 {
+			timeout = d
+		} else {
+			m.debugf("Warning: invalid stop timeout %q for process %s, using %s\n", settings.StopTimeout, name, timeout)
+		}
+	}
+	return sig, timeout
+}
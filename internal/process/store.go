@@ -25,20 +25,29 @@ type ProcessInfo struct {
 	Pid           int           `json:"pid"`
 	Status        ProcessStatus `json:"status"`
 	WorkDir       string        `json:"workdir"`
+	CommandLine   string        `json:"command,omitempty"` // Command sent to the tmux pane, used for restarts
+	Backend       string        `json:"backend,omitempty"` // Backend supervising the process, tmux when empty
 	CPUPercent    float64       `json:"cpu_percent"`
 	MemoryUsage   uint64        `json:"memory_usage"` // in bytes
 	MemoryPercent float64       `json:"memory_percent"`
 	LastUpdated   time.Time     `json:"last_updated"`
+	StartedAt     time.Time     `json:"started_at"` // When the process was last started or restarted
 	Type          ProcessType   `json:"type"`
 	ContainerID   string        `json:"container_id,omitempty"` // Docker container ID
 	Image         string        `json:"image,omitempty"`        // Docker image name
+	URL           string        `json:"url,omitempty"`          // Public URL the process serves, set by spin share
 }
 
-// Store manages persistent process information
+// Store manages persistent process information. Every write replaces the
+// file atomically and keeps the previous version as a backup, which is
+// restored when the file can't be read.
 type Store struct {
 	path    string
 	mu      sync.RWMutex
 	manager *Manager // Reference to the process manager for debug logging
+
+	recoverMu sync.Mutex
+	recovered string // How the store was last recovered, until Reconcile reports it
 }
 
 // NewStore creates a new process store
@@ -46,22 +55,22 @@ func NewStore(manager *Manager) *Store {
 	// Store process info in user's home directory
 	home, err := os.UserHomeDir()
 	if err != nil {
-		manager.debugf("Debug: Error getting home directory: %v\n", err)
+		manager.debugf("Error getting home directory: %v\n", err)
 		home = "."
 	}
 	spinDir := filepath.Join(home, ".spin")
 	if err := os.MkdirAll(spinDir, 0755); err != nil {
-		manager.debugf("Debug: Error creating spin directory: %v\n", err)
+		manager.debugf("Error creating spin directory: %v\n", err)
 	}
 
 	storePath := filepath.Join(spinDir, "processes.json")
-	manager.debugf("Debug: Process store path: %s\n", storePath)
+	manager.debugf("Process store path: %s\n", storePath)
 
 	// Ensure the file exists with proper permissions
 	if _, err := os.Stat(storePath); os.IsNotExist(err) {
-		manager.debugf("Debug: Creating new process store file\n")
-		if err := os.WriteFile(storePath, []byte("{}"), 0644); err != nil {
-			manager.debugf("Debug: Error creating process store file: %v\n", err)
+		manager.debugf("Creating new process store file\n")
+		if err := writeFileAtomic(storePath, []byte("{}")); err != nil {
+			manager.debugf("Error creating process store file: %v\n", err)
 		}
 	}
 
@@ -71,162 +80,288 @@ func NewStore(manager *Manager) *Store {
 	}
 }
 
+// projects holds the processes of every project, keyed by project and then
+// by process name, so projects can have processes of the same name
+type projects map[string]map[string]ProcessInfo
+
+// get returns a process of a project
+func (p projects) get(appName string, name string) (ProcessInfo, bool) {
+	info, ok := p[appName][name]
+	return info, ok
+}
+
+// put adds or replaces a process
+func (p projects) put(info ProcessInfo) {
+	if p[info.AppName] == nil {
+		p[info.AppName] = make(map[string]ProcessInfo)
+	}
+	p[info.AppName][info.Name] = info
+}
+
+// remove removes a process, and its project once it has none left
+func (p projects) remove(appName string, name string) {
+	delete(p[appName], name)
+	if len(p[appName]) == 0 {
+		delete(p, appName)
+	}
+}
+
+// EntryKey identifies a process of a project across the store, like
+// myapp/web. Process names can't contain a slash, so keys are unambiguous.
+func EntryKey(appName string, name string) string {
+	return appName + "/" + name
+}
+
 // SaveProcess saves process information to the store
 func (s *Store) SaveProcess(info ProcessInfo) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.manager.debugf("Debug: Saving process %s (PID: %d) to store\n", info.Name, info.Pid)
+	s.manager.debugf("Saving process %s (PID: %d) to store\n", info.Name, info.Pid)
 
 	processes, err := s.loadProcesses()
 	if err != nil {
-		s.manager.debugf("Debug: Error loading processes: %v, creating new map\n", err)
-		processes = make(map[string]ProcessInfo)
+		s.manager.debugf("Error loading processes: %v, creating new map\n", err)
+		processes = make(projects)
 	}
+	processes.put(info)
+	return s.saveProcesses(processes)
+}
 
-	// Use a combination of app name and process name as the key
-	key := fmt.Sprintf("%s-%s", SanitizeAppName(info.AppName), info.Name)
-	processes[key] = info
+// SetURL records the public URL a process serves
+func (s *Store) SetURL(appName string, name string, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	processes, err := s.loadProcesses()
+	if err != nil {
+		return err
+	}
+	info, exists := processes.get(appName, name)
+	if !exists {
+		return fmt.Errorf("process %s not found", name)
+	}
+	info.URL = url
+	processes.put(info)
 	return s.saveProcesses(processes)
 }
 
-// RemoveProcess removes a process from the store
+// RemoveProcess removes a process of the manager's project from the store
 func (s *Store) RemoveProcess(name string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.manager.debugf("Debug: Removing process %s from store\n", name)
+	s.manager.debugf("Removing process %s from store\n", name)
 
 	processes, err := s.loadProcesses()
 	if err != nil {
 		return err
 	}
-
-	// Get the app name from the manager's config
-	appName := s.manager.config.Name
-	key := fmt.Sprintf("%s-%s", SanitizeAppName(appName), name)
-	delete(processes, key)
+	processes.remove(s.manager.appName(), name)
 	return s.saveProcesses(processes)
 }
 
-// GetProcess retrieves process information from the store
+// GetProcess retrieves a process of the manager's project from the store
 func (s *Store) GetProcess(name string) (ProcessInfo, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	s.manager.debugf("Debug: Getting process %s from store\n", name)
+	s.manager.debugf("Getting process %s from store\n", name)
 
 	processes, err := s.loadProcesses()
 	if err != nil {
-		s.manager.debugf("Debug: Error loading processes: %v\n", err)
+		s.manager.debugf("Error loading processes: %v\n", err)
 		return ProcessInfo{}, err
 	}
 
-	// Get the app name from the manager's config
-	appName := s.manager.config.Name
-	key := fmt.Sprintf("%s-%s", SanitizeAppName(appName), name)
-	info, exists := processes[key]
+	info, exists := processes.get(s.manager.appName(), name)
 	if !exists {
-		s.manager.debugf("Debug: Process %s not found in store\n", name)
+		s.manager.debugf("Process %s not found in store\n", name)
 		return ProcessInfo{}, fmt.Errorf("process %s not found", name)
 	}
 
-	s.manager.debugf("Debug: Found process %s (PID: %d) in store\n", name, info.Pid)
+	s.manager.debugf("Found process %s (PID: %d) in store\n", name, info.Pid)
 	return info, nil
 }
 
-// ListProcesses returns all processes in the store
-func (s *Store) ListProcesses() ([]ProcessInfo, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// ListProcesses returns the processes of a project that are still running,
+// or of all projects when appName is empty. Entries of processes that are
+// gone are removed, whichever project they belong to.
+func (s *Store) ListProcesses(appName string) ([]ProcessInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	s.manager.debugf("Debug: Listing all processes from store\n")
+	s.manager.debugf("Listing processes from store\n")
 
 	processes, err := s.loadProcesses()
 	if err != nil {
-		s.manager.debugf("Debug: Error loading processes: %v\n", err)
+		s.manager.debugf("Error loading processes: %v\n", err)
 		return nil, err
 	}
 
-	result := make([]ProcessInfo, 0, len(processes))
-	for _, info := range processes {
-		// Check if process is still running
-		if info.Pid > 0 {
-			if proc, err := os.FindProcess(info.Pid); err == nil {
-				// On Unix systems, this always succeeds, so we need to send signal 0
-				// to test if the process exists
-				if err := proc.Signal(syscall.Signal(0)); err == nil {
-					s.manager.debugf("Debug: Process %s (PID: %d) is still running\n", info.Name, info.Pid)
-					result = append(result, info)
+	var result []ProcessInfo
+	changed := false
+	for app, named := range processes {
+		for _, info := range named {
+			// Containers of services are removed when the service stops
+			if info.Type != ProcessTypeDocker {
+				if info.Pid <= 0 {
+					continue
+				}
+				if !IsAlive(info.Pid) {
+					s.manager.debugf("Process %s (PID: %d) not found, removing from store\n", info.Name, info.Pid)
+					processes.remove(app, info.Name)
+					changed = true
 					continue
 				}
-				s.manager.debugf("Debug: Process %s (PID: %d) is not responding to signals\n", info.Name, info.Pid)
 			}
-			s.manager.debugf("Debug: Process %s (PID: %d) not found, removing from store\n", info.Name, info.Pid)
-			// Process is not running, remove it from store
-			key := fmt.Sprintf("%s-%s", SanitizeAppName(info.AppName), info.Name)
-			delete(processes, key)
+			if appName == "" || app == appName {
+				result = append(result, info)
+			}
 		}
 	}
 
-	// Save cleaned up processes
-	if err := s.saveProcesses(processes); err != nil {
-		s.manager.debugf("Debug: Error saving cleaned up processes: %v\n", err)
+	if changed {
+		if err := s.saveProcesses(processes); err != nil {
+			s.manager.debugf("Error saving cleaned up processes: %v\n", err)
+		}
 	}
 
-	s.manager.debugf("Debug: Found %d running processes\n", len(result))
+	s.manager.debugf("Found %d running processes\n", len(result))
 	return result, nil
 }
 
-// loadProcesses reads the processes from disk
-func (s *Store) loadProcesses() (map[string]ProcessInfo, error) {
-	s.manager.debugf("Debug: Loading processes from %s\n", s.path)
+// loadProcesses reads the processes from disk. Stores written before
+// processes were kept per project, as a flat map keyed by <app>-<name>, are
+// read into projects by the app and name recorded in each entry.
+func (s *Store) loadProcesses() (projects, error) {
+	s.manager.debugf("Loading processes from %s\n", s.path)
 
 	data, err := os.ReadFile(s.path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			s.manager.debugf("Debug: Store file does not exist, creating new map\n")
-			return make(map[string]ProcessInfo), nil
+			s.manager.debugf("Store file does not exist, creating new map\n")
+			return make(projects), nil
 		}
-		s.manager.debugf("Debug: Error reading store file: %v\n", err)
+		s.manager.debugf("Error reading store file: %v\n", err)
 		return nil, err
 	}
 
-	var processes map[string]ProcessInfo
-	if err := json.Unmarshal(data, &processes); err != nil {
-		s.manager.debugf("Debug: Error unmarshaling store data: %v\n", err)
+	processes := make(projects)
+	if err := json.Unmarshal(data, &processes); err == nil {
+		s.manager.debugf("Loaded processes of %d projects from store\n", len(processes))
+		return processes, nil
+	}
+
+	processes, err = parseFlat(data)
+	if err != nil {
+		s.manager.debugf("Error unmarshaling store data: %v\n", err)
+		return s.recover(data)
+	}
+	s.manager.debugf("Migrated processes of %d projects to per-project entries\n", len(processes))
+	return processes, nil
+}
+
+// parseFlat reads a store written before processes were kept per project
+func parseFlat(data []byte) (projects, error) {
+	var flat map[string]ProcessInfo
+	if err := json.Unmarshal(data, &flat); err != nil {
 		return nil, err
 	}
+	processes := make(projects)
+	for _, info := range flat {
+		processes.put(info)
+	}
+	return processes, nil
+}
+
+// parseStore reads the processes of a store file in either format
+func parseStore(data []byte) (projects, error) {
+	processes := make(projects)
+	if err := json.Unmarshal(data, &processes); err == nil {
+		return processes, nil
+	}
+	return parseFlat(data)
+}
+
+// backupPath returns the file the previous version of the store is kept in
+func (s *Store) backupPath() string {
+	return s.path + ".bak"
+}
+
+// recover replaces a store file that can't be read, left truncated by a
+// crash or edited by hand, with its backup, or with an empty store when the
+// backup can't be read either. The unreadable file is kept next to it as
+// processes.json.corrupt. Processes the backup misses are adopted again by
+// Reconcile.
+func (s *Store) recover(corrupt []byte) (projects, error) {
+	s.recoverMu.Lock()
+	defer s.recoverMu.Unlock()
+
+	// Another caller may have recovered the store in the meantime
+	if data, err := os.ReadFile(s.path); err == nil {
+		if processes, err := parseStore(data); err == nil {
+			return processes, nil
+		}
+	}
+
+	if err := writeFileAtomic(s.path+".corrupt", corrupt); err != nil {
+		s.manager.debugf("Error keeping the corrupt store: %v\n", err)
+	}
+
+	processes := make(projects)
+	s.recovered = "unreadable and no usable backup, started empty"
+	if data, err := os.ReadFile(s.backupPath()); err == nil {
+		if backup, err := parseStore(data); err == nil {
+			processes = backup
+			s.recovered = "unreadable, from " + filepath.Base(s.backupPath())
+		}
+	}
+	s.manager.debugf("Recovered process store (%s)\n", s.recovered)
 
-	s.manager.debugf("Debug: Loaded %d processes from store\n", len(processes))
+	data, err := json.MarshalIndent(processes, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return nil, err
+	}
 	return processes, nil
 }
 
+// takeRecovery returns how the store was recovered since it was last asked,
+// or an empty string
+func (s *Store) takeRecovery() string {
+	s.recoverMu.Lock()
+	defer s.recoverMu.Unlock()
+	recovered := s.recovered
+	s.recovered = ""
+	return recovered
+}
+
 // saveProcesses writes the processes to disk
-func (s *Store) saveProcesses(processes map[string]ProcessInfo) error {
-	s.manager.debugf("Debug: Saving %d processes to store\n", len(processes))
+func (s *Store) saveProcesses(processes projects) error {
+	s.manager.debugf("Saving processes of %d projects to store\n", len(processes))
 
 	data, err := json.MarshalIndent(processes, "", "  ")
 	if err != nil {
-		s.manager.debugf("Debug: Error marshaling processes: %v\n", err)
+		s.manager.debugf("Error marshaling processes: %v\n", err)
 		return err
 	}
 
-	// Write to a temporary file first
-	tmpPath := s.path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
-		s.manager.debugf("Debug: Error writing temporary file: %v\n", err)
-		return err
+	// Keep the current version, if it is readable, to recover from
+	if current, err := os.ReadFile(s.path); err == nil && json.Valid(current) {
+		if err := writeFileAtomic(s.backupPath(), current); err != nil {
+			s.manager.debugf("Error writing store backup: %v\n", err)
+		}
 	}
 
-	// Rename temporary file to actual file (atomic operation)
-	if err := os.Rename(tmpPath, s.path); err != nil {
-		s.manager.debugf("Debug: Error renaming temporary file: %v\n", err)
+	if err := writeFileAtomic(s.path, data); err != nil {
+		s.manager.debugf("Error writing store: %v\n", err)
 		return err
 	}
 
-	s.manager.debugf("Debug: Successfully saved processes to store\n")
+	s.manager.debugf("Successfully saved processes to store\n")
 	return nil
 }
 
@@ -235,27 +370,115 @@ func (s *Store) Cleanup() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.manager.debugf("Debug: Cleaning up dead processes\n")
+	s.manager.debugf("Cleaning up dead processes\n")
 
 	processes, err := s.loadProcesses()
 	if err != nil {
 		return err
 	}
 
-	cleaned := make(map[string]ProcessInfo)
-	for name, info := range processes {
-		if info.Pid > 0 {
-			if proc, err := os.FindProcess(info.Pid); err == nil {
-				if err := proc.Signal(syscall.Signal(0)); err == nil {
-					s.manager.debugf("Debug: Process %s (PID: %d) is still running\n", name, info.Pid)
-					cleaned[name] = info
-				} else {
-					s.manager.debugf("Debug: Process %s (PID: %d) is dead\n", name, info.Pid)
-				}
+	cleaned := make(projects)
+	for _, named := range processes {
+		for _, info := range named {
+			if IsAlive(info.Pid) {
+				cleaned.put(info)
+			} else {
+				s.manager.debugf("Process %s (PID: %d) is dead\n", info.Name, info.Pid)
 			}
 		}
 	}
 
-	s.manager.debugf("Debug: Cleaned up store, %d processes remaining\n", len(cleaned))
+	s.manager.debugf("Cleaned up store, %d projects remaining\n", len(cleaned))
 	return s.saveProcesses(cleaned)
 }
+
+// Entries returns every process in the store keyed by EntryKey, including
+// entries whose process is no longer running
+func (s *Store) Entries() (map[string]ProcessInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	processes, err := s.loadProcesses()
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]ProcessInfo)
+	for app, named := range processes {
+		for name, info := range named {
+			entries[EntryKey(app, name)] = info
+		}
+	}
+	return entries, nil
+}
+
+// RemoveEntries removes processes from the store by their EntryKey
+func (s *Store) RemoveEntries(keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	processes, err := s.loadProcesses()
+	if err != nil {
+		return err
+	}
+	remove := make(map[string]bool)
+	for _, key := range keys {
+		remove[key] = true
+	}
+	for app, named := range processes {
+		for name := range named {
+			if remove[EntryKey(app, name)] {
+				processes.remove(app, name)
+			}
+		}
+	}
+	return s.saveProcesses(processes)
+}
+
+// writeFileAtomic replaces a file with data, so that readers and crashes
+// only ever see the old or the new content. The data is written to a
+// temporary file of its own, since several spin processes write the store,
+// and flushed to disk before it is renamed into place.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Flush the rename too, where directories can be synced
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// IsAlive checks if a process with the given PID is still running
+func IsAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
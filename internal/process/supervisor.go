@@ -0,0 +1,279 @@
+package process
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Requests a client can send to a supervisor's control socket, as the first
+// line of a connection
+const (
+	requestAttach  = "attach"  // attach <rows> <cols>, then raw terminal I/O
+	requestRestart = "restart" // Restart the command in place
+	requestStop    = "stop"    // Stop the command and exit
+)
+
+// Supervisor runs a single command in a pseudo-terminal, writes its output to
+// a log file and serves attach and restart requests on a Unix socket. It is the
+// native alternative to running a process in a tmux session.
+type Supervisor struct {
+	CommandLine string // Command run through the shell
+	LogFile     string // File the command's output is appended to
+	Socket      string // Path of the control socket
+	StopSignal  syscall.Signal
+	StopTimeout time.Duration   // Time to exit after StopSignal before the command is killed
+	OnExit      func(err error) // Called when the command exits without being asked to stop
+
+	mu         sync.Mutex
+	cmd        *exec.Cmd
+	tty        io.ReadWriteCloser // Terminal (or pipes) of the running command
+	clients    map[net.Conn]bool  // Attached clients receiving output
+	log        *os.File
+	restarting bool
+	stopping   bool
+	stop       chan struct{}
+	killTimer  *time.Timer
+	exited     chan error
+}
+
+// Run starts the command and supervises it until it exits or the supervisor
+// is asked to stop
+func (s *Supervisor) Run() error {
+	log, err := os.OpenFile(s.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer log.Close()
+	s.log = log
+	s.clients = make(map[net.Conn]bool)
+	s.exited = make(chan error, 1)
+	s.stop = make(chan struct{}, 1)
+
+	// A stale socket is left behind when a supervisor gets killed
+	os.Remove(s.Socket)
+	listener, err := net.Listen("unix", s.Socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Socket, err)
+	}
+	defer os.Remove(s.Socket)
+	defer listener.Close()
+
+	if err := s.start(); err != nil {
+		return err
+	}
+	go s.serve(listener)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	for {
+		select {
+		case <-sigChan:
+			s.shutdown()
+		case <-s.stop:
+			s.shutdown()
+		case err := <-s.exited:
+			s.mu.Lock()
+			restart, stopping := s.restarting, s.stopping
+			s.restarting = false
+			if s.killTimer != nil {
+				s.killTimer.Stop()
+			}
+			s.mu.Unlock()
+
+			if !restart {
+				s.closeClients()
+				if !stopping && s.OnExit != nil {
+					s.OnExit(err)
+				}
+				if stopping || err == nil {
+					return nil
+				}
+				return fmt.Errorf("command exited: %w", err)
+			}
+			fmt.Fprintf(s.log, "\n--- restarting %s ---\n", s.CommandLine)
+			if err := s.start(); err != nil {
+				s.closeClients()
+				return err
+			}
+		}
+	}
+}
+
+// start launches the command and begins copying its output
+func (s *Supervisor) start() error {
+	cmd := shellCommand(s.CommandLine)
+	tty, err := startTerminal(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", s.CommandLine, err)
+	}
+
+	s.mu.Lock()
+	s.cmd = cmd
+	s.tty = tty
+	s.mu.Unlock()
+
+	go s.copyOutput(tty)
+	go func() {
+		s.exited <- cmd.Wait()
+	}()
+	return nil
+}
+
+// shutdown stops the command for good, Run returns once it has exited
+func (s *Supervisor) shutdown() {
+	s.mu.Lock()
+	s.restarting = false
+	s.stopping = true
+	s.mu.Unlock()
+	s.terminate()
+}
+
+// terminate asks the running command to stop and kills it if it doesn't
+// exit in time
+func (s *Supervisor) terminate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cmd == nil || s.cmd.Process == nil {
+		return
+	}
+	pid := s.cmd.Process.Pid
+	if err := signalGroup(pid, s.StopSignal); err != nil {
+		s.cmd.Process.Kill()
+		return
+	}
+	s.killTimer = time.AfterFunc(s.StopTimeout, func() {
+		signalGroup(pid, syscall.SIGKILL)
+	})
+}
+
+// copyOutput writes the command's output to the log file and attached clients
+func (s *Supervisor) copyOutput(tty io.ReadCloser) {
+	defer tty.Close()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := tty.Read(buf)
+		if n > 0 {
+			s.mu.Lock()
+			s.log.Write(buf[:n])
+			for conn := range s.clients {
+				if _, err := conn.Write(buf[:n]); err != nil {
+					conn.Close()
+					delete(s.clients, conn)
+				}
+			}
+			s.mu.Unlock()
+		}
+		if err != nil {
+			// Reading a terminal fails with EIO once the command has exited
+			return
+		}
+	}
+}
+
+// serve accepts control connections until the listener is closed
+func (s *Supervisor) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle serves a single control connection
+func (s *Supervisor) handle(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		conn.Close()
+		return
+	}
+
+	switch fields[0] {
+	case requestRestart:
+		conn.Close()
+		s.mu.Lock()
+		s.restarting = true
+		s.mu.Unlock()
+		s.terminate()
+	case requestStop:
+		conn.Close()
+		select {
+		case s.stop <- struct{}{}:
+		default:
+			// A stop is already pending
+		}
+	case requestAttach:
+		if len(fields) == 3 {
+			rows, _ := strconv.Atoi(fields[1])
+			cols, _ := strconv.Atoi(fields[2])
+			s.mu.Lock()
+			resizeTerminal(s.tty, rows, cols)
+			s.mu.Unlock()
+		}
+		s.attach(conn, reader)
+	default:
+		conn.Close()
+	}
+}
+
+// attach forwards a client's input to the command until it disconnects,
+// output reaches it through copyOutput
+func (s *Supervisor) attach(conn net.Conn, input io.Reader) {
+	s.mu.Lock()
+	s.clients[conn] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	buf := make([]byte, 1024)
+	for {
+		n, err := input.Read(buf)
+		if n > 0 {
+			// Input goes to whichever command is running after a restart
+			s.mu.Lock()
+			tty := s.tty
+			s.mu.Unlock()
+			tty.Write(buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// closeClients disconnects all attached clients
+func (s *Supervisor) closeClients() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.clients {
+		conn.Close()
+		delete(s.clients, conn)
+	}
+}
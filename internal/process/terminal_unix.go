@@ -0,0 +1,62 @@
+//go:build !windows
+
+package process
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+// shellCommand runs a command line through the user's shell, like a command
+// typed into a tmux pane
+func shellCommand(commandLine string) *exec.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return exec.Command(shell, "-c", commandLine)
+}
+
+// startTerminal starts cmd in a new pseudo-terminal and returns its master side
+func startTerminal(cmd *exec.Cmd) (io.ReadWriteCloser, error) {
+	return pty.Start(cmd)
+}
+
+// resizeTerminal sets the window size of a pseudo-terminal
+func resizeTerminal(tty io.ReadWriteCloser, rows, cols int) {
+	if f, ok := tty.(*os.File); ok && rows > 0 && cols > 0 {
+		pty.Setsize(f, &pty.Winsize{Rows: uint16(rows), Cols: uint16(cols)})
+	}
+}
+
+// signalGroup sends a signal to a process group. Commands started in a
+// terminal lead their own group, so this reaches the processes they started.
+func signalGroup(pgid int, sig syscall.Signal) error {
+	return syscall.Kill(-pgid, sig)
+}
+
+// groupAlive checks if any process of a process group is still running
+func groupAlive(pgid int) bool {
+	return syscall.Kill(-pgid, 0) == nil
+}
+
+// foregroundGroup returns the foreground process group of the terminal a
+// process is attached to, which is the command a shell is running
+func foregroundGroup(pid int) (int, error) {
+	output, err := exec.Command("ps", "-o", "tpgid=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// detachedAttrs lets a supervisor outlive the spin command that started it
+func detachedAttrs() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
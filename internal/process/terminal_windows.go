@@ -0,0 +1,88 @@
+//go:build windows
+
+package process
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// detachedProcess starts a process without a console, see the Windows
+// process creation flags
+const detachedProcess = 0x00000008
+
+// pipeTerminal connects to a command through pipes, pseudo-terminals aren't
+// available on Windows
+type pipeTerminal struct {
+	out *os.File       // Read end of the command's output
+	in  io.WriteCloser // The command's input
+}
+
+func (t *pipeTerminal) Read(p []byte) (int, error)  { return t.out.Read(p) }
+func (t *pipeTerminal) Write(p []byte) (int, error) { return t.in.Write(p) }
+
+// Close closes both pipes
+func (t *pipeTerminal) Close() error {
+	t.in.Close()
+	return t.out.Close()
+}
+
+// shellCommand runs a command line through cmd.exe
+func shellCommand(commandLine string) *exec.Cmd {
+	return exec.Command("cmd", "/C", commandLine)
+}
+
+// startTerminal starts cmd with its input and output connected to pipes
+func startTerminal(cmd *exec.Cmd) (io.ReadWriteCloser, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	// The command holds its own copy of the write end, reads end with it
+	w.Close()
+
+	return &pipeTerminal{out: r, in: stdin}, nil
+}
+
+// resizeTerminal does nothing, pipes have no window size
+func resizeTerminal(tty io.ReadWriteCloser, rows, cols int) {}
+
+// signalGroup kills a process, Windows can't deliver Unix signals
+func signalGroup(pgid int, sig syscall.Signal) error {
+	proc, err := os.FindProcess(pgid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
+
+// groupAlive checks if a process is still running
+func groupAlive(pgid int) bool {
+	return IsAlive(pgid)
+}
+
+// foregroundGroup is not supported, Windows has no terminal process groups
+func foregroundGroup(pid int) (int, error) {
+	return 0, fmt.Errorf("process groups are not supported on Windows")
+}
+
+// detachedAttrs lets a supervisor outlive the console that started it
+func detachedAttrs() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
@@ -0,0 +1,122 @@
+package procfile
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formation is the number of instances to run of each process, written like
+// foreman's and overmind's web=1,worker=2
+type Formation map[string]int
+
+// formationAll is the name that sets the count of the processes a formation
+// doesn't name
+const formationAll = "all"
+
+// ReservedPrefix starts the names of the processes spin runs itself, like
+// spin-api, which Procfile entries can't take
+const ReservedPrefix = "spin-"
+
+// ParseFormation parses a formation like web=1,worker=2. all=N sets the count
+// of the processes it doesn't name, which otherwise run once.
+func ParseFormation(s string) (Formation, error) {
+	formation := make(Formation)
+	for _, pair := range strings.Split(strings.ReplaceAll(s, " ", ""), ",") {
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		count, err := strconv.Atoi(value)
+		if !ok || name == "" || err != nil || count < 0 {
+			return nil, fmt.Errorf("invalid formation %q, use process=count pairs like web=1,worker=2", pair)
+		}
+		formation[name] = count
+	}
+	return formation, nil
+}
+
+// Count returns the number of instances to run of a process
+func (f Formation) Count(name string) int {
+	if count, ok := f[name]; ok {
+		return count
+	}
+	if count, ok := f[formationAll]; ok {
+		return count
+	}
+	return 1
+}
+
+// Scale applies a formation to the processes of a Procfile. Processes with a
+// count of 0 are left out and processes with several instances are repeated
+// with numbered names, like worker.1 and worker.2. Instances can't share the
+// name of another process once dots become dashes, like worker.2 and a
+// worker-2 of the Procfile, since processes are tracked by that name.
+func Scale(entries []Entry, formation Formation) ([]Entry, error) {
+	known := make(map[string]bool)
+	for _, entry := range entries {
+		known[entry.Name] = true
+	}
+	var unknown []string
+	for name := range formation {
+		if name != formationAll && !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("formation names unknown processes: %s", strings.Join(unknown, ", "))
+	}
+
+	var scaled []Entry
+	for _, entry := range entries {
+		count := formation.Count(entry.Name)
+		if count == 1 {
+			scaled = append(scaled, entry)
+			continue
+		}
+		for i := 1; i <= count; i++ {
+			instance := entry
+			instance.Name = instanceName(entry.Name, i)
+			scaled = append(scaled, instance)
+		}
+	}
+
+	names := make(map[string]string, len(scaled))
+	for _, entry := range scaled {
+		name := strings.ReplaceAll(entry.Name, ".", "-")
+		if strings.HasPrefix(name, ReservedPrefix) {
+			return nil, fmt.Errorf("%s can't run, names starting with %s are kept for the processes spin runs itself, rename it in the Procfile", entry.Name, ReservedPrefix)
+		}
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("%s and %s can't run together, both are tracked as %s, rename one in the Procfile", other, entry.Name, name)
+		}
+		names[name] = entry.Name
+	}
+	return scaled, nil
+}
+
+// Ports allocates a PORT to each instance of the processes like foreman
+// does: processes get blocks of step ports from base in Procfile order, and
+// their instances the ports of their block in turn. entries are the processes
+// before the formation is applied, the result is keyed by instance name.
+func Ports(entries []Entry, formation Formation, base int, step int) map[string]int {
+	ports := make(map[string]int)
+	for i, entry := range entries {
+		count := formation.Count(entry.Name)
+		if count == 1 {
+			ports[entry.Name] = base + i*step
+			continue
+		}
+		for n := 1; n <= count; n++ {
+			ports[instanceName(entry.Name, n)] = base + i*step + n - 1
+		}
+	}
+	return ports
+}
+
+// instanceName names an instance of a process, like worker.2
+func instanceName(name string, n int) string {
+	return fmt.Sprintf("%s.%d", name, n)
+}
@@ -0,0 +1,71 @@
+package procfile
+
+import "github.com/afomera/spin/internal/detector"
+
+// Generate builds Procfile entries from the detected characteristics of the
+// project at path: the Rails server, background job workers, AnyCable, the
+// JavaScript bundler or its dev server and CSS watchers. Node-only projects
+// get their dev server and the package.json scripts that rebuild on changes,
+// run with packageManager or the one detected when it is empty.
+func Generate(path string, packageManager string) []Entry {
+	var entries []Entry
+
+	node, _ := detector.DetectNode(path)
+	if node != nil && packageManager == "" {
+		packageManager = node.PackageManager
+	}
+	runScript := func(script, args string) string {
+		return detector.RunScriptCommand(packageManager, script, args)
+	}
+	rails, err := detector.DetectRails(path)
+	if err != nil {
+		if node != nil {
+			node.PackageManager = packageManager
+			for _, process := range node.DevProcesses(path) {
+				entries = append(entries, Entry{Name: process.Name, Command: process.Command})
+			}
+		}
+		return entries
+	}
+
+	entries = append(entries, Entry{Name: "web", Command: "bin/rails server -p 3000"})
+
+	// Background jobs
+	if worker, ok := rails.JobWorker(path); ok {
+		entries = append(entries, Entry{Name: worker.Name, Command: worker.Command})
+	}
+
+	// AnyCable takes the WebSocket connections instead of the Rails server
+	for _, process := range rails.AnyCableProcesses(path) {
+		entries = append(entries, Entry{Name: process.Name, Command: process.Command})
+	}
+
+	// JavaScript bundling, with the bundler's dev server when it has one
+	devServer, _ := detector.DetectDevServer(path)
+	switch {
+	case devServer.Command != "":
+		entries = append(entries, Entry{Name: devServer.Name, Command: devServer.Command})
+	case node != nil && hasScript(node, "build"):
+		entries = append(entries, Entry{Name: "js", Command: runScript("build", "--watch")})
+	}
+
+	// CSS watchers
+	switch rails.Assets.CSS {
+	case "tailwindcss":
+		entries = append(entries, Entry{Name: "css", Command: "bin/rails tailwindcss:watch"})
+	case "dartsass":
+		entries = append(entries, Entry{Name: "css", Command: "bin/rails dartsass:watch"})
+	case "cssbundling":
+		if node != nil && hasScript(node, "build:css") {
+			entries = append(entries, Entry{Name: "css", Command: runScript("build:css", "--watch")})
+		}
+	}
+
+	return entries
+}
+
+// hasScript checks if package.json defines a script
+func hasScript(node *detector.NodeConfig, name string) bool {
+	_, ok := node.PackageJSON.Scripts[name]
+	return ok
+}
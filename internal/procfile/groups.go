@@ -0,0 +1,191 @@
+package procfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/afomera/spin/internal/config"
+)
+
+// Selection narrows down the processes of a project by group or process name
+type Selection struct {
+	Only   []string // Start only these groups or processes
+	Except []string // Start everything but these groups or processes
+}
+
+// Resolve returns the processes of a project: the entries of the main
+// Procfile followed by those only defined in group Procfiles and those of its
+// apps, narrowed down by sel. The main Procfile may be missing when groups or
+// apps bring their own. An app's name selects all of its processes.
+func Resolve(cfg *config.Config, appPath string, sel Selection) ([]Entry, error) {
+	if len(sel.Only) > 0 && len(sel.Except) > 0 {
+		return nil, fmt.Errorf("only and except can't be combined")
+	}
+	if err := config.CheckApps(appPath, cfg); err != nil {
+		return nil, err
+	}
+
+	var groups map[string]config.ProcessGroup
+	if cfg.Processes != nil {
+		groups = cfg.Processes.Groups
+	}
+
+	main, err := Load(filepath.Join(appPath, cfg.GetProcfilePath()))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) || (!hasGroupProcfiles(groups) && len(cfg.Apps) == 0) {
+			return nil, err
+		}
+		main = New(nil)
+	}
+
+	// Resolve the entries of every group
+	groupEntries := make(map[string][]Entry)
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entries, err := groupProcesses(groups[name], main, appPath)
+		if err != nil {
+			return nil, fmt.Errorf("process group %s: %v", name, err)
+		}
+		groupEntries[name] = entries
+	}
+
+	// Processes of apps are namespaced with the app's name
+	var appEntries [][]Entry
+	for _, app := range cfg.Apps {
+		entries, err := appProcesses(app, appPath)
+		if err != nil {
+			return nil, err
+		}
+		appEntries = append(appEntries, entries)
+		if _, ok := groupEntries[app.GetName()]; !ok {
+			groupEntries[app.GetName()] = entries
+		}
+	}
+
+	// Collect all processes once, the main Procfile wins on name clashes
+	var all []Entry
+	seen := make(map[string]bool)
+	add := func(entries []Entry) {
+		for _, entry := range entries {
+			if !seen[entry.Name] {
+				seen[entry.Name] = true
+				all = append(all, entry)
+			}
+		}
+	}
+	add(main.Entries())
+	for _, name := range names {
+		add(groupEntries[name])
+	}
+	for _, entries := range appEntries {
+		add(entries)
+	}
+
+	if len(sel.Only) == 0 && len(sel.Except) == 0 {
+		return all, nil
+	}
+
+	only := len(sel.Only) > 0
+	selected, err := expand(append(sel.Only, sel.Except...), groupEntries, seen)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Entry
+	for _, entry := range all {
+		if selected[entry.Name] == only {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// hasGroupProcfiles checks if any group reads its own Procfile
+func hasGroupProcfiles(groups map[string]config.ProcessGroup) bool {
+	for _, group := range groups {
+		if group.Procfile != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// groupProcesses returns the entries selected by a group
+func groupProcesses(group config.ProcessGroup, main *Procfile, appPath string) ([]Entry, error) {
+	source := main
+	if group.Procfile != "" {
+		pf, err := Load(filepath.Join(appPath, group.Procfile))
+		if err != nil {
+			return nil, err
+		}
+		source = pf
+	}
+
+	if len(group.Processes) == 0 {
+		return source.Entries(), nil
+	}
+
+	var entries []Entry
+	for _, name := range group.Processes {
+		entry, ok := source.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("process %s is not defined", name)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// appProcesses returns the processes of an app, renamed like api-web and
+// running in the app's directory with its development env
+func appProcesses(app config.App, root string) ([]Entry, error) {
+	appCfg, err := config.LoadApp(root, app)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := Resolve(appCfg, filepath.Join(root, app.Path), Selection{})
+	if err != nil {
+		return nil, fmt.Errorf("app %s: %w", app.GetName(), err)
+	}
+
+	for i, entry := range entries {
+		entries[i].Name = app.ProcessName(entry.Name)
+		entries[i].Dir = filepath.Join(app.Path, entry.Dir)
+		env := make(map[string]string)
+		for key, value := range appCfg.GetEnvVars("development") {
+			env[key] = value
+		}
+		// Nested apps' env wins over their parent's
+		for key, value := range entry.Env {
+			env[key] = value
+		}
+		entries[i].Env = env
+	}
+	return entries, nil
+}
+
+// expand turns group and process names into the set of process names they cover
+func expand(names []string, groups map[string][]Entry, processes map[string]bool) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	for _, name := range names {
+		if entries, ok := groups[name]; ok {
+			for _, entry := range entries {
+				selected[entry.Name] = true
+			}
+			continue
+		}
+		if processes[name] {
+			selected[name] = true
+			continue
+		}
+		return nil, fmt.Errorf("unknown process or group %q", name)
+	}
+	return selected, nil
+}
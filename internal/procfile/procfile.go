@@ -0,0 +1,185 @@
+package procfile
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/afomera/spin/internal/detector"
+)
+
+// namePattern matches valid process names
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Entry is a single process definition in a Procfile
+type Entry struct {
+	Name    string
+	Command string
+	Dir     string            // Working directory relative to the project, set for processes of apps
+	Env     map[string]string // Development env of the app the process belongs to
+}
+
+// Procfile holds the lines of a Procfile. Comments and blank lines are kept
+// so edits don't rewrite the rest of the file.
+type Procfile struct {
+	lines []string
+}
+
+// Load reads a Procfile from disk
+func Load(path string) (*Procfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(data)), nil
+}
+
+// Parse reads a Procfile from its contents
+func Parse(content string) *Procfile {
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return &Procfile{}
+	}
+	return &Procfile{lines: strings.Split(content, "\n")}
+}
+
+// New builds a Procfile from a list of entries
+func New(entries []Entry) *Procfile {
+	p := &Procfile{}
+	for _, entry := range entries {
+		p.Set(entry.Name, entry.Command)
+	}
+	return p
+}
+
+// parseLine returns the entry defined by a line, if any
+func parseLine(line string) (Entry, bool) {
+	if line == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return Entry{}, false
+	}
+
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return Entry{}, false
+	}
+
+	return Entry{
+		Name:    strings.TrimSpace(parts[0]),
+		Command: strings.TrimSpace(parts[1]),
+	}, true
+}
+
+// Entries returns the processes defined in the Procfile in order
+func (p *Procfile) Entries() []Entry {
+	var entries []Entry
+	for _, line := range p.lines {
+		if entry, ok := parseLine(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Get returns the process with the given name
+func (p *Procfile) Get(name string) (Entry, bool) {
+	for _, entry := range p.Entries() {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Set adds a process or replaces the command of an existing one in place
+func (p *Procfile) Set(name, command string) {
+	line := fmt.Sprintf("%s: %s", name, command)
+	for i, l := range p.lines {
+		if entry, ok := parseLine(l); ok && entry.Name == name {
+			p.lines[i] = line
+			return
+		}
+	}
+	p.lines = append(p.lines, line)
+}
+
+// Remove deletes a process and reports whether it existed
+func (p *Procfile) Remove(name string) bool {
+	for i, l := range p.lines {
+		if entry, ok := parseLine(l); ok && entry.Name == name {
+			p.lines = append(p.lines[:i], p.lines[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the Procfile contents
+func (p *Procfile) String() string {
+	if len(p.lines) == 0 {
+		return ""
+	}
+	return strings.Join(p.lines, "\n") + "\n"
+}
+
+// Save writes the Procfile to disk
+func (p *Procfile) Save(path string) error {
+	return os.WriteFile(path, []byte(p.String()), 0644)
+}
+
+// ValidateName checks that a process name can be used in a Procfile
+// and as part of a tmux session name
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid process name %q, use letters, digits, dashes and underscores", name)
+	}
+	return nil
+}
+
+// ImagePrefix starts the commands of processes that run the app image built by
+// spin build, like "web: docker:" or "web: docker: bin/rails server"
+const ImagePrefix = "docker:"
+
+// ImageCommand returns the command to run in the app image for a command
+// starting with docker:, empty for the image's default command, and whether
+// the command runs the image
+func ImageCommand(command string) (string, bool) {
+	if !strings.HasPrefix(command, ImagePrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(command, ImagePrefix)), true
+}
+
+// SplitCommand splits a Procfile command into the executable and its arguments
+func SplitCommand(command string) (string, []string) {
+	// Keep package manager commands intact to preserve colons and other special characters
+	if isPackageManagerCommand(command) {
+		parts := strings.SplitN(command, " ", 2)
+		if len(parts) > 1 {
+			// Keep the rest as a single argument
+			return parts[0], []string{parts[1]}
+		}
+		return parts[0], nil
+	}
+
+	parts := strings.Fields(command)
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return parts[0], parts[1:]
+}
+
+// packageRunners are the commands of Node.js package managers that run a
+// package's executable
+var packageRunners = []string{"npx", "pnpx", "bunx"}
+
+// isPackageManagerCommand reports whether a command runs a Node.js package
+// manager or one of their package runners
+func isPackageManagerCommand(command string) bool {
+	for _, name := range append(append([]string{}, detector.PackageManagers...), packageRunners...) {
+		if strings.HasPrefix(command, name+" ") {
+			return true
+		}
+	}
+	return false
+}
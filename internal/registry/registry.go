@@ -0,0 +1,146 @@
+// Package registry records the projects running on the machine, so they can
+// be listed and opened from any directory
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/afomera/spin/internal/filelock"
+)
+
+// Entry is a project started with spin up
+type Entry struct {
+	Name      string    `json:"name"`
+	Dir       string    `json:"dir"`            // Directory of the project's spin.config.json
+	Port      int       `json:"port,omitempty"` // Port of the web process, 0 without one
+	StartedAt time.Time `json:"started_at"`
+}
+
+// URL returns the address of the project's web process
+func (e Entry) URL() string {
+	if e.Port == 0 {
+		return ""
+	}
+	return fmt.Sprintf("http://localhost:%d", e.Port)
+}
+
+// Path returns the file the registry is kept in
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".spin", "registry.json"), nil
+}
+
+// load reads the registry, keyed by project name
+func load() (map[string]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make(map[string]Entry), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]Entry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// save writes the registry
+func save(entries map[string]Entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// lock keeps other spin commands from changing the registry until it is
+// released, so updates made at the same time aren't lost
+func lock() (*filelock.Lock, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return filelock.Acquire(path + ".lock")
+}
+
+// Register records that a project runs, replacing an earlier entry of it
+func Register(entry Entry) error {
+	l, err := lock()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+	entries[entry.Name] = entry
+	return save(entries)
+}
+
+// Unregister removes projects from the registry
+func Unregister(names ...string) error {
+	l, err := lock()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		delete(entries, name)
+	}
+	return save(entries)
+}
+
+// Get returns the entry of a project
+func Get(name string) (Entry, bool, error) {
+	entries, err := load()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	entry, ok := entries[name]
+	return entry, ok, nil
+}
+
+// List returns the registered projects, sorted by name
+func List() ([]Entry, error) {
+	entries, err := load()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
@@ -0,0 +1,203 @@
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/afomera/spin/internal/userconfig"
+)
+
+//go:embed templates
+var builtin embed.FS
+
+// Template is a project template that can be scaffolded
+type Template struct {
+	Name   string
+	Source string // "built-in" or the repository it came from
+	files  fs.FS
+}
+
+// Data is what template files are rendered with
+type Data struct {
+	Name string // Name of the app
+}
+
+// orgDir returns where the template repository of the user config is cloned
+func orgDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".spin", "templates"), nil
+}
+
+// Update clones the template repository of the user config, or pulls it if
+// it was cloned from the same repository before. It does nothing when no
+// repository is configured.
+func Update() error {
+	cfg, err := userconfig.Load()
+	if err != nil {
+		return err
+	}
+	if cfg.TemplatesRepo == "" {
+		return nil
+	}
+
+	dir, err := orgDir()
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil && remoteURL(dir) == cfg.TemplatesRepo {
+		cmd = exec.Command("git", "-C", dir, "pull", "--ff-only", "--quiet")
+	} else {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return err
+		}
+		os.RemoveAll(dir)
+		cmd = exec.Command("git", "clone", "--depth", "1", "--quiet", cfg.TemplatesRepo, dir)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch templates from %s: %v: %s", cfg.TemplatesRepo, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// remoteURL returns the repository the clone in dir was made from
+func remoteURL(dir string) string {
+	output, err := exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// List returns the available templates sorted by name. Templates of the
+// organization repository, one per top-level directory, replace built-in
+// templates with the same name.
+func List() ([]Template, error) {
+	templates := make(map[string]Template)
+
+	sub, err := fs.Sub(builtin, "templates")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(sub, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			files, _ := fs.Sub(sub, entry.Name())
+			templates[entry.Name()] = Template{Name: entry.Name(), Source: "built-in", files: files}
+		}
+	}
+
+	if cfg, err := userconfig.Load(); err == nil && cfg.TemplatesRepo != "" {
+		dir, err := orgDir()
+		if err != nil {
+			return nil, err
+		}
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				templates[entry.Name()] = Template{
+					Name:   entry.Name(),
+					Source: cfg.TemplatesRepo,
+					files:  os.DirFS(filepath.Join(dir, entry.Name())),
+				}
+			}
+		}
+	}
+
+	list := make([]Template, 0, len(templates))
+	for _, t := range templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// Find returns the template with the given name
+func Find(name string) (*Template, error) {
+	templates, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(templates))
+	for _, t := range templates {
+		if t.Name == name {
+			return &t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return nil, fmt.Errorf("unknown template %q, available templates: %s", name, strings.Join(names, ", "))
+}
+
+// Render writes the files of the template into dir and returns the paths
+// written. Files are Go templates using [[ ]] as delimiters, so the {{ }}
+// placeholders of scripts are left alone. Existing files are only replaced
+// when overwrite is set.
+func (t *Template) Render(dir string, data Data, overwrite bool) ([]string, error) {
+	type file struct {
+		path    string
+		content []byte
+	}
+	var files []file
+
+	// Render everything before writing, so a broken template writes nothing
+	err := fs.WalkDir(t.files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		raw, err := fs.ReadFile(t.files, path)
+		if err != nil {
+			return err
+		}
+		tmpl, err := template.New(path).Delims("[[", "]]").Option("missingkey=error").Parse(string(raw))
+		if err != nil {
+			return fmt.Errorf("invalid template file %s: %w", path, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", path, err)
+		}
+		files = append(files, file{path: filepath.Join(dir, filepath.FromSlash(path)), content: buf.Bytes()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !overwrite {
+		for _, f := range files {
+			if _, err := os.Stat(f.path); err == nil {
+				return nil, fmt.Errorf("%s already exists", f.path)
+			}
+		}
+	}
+
+	written := make([]string, 0, len(files))
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(f.path, f.content, 0644); err != nil {
+			return written, err
+		}
+		written = append(written, f.path)
+	}
+	return written, nil
+}
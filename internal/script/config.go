@@ -1,15 +1,19 @@
 package script
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+
+	spinconfig "github.com/afomera/spin/internal/config"
 )
 
 // Config represents the script configuration structure
 type Config struct {
+	Name    string                  `json:"name"` // Name of the project, for ${PROJECT_NAME}
 	Scripts map[string]ScriptConfig `json:"scripts"`
 }
 
@@ -17,7 +21,9 @@ type Config struct {
 type ScriptConfig struct {
 	Command     string            `json:"command"`
 	Description string            `json:"description"`
+	Interpreter string            `json:"interpreter,omitempty"`
 	Env         map[string]string `json:"env,omitempty"`
+	Params      map[string]Param  `json:"params,omitempty"`
 	Hooks       HooksConfig       `json:"hooks,omitempty"`
 }
 
@@ -34,18 +40,24 @@ type HookConfig struct {
 	Env         map[string]string `json:"env,omitempty"`
 }
 
-// LoadConfig loads script configuration from a file
+// LoadConfig loads script configuration from a file, including the personal
+// scripts of spin.config.local.json
 func LoadConfig(path string) (*Config, error) {
-	file, err := os.Open(path)
+	data, err := spinconfig.ReadMerged(path)
 	if err != nil {
+		if _, statErr := os.Stat(path); statErr == nil {
+			return nil, NewValidationError(
+				"failed to parse config file",
+				err.Error(),
+			).WithFix("Ensure the config file contains valid JSON")
+		}
 		return nil, NewScriptError(
 			"failed to open config file",
 			err.Error(),
 		).WithFix(fmt.Sprintf("Ensure the file exists at %s", path))
 	}
-	defer file.Close()
 
-	return LoadConfigFromReader(file)
+	return LoadConfigFromReader(bytes.NewReader(data))
 }
 
 // LoadConfigFromReader loads script configuration from an io.Reader
@@ -57,16 +69,37 @@ func LoadConfigFromReader(r io.Reader) (*Config, error) {
 			err.Error(),
 		).WithFix("Ensure the config file contains valid JSON")
 	}
+	config.interpolate()
 
 	return &config, nil
 }
 
+// interpolate replaces ${PROJECT_NAME} and ${env:VAR} in the commands and
+// env of the scripts. ${PORT} is left for the shell to expand.
+func (c *Config) interpolate() {
+	vars := spinconfig.Vars{ProjectName: c.Name}
+	for name, script := range c.Scripts {
+		script.Command = spinconfig.Interpolate(script.Command, vars)
+		for key, value := range script.Env {
+			script.Env[key] = spinconfig.Interpolate(value, vars)
+		}
+		for _, hook := range []*HookConfig{script.Hooks.Pre, script.Hooks.Post} {
+			if hook != nil {
+				hook.Command = spinconfig.Interpolate(hook.Command, vars)
+			}
+		}
+		c.Scripts[name] = script
+	}
+}
+
 // ToScripts converts the configuration into Script objects
 func (c *Config) ToScripts() ([]*Script, error) {
 	scripts := make([]*Script, 0, len(c.Scripts))
 
 	for name, cfg := range c.Scripts {
 		script := NewScript(name, cfg.Command, cfg.Description)
+		script.Interpreter = cfg.Interpreter
+		script.Params = cfg.Params
 
 		// Add environment variables
 		for k, v := range cfg.Env {
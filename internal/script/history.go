@@ -0,0 +1,226 @@
+package script
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/process"
+)
+
+// OutputLogName is the name the output of scripts is logged under next to
+// the logs of processes, so spin logs scripts shows it
+const OutputLogName = "scripts"
+
+// maxHistoryLogs is the number of run logs kept in ~/.spin/history
+const maxHistoryLogs = 100
+
+// maxHistorySize is the size at which the history is rotated to scripts.jsonl.1
+const maxHistorySize = 1024 * 1024
+
+// HistoryEntry records a single run of a script
+type HistoryEntry struct {
+	ID       string            `json:"id"`
+	App      string            `json:"app,omitempty"` // Project the script belongs to
+	Name     string            `json:"name"`
+	Args     []string          `json:"args,omitempty"`
+	Env      map[string]string `json:"env,omitempty"` // Variables given with --env
+	WorkDir  string            `json:"workdir,omitempty"`
+	Started  time.Time         `json:"started"`
+	Duration time.Duration     `json:"duration"`
+	ExitCode int               `json:"exit_code"` // -1 when the script couldn't be run
+	Error    string            `json:"error,omitempty"`
+	LogFile  string            `json:"log_file,omitempty"`
+
+	log      *os.File
+	output   *os.File
+	prefixed io.Writer
+}
+
+// historyMu serializes writes to the history within a process
+var historyMu sync.Mutex
+
+// HistoryDir returns the directory script runs are recorded in
+func HistoryDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".spin", "history"), nil
+}
+
+// OutputPath returns the log the output of the scripts of app is appended to,
+// every line prefixed with the name of the script
+func OutputPath(app string) (string, error) {
+	return process.LogPath(app, OutputLogName)
+}
+
+// StartRun begins recording a run of a script in the history of app. The
+// returned options copy the output of the run to a log file of its own and
+// to the output log of the app, Finish records how it went.
+func StartRun(app string, name string, opts *RunOptions) (*HistoryEntry, *RunOptions) {
+	logged := RunOptions{}
+	if opts != nil {
+		logged = *opts
+	}
+
+	entry := &HistoryEntry{
+		App:     app,
+		Name:    name,
+		Args:    logged.Args,
+		Env:     logged.Env,
+		WorkDir: logged.WorkDir,
+		Started: time.Now(),
+	}
+	entry.ID = fmt.Sprintf("%s-%s", entry.Started.Format("20060102-150405.000"), strings.ReplaceAll(name, string(filepath.Separator), "_"))
+
+	var logs []io.Writer
+	if logged.Log != nil {
+		logs = append(logs, logged.Log)
+	}
+
+	if dir, err := HistoryDir(); err == nil && os.MkdirAll(dir, 0755) == nil {
+		path := filepath.Join(dir, entry.ID+".log")
+		if f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600); err == nil {
+			entry.LogFile = path
+			entry.log = f
+			logs = append(logs, f)
+		}
+	}
+
+	if path, err := OutputPath(app); err == nil && os.MkdirAll(filepath.Dir(path), 0755) == nil {
+		if f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			entry.output = f
+			entry.prefixed = logger.NewPrefixedWriter(name, logger.GetColorForService(name), f)
+			fmt.Fprintf(entry.prefixed, "▶ %s %s\n", name, strings.Join(entry.Args, " "))
+			logs = append(logs, entry.prefixed)
+		}
+	}
+
+	if len(logs) > 0 {
+		logged.Log = io.MultiWriter(logs...)
+	}
+	return entry, &logged
+}
+
+// Finish records the outcome of the run in the history
+func (e *HistoryEntry) Finish(runErr error) error {
+	e.Duration = time.Since(e.Started)
+	e.ExitCode = exitCode(runErr)
+	if runErr != nil {
+		e.Error = runErr.Error()
+	}
+	if e.log != nil {
+		e.log.Close()
+	}
+	if e.output != nil {
+		if runErr != nil {
+			fmt.Fprintf(e.prefixed, "✗ failed after %s with exit code %d\n", e.Duration.Round(time.Millisecond), e.ExitCode)
+		} else {
+			fmt.Fprintf(e.prefixed, "✓ finished in %s\n", e.Duration.Round(time.Millisecond))
+		}
+		e.output.Close()
+	}
+
+	dir, err := HistoryDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	return recordRun(dir, e)
+}
+
+// exitCode returns the exit code of a failed script, 0 if it succeeded and
+// -1 if it couldn't be run at all
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// recordRun appends a run to the history and removes the oldest run logs
+func recordRun(dir string, entry *HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	path := filepath.Join(dir, "scripts.jsonl")
+	if info, err := os.Stat(path); err == nil && info.Size() > maxHistorySize {
+		os.Rename(path, path+".1")
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	// Run logs are named after when they started, so they sort by age
+	logs, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+	sort.Strings(logs)
+	for len(logs) > maxHistoryLogs {
+		os.Remove(logs[0])
+		logs = logs[1:]
+	}
+	return nil
+}
+
+// History returns the last n runs of scripts of app, all of them when n is
+// 0, oldest first. An empty name matches every script.
+func History(app string, name string, n int) ([]HistoryEntry, error) {
+	dir, err := HistoryDir()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, "scripts.jsonl"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e HistoryEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		if e.App != app || (name != "" && e.Name != name) {
+			continue
+		}
+		entries = append(entries, e)
+		if n > 0 && len(entries) > n {
+			entries = entries[1:]
+		}
+	}
+	return entries, scanner.Err()
+}
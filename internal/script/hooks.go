@@ -0,0 +1,109 @@
+package script
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	spinconfig "github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/logger"
+)
+
+// webhookTimeout bounds how long posting to a hook webhook may take
+const webhookTimeout = 5 * time.Second
+
+// HookEvent is what triggered a hook point. Its commands get it in SPIN_HOOK,
+// SPIN_APP, SPIN_NAME and SPIN_MESSAGE, and webhooks get it as JSON.
+type HookEvent struct {
+	Hook    string    `json:"hook"`
+	App     string    `json:"app"`
+	Name    string    `json:"name,omitempty"` // Process or service the event is about
+	Message string    `json:"message,omitempty"`
+	Text    string    `json:"text"` // Summary of the event, which Slack shows
+	Time    time.Time `json:"time"`
+}
+
+// summary describes the event in a sentence
+func (e HookEvent) summary() string {
+	switch e.Hook {
+	case "on_process_crash":
+		if e.Message != "" {
+			return fmt.Sprintf("%s of %s exited unexpectedly (%s)", e.Name, e.App, e.Message)
+		}
+		return fmt.Sprintf("%s of %s exited unexpectedly", e.Name, e.App)
+	case "on_service_unhealthy":
+		return fmt.Sprintf("The health check of service %s of %s is failing", e.Name, e.App)
+	}
+	return fmt.Sprintf("spin %s of %s", e.Hook, e.App)
+}
+
+// env returns the variables describing the event to the hook's commands
+func (e HookEvent) env() map[string]string {
+	env := map[string]string{"SPIN_HOOK": e.Hook, "SPIN_APP": e.App}
+	if e.Name != "" {
+		env["SPIN_NAME"] = e.Name
+	}
+	if e.Message != "" {
+		env["SPIN_MESSAGE"] = e.Message
+	}
+	return env
+}
+
+// RunHook posts an event to the webhooks of its hook point and runs the
+// point's steps. Webhooks that fail are reported as warnings, only failing
+// steps return an error.
+func (m *Manager) RunHook(hooks *spinconfig.LifecycleHooks, e HookEvent, opts LifecycleOptions) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Text == "" {
+		e.Text = e.summary()
+	}
+
+	for _, webhook := range hooks.WebhooksFor(e.Hook) {
+		if err := PostWebhook(webhook, e); err != nil {
+			logger.Warnf("%s webhook: %v", e.Hook, err)
+		}
+	}
+
+	env := make(map[string]string, len(opts.Env)+4)
+	for key, value := range opts.Env {
+		env[key] = value
+	}
+	for key, value := range e.env() {
+		env[key] = value
+	}
+	opts.Env = env
+	return m.RunLifecycle(e.Hook, hooks.Steps(e.Hook), opts)
+}
+
+// PostWebhook posts an event as JSON to a webhook
+func PostWebhook(webhook spinconfig.HookWebhook, e HookEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range webhook.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
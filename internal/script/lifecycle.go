@@ -0,0 +1,99 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/afomera/spin/internal/events"
+	"github.com/afomera/spin/internal/logger"
+)
+
+// LifecycleOptions configure how the hooks of spin up and spin down run
+type LifecycleOptions struct {
+	App         string            // Project the hooks belong to
+	WorkDir     string            // Directory the commands run in
+	Env         map[string]string // Environment of the commands
+	MaxParallel int               // Commands of a step run at once, all of them when 0
+}
+
+// RunLifecycle runs the steps of a hook point one after another. The
+// commands of a step with several of them run concurrently with their output
+// prefixed, and the step fails if any of them fails. Commands naming a script
+// of the manager run the script with its own hooks.
+func (m *Manager) RunLifecycle(point string, steps [][]string, opts LifecycleOptions) error {
+	for _, step := range steps {
+		if len(step) == 1 {
+			if err := m.runLifecycleCommand(point, step[0], opts, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		limit := opts.MaxParallel
+		if limit <= 0 || limit > len(step) {
+			limit = len(step)
+		}
+		slots := make(chan struct{}, limit)
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(step))
+		for i, command := range step {
+			wg.Add(1)
+			go func(i int, command string) {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+				errs[i] = m.runLifecycleCommand(point, command, opts, true)
+			}(i, command)
+		}
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runLifecycleCommand runs a script or command of a hook point, recording it
+// in the script history
+func (m *Manager) runLifecycleCommand(point string, command string, opts LifecycleOptions, concurrent bool) error {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+
+	name := command
+	s, err := m.Get(command)
+	if err != nil {
+		s = nil
+		name = fmt.Sprintf("%s:%s", point, strings.Fields(command)[0])
+	}
+
+	runOpts := &RunOptions{Env: opts.Env, WorkDir: opts.WorkDir}
+	if concurrent {
+		runOpts.Stdout = logger.CreatePrefixedWriter(name)
+	}
+
+	fmt.Printf("%s-> Running %s hook: %s%s\n", logger.Blue, point, command, logger.Reset)
+	entry, runOpts := StartRun(opts.App, name, runOpts)
+	if s != nil {
+		err = m.Run(s.Name, runOpts)
+	} else {
+		err = NewScript(name, command, "").Execute(runOpts)
+	}
+	entry.Finish(err)
+
+	event := events.Event{Type: events.ScriptRun, App: opts.App, Name: name}
+	if err != nil {
+		event.Type = events.ScriptFailed
+		event.Message = err.Error()
+	}
+	events.Publish(event)
+
+	if err != nil {
+		return fmt.Errorf("%s hook %q failed: %w", point, command, err)
+	}
+	return nil
+}
@@ -60,25 +60,38 @@ func (m *Manager) Run(name string, opts *RunOptions) error {
 		return err
 	}
 
+	// Fill in the parameters of the command and hooks
+	var args []string
+	if opts != nil {
+		args = opts.Args
+	}
+	script, err = script.Bind(args)
+	if err != nil {
+		return err
+	}
+
 	// Run pre hooks
 	if err := m.runHooks(script, "pre", opts); err != nil {
 		if opts != nil && opts.SkipHooksOnError {
 			// Log warning about skipping failed hook
-			fmt.Printf("Warning: Pre-hook failed but continuing due to SkipHooksOnError: %v\n", err)
+			fmt.Fprintf(opts.stdout(), "Warning: Pre-hook failed but continuing due to SkipHooksOnError: %v\n", err)
 		} else {
 			return err
 		}
 	}
 
 	// Run the main script
+	opts.progress(Step{Name: script.Name, Description: script.Description})
 	if err := script.Execute(opts); err != nil {
-		return NewExecutionError(fmt.Sprintf("failed to execute script %s", name), err.Error())
+		opts.progress(Step{Name: script.Name, Description: script.Description, Done: true, Err: err})
+		return NewExecutionError(fmt.Sprintf("failed to execute script %s", name), err.Error()).WithCause(err)
 	}
+	opts.progress(Step{Name: script.Name, Description: script.Description, Done: true})
 
 	// Run post hooks
 	if err := m.runHooks(script, "post", opts); err != nil {
 		if opts != nil && opts.SkipHooksOnError {
-			fmt.Printf("Warning: Post-hook failed but continuing due to SkipHooksOnError: %v\n", err)
+			fmt.Fprintf(opts.stdout(), "Warning: Post-hook failed but continuing due to SkipHooksOnError: %v\n", err)
 		} else {
 			return err
 		}
@@ -103,12 +116,15 @@ func (m *Manager) runHooks(script *Script, hookType string, opts *RunOptions) er
 	hookScript.Env = hook.Env
 
 	// Execute the hook
+	opts.progress(Step{Name: hookType, Description: hook.Description})
 	if err := hookScript.Execute(opts); err != nil {
+		opts.progress(Step{Name: hookType, Description: hook.Description, Done: true, Err: err})
 		return NewHookError(
 			fmt.Sprintf("failed to execute %s hook for script %s", hookType, script.Name),
 			err.Error(),
-		)
+		).WithCause(err)
 	}
+	opts.progress(Step{Name: hookType, Description: hook.Description, Done: true})
 
 	return nil
 }
@@ -0,0 +1,190 @@
+package script
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ArgsPlaceholder is replaced by the arguments of a run that aren't named
+// parameters
+const ArgsPlaceholder = "args"
+
+// Param is a named parameter of a script, used as {{name}} in its command
+// and hooks and given as --name value when running it
+type Param struct {
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// placeholderPattern matches placeholders like {{tag}} or {{ args }}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// safeShellWord matches arguments that don't need quoting in a shell
+var safeShellWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ParamNames returns the names of the parameters of the script, those
+// declared in its configuration and those used as placeholders, sorted
+func (s *Script) ParamNames() []string {
+	seen := make(map[string]bool)
+	for name := range s.Params {
+		seen[name] = true
+	}
+	for _, command := range s.commands() {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(command, -1) {
+			if match[1] != ArgsPlaceholder {
+				seen[match[1]] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Bind returns a copy of the script with the placeholders of its command and
+// hooks replaced by the arguments of a run. Named parameters are given as
+// --name value or --name=value, and the other arguments replace {{args}}, or
+// are appended to the command if it doesn't use them.
+func (s *Script) Bind(args []string) (*Script, error) {
+	values, rest := s.parseArgs(args)
+
+	var missing []string
+	for _, name := range s.ParamNames() {
+		if _, ok := values[name]; ok {
+			continue
+		}
+		param, declared := s.Params[name]
+		switch {
+		case !declared || param.Required && param.Default == "":
+			missing = append(missing, name)
+		default:
+			values[name] = param.Default
+		}
+	}
+	if len(missing) > 0 {
+		flags := make([]string, len(missing))
+		for i, name := range missing {
+			flags[i] = fmt.Sprintf("--%s <value>", name)
+		}
+		return nil, NewValidationError(
+			fmt.Sprintf("script %s is missing required parameters: %s", s.Name, strings.Join(missing, ", ")),
+		).WithFix(fmt.Sprintf("Run it with: spin scripts run %s -- %s", s.Name, strings.Join(flags, " ")))
+	}
+
+	quote := func(v string) string { return v }
+	if s.runsInShell() {
+		quote = ShellQuote
+	}
+	quoted := make([]string, len(rest))
+	for i, arg := range rest {
+		quoted[i] = quote(arg)
+	}
+	render := func(command string) string {
+		return placeholderPattern.ReplaceAllStringFunc(command, func(placeholder string) string {
+			name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+			if name == ArgsPlaceholder {
+				return strings.Join(quoted, " ")
+			}
+			return quote(values[name])
+		})
+	}
+
+	bound := *s
+	bound.Command = render(s.Command)
+	if len(quoted) > 0 && !usesArgs(s.Command) {
+		bound.Command += " " + strings.Join(quoted, " ")
+	}
+	bound.Hooks = make(map[string]*Hook, len(s.Hooks))
+	for name, hook := range s.Hooks {
+		if hook == nil {
+			continue
+		}
+		h := *hook
+		h.Command = render(hook.Command)
+		bound.Hooks[name] = &h
+	}
+	return &bound, nil
+}
+
+// parseArgs splits the arguments of a run into the values of named
+// parameters and the remaining arguments. Everything after -- is passed on
+// as is.
+func (s *Script) parseArgs(args []string) (map[string]string, []string) {
+	params := make(map[string]bool)
+	for _, name := range s.ParamNames() {
+		params[name] = true
+	}
+
+	values := make(map[string]string)
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "--") {
+			rest = append(rest, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if !params[name] {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		values[name] = value
+	}
+	return values, rest
+}
+
+// commands returns the command of the script and of its hooks
+func (s *Script) commands() []string {
+	commands := []string{s.Command}
+	for _, hook := range s.Hooks {
+		if hook != nil {
+			commands = append(commands, hook.Command)
+		}
+	}
+	return commands
+}
+
+// runsInShell reports whether the command of the script is run by a shell,
+// in which case values are quoted to stay a single word
+func (s *Script) runsInShell() bool {
+	if s.Interpreter == "" {
+		return true
+	}
+	parts := strings.Fields(s.Interpreter)
+	return len(parts) > 0 && shells[filepath.Base(parts[0])]
+}
+
+// usesArgs reports whether a command has an {{args}} placeholder
+func usesArgs(command string) bool {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(command, -1) {
+		if match[1] == ArgsPlaceholder {
+			return true
+		}
+	}
+	return false
+}
+
+// ShellQuote quotes a value so the shell passes it as a single argument
+func ShellQuote(v string) string {
+	if safeShellWord.MatchString(v) {
+		return v
+	}
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
@@ -3,9 +3,14 @@ package script
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+
+	"github.com/afomera/spin/internal/userconfig"
+	"golang.org/x/term"
 )
 
 // Script represents a runnable script with optional hooks and environment variables
@@ -13,7 +18,9 @@ type Script struct {
 	Name        string            // Name of the script
 	Command     string            // Command to execute
 	Description string            // Description of what the script does
+	Interpreter string            // Program running the command instead of the shell, like bash or python3
 	Env         map[string]string // Environment variables for the script
+	Params      map[string]Param  // Named parameters used as {{name}} in the command
 	Hooks       map[string]*Hook  // Pre and post execution hooks
 }
 
@@ -41,7 +48,9 @@ func (s *Script) UnmarshalJSON(data []byte) error {
 		Name        string            `json:"name,omitempty"`
 		Command     string            `json:"command"`
 		Description string            `json:"description,omitempty"`
+		Interpreter string            `json:"interpreter,omitempty"`
 		Env         map[string]string `json:"env,omitempty"`
+		Params      map[string]Param  `json:"params,omitempty"`
 		Hooks       Hooks             `json:"hooks,omitempty"`
 	}
 
@@ -52,6 +61,8 @@ func (s *Script) UnmarshalJSON(data []byte) error {
 	s.Name = alias.Name
 	s.Command = alias.Command
 	s.Description = alias.Description
+	s.Interpreter = alias.Interpreter
+	s.Params = alias.Params
 	s.Env = alias.Env
 	if s.Env == nil {
 		s.Env = make(map[string]string)
@@ -85,13 +96,17 @@ func (s *Script) MarshalJSON() ([]byte, error) {
 		Name        string            `json:"name,omitempty"`
 		Command     string            `json:"command"`
 		Description string            `json:"description,omitempty"`
+		Interpreter string            `json:"interpreter,omitempty"`
 		Env         map[string]string `json:"env,omitempty"`
+		Params      map[string]Param  `json:"params,omitempty"`
 		Hooks       *Hooks            `json:"hooks,omitempty"`
 	}{
 		Name:        s.Name,
 		Command:     s.Command,
 		Description: s.Description,
+		Interpreter: s.Interpreter,
 		Env:         s.Env,
+		Params:      s.Params,
 	}
 
 	// Only include hooks if they exist
@@ -114,6 +129,70 @@ type RunOptions struct {
 	Env              map[string]string // Additional environment variables
 	WorkDir          string            // Working directory for script execution
 	SkipHooksOnError bool              // Whether to continue if a hook fails
+	Shell            string            // Shell commands run in, the shell of the user config when empty
+	Args             []string          // Arguments given after the script name, see Script.Bind
+
+	// Output of the script and its hooks, defaulting to the terminal. When set,
+	// the script doesn't read from stdin.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Log also receives the output of the script and its hooks, unless it
+	// goes to a terminal: teeing it would turn the terminal into a pipe,
+	// losing colors and prompts
+	Log io.Writer
+
+	// Progress is called when a hook or the script itself starts and finishes
+	Progress func(Step)
+}
+
+// Step reports the progress of one part of a script run
+type Step struct {
+	Name        string // "pre", "post" or the name of the script
+	Description string
+	Done        bool
+	Err         error
+}
+
+// progress reports a step if the caller asked for progress
+func (o *RunOptions) progress(step Step) {
+	if o != nil && o.Progress != nil {
+		o.Progress(step)
+	}
+}
+
+// stdout returns where the output of the script goes
+func (o *RunOptions) stdout() io.Writer {
+	if o != nil && o.Stdout != nil {
+		return o.Stdout
+	}
+	return os.Stdout
+}
+
+// shell returns the shell commands run in
+func (o *RunOptions) shell() string {
+	if o != nil && o.Shell != "" {
+		return o.Shell
+	}
+	if cfg, err := userconfig.Load(); err == nil {
+		return cfg.Shell()
+	}
+	return "sh"
+}
+
+// codeFlags are the flags interpreters take the code to run with, for those
+// that don't use -c like shells and python do
+var codeFlags = map[string]string{
+	"node":   "-e",
+	"ruby":   "-e",
+	"perl":   "-e",
+	"php":    "-r",
+	"elixir": "-e",
+}
+
+// shells are the interpreters that take the name of the script as $0
+var shells = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "fish": true,
 }
 
 // NewScript creates a new Script instance
@@ -190,14 +269,12 @@ func (s *Script) Execute(opts *RunOptions) error {
 		return fmt.Errorf("script command cannot be empty")
 	}
 
-	// Split the command into parts
-	parts := strings.Fields(s.Command)
-	if len(parts) == 0 {
-		return fmt.Errorf("invalid command format")
+	// Run the command through the shell, so pipes, quotes and variables work
+	// like they do in a terminal
+	cmd, err := s.command(opts)
+	if err != nil {
+		return err
 	}
-
-	// Create command with the merged environment
-	cmd := exec.Command(parts[0], parts[1:]...)
 	cmd.Env = s.mergeEnv(opts)
 
 	// Set working directory if specified
@@ -205,14 +282,64 @@ func (s *Script) Execute(opts *RunOptions) error {
 		cmd.Dir = opts.WorkDir
 	}
 
-	// Connect to standard streams
+	// Connect to standard streams, unless the caller captures the output
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
+	if opts != nil && opts.Stdout != nil {
+		cmd.Stdout = opts.Stdout
+		cmd.Stderr = opts.Stdout
+		if opts.Stderr != nil {
+			cmd.Stderr = opts.Stderr
+		}
+		cmd.Stdin = nil
+	}
+	if opts != nil && opts.Log != nil {
+		if !isTerminal(cmd.Stdout) {
+			cmd.Stdout = io.MultiWriter(cmd.Stdout, opts.Log)
+		}
+		if !isTerminal(cmd.Stderr) {
+			cmd.Stderr = io.MultiWriter(cmd.Stderr, opts.Log)
+		}
+	}
 
 	return cmd.Run()
 }
 
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// command returns the command running the script with its interpreter, or
+// the shell when it has none
+func (s *Script) command(opts *RunOptions) (*exec.Cmd, error) {
+	interpreter := s.Interpreter
+	if interpreter == "" {
+		interpreter = opts.shell()
+	}
+
+	// The interpreter may come with arguments, like "bash -eo pipefail"
+	parts := strings.Fields(interpreter)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("invalid interpreter %q", interpreter)
+	}
+
+	// Versioned binaries like python3 or ruby3.3 take the same flags
+	program := strings.TrimRight(filepath.Base(parts[0]), "0123456789.")
+	flag, ok := codeFlags[program]
+	if !ok {
+		flag = "-c"
+	}
+
+	args := append(parts[1:], flag, s.Command)
+	if shells[program] {
+		args = append(args, s.Name)
+	}
+	return exec.Command(parts[0], args...), nil
+}
+
 // Validate checks if the script is properly configured
 func (s *Script) Validate() error {
 	if s.Command == "" {
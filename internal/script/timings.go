@@ -0,0 +1,90 @@
+package script
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Timing records how long a spin command, like up or setup, took
+type Timing struct {
+	App      string        `json:"app"`
+	Command  string        `json:"command"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Failed   bool          `json:"failed,omitempty"`
+}
+
+// timingsFile is the file in the history directory timings are kept in
+const timingsFile = "commands.jsonl"
+
+// RecordTiming records that a command of app started at started has just
+// finished, successfully unless runErr is set
+func RecordTiming(app string, command string, started time.Time, runErr error) error {
+	dir, err := HistoryDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(Timing{
+		App:      app,
+		Command:  command,
+		Started:  started,
+		Duration: time.Since(started),
+		Failed:   runErr != nil,
+	})
+	if err != nil {
+		return err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	path := filepath.Join(dir, timingsFile)
+	if info, err := os.Stat(path); err == nil && info.Size() > maxHistorySize {
+		os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Timings returns the timings of the commands of app since a time, oldest
+// first
+func Timings(app string, since time.Time) ([]Timing, error) {
+	dir, err := HistoryDir()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, timingsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var timings []Timing
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var t Timing
+		if json.Unmarshal(scanner.Bytes(), &t) != nil {
+			continue
+		}
+		if t.App == app && !t.Started.Before(since) {
+			timings = append(timings, t)
+		}
+	}
+	return timings, scanner.Err()
+}
@@ -0,0 +1,99 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// BuildOptions configures the build of an image from a Dockerfile
+type BuildOptions struct {
+	Tag        string            // Tag of the built image
+	Dockerfile string            // Path of the Dockerfile
+	Context    string            // Directory sent as the build context
+	Target     string            // Stage of a multi-stage Dockerfile to build
+	Args       map[string]string // Build arguments
+	CacheFrom  []string          // Images to use as cache sources
+	NoCache    bool              // Build every step again
+	Pull       bool              // Pull newer versions of the base images
+}
+
+// BuildArgs returns the arguments of docker build for the options
+func (o BuildOptions) BuildArgs() []string {
+	args := []string{"build", "-t", o.Tag}
+	if o.Dockerfile != "" {
+		args = append(args, "-f", o.Dockerfile)
+	}
+	if o.Target != "" {
+		args = append(args, "--target", o.Target)
+	}
+
+	keys := make([]string, 0, len(o.Args))
+	for key := range o.Args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--build-arg", key+"="+o.Args[key])
+	}
+
+	for _, image := range o.CacheFrom {
+		args = append(args, "--cache-from", image)
+	}
+	if o.NoCache {
+		args = append(args, "--no-cache")
+	}
+	if o.Pull {
+		args = append(args, "--pull")
+	}
+
+	context := o.Context
+	if context == "" {
+		context = "."
+	}
+	return append(args, context)
+}
+
+// BuildImage builds an image from a Dockerfile with the docker CLI, which
+// sends the build context and shows the progress of the build
+func BuildImage(opts BuildOptions) error {
+	cmd := exec.Command("docker", opts.BuildArgs()...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to build %s: %w", opts.Tag, err)
+	}
+	return nil
+}
+
+// ImageExists checks if an image is available locally
+func (m *ServiceManager) ImageExists(image string) bool {
+	_, _, err := m.client.ImageInspectWithRaw(m.ctx, image)
+	return err == nil
+}
+
+// ProcessLabel marks the containers docker: processes run in with the name of
+// their process
+const ProcessLabel = "spin.process"
+
+// RemoveProcessContainers removes the containers the docker: processes of an
+// app run in, which can outlive their process when it is killed
+func (m *ServiceManager) RemoveProcessContainers(app string) error {
+	containers, err := m.client.ContainerList(m.ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", "spin.app="+app), filters.Arg("label", ProcessLabel)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, c := range containers {
+		if err := m.client.ContainerRemove(m.ctx, c.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return fmt.Errorf("failed to remove container of %s: %w", c.Labels[ProcessLabel], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+// NetworkName is the Docker network services and dev containers share, so
+// processes in a dev container reach services by their names
+const NetworkName = "spin"
+
+// EnsureNetwork creates the spin network unless it exists
+func (m *ServiceManager) EnsureNetwork() error {
+	networks, err := m.client.NetworkList(m.ctx, types.NetworkListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", NetworkName)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list networks: %w", err)
+	}
+	for _, n := range networks {
+		if n.Name == NetworkName {
+			return nil
+		}
+	}
+
+	if _, err := m.client.NetworkCreate(m.ctx, NetworkName, types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         "bridge",
+	}); err != nil {
+		return fmt.Errorf("failed to create network %s: %w", NetworkName, err)
+	}
+	return nil
+}
+
+// NetworkExists checks if the spin network exists
+func (m *ServiceManager) NetworkExists() bool {
+	_, err := m.client.NetworkInspect(m.ctx, NetworkName, types.NetworkInspectOptions{})
+	return err == nil
+}
+
+// RemoveNetwork removes the spin network, unless containers still use it.
+// It reports whether the network was removed.
+func (m *ServiceManager) RemoveNetwork() (bool, error) {
+	n, err := m.client.NetworkInspect(m.ctx, NetworkName, types.NetworkInspectOptions{})
+	if err != nil {
+		return false, nil
+	}
+	if len(n.Containers) > 0 {
+		return false, nil
+	}
+	if err := m.client.NetworkRemove(m.ctx, n.ID); err != nil {
+		return false, fmt.Errorf("failed to remove network %s: %w", NetworkName, err)
+	}
+	return true, nil
+}
+
+// ConnectServices joins the containers of services to the spin network.
+// Containers created before spin had the network aren't on it, and dev
+// containers couldn't reach them by name.
+func (m *ServiceManager) ConnectServices(names []string) error {
+	if err := m.EnsureNetwork(); err != nil {
+		return err
+	}
+	for _, name := range names {
+		id, err := m.FindContainer(name)
+		if err != nil {
+			continue
+		}
+		info, err := m.client.ContainerInspect(m.ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", name, err)
+		}
+		if info.NetworkSettings != nil {
+			if _, ok := info.NetworkSettings.Networks[NetworkName]; ok {
+				continue
+			}
+		}
+		if err := m.client.NetworkConnect(m.ctx, NetworkName, id, &network.EndpointSettings{Aliases: []string{name}}); err != nil {
+			return fmt.Errorf("failed to connect %s to network %s: %w", name, NetworkName, err)
+		}
+	}
+	return nil
+}
+
+// networkingConfig joins a container to the spin network under an alias
+func networkingConfig(alias string) *network.NetworkingConfig {
+	return &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			NetworkName: {Aliases: []string{alias}},
+		},
+	}
+}
+
+// DevContainerName returns the name of the dev container of an app, without
+// the spin_ prefix of containers
+func DevContainerName(app string) string {
+	return containerSafe(app) + "_dev"
+}
+
+// ProcessContainerName returns the name of the container a docker: process
+// of an app runs in, without the spin_ prefix of containers
+func ProcessContainerName(app string, process string) string {
+	return containerSafe(app) + "_" + containerSafe(process)
+}
+
+// containerSafe replaces the characters Docker doesn't allow in container
+// names
+func containerSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+}
+
+// devImage returns the image of a dev container, the configured one or the
+// tag its Dockerfile is built as
+func devImage(app string, cfg *config.DevContainerConfig) string {
+	if cfg.Image != "" {
+		return cfg.Image
+	}
+	return "spin-dev-" + strings.ToLower(strings.TrimSuffix(DevContainerName(app), "_dev"))
+}
+
+// StartDevContainer starts the dev container of an app with the project in
+// root mounted into it, building its image first when it comes from a
+// Dockerfile. A running dev container is reused. It returns the name of the
+// container to run commands in.
+func (m *ServiceManager) StartDevContainer(app string, root string, cfg *config.DevContainerConfig) (string, error) {
+	name := DevContainerName(app)
+	containerName := "spin_" + name
+	if m.IsRunning(name) {
+		return containerName, nil
+	}
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	image := devImage(app, cfg)
+	if cfg.Dockerfile != "" {
+		context := filepath.Join(root, cfg.Context)
+		if err := BuildImage(BuildOptions{Tag: image, Dockerfile: filepath.Join(root, cfg.Dockerfile), Context: context}); err != nil {
+			return "", err
+		}
+	} else if err := m.ensureImage(&config.DockerServiceConfig{Image: image}); err != nil {
+		return "", err
+	}
+
+	if err := m.EnsureNetwork(); err != nil {
+		return "", err
+	}
+	if existingID, _ := m.FindContainer(name); existingID != "" {
+		if err := m.client.ContainerRemove(m.ctx, existingID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return "", fmt.Errorf("failed to remove old dev container: %w", err)
+		}
+	}
+
+	exposed := nat.PortSet{}
+	bindings := nat.PortMap{}
+	for _, port := range cfg.Ports {
+		containerPort := nat.Port(fmt.Sprintf("%d/tcp", port))
+		exposed[containerPort] = struct{}{}
+		bindings[containerPort] = []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: fmt.Sprintf("%d", port)}}
+	}
+
+	// The container idles, processes are started in it with docker exec
+	useInit := true
+	resp, err := m.client.ContainerCreate(
+		m.ctx,
+		&container.Config{
+			Image:        image,
+			Cmd:          []string{"sleep", "infinity"},
+			Env:          m.mapToEnvSlice(cfg.Env),
+			WorkingDir:   cfg.GetWorkDir(),
+			User:         cfg.User,
+			ExposedPorts: exposed,
+			Labels:       map[string]string{"spin.app": app, "spin.dev_container": "true"},
+		},
+		&container.HostConfig{
+			Init:         &useInit,
+			PortBindings: bindings,
+			Mounts: []mount.Mount{
+				{Type: mount.TypeBind, Source: root, Target: cfg.GetWorkDir()},
+			},
+		},
+		networkingConfig(name),
+		nil,
+		containerName,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dev container: %w", err)
+	}
+	if err := m.client.ContainerStart(m.ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", fmt.Errorf("failed to start dev container: %w", err)
+	}
+	return containerName, nil
+}
+
+// StopDevContainer stops and removes the dev container of an app, which ends
+// the processes running in it
+func (m *ServiceManager) StopDevContainer(app string) error {
+	id, err := m.FindContainer(DevContainerName(app))
+	if err != nil {
+		return nil
+	}
+	timeout := 10 * time.Second
+	if err := m.client.ContainerStop(m.ctx, id, &timeout); err != nil {
+		return fmt.Errorf("failed to stop dev container: %w", err)
+	}
+	if err := m.client.ContainerRemove(m.ctx, id, types.ContainerRemoveOptions{}); err != nil {
+		return fmt.Errorf("failed to remove dev container: %w", err)
+	}
+	return nil
+}
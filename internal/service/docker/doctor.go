@@ -0,0 +1,225 @@
+package docker
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/docker/docker/client"
+)
+
+// DiagnosticStatus is the outcome of a single diagnostic check
+type DiagnosticStatus int
+
+const (
+	// DiagnosticOK means the check passed
+	DiagnosticOK DiagnosticStatus = iota
+	// DiagnosticWarn means the check found something worth looking at
+	DiagnosticWarn
+	// DiagnosticFail means the check found a problem that breaks the service
+	DiagnosticFail
+)
+
+// Diagnostic is the result of a service check with a suggested fix
+type Diagnostic struct {
+	Check   string
+	Status  DiagnosticStatus
+	Message string
+	Fix     string
+}
+
+// Diagnose runs a series of checks against a service and its container
+func (m *ServiceManager) Diagnose(name string, cfg *config.DockerServiceConfig) []Diagnostic {
+	var results []Diagnostic
+
+	// Image availability
+	if _, _, err := m.client.ImageInspectWithRaw(m.ctx, cfg.Image); err == nil {
+		results = append(results, Diagnostic{Check: "image", Status: DiagnosticOK, Message: fmt.Sprintf("%s is available locally", cfg.Image)})
+	} else if client.IsErrNotFound(err) {
+		results = append(results, Diagnostic{
+			Check:   "image",
+			Status:  DiagnosticWarn,
+			Message: fmt.Sprintf("%s has not been pulled", cfg.Image),
+			Fix:     fmt.Sprintf("Run 'spin services start %s' to pull it, or check the image name and tag", name),
+		})
+	} else {
+		results = append(results, Diagnostic{
+			Check:   "image",
+			Status:  DiagnosticFail,
+			Message: fmt.Sprintf("failed to inspect %s: %v", cfg.Image, err),
+			Fix:     "Check that the Docker daemon is running",
+		})
+	}
+
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		results = append(results, Diagnostic{
+			Check:   "container",
+			Status:  DiagnosticFail,
+			Message: "no container exists for this service",
+			Fix:     fmt.Sprintf("Run 'spin services start %s'", name),
+		})
+		return results
+	}
+
+	container, err := m.client.ContainerInspect(m.ctx, containerID)
+	if err != nil {
+		results = append(results, Diagnostic{
+			Check:   "container",
+			Status:  DiagnosticFail,
+			Message: fmt.Sprintf("failed to inspect container: %v", err),
+			Fix:     "Check that the Docker daemon is running",
+		})
+		return results
+	}
+
+	// Restart loops and crashes
+	switch {
+	case container.State.Restarting || container.RestartCount > 3:
+		results = append(results, Diagnostic{
+			Check:   "restarts",
+			Status:  DiagnosticFail,
+			Message: fmt.Sprintf("container has restarted %d times", container.RestartCount),
+			Fix:     fmt.Sprintf("Inspect 'spin services logs %s' for the crash reason", name),
+		})
+	case container.State.OOMKilled:
+		results = append(results, Diagnostic{
+			Check:   "restarts",
+			Status:  DiagnosticFail,
+			Message: "container was killed because it ran out of memory",
+			Fix:     "Increase the memory available to Docker",
+		})
+	case !container.State.Running && container.State.ExitCode != 0:
+		results = append(results, Diagnostic{
+			Check:   "restarts",
+			Status:  DiagnosticFail,
+			Message: fmt.Sprintf("container exited with code %d", container.State.ExitCode),
+			Fix:     fmt.Sprintf("Inspect 'spin services logs %s' for the exit reason", name),
+		})
+	case !container.State.Running:
+		results = append(results, Diagnostic{
+			Check:   "restarts",
+			Status:  DiagnosticWarn,
+			Message: "container is stopped",
+			Fix:     fmt.Sprintf("Run 'spin services start %s'", name),
+		})
+	default:
+		results = append(results, Diagnostic{Check: "restarts", Status: DiagnosticOK, Message: "container is running without restarts"})
+	}
+
+	// Port reachability from the host
+	if cfg.Port != 0 {
+		address := fmt.Sprintf("127.0.0.1:%d", cfg.PublishedPort())
+		conn, err := net.DialTimeout("tcp", address, 2*time.Second)
+		if err == nil {
+			conn.Close()
+			results = append(results, Diagnostic{Check: "port", Status: DiagnosticOK, Message: fmt.Sprintf("%s is reachable", address)})
+		} else if container.State.Running {
+			results = append(results, Diagnostic{
+				Check:   "port",
+				Status:  DiagnosticFail,
+				Message: fmt.Sprintf("%s is not reachable: %v", address, err),
+				Fix:     "Check that the service listens on the configured port, or that no other process owns it",
+			})
+		}
+	}
+
+	// Volume mounts
+	mounted := make(map[string]bool)
+	for _, mnt := range container.Mounts {
+		mounted[mnt.Destination] = true
+	}
+	for key, target := range cfg.Volumes {
+		if mounted[target] {
+			results = append(results, Diagnostic{Check: "volume", Status: DiagnosticOK, Message: fmt.Sprintf("%s is mounted at %s", key, target)})
+			continue
+		}
+		results = append(results, Diagnostic{
+			Check:   "volume",
+			Status:  DiagnosticFail,
+			Message: fmt.Sprintf("%s is not mounted at %s, data will be lost when the container is removed", key, target),
+			Fix:     fmt.Sprintf("Run 'spin services restart %s' to recreate the container with its volumes", name),
+		})
+	}
+
+	// Native checks need nothing installed, only their answer matters
+	if hc := cfg.HealthCheck; hc.Native() && container.State.Running {
+		check := fmt.Sprintf("something in the container listens on port %d", hc.TCP)
+		if hc.HTTP != nil {
+			check = hc.HTTP.URL + " answers"
+		}
+		if status, _ := m.HealthStatus(name); status == "unhealthy" {
+			results = append(results, Diagnostic{
+				Check:   "health check",
+				Status:  DiagnosticFail,
+				Message: fmt.Sprintf("spin checks that %s, which fails", check),
+				Fix:     "Check that the service listens on the port of its check, and the service logs",
+			})
+		} else {
+			results = append(results, Diagnostic{Check: "health check", Status: DiagnosticOK, Message: fmt.Sprintf("spin checks that %s", check)})
+		}
+	}
+
+	// Health check command validity
+	if cfg.HealthCheck != nil && !cfg.HealthCheck.Native() && len(cfg.HealthCheck.Command) > 0 && container.State.Running {
+		command := cfg.HealthCheck.Command
+		if (command[0] == "CMD" || command[0] == "CMD-SHELL") && len(command) > 1 {
+			command = command[1:]
+		}
+		// Shell-form checks carry the whole command line in one string
+		binary := strings.TrimSpace(command[0])
+		if fields := strings.Fields(binary); len(fields) > 0 {
+			binary = fields[0]
+		}
+		if cfg.HealthCheck.OnHost() {
+			if _, err := exec.LookPath(binary); err != nil {
+				results = append(results, Diagnostic{
+					Check:   "health check",
+					Status:  DiagnosticFail,
+					Message: fmt.Sprintf("%s is not installed on the host", binary),
+					Fix:     fmt.Sprintf("Install %s, or remove health_check.mode to run the check in the container", binary),
+				})
+			} else if status, _ := m.HealthStatus(name); status == "unhealthy" {
+				results = append(results, Diagnostic{
+					Check:   "health check",
+					Status:  DiagnosticFail,
+					Message: "the check run on the host fails",
+					Fix:     fmt.Sprintf("Run %s yourself and check the service logs", strings.Join(cfg.HealthCheck.Command, " ")),
+				})
+			} else {
+				results = append(results, Diagnostic{Check: "health check", Status: DiagnosticOK, Message: fmt.Sprintf("%s is available on the host", binary)})
+			}
+		} else if _, err := m.Exec(containerID, []string{"sh", "-c", "command -v " + binary}); err != nil {
+			fix := "Update health_check.command to use a binary shipped with the image"
+			if _, err := exec.LookPath(binary); err == nil {
+				fix += `, or set health_check.mode to "host" to run it on the host`
+			}
+			results = append(results, Diagnostic{
+				Check:   "health check",
+				Status:  DiagnosticFail,
+				Message: fmt.Sprintf("%s is not available inside the container", binary),
+				Fix:     fix,
+			})
+		} else {
+			results = append(results, Diagnostic{Check: "health check", Status: DiagnosticOK, Message: fmt.Sprintf("%s is available inside the container", binary)})
+		}
+
+		if container.State.Health != nil && container.State.Health.Status == "unhealthy" {
+			fix := "Check the health check command and the service logs"
+			if n := len(container.State.Health.Log); n > 0 {
+				fix = fmt.Sprintf("Last check output: %s", container.State.Health.Log[n-1].Output)
+			}
+			results = append(results, Diagnostic{
+				Check:   "health check",
+				Status:  DiagnosticFail,
+				Message: fmt.Sprintf("container is unhealthy after %d failing checks", container.State.Health.FailingStreak),
+				Fix:     fix,
+			})
+		}
+	}
+
+	return results
+}
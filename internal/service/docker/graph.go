@@ -0,0 +1,297 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/logger"
+)
+
+// CycleError is returned when service dependencies form a cycle
+type CycleError struct {
+	Cycle []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("dependency cycle detected: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// ResolveStartOrder builds the dependency graph for the named services and
+// returns them grouped in levels. Every service in a level only depends on
+// services from earlier levels, so a level can be started in parallel.
+func ResolveStartOrder(services map[string]*config.DockerServiceConfig, names []string) ([][]string, error) {
+	// Collect the requested services and everything they depend on
+	deps := make(map[string][]string)
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		for i, p := range path {
+			if p == name {
+				cycle := append(append([]string{}, path[i:]...), name)
+				return &CycleError{Cycle: cycle}
+			}
+		}
+		if _, seen := deps[name]; seen {
+			return nil
+		}
+
+		cfg, ok := services[name]
+		if !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("service %s depends on unknown service %s", path[len(path)-1], name)
+			}
+			return fmt.Errorf("service %s not found", name)
+		}
+
+		path = append(path, name)
+		for _, dep := range cfg.DependsOn {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		deps[name] = cfg.DependsOn
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// Peel off services whose dependencies are all satisfied
+	var levels [][]string
+	done := make(map[string]bool)
+	for len(done) < len(deps) {
+		var level []string
+		for name, ds := range deps {
+			if done[name] {
+				continue
+			}
+			ready := true
+			for _, d := range ds {
+				if !done[d] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, name)
+			}
+		}
+		sort.Strings(level)
+		for _, name := range level {
+			done[name] = true
+		}
+		levels = append(levels, level)
+	}
+
+	return levels, nil
+}
+
+// StartServices starts the named services and their dependencies in
+// dependency order. Services within the same level are started in parallel
+// and services that are already running are left alone, or unpaused when they
+// are paused. Startup stops at the first level with a failure.
+func (m *ServiceManager) StartServices(services map[string]*config.DockerServiceConfig, names []string) error {
+	levels, err := ResolveStartOrder(services, names)
+	if err != nil {
+		return err
+	}
+
+	for _, level := range levels {
+		var wg sync.WaitGroup
+		errs := make([]error, len(level))
+		for i, name := range level {
+			if m.IsPaused(name) {
+				logger.Printf("Unpausing %s...\n", name)
+				if err := m.UnpauseService(name); err != nil {
+					errs[i] = err
+				}
+				continue
+			}
+			if m.IsRunning(name) {
+				logger.Printf("Service %s is already running\n", name)
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				logger.Printf("Starting %s...\n", name)
+				if err := m.StartService(name, services[name]); err != nil {
+					errs[i] = fmt.Errorf("failed to start %s: %w", name, err)
+				}
+			}(i, name)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Results of starting or stopping a service in a batch
+const (
+	ResultStarted        = "started"
+	ResultUnpaused       = "unpaused"
+	ResultStopped        = "stopped"
+	ResultAlreadyRunning = "already running"
+	ResultNotRunning     = "not running"
+	ResultSkipped        = "skipped"
+	ResultFailed         = "failed"
+)
+
+// ServiceResult is the outcome of starting or stopping one service of a batch
+type ServiceResult struct {
+	Name     string
+	Result   string
+	Duration time.Duration
+	Err      error
+}
+
+// StartServicesBatch starts the named services and their dependencies like
+// StartServices, but carries on after a failure. Services whose dependencies
+// failed are skipped. It returns the outcome of every service in start order.
+func (m *ServiceManager) StartServicesBatch(services map[string]*config.DockerServiceConfig, names []string) ([]ServiceResult, error) {
+	levels, err := ResolveStartOrder(services, names)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ServiceResult
+	failed := make(map[string]bool)
+	for _, level := range levels {
+		batch := make([]ServiceResult, len(level))
+		var wg sync.WaitGroup
+		for i, name := range level {
+			batch[i].Name = name
+			if dep := failedDependency(services[name], failed); dep != "" {
+				batch[i].Result = ResultSkipped
+				batch[i].Err = fmt.Errorf("dependency %s failed", dep)
+				continue
+			}
+			if m.IsPaused(name) {
+				batch[i].Err = m.UnpauseService(name)
+				batch[i].Result = ResultUnpaused
+				if batch[i].Err != nil {
+					batch[i].Result = ResultFailed
+				}
+				continue
+			}
+			if m.IsRunning(name) {
+				batch[i].Result = ResultAlreadyRunning
+				continue
+			}
+
+			wg.Add(1)
+			go func(r *ServiceResult) {
+				defer wg.Done()
+				started := time.Now()
+				r.Err = m.StartService(r.Name, services[r.Name])
+				r.Duration = time.Since(started)
+				r.Result = ResultStarted
+				if r.Err != nil {
+					r.Result = ResultFailed
+				}
+			}(&batch[i])
+		}
+		wg.Wait()
+
+		for _, r := range batch {
+			if r.Err != nil {
+				failed[r.Name] = true
+			}
+		}
+		results = append(results, batch...)
+	}
+
+	return results, nil
+}
+
+// failedDependency returns a dependency of a service that failed to start, or
+// an empty string
+func failedDependency(cfg *config.DockerServiceConfig, failed map[string]bool) string {
+	if cfg == nil {
+		return ""
+	}
+	for _, dep := range cfg.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+// StopServicesBatch stops the named services in parallel, stopping services
+// before the services they depend on. Services that aren't in the
+// configuration are stopped first, without hooks. It returns the outcome of
+// every service in stop order.
+func (m *ServiceManager) StopServicesBatch(services map[string]*config.DockerServiceConfig, names []string) []ServiceResult {
+	var configured, unknown []string
+	for _, name := range names {
+		if _, ok := services[name]; ok {
+			configured = append(configured, name)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+
+	// Reverse the start order, keeping only the services asked for. Ignore a
+	// broken graph and stop everything at once rather than nothing.
+	requested := make(map[string]bool)
+	for _, name := range configured {
+		requested[name] = true
+	}
+	levels := [][]string{unknown}
+	if order, err := ResolveStartOrder(services, configured); err == nil {
+		for i := len(order) - 1; i >= 0; i-- {
+			var level []string
+			for _, name := range order[i] {
+				if requested[name] {
+					level = append(level, name)
+				}
+			}
+			levels = append(levels, level)
+		}
+	} else {
+		levels[0] = append(levels[0], configured...)
+	}
+
+	var results []ServiceResult
+	for _, level := range levels {
+		batch := make([]ServiceResult, len(level))
+		var wg sync.WaitGroup
+		for i, name := range level {
+			batch[i].Name = name
+			if !m.IsRunning(name) {
+				batch[i].Result = ResultNotRunning
+				continue
+			}
+
+			wg.Add(1)
+			go func(r *ServiceResult) {
+				defer wg.Done()
+				started := time.Now()
+				r.Err = m.StopService(r.Name, services[r.Name])
+				r.Duration = time.Since(started)
+				r.Result = ResultStopped
+				if r.Err != nil {
+					r.Result = ResultFailed
+				}
+			}(&batch[i])
+		}
+		wg.Wait()
+		results = append(results, batch...)
+	}
+
+	return results
+}
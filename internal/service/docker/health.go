@@ -0,0 +1,374 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// HealthCheckLabel holds the health check of containers whose check runs on
+// the host, so every spin command finds it without the project's config
+const HealthCheckLabel = "spin.health_check"
+
+// Defaults of host checks, the same as those of Docker HEALTHCHECKs
+const (
+	defaultCheckInterval = 30 * time.Second
+	defaultCheckTimeout  = 30 * time.Second
+	defaultCheckRetries  = 3
+)
+
+// hostCheckPoll is the time between host checks while waiting for a service
+// to become healthy
+const hostCheckPoll = time.Second
+
+// hostCheckState is what spin knows about the host check of a container
+type hostCheckState struct {
+	status   string
+	failures int // Failed checks in a row
+	last     time.Time
+}
+
+// hostChecks tracks host checks by container, so long running commands like
+// the monitor run them on their interval and count retries like Docker does
+var (
+	hostChecksMu sync.Mutex
+	hostChecks   = make(map[string]*hostCheckState)
+)
+
+// WaitForHealthy blocks until the named service reports healthy or the
+// timeout expires. Services without a health check are considered healthy
+// as soon as they are running.
+func (m *ServiceManager) WaitForHealthy(name string, timeout time.Duration) error {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return err
+	}
+	return m.waitForHealthy(containerID, timeout)
+}
+
+// HealthStatus returns the health status of a running service, such as
+// "healthy" or "unhealthy". It is empty for services without a health check.
+func (m *ServiceManager) HealthStatus(name string) (string, error) {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return "", err
+	}
+
+	container, err := m.client.ContainerInspect(m.ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+	if hc := hostHealthCheck(container.Config.Labels); hc != nil {
+		if container.State == nil || !container.State.Running {
+			return "", nil
+		}
+		startedAt, _ := time.Parse(time.RFC3339Nano, container.State.StartedAt)
+		return m.hostHealthStatus(containerID, hc, startedAt), nil
+	}
+	if container.State == nil || container.State.Health == nil {
+		return "", nil
+	}
+	return container.State.Health.Status, nil
+}
+
+// hostHealthLabels returns the labels of the container of a service with a
+// host check. The URL of http is resolved to the published port, so the label
+// holds all the check needs.
+func hostHealthLabels(cfg *config.DockerServiceConfig) (map[string]string, error) {
+	if !cfg.HealthCheck.OnHost() {
+		return nil, nil
+	}
+	hc := *cfg.HealthCheck
+	if hc.HTTP != nil {
+		check := *hc.HTTP
+		if strings.HasPrefix(check.URL, "/") || check.URL == "" {
+			check.URL = fmt.Sprintf("http://localhost:%d%s", cfg.PublishedPort(), check.URL)
+		}
+		hc.HTTP = &check
+	}
+	data, err := json.Marshal(hc)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{HealthCheckLabel: string(data)}, nil
+}
+
+// hostHealthCheck returns the host check in the labels of a container, or
+// nil when its check runs in the container
+func hostHealthCheck(labels map[string]string) *config.HealthCheckConfig {
+	data, ok := labels[HealthCheckLabel]
+	if !ok {
+		return nil
+	}
+	var hc config.HealthCheckConfig
+	if err := json.Unmarshal([]byte(data), &hc); err != nil {
+		return nil
+	}
+	return &hc
+}
+
+// hostHealthStatus returns the health of a container with a host check,
+// running the check when its interval passed since the last one. Like
+// Docker, a container is unhealthy after retries failed checks in a row,
+// and failures during the start period don't count.
+func (m *ServiceManager) hostHealthStatus(containerID string, hc *config.HealthCheckConfig, startedAt time.Time) string {
+	interval := parseCheckDuration(hc.Interval, defaultCheckInterval)
+	startPeriod := parseCheckDuration(hc.StartPeriod, 0)
+	retries := hc.Retries
+	if retries <= 0 {
+		retries = defaultCheckRetries
+	}
+
+	hostChecksMu.Lock()
+	defer hostChecksMu.Unlock()
+	now := time.Now()
+	state, ok := hostChecks[containerID]
+	if !ok {
+		state = &hostCheckState{status: "starting"}
+		// Without earlier checks to count, a failure after the start period
+		// is taken as the last retry
+		if now.Sub(startedAt) >= startPeriod {
+			state.failures = retries - 1
+		}
+		hostChecks[containerID] = state
+	} else if now.Sub(state.last) < interval {
+		return state.status
+	}
+
+	state.last = now
+	if err := m.runHostCheck(m.ctx, containerID, hc); err == nil {
+		state.status = "healthy"
+		state.failures = 0
+		return state.status
+	}
+	if now.Sub(startedAt) < startPeriod {
+		return state.status
+	}
+	if state.failures++; state.failures >= retries {
+		state.status = "unhealthy"
+	}
+	return state.status
+}
+
+// runHostCheck runs a health check of a container from spin, with its
+// timeout. Native checks look for the port of tcp in the container or
+// request the URL of http. Commands are written like those of Docker
+// HEALTHCHECKs: ["CMD-SHELL", "curl -f localhost:9200"] runs through the
+// shell, ["CMD", "curl", ...] and plain lists run as is.
+func (m *ServiceManager) runHostCheck(ctx context.Context, containerID string, hc *config.HealthCheckConfig) error {
+	timeout := parseCheckDuration(hc.Timeout, defaultCheckTimeout)
+	switch {
+	case hc.TCP != 0:
+		return m.checkListening(containerID, hc.TCP)
+	case hc.HTTP != nil:
+		return checkHTTP(ctx, hc.HTTP, timeout)
+	}
+
+	command := hc.Command
+	if len(command) > 0 && (command[0] == "CMD" || command[0] == "CMD-SHELL") {
+		if command[0] == "CMD-SHELL" {
+			command = []string{"sh", "-c", strings.Join(command[1:], " ")}
+		} else {
+			command = command[1:]
+		}
+	}
+	if len(command) == 0 {
+		return fmt.Errorf("health check has no command")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return exec.CommandContext(ctx, command[0], command[1:]...).Run()
+}
+
+// checkListening checks that a process of the container listens on port.
+// Connecting to the published port isn't enough: Docker's proxy accepts
+// connections there before anything in the container listens. The sockets
+// are read from the container's /proc/net, which needs nothing in the image
+// but cat.
+func (m *ServiceManager) checkListening(containerID string, port int) error {
+	tables, err := m.socketTables(containerID)
+	if err != nil {
+		return err
+	}
+	if hasSocket(tables, port, socketListen) {
+		return nil
+	}
+	return fmt.Errorf("nothing listens on port %d", port)
+}
+
+// HasClients reports whether clients are connected to port in the container
+// of a service, like the idle connections of a pool
+func (m *ServiceManager) HasClients(name string, port int) (bool, error) {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return false, err
+	}
+	tables, err := m.socketTables(containerID)
+	if err != nil {
+		return false, err
+	}
+	return hasSocket(tables, port, socketEstablished), nil
+}
+
+// States of sockets in /proc/net/tcp
+const (
+	socketEstablished = "01"
+	socketListen      = "0A"
+)
+
+// socketTables returns the TCP sockets of a container, read from its
+// /proc/net
+func (m *ServiceManager) socketTables(containerID string) (string, error) {
+	output, err := m.Exec(containerID, []string{"cat", "/proc/net/tcp", "/proc/net/tcp6"})
+	if output == "" && err != nil {
+		return "", err
+	}
+	return output, nil
+}
+
+// hasSocket checks whether the socket tables of /proc/net/tcp have a socket
+// in state on the local port. Local addresses are written like
+// 00000000:1538, with the port in hex.
+func hasSocket(tables string, port int, state string) bool {
+	suffix := fmt.Sprintf(":%04X", port)
+	for _, line := range strings.Split(tables, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[3] != state {
+			continue
+		}
+		if strings.HasSuffix(fields[1], suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHTTP requests the URL of an HTTP check, which passes when it answers
+// with its status, or any 2xx or 3xx
+func checkHTTP(ctx context.Context, check *config.HTTPHealthCheck, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.URL, nil)
+	if err != nil {
+		return err
+	}
+	// Redirects count as answers, they aren't followed
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if check.Status != 0 {
+		if resp.StatusCode != check.Status {
+			return fmt.Errorf("%s answered %s, expected %d", check.URL, resp.Status, check.Status)
+		}
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s answered %s", check.URL, resp.Status)
+	}
+	return nil
+}
+
+// parseCheckDuration parses a duration of a health check, like "30s"
+func parseCheckDuration(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// waitForHealthy subscribes to the container's health_status events instead
+// of polling ContainerInspect
+func (m *ServiceManager) waitForHealthy(containerID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(m.ctx, timeout)
+	defer cancel()
+
+	// Subscribe before inspecting so no transition is missed in between
+	args := filters.NewArgs()
+	args.Add("type", "container")
+	args.Add("container", containerID)
+	args.Add("event", "health_status")
+	args.Add("event", "die")
+	messages, errs := m.client.Events(ctx, types.EventsOptions{Filters: args})
+
+	container, err := m.client.ContainerInspect(m.ctx, containerID)
+	if err != nil {
+		return err
+	}
+	if hc := hostHealthCheck(container.Config.Labels); hc != nil {
+		return m.waitForHostCheck(ctx, containerID, hc, timeout)
+	}
+	if container.State.Health == nil {
+		if container.State.Running {
+			return nil
+		}
+		return fmt.Errorf("container is not running")
+	}
+	if container.State.Health.Status == "healthy" {
+		logger.Printf("Service is healthy\n")
+		return nil
+	}
+
+	logger.Printf("Waiting for service to become healthy (timeout: %s)...\n", timeout)
+	for {
+		select {
+		case msg := <-messages:
+			if msg.Action == "die" {
+				return fmt.Errorf("container exited before becoming healthy")
+			}
+			// Health events carry the status in the action, e.g. "health_status: healthy"
+			status := strings.TrimSpace(strings.TrimPrefix(msg.Action, "health_status:"))
+			if status == "healthy" {
+				logger.Printf("Service is healthy\n")
+				return nil
+			}
+			logger.Printf("Health status: %s, waiting...\n", status)
+		case err := <-errs:
+			if ctx.Err() != nil {
+				return fmt.Errorf("service failed to become healthy within %s", timeout)
+			}
+			return fmt.Errorf("failed to watch container events: %w", err)
+		}
+	}
+}
+
+// waitForHostCheck runs the host check of a container every second until it
+// passes, the container exits or ctx is done
+func (m *ServiceManager) waitForHostCheck(ctx context.Context, containerID string, hc *config.HealthCheckConfig, timeout time.Duration) error {
+	waiting := false
+	for {
+		if err := m.runHostCheck(ctx, containerID, hc); err == nil {
+			hostChecksMu.Lock()
+			hostChecks[containerID] = &hostCheckState{status: "healthy", last: time.Now()}
+			hostChecksMu.Unlock()
+			logger.Printf("Service is healthy\n")
+			return nil
+		}
+		if container, err := m.client.ContainerInspect(m.ctx, containerID); err == nil && !container.State.Running {
+			return fmt.Errorf("container exited before becoming healthy")
+		}
+		if !waiting {
+			logger.Printf("Waiting for service to become healthy (timeout: %s)...\n", timeout)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("service failed to become healthy within %s", timeout)
+		case <-time.After(hostCheckPoll):
+		}
+	}
+}
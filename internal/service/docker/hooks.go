@@ -0,0 +1,88 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Exec runs a command inside a service container and returns its combined
+// output. A non-zero exit code is reported as an error.
+func (m *ServiceManager) Exec(containerID string, command []string) (string, error) {
+	return m.execEnv(containerID, command, nil)
+}
+
+// execEnv runs a command inside a service container like Exec, with extra
+// environment variables written as KEY=value
+func (m *ServiceManager) execEnv(containerID string, command []string, env []string) (string, error) {
+	exec, err := m.client.ContainerExecCreate(m.ctx, containerID, types.ExecConfig{
+		Cmd:          command,
+		Env:          env,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	resp, err := m.client.ContainerExecAttach(m.ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return "", fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer resp.Close()
+
+	var output bytes.Buffer
+	if _, err := stdcopy.StdCopy(&output, &output, resp.Reader); err != nil {
+		return "", fmt.Errorf("failed to read exec output: %w", err)
+	}
+
+	inspect, err := m.client.ContainerExecInspect(m.ctx, exec.ID)
+	if err != nil {
+		return output.String(), fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	if inspect.ExitCode != 0 {
+		return output.String(), fmt.Errorf("command exited with code %d", inspect.ExitCode)
+	}
+
+	return output.String(), nil
+}
+
+// runHooks executes service hook commands inside the container in order
+func (m *ServiceManager) runHooks(name, containerID, stage string, commands [][]string) error {
+	for _, command := range commands {
+		if len(command) == 0 {
+			continue
+		}
+
+		logger.Printf("Running %s hook for %s: %s\n", stage, name, strings.Join(command, " "))
+		output, err := m.Exec(containerID, command)
+		if output != "" {
+			fmt.Print(output)
+		}
+		if err != nil {
+			return fmt.Errorf("%s hook %q failed: %w", stage, strings.Join(command, " "), err)
+		}
+	}
+	return nil
+}
+
+// runPostStartHooks runs the post-start hooks of a service
+func (m *ServiceManager) runPostStartHooks(name, containerID string, cfg *config.DockerServiceConfig) error {
+	if cfg == nil || cfg.Hooks == nil {
+		return nil
+	}
+	return m.runHooks(name, containerID, "post-start", cfg.Hooks.PostStart)
+}
+
+// runPreStopHooks runs the pre-stop hooks of a service
+func (m *ServiceManager) runPreStopHooks(name, containerID string, cfg *config.DockerServiceConfig) error {
+	if cfg == nil || cfg.Hooks == nil {
+		return nil
+	}
+	return m.runHooks(name, containerID, "pre-stop", cfg.Hooks.PreStop)
+}
@@ -0,0 +1,67 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// NetworkBytes returns the bytes a service has received and sent over all
+// of its networks since its container started
+func (m *ServiceManager) NetworkBytes(name string) (uint64, error) {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return 0, err
+	}
+
+	stats, err := m.client.ContainerStats(m.ctx, containerID, false)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stats for %s: %w", name, err)
+	}
+	defer stats.Body.Close()
+
+	var containerStats types.StatsJSON
+	if err := json.NewDecoder(stats.Body).Decode(&containerStats); err != nil {
+		return 0, fmt.Errorf("failed to decode stats for %s: %w", name, err)
+	}
+
+	var total uint64
+	for _, network := range containerStats.Networks {
+		total += network.RxBytes + network.TxBytes
+	}
+	return total, nil
+}
+
+// IdleTracker finds services without connections by watching the network
+// byte counts of their containers. Health checks run inside the container,
+// so they don't count as traffic.
+type IdleTracker struct {
+	bytes map[string]uint64
+	since map[string]time.Time
+}
+
+// NewIdleTracker creates a tracker that hasn't seen any service yet
+func NewIdleTracker() *IdleTracker {
+	return &IdleTracker{
+		bytes: make(map[string]uint64),
+		since: make(map[string]time.Time),
+	}
+}
+
+// Observe records the network bytes of a service and returns how long they
+// have stayed the same. A service seen for the first time counts as active.
+func (t *IdleTracker) Observe(name string, bytes uint64, now time.Time) time.Duration {
+	if last, seen := t.bytes[name]; !seen || last != bytes {
+		t.bytes[name] = bytes
+		t.since[name] = now
+	}
+	return now.Sub(t.since[name])
+}
+
+// Forget drops what the tracker knows about a service, e.g. once it stopped
+func (t *IdleTracker) Forget(name string) {
+	delete(t.bytes, name)
+	delete(t.since, name)
+}
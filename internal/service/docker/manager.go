@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/spinerr"
 	"github.com/afomera/spin/internal/tracker"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -26,6 +29,9 @@ type ServiceManager struct {
 	dataDir string // Base directory for service data (volumes)
 }
 
+// debugLog writes the debug messages of services, --verbose=docker
+var debugLog = logger.For("docker")
+
 // Client returns the Docker client instance
 func (m *ServiceManager) Client() *client.Client {
 	return m.client
@@ -56,6 +62,7 @@ func (m *ServiceManager) StartService(name string, cfg *config.DockerServiceConf
 			return fmt.Errorf("failed to inspect container: %w", err)
 		}
 
+		debugLog.Debugf("Found container %s of %s, running: %t", existingID[:12], name, container.State.Running)
 		if container.State.Running {
 			// Container is running, stop it
 			timeout := 10 * time.Second
@@ -65,13 +72,15 @@ func (m *ServiceManager) StartService(name string, cfg *config.DockerServiceConf
 		}
 	} else {
 		// No existing container, check if port is available
-		if !m.isPortAvailable(cfg.Port) {
-			return fmt.Errorf("port %d is already in use by another process", cfg.Port)
+		if port := cfg.PublishedPort(); !m.isPortAvailable(port) {
+			return spinerr.New(spinerr.Service, fmt.Sprintf("port %d is already in use", port), "another program is listening on it").
+				WithFix(fmt.Sprintf("Stop the program listening on %d, or publish %s on another port with \"host_port\"", port, name)).
+				WithCode(spinerr.ExitPortConflict)
 		}
 	}
 
-	// Pull image if needed
-	if err := m.pullImage(cfg.Image); err != nil {
+	// Pull image according to the service's pull policy
+	if err := m.ensureImage(cfg); err != nil {
 		return err
 	}
 
@@ -82,17 +91,25 @@ func (m *ServiceManager) StartService(name string, cfg *config.DockerServiceConf
 	}
 
 	// Start container
+	debugLog.Debugf("Starting container %s of %s", containerID[:12], name)
 	if err := m.client.ContainerStart(m.ctx, containerID, types.ContainerStartOptions{}); err != nil {
-		return fmt.Errorf("failed to start container %s: %w", name, err)
+		return daemonError(fmt.Sprintf("failed to start container %s", name), err)
 	}
 
 	// Wait for health check if configured
 	if cfg.HealthCheck != nil {
-		if err := m.waitForHealthy(containerID, cfg.HealthCheck); err != nil {
-			return fmt.Errorf("service %s failed health check: %w", name, err)
+		if err := m.waitForHealthy(containerID, cfg.ReadyWithin()); err != nil {
+			return spinerr.Wrap(spinerr.Service, fmt.Sprintf("service %s failed health check", name), err).
+				WithFix(fmt.Sprintf("Check its logs with 'spin services logs %s', or give it longer with \"ready_timeout\"", name)).
+				WithCode(spinerr.ExitHealthTimeout)
 		}
 	}
 
+	// Run post-start hooks once the service is ready
+	if err := m.runPostStartHooks(name, containerID, cfg); err != nil {
+		return err
+	}
+
 	// Notify process tracker if set
 	if t := tracker.GetTracker(); t != nil {
 		if err := t.StartDockerProcess(name, containerID, cfg.Image); err != nil {
@@ -103,6 +120,16 @@ func (m *ServiceManager) StartService(name string, cfg *config.DockerServiceConf
 	return nil
 }
 
+// daemonError returns err as a Docker error saying how to start Docker when
+// the daemon isn't reachable, or else wraps it with message
+func daemonError(message string, err error) error {
+	if client.IsErrConnectionFailed(err) {
+		return spinerr.Wrap(spinerr.Docker, "Docker isn't running", err).
+			WithFix("Start Docker Desktop, or the Docker daemon with 'sudo systemctl start docker'")
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
 // isPortAvailable checks if a port is available
 func (m *ServiceManager) isPortAvailable(port int) bool {
 	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
@@ -113,18 +140,44 @@ func (m *ServiceManager) isPortAvailable(port int) bool {
 	return true
 }
 
-// StopService stops a Docker service
-func (m *ServiceManager) StopService(name string) error {
+// StopService stops a Docker service, running its pre-stop hooks first.
+// cfg may be nil when the service configuration isn't available.
+func (m *ServiceManager) StopService(name string, cfg *config.DockerServiceConfig) error {
 	containerID, err := m.FindContainer(name)
 	if err != nil {
 		return err
 	}
 
+	// A paused service can't run its hooks or handle the stop signal
+	if m.IsPaused(name) {
+		if err := m.UnpauseService(name); err != nil {
+			return err
+		}
+	}
+
+	if m.IsRunning(name) {
+		if err := m.runPreStopHooks(name, containerID, cfg); err != nil {
+			debugLog.Warnf("%v", err)
+		}
+	}
+
 	timeout := 10 * time.Second
+	debugLog.Debugf("Stopping container %s of %s", containerID[:12], name)
 	if err := m.client.ContainerStop(m.ctx, containerID, &timeout); err != nil {
 		return fmt.Errorf("failed to stop container %s: %w", name, err)
 	}
 
+	return untrack(name)
+}
+
+// untrack notifies the process tracker, if set, that the container of a
+// service is gone
+func untrack(name string) error {
+	if t := tracker.GetTracker(); t != nil {
+		if err := t.StopDockerProcess(name); err != nil {
+			return fmt.Errorf("failed to untrack container: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -144,7 +197,7 @@ func (m *ServiceManager) RemoveService(name string, removeVolumes bool) error {
 		return fmt.Errorf("failed to remove container %s: %w", name, err)
 	}
 
-	return nil
+	return untrack(name)
 }
 
 // GetServiceLogs returns logs for a service
@@ -222,6 +275,65 @@ func (m *ServiceManager) IsRunning(name string) bool {
 	return container.State.Running
 }
 
+// IsPaused checks if a service is paused
+func (m *ServiceManager) IsPaused(name string) bool {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return false
+	}
+
+	container, err := m.client.ContainerInspect(m.ctx, containerID)
+	if err != nil {
+		return false
+	}
+
+	return container.State.Paused
+}
+
+// PauseService freezes the processes of a running service with the Docker
+// pause API. The service keeps its memory and resumes where it left off.
+func (m *ServiceManager) PauseService(name string) error {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return err
+	}
+	if !m.IsRunning(name) {
+		return fmt.Errorf("service %s is not running", name)
+	}
+
+	if err := m.client.ContainerPause(m.ctx, containerID); err != nil {
+		return fmt.Errorf("failed to pause container %s: %w", name, err)
+	}
+	return nil
+}
+
+// UnpauseService resumes a paused service
+func (m *ServiceManager) UnpauseService(name string) error {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return err
+	}
+
+	if err := m.client.ContainerUnpause(m.ctx, containerID); err != nil {
+		return fmt.Errorf("failed to unpause container %s: %w", name, err)
+	}
+	return nil
+}
+
+// StartedAt returns when the container of a running service was started
+func (m *ServiceManager) StartedAt(name string) (time.Time, error) {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	container, err := m.client.ContainerInspect(m.ctx, containerID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to inspect %s: %w", name, err)
+	}
+	return time.Parse(time.RFC3339Nano, container.State.StartedAt)
+}
+
 // GetServiceStats returns resource usage statistics for a service
 func (m *ServiceManager) GetServiceStats(name string) (*types.Stats, error) {
 	containerID, err := m.FindContainer(name)
@@ -236,22 +348,42 @@ func (m *ServiceManager) GetServiceStats(name string) (*types.Stats, error) {
 	defer stats.Body.Close()
 
 	var containerStats types.Stats
-	// TODO: Decode stats from response body
+	if err := json.NewDecoder(stats.Body).Decode(&containerStats); err != nil {
+		return nil, fmt.Errorf("failed to decode stats for %s: %w", name, err)
+	}
 	return &containerStats, nil
 }
 
-// CleanupVolumes removes unused Docker volumes created by Spin
-func (m *ServiceManager) CleanupVolumes() error {
+// ServiceUsage returns the CPU percentage and memory usage in bytes of a
+// running service
+func (m *ServiceManager) ServiceUsage(name string) (float64, uint64, error) {
+	stats, err := m.GetServiceStats(name)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage - stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage - stats.PreCPUStats.SystemUsage)
+	cpuPercent := 0.0
+	if systemDelta > 0 && cpuDelta > 0 {
+		cpuPercent = (cpuDelta / systemDelta) * float64(len(stats.CPUStats.CPUUsage.PercpuUsage)) * 100.0
+	}
+	return cpuPercent, stats.MemoryStats.Usage, nil
+}
+
+// UnusedVolumes returns the Docker volumes created by Spin that no container
+// uses, leaving out the backups made before upgrades
+func (m *ServiceManager) UnusedVolumes() ([]string, error) {
 	// List all containers to check volume references
 	containers, err := m.client.ContainerList(m.ctx, types.ContainerListOptions{All: true})
 	if err != nil {
-		return fmt.Errorf("failed to list containers: %w", err)
+		return nil, fmt.Errorf("failed to list containers: %w", err)
 	}
 
 	// Get all volumes
 	volumes, err := m.client.VolumeList(m.ctx, filters.NewArgs())
 	if err != nil {
-		return fmt.Errorf("failed to list volumes: %w", err)
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
 	}
 
 	// Map of volume names that are in use
@@ -264,44 +396,19 @@ func (m *ServiceManager) CleanupVolumes() error {
 		}
 	}
 
-	var removed int
+	var unused []string
 	for _, volume := range volumes.Volumes {
-		// Only remove volumes created by Spin (prefixed with "spin_")
-		if strings.HasPrefix(volume.Name, "spin_") && !inUse[volume.Name] {
-			fmt.Printf("Removing unused volume %s...\n", volume.Name)
-			if err := m.client.VolumeRemove(m.ctx, volume.Name, false); err != nil {
-				fmt.Printf("Warning: failed to remove volume %s: %v\n", volume.Name, err)
-				continue
-			}
-			removed++
+		// Only volumes created by Spin (prefixed with "spin_"), upgrade
+		// backups are never in use and must be removed by hand
+		if strings.HasPrefix(volume.Name, "spin_") && !inUse[volume.Name] && !isBackupVolume(volume.Name) {
+			unused = append(unused, volume.Name)
 		}
 	}
-
-	fmt.Printf("Removed %d unused volumes\n", removed)
-	return nil
+	return unused, nil
 }
 
 // Helper functions
 
-func (m *ServiceManager) pullImage(image string) error {
-	fmt.Printf("Pulling image %s...\n", image)
-
-	reader, err := m.client.ImagePull(m.ctx, image, types.ImagePullOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to pull image %s: %w", image, err)
-	}
-	defer reader.Close()
-
-	// Copy the response to stdout to show pull progress
-	_, err = io.Copy(os.Stdout, reader)
-	if err != nil {
-		return fmt.Errorf("error reading pull response: %w", err)
-	}
-
-	fmt.Printf("Successfully pulled image %s\n", image)
-	return nil
-}
-
 func (m *ServiceManager) createContainer(name string, cfg *config.DockerServiceConfig) (string, error) {
 	// Check if container already exists
 	if containerID, _ := m.FindContainer(name); containerID != "" {
@@ -319,28 +426,52 @@ func (m *ServiceManager) createContainer(name string, cfg *config.DockerServiceC
 	if cfg.Port != 0 {
 		containerPort := nat.Port(fmt.Sprintf("%d/tcp", cfg.Port))
 		portBindings[containerPort] = []nat.PortBinding{
-			{HostIP: "127.0.0.1", HostPort: fmt.Sprintf("%d", cfg.Port)},
+			{HostIP: "127.0.0.1", HostPort: fmt.Sprintf("%d", cfg.PublishedPort())},
 		}
 	}
 
 	// Prepare volume mounts
 	var mounts []mount.Mount
-	for name, target := range cfg.Volumes {
+	for key, target := range cfg.Volumes {
 		// For PostgreSQL, ensure we're using the correct data directory
 		mountTarget := target
-		if name == "data" && strings.HasPrefix(cfg.Image, "postgres:") {
+		if key == "data" && strings.HasPrefix(cfg.Image, "postgres:") {
 			// Always use /var/lib/postgresql/data as the container target path
 			// This is required by the PostgreSQL image
 			mountTarget = "/var/lib/postgresql/data"
 		}
 
-		mounts = append(mounts, mount.Mount{
-			Type:   mount.TypeVolume,
-			Source: fmt.Sprintf("spin_%s_data", name),
-			Target: mountTarget,
-		})
+		volumeMount, err := m.volumeMount(name, key, mountTarget)
+		if err != nil {
+			return "", err
+		}
+		mounts = append(mounts, volumeMount)
 	}
 
+	// Services join the spin network, where dev containers reach them by name
+	if err := m.EnsureNetwork(); err != nil {
+		return "", err
+	}
+
+	if hc := cfg.HealthCheck; hc != nil {
+		switch {
+		case hc.Mode != "" && hc.Mode != config.HealthCheckContainer && hc.Mode != config.HealthCheckHost:
+			return "", fmt.Errorf("invalid health_check mode %q (expected %s or %s)", hc.Mode, config.HealthCheckContainer, config.HealthCheckHost)
+		case hc.Native() && hc.Mode == config.HealthCheckContainer:
+			return "", fmt.Errorf("health_check of %s checks tcp or http, which spin does from the host, remove mode %q", name, hc.Mode)
+		case hc.TCP != 0 && hc.HTTP != nil:
+			return "", fmt.Errorf("health_check of %s has both tcp and http, pick one", name)
+		}
+	}
+	labels, err := hostHealthLabels(cfg)
+	if err != nil {
+		return "", err
+	}
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[ProjectLabel] = projectDir()
+
 	// Create container
 	resp, err := m.client.ContainerCreate(
 		m.ctx,
@@ -350,18 +481,20 @@ func (m *ServiceManager) createContainer(name string, cfg *config.DockerServiceC
 			Cmd:         cfg.Command,
 			Entrypoint:  cfg.Entrypoint,
 			Healthcheck: m.createHealthCheck(cfg.HealthCheck),
+			Labels:      labels,
 		},
 		&container.HostConfig{
 			PortBindings: portBindings,
 			Mounts:       mounts,
 		},
-		nil,
+		networkingConfig(name),
 		nil,
 		fmt.Sprintf("spin_%s", name),
 	)
 	if err != nil {
 		return "", fmt.Errorf("failed to create container %s: %w", name, err)
 	}
+	debugLog.Debugf("Created container %s of %s from %s, %d mounts, ports %v", resp.ID[:12], name, cfg.Image, len(mounts), portBindings)
 
 	return resp.ID, nil
 }
@@ -385,42 +518,6 @@ func (m *ServiceManager) FindContainer(name string) (string, error) {
 	return "", fmt.Errorf("container %s not found", name)
 }
 
-func (m *ServiceManager) waitForHealthy(containerID string, healthCheck *config.HealthCheckConfig) error {
-	if healthCheck == nil {
-		return nil // No health check configured
-	}
-
-	timeout, err := time.ParseDuration(healthCheck.StartPeriod)
-	if err != nil {
-		timeout = 60 * time.Second // Default timeout
-	}
-
-	fmt.Printf("Waiting for service to become healthy (timeout: %s)...\n", timeout)
-	deadline := time.Now().Add(timeout)
-	for time.Now().Before(deadline) {
-		container, err := m.client.ContainerInspect(m.ctx, containerID)
-		if err != nil {
-			return err
-		}
-
-		// If container has no health check configured, consider it healthy
-		if container.State.Health == nil {
-			return nil
-		}
-
-		status := container.State.Health.Status
-		if status == "healthy" {
-			fmt.Println("Service is healthy")
-			return nil
-		}
-
-		fmt.Printf("Health status: %s, waiting...\n", status)
-		time.Sleep(1 * time.Second)
-	}
-
-	return fmt.Errorf("service failed to become healthy within %s", timeout)
-}
-
 func (m *ServiceManager) mapToEnvSlice(env map[string]string) []string {
 	var result []string
 	for k, v := range env {
@@ -429,8 +526,10 @@ func (m *ServiceManager) mapToEnvSlice(env map[string]string) []string {
 	return result
 }
 
+// createHealthCheck returns the Docker HEALTHCHECK of a service, none when
+// spin runs its check on the host
 func (m *ServiceManager) createHealthCheck(cfg *config.HealthCheckConfig) *container.HealthConfig {
-	if cfg == nil {
+	if cfg == nil || cfg.OnHost() {
 		return nil
 	}
 
@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// OneShotOptions configures a short-lived container run
+type OneShotOptions struct {
+	Name       string            // Container name suffix, the container is named spin_<Name>
+	Image      string            // Image to run
+	Command    []string          // Command to run, the image default is used when empty
+	Env        map[string]string // Environment variables
+	Service    string            // Optional service whose network namespace is shared
+	Entrypoint []string          // Optional override for the image entrypoint
+	Mounts     []mount.Mount     // Optional volumes to mount
+}
+
+// RunOneShot runs a container to completion, streams its output to stdout
+// and removes it afterwards. A non-zero exit code is reported as an error.
+func (m *ServiceManager) RunOneShot(opts OneShotOptions) error {
+	if err := m.ensureImage(&config.DockerServiceConfig{Image: opts.Image}); err != nil {
+		return err
+	}
+
+	hostConfig := &container.HostConfig{Mounts: opts.Mounts}
+	if opts.Service != "" {
+		// Share the service's network so it is reachable on localhost
+		serviceID, err := m.FindContainer(opts.Service)
+		if err != nil {
+			return fmt.Errorf("service %s is not available: %w", opts.Service, err)
+		}
+		hostConfig.NetworkMode = container.NetworkMode("container:" + serviceID)
+	}
+
+	containerName := fmt.Sprintf("spin_%s", opts.Name)
+	if existingID, _ := m.FindContainer(opts.Name); existingID != "" {
+		_ = m.client.ContainerRemove(m.ctx, existingID, types.ContainerRemoveOptions{Force: true})
+	}
+
+	resp, err := m.client.ContainerCreate(
+		m.ctx,
+		&container.Config{
+			Image:      opts.Image,
+			Cmd:        opts.Command,
+			Entrypoint: opts.Entrypoint,
+			Env:        m.mapToEnvSlice(opts.Env),
+		},
+		hostConfig,
+		nil,
+		nil,
+		containerName,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create container %s: %w", containerName, err)
+	}
+	defer m.client.ContainerRemove(m.ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+
+	if err := m.client.ContainerStart(m.ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("failed to start container %s: %w", containerName, err)
+	}
+
+	logs, err := m.client.ContainerLogs(m.ctx, resp.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err == nil {
+		_, _ = stdcopy.StdCopy(os.Stdout, os.Stderr, logs)
+		logs.Close()
+	}
+
+	statusCh, errCh := m.client.ContainerWait(m.ctx, resp.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed waiting for container %s: %w", containerName, err)
+	case status := <-statusCh:
+		if status.StatusCode != 0 {
+			return fmt.Errorf("container %s exited with code %d", containerName, status.StatusCode)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+)
+
+// IsolatedService returns a copy of a service for a preview environment,
+// where it runs as name: published on hostPort, not shared with other
+// projects, and with empty volumes of its own
+func IsolatedService(name string, cfg *config.DockerServiceConfig, hostPort int) *config.DockerServiceConfig {
+	isolated := *cfg
+	isolated.HostPort = hostPort
+	isolated.Shared = false
+	isolated.Volumes = make(map[string]string, len(cfg.Volumes))
+	for key, target := range cfg.Volumes {
+		base := strings.Trim(filepath.Base(filepath.Clean(key)), ".")
+		if base == "" {
+			base = "data"
+		}
+		isolated.Volumes[name+"_"+base] = target
+	}
+	return &isolated
+}
+
+// RemoveServiceVolumes removes the named volumes of a service. Directories
+// of the host it mounts are left alone.
+func (m *ServiceManager) RemoveServiceVolumes(name string, cfg *config.DockerServiceConfig) error {
+	for key := range cfg.Volumes {
+		if isHostPath(key) {
+			continue
+		}
+		volume := volumeSource(name, key)
+		if err := m.client.VolumeRemove(m.ctx, volume, true); err != nil {
+			return fmt.Errorf("failed to remove volume %s: %w", volume, err)
+		}
+	}
+	return nil
+}
+
+// ServiceVolumes returns the named volumes of a service that exist, the ones
+// RemoveServiceVolumes removes
+func (m *ServiceManager) ServiceVolumes(name string, cfg *config.DockerServiceConfig) []string {
+	var volumes []string
+	for key := range cfg.Volumes {
+		if isHostPath(key) {
+			continue
+		}
+		volume := volumeSource(name, key)
+		if _, err := m.client.VolumeInspect(m.ctx, volume); err == nil {
+			volumes = append(volumes, volume)
+		}
+	}
+	sort.Strings(volumes)
+	return volumes
+}
@@ -0,0 +1,214 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/spinerr"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"golang.org/x/term"
+)
+
+// Pull policies for service images
+const (
+	PullAlways       = "always"
+	PullIfNotPresent = "if-not-present"
+	PullNever        = "never"
+)
+
+// pullEvent is a single JSON message from the image pull stream
+type pullEvent struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	Error          string `json:"error"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+}
+
+// layerProgress tracks the last known state of a single image layer
+type layerProgress struct {
+	status  string
+	current int64
+	total   int64
+}
+
+// pullRenderer draws one progress line per layer
+type pullRenderer struct {
+	out      io.Writer
+	tty      bool
+	order    []string
+	layers   map[string]*layerProgress
+	rendered int // Number of lines drawn by the last render
+}
+
+func newPullRenderer(out io.Writer) *pullRenderer {
+	tty := false
+	if f, ok := out.(*os.File); ok {
+		tty = term.IsTerminal(int(f.Fd()))
+	}
+	return &pullRenderer{
+		out:    out,
+		tty:    tty,
+		layers: make(map[string]*layerProgress),
+	}
+}
+
+// handle records an event and redraws the progress lines
+func (r *pullRenderer) handle(ev pullEvent) {
+	if ev.ID == "" {
+		// Image-level messages such as "Digest: ..." or "Status: ..."
+		if !r.tty {
+			fmt.Fprintln(r.out, ev.Status)
+		}
+		return
+	}
+
+	layer, ok := r.layers[ev.ID]
+	if !ok {
+		layer = &layerProgress{}
+		r.layers[ev.ID] = layer
+		r.order = append(r.order, ev.ID)
+	}
+	changed := layer.status != ev.Status
+	layer.status = ev.Status
+	layer.current = ev.ProgressDetail.Current
+	layer.total = ev.ProgressDetail.Total
+
+	if r.tty {
+		r.render()
+	} else if changed {
+		// Without a terminal only print state transitions
+		fmt.Fprintf(r.out, "%s: %s\n", ev.ID, ev.Status)
+	}
+}
+
+// render redraws every layer line in place
+func (r *pullRenderer) render() {
+	if r.rendered > 0 {
+		fmt.Fprintf(r.out, "\033[%dA", r.rendered)
+	}
+	for _, id := range r.order {
+		layer := r.layers[id]
+		fmt.Fprintf(r.out, "\033[2K%s: %s\n", id, formatLayer(layer))
+	}
+	r.rendered = len(r.order)
+}
+
+// formatLayer renders a layer status with a progress bar when sizes are known
+func formatLayer(l *layerProgress) string {
+	if l.total <= 0 {
+		return l.status
+	}
+
+	const width = 30
+	current := l.current
+	if current > l.total {
+		current = l.total
+	}
+	filled := int(float64(width) * float64(current) / float64(l.total))
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	return fmt.Sprintf("%-12s [%s] %s/%s", l.status, bar, FormatBytes(current), FormatBytes(l.total))
+}
+
+// FormatBytes formats a byte count for display, like 1.5GB
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// ensureImage makes the service image available according to its pull policy
+func (m *ServiceManager) ensureImage(cfg *config.DockerServiceConfig) error {
+	policy := cfg.PullPolicy
+	if policy == "" {
+		policy = PullIfNotPresent
+	}
+
+	debugLog.Debugf("Image %s has pull_policy %s", cfg.Image, policy)
+	switch policy {
+	case PullAlways:
+		return m.PullImage(cfg.Image)
+	case PullIfNotPresent, PullNever:
+		_, _, err := m.client.ImageInspectWithRaw(m.ctx, cfg.Image)
+		if err == nil {
+			return nil
+		}
+		if !client.IsErrNotFound(err) {
+			return daemonError(fmt.Sprintf("failed to inspect image %s", cfg.Image), err)
+		}
+		if policy == PullNever {
+			return spinerr.New(spinerr.Service, fmt.Sprintf("image %s is not present locally", cfg.Image), fmt.Sprintf("pull_policy is %q", PullNever)).
+				WithFix(fmt.Sprintf("Build or load the image, or set \"pull_policy\" to %q", PullIfNotPresent))
+		}
+		return m.PullImage(cfg.Image)
+	default:
+		return fmt.Errorf("invalid pull_policy %q (expected %s, %s or %s)", policy, PullAlways, PullIfNotPresent, PullNever)
+	}
+}
+
+// PullImage pulls an image and renders per-layer progress
+func (m *ServiceManager) PullImage(image string) error {
+	if logger.IsQuiet() {
+		return m.PullImageQuietly(image)
+	}
+	logger.Printf("Pulling image %s...\n", image)
+	// JSON logs get no progress bars redrawn in place
+	handle := func(pullEvent) {}
+	if !logger.IsJSON() {
+		handle = newPullRenderer(os.Stdout).handle
+	}
+	if err := m.pullImage(image, handle); err != nil {
+		return err
+	}
+	logger.Printf("Successfully pulled image %s\n", image)
+	return nil
+}
+
+// PullImageQuietly pulls an image without printing progress, so several can
+// be pulled at once
+func (m *ServiceManager) PullImageQuietly(image string) error {
+	return m.pullImage(image, func(pullEvent) {})
+}
+
+// pullImage pulls an image, passing the events of the pull to handle
+func (m *ServiceManager) pullImage(image string, handle func(pullEvent)) error {
+	reader, err := m.client.ImagePull(m.ctx, image, types.ImagePullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", image, err)
+	}
+	defer reader.Close()
+
+	decoder := json.NewDecoder(reader)
+	for {
+		var ev pullEvent
+		if err := decoder.Decode(&ev); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("error reading pull response: %w", err)
+		}
+		if ev.Error != "" {
+			return fmt.Errorf("failed to pull image %s: %s", image, ev.Error)
+		}
+		handle(ev)
+	}
+	return nil
+}
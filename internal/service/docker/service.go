@@ -44,7 +44,7 @@ func (s *DockerService) Stop() error {
 		return fmt.Errorf("failed to create Docker manager: %w", err)
 	}
 
-	return manager.StopService(s.Name())
+	return manager.StopService(s.Name(), s.config)
 }
 
 func (s *DockerService) IsRunning() bool {
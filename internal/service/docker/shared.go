@@ -0,0 +1,194 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/filelock"
+)
+
+// invalidDatabaseChars matches characters that can't be used unquoted in a
+// database name
+var invalidDatabaseChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// sharedPath returns the file recording which projects use shared services
+func sharedPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".spin", "shared_services.json"), nil
+}
+
+// lockShared keeps other spin commands from changing the users of shared
+// services until it is released
+func lockShared() (*filelock.Lock, error) {
+	path, err := sharedPath()
+	if err != nil {
+		return nil, err
+	}
+	return filelock.Acquire(path + ".lock")
+}
+
+// loadShared reads the projects using each shared service
+func loadShared() (map[string][]string, error) {
+	path, err := sharedPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make(map[string][]string), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string][]string)
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return users, nil
+}
+
+// saveShared writes the projects using each shared service
+func saveShared(users map[string][]string) error {
+	path, err := sharedPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// SharedUsers returns the projects using a shared service
+func SharedUsers(name string) ([]string, error) {
+	users, err := loadShared()
+	if err != nil {
+		return nil, err
+	}
+	return users[name], nil
+}
+
+// AcquireShared records that a project uses a shared service
+func AcquireShared(name string, project string) error {
+	lock, err := lockShared()
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	users, err := loadShared()
+	if err != nil {
+		return err
+	}
+	for _, user := range users[name] {
+		if user == project {
+			return nil
+		}
+	}
+	users[name] = append(users[name], project)
+	sort.Strings(users[name])
+	return saveShared(users)
+}
+
+// ReleaseShared records that a project no longer uses a shared service and
+// returns the projects still using it
+func ReleaseShared(name string, project string) ([]string, error) {
+	lock, err := lockShared()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	users, err := loadShared()
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining []string
+	for _, user := range users[name] {
+		if user != project {
+			remaining = append(remaining, user)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(users, name)
+	} else {
+		users[name] = remaining
+	}
+	return remaining, saveShared(users)
+}
+
+// ProjectDatabase returns the database a project uses in a shared database
+// service, like myapp_development
+func ProjectDatabase(project string) string {
+	name := invalidDatabaseChars.ReplaceAllString(strings.ToLower(project), "_")
+	return strings.Trim(name, "_") + "_development"
+}
+
+// ProjectDatabaseURL returns the URL of the database a project uses in a
+// shared database service
+func ProjectDatabaseURL(cfg *config.DockerServiceConfig, project string) string {
+	u, err := url.Parse(cfg.URL())
+	if err != nil || u.Scheme == "" {
+		return ""
+	}
+	u.Path = "/" + ProjectDatabase(project)
+	return u.String()
+}
+
+// HasProjectDatabases reports whether projects get their own database in a
+// shared service, which they do in PostgreSQL and MySQL
+func HasProjectDatabases(cfg *config.DockerServiceConfig) bool {
+	engine := databaseEngine(cfg.Image)
+	return engine == "postgres" || engine == "mysql"
+}
+
+// UseShared records that a project uses a running shared service and creates
+// the project's database in it when the service is PostgreSQL or MySQL
+func (m *ServiceManager) UseShared(name string, cfg *config.DockerServiceConfig, project string) error {
+	if err := AcquireShared(name, project); err != nil {
+		return fmt.Errorf("failed to record use of shared service %s: %w", name, err)
+	}
+
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return err
+	}
+
+	// The database name only holds [a-z0-9_], so it is safe to write in SQL
+	database := ProjectDatabase(project)
+	var output string
+	switch databaseEngine(cfg.Image) {
+	case "postgres":
+		user := postgresUser(cfg)
+		query := fmt.Sprintf("SELECT 1 FROM pg_database WHERE datname = '%s'", database)
+		output, err = m.Exec(containerID, []string{"psql", "-U", user, "-d", "postgres", "-tAc", query})
+		if err == nil && strings.TrimSpace(output) != "1" {
+			output, err = m.Exec(containerID, []string{"createdb", "-U", user, database})
+		}
+	case "mysql":
+		env := []string{"MYSQL_PWD=" + cfg.Environment["MYSQL_ROOT_PASSWORD"]}
+		output, err = m.execEnv(containerID, []string{"mysql", "-uroot", "-e", "CREATE DATABASE IF NOT EXISTS " + database}, env)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create database %s in %s: %w\n%s", database, name, err, output)
+	}
+	return nil
+}
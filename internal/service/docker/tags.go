@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hubTagsURL lists the most recently pushed tags of a Docker Hub repository
+const hubTagsURL = "https://hub.docker.com/v2/repositories/%s/tags?page_size=100&ordering=last_updated"
+
+// versionTag matches tags that are plain versions like 16 or 7.2.4
+var versionTag = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// variantTag matches versions with a variant like 7.0-ubuntu2204
+var variantTag = regexp.MustCompile(`^\d+(\.\d+)*-[a-z0-9.]+$`)
+
+// knownTags are offered when Docker Hub can't be reached
+var knownTags = map[string][]string{
+	"postgres":                         {"17", "16", "15", "14", "13"},
+	"redis":                            {"7.4", "7.2", "7", "6.2", "6"},
+	"mysql":                            {"9", "8.4", "8.0", "8", "5.7"},
+	"mongodb/mongodb-community-server": {"8.0-ubi8", "7.0-ubuntu2204", "6.0-ubuntu2204"},
+	"elasticsearch":                    {"8.15.3", "8.11.3", "7.17.24"},
+	"memcached":                        {"1.6", "1.5"},
+}
+
+// Repository returns the repository of an image, without its tag
+func Repository(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
+
+// ListTags returns the version tags of a Docker Hub repository, newest
+// version first. Tags with a variant are only returned when there are no
+// plain versions.
+func ListTags(repository string) ([]string, error) {
+	path := repository
+	if !strings.Contains(path, "/") {
+		path = "library/" + path
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(fmt.Sprintf(hubTagsURL, path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Docker Hub: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Docker Hub returned %s for %s", resp.Status, repository)
+	}
+
+	var page struct {
+		Results []struct {
+			Name string `json:"name"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode tags: %w", err)
+	}
+
+	var versions, variants []string
+	for _, result := range page.Results {
+		switch {
+		case versionTag.MatchString(result.Name):
+			versions = append(versions, result.Name)
+		case variantTag.MatchString(result.Name):
+			variants = append(variants, result.Name)
+		}
+	}
+	if len(versions) == 0 {
+		versions = variants
+	}
+	sortTags(versions)
+	return versions, nil
+}
+
+// KnownTags returns versions of a repository to offer when Docker Hub can't
+// be reached
+func KnownTags(repository string) []string {
+	return knownTags[repository]
+}
+
+// sortTags sorts tags by version, newest first, and shorter tags like 16
+// before 16.4
+func sortTags(tags []string) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		a := strings.FieldsFunc(strings.SplitN(tags[i], "-", 2)[0], func(r rune) bool { return r == '.' })
+		b := strings.FieldsFunc(strings.SplitN(tags[j], "-", 2)[0], func(r rune) bool { return r == '.' })
+		for k := 0; k < len(a) && k < len(b); k++ {
+			x, _ := strconv.Atoi(a[k])
+			y, _ := strconv.Atoi(b[k])
+			if x != y {
+				return x > y
+			}
+		}
+		return len(a) < len(b)
+	})
+}
@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+)
+
+// IsPostgres reports whether a service runs PostgreSQL
+func IsPostgres(cfg *config.DockerServiceConfig) bool {
+	return databaseEngine(cfg.Image) == "postgres"
+}
+
+// TestDatabase returns the database the nth worker of parallel tests uses,
+// like myapp_test_2, counting from 1
+func TestDatabase(project string, n int) string {
+	return strings.TrimSuffix(ProjectDatabase(project), "_development") + "_test_" + strconv.Itoa(n)
+}
+
+// CreateTestDatabases creates count empty databases for parallel tests in a
+// PostgreSQL service, replacing the ones a previous run left behind, and
+// returns their names
+func (m *ServiceManager) CreateTestDatabases(name string, cfg *config.DockerServiceConfig, project string, count int) ([]string, error) {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	user := postgresUser(cfg)
+	databases := make([]string, count)
+	for i := range databases {
+		databases[i] = TestDatabase(project, i+1)
+		if output, err := m.Exec(containerID, []string{"dropdb", "-U", user, "--if-exists", databases[i]}); err != nil {
+			return nil, fmt.Errorf("failed to drop database %s in %s: %w\n%s", databases[i], name, err, output)
+		}
+		if output, err := m.Exec(containerID, []string{"createdb", "-U", user, databases[i]}); err != nil {
+			return nil, fmt.Errorf("failed to create database %s in %s: %w\n%s", databases[i], name, err, output)
+		}
+	}
+	return databases, nil
+}
+
+// DropTestDatabases drops the databases of parallel tests
+func (m *ServiceManager) DropTestDatabases(name string, cfg *config.DockerServiceConfig, databases []string) error {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return err
+	}
+
+	user := postgresUser(cfg)
+	for _, database := range databases {
+		if output, err := m.Exec(containerID, []string{"dropdb", "-U", user, "--if-exists", database}); err != nil {
+			return fmt.Errorf("failed to drop database %s in %s: %w\n%s", database, name, err, output)
+		}
+	}
+	return nil
+}
+
+// TestDatabaseEnv returns the variables parallel tests of a project connect
+// to a PostgreSQL service with: the PG variables the pg gem reads when
+// database.yml leaves them out, and the worker count the parallel_tests gem
+// reads. Workers pick their database by the TEST_ENV_NUMBER parallel_tests
+// gives them, 1 to count. Rails' parallelize creates databases of its own,
+// so it isn't told about them.
+func TestDatabaseEnv(cfg *config.DockerServiceConfig, count int) map[string]string {
+	env := map[string]string{
+		"RAILS_ENV":                "test",
+		"PGHOST":                   "localhost",
+		"PGPORT":                   strconv.Itoa(cfg.PublishedPort()),
+		"PGUSER":                   postgresUser(cfg),
+		"PARALLEL_TEST_PROCESSORS": strconv.Itoa(count),
+		"PARALLEL_TEST_FIRST_IS_1": "true",
+	}
+	if password := cfg.Environment["POSTGRES_PASSWORD"]; password != "" {
+		env["PGPASSWORD"] = password
+	}
+	return env
+}
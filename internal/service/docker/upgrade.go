@@ -0,0 +1,355 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// Data migration strategies for major database upgrades
+const (
+	MigrateDump      = "dump"       // pg_dumpall from the old version, restore into the new one
+	MigratePgUpgrade = "pg_upgrade" // Run pg_upgrade between the old and new data directories
+)
+
+// backupVolumePattern matches the volume names created by BackupVolumes
+var backupVolumePattern = regexp.MustCompile(`_v\d+_\d{14}$`)
+
+// isBackupVolume checks if a volume holds data backed up before an upgrade
+func isBackupVolume(name string) bool {
+	return backupVolumePattern.MatchString(name)
+}
+
+// MajorUpgrade describes a database image change that crosses a major version
+type MajorUpgrade struct {
+	Engine string // postgres, mysql or mongodb
+	From   int
+	To     int
+}
+
+// String returns a human-readable description of the upgrade
+func (u *MajorUpgrade) String() string {
+	return fmt.Sprintf("%s %d → %d", u.Engine, u.From, u.To)
+}
+
+// databaseEngine returns the database an image runs, or "" if it isn't a
+// database whose on-disk format is tied to its major version
+func databaseEngine(image string) string {
+	repo := image
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	base := path.Base(repo)
+
+	switch {
+	case strings.Contains(base, "postgres"):
+		return "postgres"
+	case strings.Contains(base, "mysql"), strings.Contains(base, "mariadb"):
+		return "mysql"
+	case strings.Contains(base, "mongo"):
+		return "mongodb"
+	}
+	return ""
+}
+
+// imageMajorVersion returns the major version encoded in an image tag, e.g.
+// 14 for postgres:14.2-alpine. Tags like latest carry no version.
+func imageMajorVersion(image string) (int, bool) {
+	i := strings.LastIndex(image, ":")
+	if i <= strings.LastIndex(image, "/") {
+		return 0, false
+	}
+	tag := image[i+1:]
+
+	end := 0
+	for end < len(tag) && tag[end] >= '0' && tag[end] <= '9' {
+		end++
+	}
+	major, err := strconv.Atoi(tag[:end])
+	if err != nil {
+		return 0, false
+	}
+	return major, true
+}
+
+// DetectMajorUpgrade reports whether moving a service from oldImage to newImage
+// crosses a major version of a database, which makes the existing data
+// directory unreadable by the new server. It returns nil otherwise.
+func DetectMajorUpgrade(oldImage, newImage string) *MajorUpgrade {
+	engine := databaseEngine(newImage)
+	if engine == "" || engine != databaseEngine(oldImage) {
+		return nil
+	}
+
+	from, ok := imageMajorVersion(oldImage)
+	if !ok {
+		return nil
+	}
+	to, ok := imageMajorVersion(newImage)
+	if !ok || from == to {
+		return nil
+	}
+	return &MajorUpgrade{Engine: engine, From: from, To: to}
+}
+
+// CurrentImage returns the image of the service's existing container, or ""
+// if the service has never been started
+func (m *ServiceManager) CurrentImage(name string) string {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return ""
+	}
+	container, err := m.client.ContainerInspect(m.ctx, containerID)
+	if err != nil {
+		return ""
+	}
+	return container.Config.Image
+}
+
+// BackupVolumes copies every volume of a stopped service into a new named
+// volume and returns the backup volume names keyed by volume key. The copy
+// runs in a container of the service's own image.
+func (m *ServiceManager) BackupVolumes(name string, cfg *config.DockerServiceConfig, suffix string) (map[string]string, error) {
+	backups := make(map[string]string)
+	for key := range cfg.Volumes {
+		source, err := m.volumeMount(name, key, "/from")
+		if err != nil {
+			return nil, err
+		}
+
+		backup := fmt.Sprintf("%s_%s", volumeSource(name, key), suffix)
+		err = m.RunOneShot(OneShotOptions{
+			Name:       name + "_backup",
+			Image:      cfg.Image,
+			Entrypoint: []string{"sh", "-c"},
+			Command:    []string{"cp -a /from/. /to/"},
+			Mounts: []mount.Mount{
+				source,
+				{Type: mount.TypeVolume, Source: backup, Target: "/to"},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up volume %s: %w", key, err)
+		}
+		backups[key] = backup
+	}
+	return backups, nil
+}
+
+// checkClearable refuses to clear the volumes of a service whose container
+// another project created, or that other containers mount, so an upgrade
+// never wipes data that isn't the service's own
+func (m *ServiceManager) checkClearable(name string, cfg *config.DockerServiceConfig) error {
+	if project := m.ContainerProject(name); project != "" && project != projectDir() {
+		return fmt.Errorf("the container of %s belongs to the project in %s, upgrade it from there", name, project)
+	}
+	for key := range cfg.Volumes {
+		source, err := m.volumeMount(name, key, "/from")
+		if err != nil {
+			return err
+		}
+		if source.Type != mount.TypeVolume {
+			continue
+		}
+		users, err := m.VolumeUsers(source.Source, name)
+		if err != nil {
+			return err
+		}
+		if len(users) > 0 {
+			return fmt.Errorf("volume %s of %s is also mounted by %s, refusing to clear it", source.Source, name, strings.Join(users, ", "))
+		}
+	}
+	return nil
+}
+
+// clearVolumes empties every volume of a stopped service so a new major
+// version can initialize a fresh data directory
+func (m *ServiceManager) clearVolumes(name string, cfg *config.DockerServiceConfig) error {
+	if err := m.checkClearable(name, cfg); err != nil {
+		return err
+	}
+	for key := range cfg.Volumes {
+		source, err := m.volumeMount(name, key, "/from")
+		if err != nil {
+			return err
+		}
+
+		err = m.RunOneShot(OneShotOptions{
+			Name:       name + "_clear",
+			Image:      cfg.Image,
+			Entrypoint: []string{"sh", "-c"},
+			Command:    []string{"find /from -mindepth 1 -delete"},
+			Mounts:     []mount.Mount{source},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to clear volume %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// UpgradeService moves a service from oldCfg to newCfg across a major database
+// version. The old data is always kept in backup volumes. strategy selects how
+// data is carried over: MigrateDump, MigratePgUpgrade, or "" to start the new
+// version with an empty data directory.
+func (m *ServiceManager) UpgradeService(name string, oldCfg, newCfg *config.DockerServiceConfig, upgrade *MajorUpgrade, strategy string) (map[string]string, error) {
+	if strategy != "" && upgrade.Engine != "postgres" {
+		return nil, fmt.Errorf("automatic migration is only supported for postgres, not %s", upgrade.Engine)
+	}
+	if err := m.checkClearable(name, oldCfg); err != nil {
+		return nil, err
+	}
+
+	// Dumps are taken from the old server while it is still running
+	var dump []byte
+	if strategy == MigrateDump {
+		if !m.IsRunning(name) {
+			if err := m.StartService(name, oldCfg); err != nil {
+				return nil, fmt.Errorf("failed to start %s to dump its data: %w", upgrade.Engine, err)
+			}
+		}
+		var err error
+		if dump, err = m.dumpPostgres(name, oldCfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.IsRunning(name) {
+		if err := m.StopService(name, oldCfg); err != nil {
+			return nil, err
+		}
+	}
+
+	suffix := fmt.Sprintf("v%d_%s", upgrade.From, time.Now().Format("20060102150405"))
+	backups, err := m.BackupVolumes(name, oldCfg, suffix)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.clearVolumes(name, oldCfg); err != nil {
+		return backups, err
+	}
+
+	if strategy == MigratePgUpgrade {
+		if err := m.pgUpgrade(name, oldCfg, upgrade, backups); err != nil {
+			return backups, err
+		}
+	}
+
+	if err := m.StartService(name, newCfg); err != nil {
+		return backups, err
+	}
+
+	if strategy == MigrateDump {
+		if err := m.restorePostgres(name, newCfg, dump); err != nil {
+			return backups, err
+		}
+	}
+
+	return backups, nil
+}
+
+// postgresUser returns the superuser configured for a postgres service
+func postgresUser(cfg *config.DockerServiceConfig) string {
+	if user := cfg.Environment["POSTGRES_USER"]; user != "" {
+		return user
+	}
+	return "postgres"
+}
+
+// dumpPostgres runs pg_dumpall in the service container and returns the dump
+// as a tar archive ready to be copied into another container
+func (m *ServiceManager) dumpPostgres(name string, cfg *config.DockerServiceConfig) ([]byte, error) {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("Dumping databases with pg_dumpall...")
+	command := []string{"pg_dumpall", "-U", postgresUser(cfg), "-f", "/tmp/spin_upgrade.sql"}
+	if output, err := m.Exec(containerID, command); err != nil {
+		return nil, fmt.Errorf("pg_dumpall failed: %w\n%s", err, output)
+	}
+
+	reader, _, err := m.client.CopyFromContainer(m.ctx, containerID, "/tmp/spin_upgrade.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dump: %w", err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// restorePostgres loads a dump produced by dumpPostgres into the service
+func (m *ServiceManager) restorePostgres(name string, cfg *config.DockerServiceConfig, dump []byte) error {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Restoring databases into the new version...")
+	if err := m.client.CopyToContainer(m.ctx, containerID, "/tmp", bytes.NewReader(dump), types.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy dump: %w", err)
+	}
+
+	// Errors such as the superuser already existing are expected, so psql
+	// keeps going and only a failure to run at all is reported
+	command := []string{"psql", "-q", "-U", postgresUser(cfg), "-d", "postgres", "-f", "/tmp/spin_upgrade.sql"}
+	if output, err := m.Exec(containerID, command); err != nil {
+		return fmt.Errorf("restore failed: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// pgUpgrade runs pg_upgrade from the backed up data directory into the
+// freshly cleared one using the tianon/postgres-upgrade images
+func (m *ServiceManager) pgUpgrade(name string, cfg *config.DockerServiceConfig, upgrade *MajorUpgrade, backups map[string]string) error {
+	// Find the volume holding PGDATA
+	pgdata := cfg.Environment["PGDATA"]
+	if pgdata == "" {
+		pgdata = "/var/lib/postgresql/data"
+	}
+	var key, subdir string
+	for k, target := range cfg.Volumes {
+		if pgdata == target || strings.HasPrefix(pgdata, target+"/") {
+			key, subdir = k, strings.TrimPrefix(pgdata, target)
+			break
+		}
+	}
+	if key == "" {
+		return fmt.Errorf("no volume contains the data directory %s", pgdata)
+	}
+
+	oldRoot := fmt.Sprintf("/var/lib/postgresql/%d/data", upgrade.From)
+	newRoot := fmt.Sprintf("/var/lib/postgresql/%d/data", upgrade.To)
+	current, err := m.volumeMount(name, key, newRoot)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Running pg_upgrade...")
+	return m.RunOneShot(OneShotOptions{
+		Name:  name + "_pg_upgrade",
+		Image: fmt.Sprintf("tianon/postgres-upgrade:%d-to-%d", upgrade.From, upgrade.To),
+		Env: map[string]string{
+			"PGUSER":               postgresUser(cfg),
+			"POSTGRES_INITDB_ARGS": "--username=" + postgresUser(cfg),
+			"PGDATAOLD":            oldRoot + subdir,
+			"PGDATANEW":            newRoot + subdir,
+		},
+		// Keep the old authentication rules, initdb only allows local connections
+		Entrypoint: []string{"sh", "-c"},
+		Command:    []string{`docker-upgrade pg_upgrade && cp "$PGDATAOLD/pg_hba.conf" "$PGDATANEW/pg_hba.conf"`},
+		Mounts: []mount.Mount{
+			{Type: mount.TypeVolume, Source: backups[key], Target: oldRoot},
+			current,
+		},
+	})
+}
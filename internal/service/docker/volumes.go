@@ -0,0 +1,326 @@
+package docker
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+)
+
+// IsRemote reports whether the Docker engine lives on another machine.
+// Bind mounts of local paths don't work against a remote engine, so services
+// fall back to named volumes that can be synced with PushVolumes/PullVolumes.
+func (m *ServiceManager) IsRemote() bool {
+	hostURL, err := client.ParseHostURL(m.client.DaemonHost())
+	if err != nil {
+		return false
+	}
+
+	switch hostURL.Scheme {
+	case "unix", "npipe":
+		return false
+	case "ssh":
+		return true
+	}
+
+	host := hostURL.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	switch host {
+	case "", "localhost", "127.0.0.1", "::1":
+		return false
+	}
+	return true
+}
+
+// isHostPath checks if a volume key refers to a directory on the host
+// rather than the name of a Docker-managed volume
+func isHostPath(key string) bool {
+	return strings.HasPrefix(key, ".") || strings.HasPrefix(key, "/") || strings.HasPrefix(key, "~")
+}
+
+// volumeSource returns the named volume used for a volume key. Volumes are
+// named after their service, so services with the same key don't share data.
+func volumeSource(serviceName, key string) string {
+	if isHostPath(key) {
+		key = strings.Trim(filepath.Base(filepath.Clean(key)), ".")
+		if key == "" {
+			key = "data"
+		}
+	}
+	return fmt.Sprintf("spin_%s_%s", serviceName, key)
+}
+
+// ProjectLabel marks the containers of services with the directory of the
+// project that created them
+const ProjectLabel = "spin.project"
+
+// projectDir returns the directory of the project spin runs for
+func projectDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// ContainerProject returns the directory of the project that created the
+// container of a service, "" when it has no container or was created before
+// containers were labeled
+func (m *ServiceManager) ContainerProject(name string) string {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return ""
+	}
+	container, err := m.client.ContainerInspect(m.ctx, containerID)
+	if err != nil {
+		return ""
+	}
+	return container.Config.Labels[ProjectLabel]
+}
+
+// OwnsContainer reports whether the container of a service was created by
+// the project in the working directory
+func (m *ServiceManager) OwnsContainer(name string) bool {
+	project := m.ContainerProject(name)
+	return project != "" && project == projectDir()
+}
+
+// VolumeUsers returns the containers mounting a named volume, other than the
+// container of the service name
+func (m *ServiceManager) VolumeUsers(volume string, name string) ([]string, error) {
+	containers, err := m.client.ContainerList(m.ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("volume", volume)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	var users []string
+	for _, c := range containers {
+		if len(c.Names) == 0 || c.Names[0] == "/spin_"+name {
+			continue
+		}
+		users = append(users, strings.TrimPrefix(c.Names[0], "/"))
+	}
+	return users, nil
+}
+
+// volumeMount builds the mount for a single volume entry of a service.
+// Host paths are bind mounted on local engines and converted to named
+// volumes on remote engines.
+func (m *ServiceManager) volumeMount(serviceName, key, target string) (mount.Mount, error) {
+	if isHostPath(key) && !m.IsRemote() {
+		source := key
+		if strings.HasPrefix(source, "~") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return mount.Mount{}, fmt.Errorf("failed to get home directory: %w", err)
+			}
+			source = filepath.Join(home, strings.TrimPrefix(source, "~"))
+		}
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return mount.Mount{}, fmt.Errorf("failed to resolve volume path %s: %w", key, err)
+		}
+		if err := os.MkdirAll(abs, 0755); err != nil {
+			return mount.Mount{}, fmt.Errorf("failed to create volume directory %s: %w", abs, err)
+		}
+		return mount.Mount{
+			Type:   mount.TypeBind,
+			Source: abs,
+			Target: target,
+		}, nil
+	}
+
+	if isHostPath(key) {
+		fmt.Printf("Docker engine is remote, using named volume %s for %s (see 'spin services sync-data')\n",
+			volumeSource(serviceName, key), key)
+	}
+
+	return mount.Mount{
+		Type:   mount.TypeVolume,
+		Source: volumeSource(serviceName, key),
+		Target: target,
+	}, nil
+}
+
+// PullVolumes copies the contents of every volume of a service into localDir.
+// Each volume is written to a subdirectory named after its volume key.
+func (m *ServiceManager) PullVolumes(name string, cfg *config.DockerServiceConfig, localDir string) error {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return fmt.Errorf("service %s has no container, start it once before syncing: %w", name, err)
+	}
+
+	for key, target := range cfg.Volumes {
+		dest := filepath.Join(localDir, syncDirName(key))
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dest, err)
+		}
+
+		reader, _, err := m.client.CopyFromContainer(m.ctx, containerID, target)
+		if err != nil {
+			return fmt.Errorf("failed to read volume %s: %w", key, err)
+		}
+		err = extractTar(reader, dest)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to extract volume %s: %w", key, err)
+		}
+		fmt.Printf("Pulled %s -> %s\n", key, dest)
+	}
+
+	return nil
+}
+
+// PushVolumes uploads the contents of localDir into every volume of a service.
+// The service must be stopped so running software doesn't see partial writes.
+func (m *ServiceManager) PushVolumes(name string, cfg *config.DockerServiceConfig, localDir string) error {
+	containerID, err := m.FindContainer(name)
+	if err != nil {
+		return fmt.Errorf("service %s has no container, start it once before syncing: %w", name, err)
+	}
+	if m.IsRunning(name) {
+		return fmt.Errorf("service %s is running, stop it before pushing data", name)
+	}
+
+	for key, target := range cfg.Volumes {
+		src := filepath.Join(localDir, syncDirName(key))
+		if _, err := os.Stat(src); err != nil {
+			fmt.Printf("Skipping %s: %s does not exist\n", key, src)
+			continue
+		}
+
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(createTar(src, pw))
+		}()
+
+		err := m.client.CopyToContainer(m.ctx, containerID, target, pr, types.CopyToContainerOptions{
+			AllowOverwriteDirWithFile: true,
+		})
+		pr.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write volume %s: %w", key, err)
+		}
+		fmt.Printf("Pushed %s -> %s\n", src, key)
+	}
+
+	return nil
+}
+
+// syncDirName returns the local directory name used for a volume key
+func syncDirName(key string) string {
+	if isHostPath(key) {
+		return filepath.Base(filepath.Clean(key))
+	}
+	return key
+}
+
+// extractTar unpacks a tar stream into dest
+func extractTar(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Entries are rooted at the base name of the copied directory
+		rel := path.Clean(hdr.Name)
+		if i := strings.Index(rel, "/"); i >= 0 {
+			rel = rel[i+1:]
+		} else {
+			continue
+		}
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in archive: %s", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(hdr.Mode)|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(hdr.Mode)|0600)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			f.Close()
+		case tar.TypeSymlink:
+			os.Remove(target)
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// createTar writes the contents of src to w as a tar stream
+func createTar(src string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(tw, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -195,7 +195,7 @@ func (s *DockerService) Stop() error {
 		return fmt.Errorf("failed to create Docker manager: %w", err)
 	}
 
-	return manager.StopService(s.name)
+	return manager.StopService(s.name, s.config)
 }
 
 func (s *DockerService) IsRunning() bool {
@@ -0,0 +1,244 @@
+package setup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/credentials"
+	"github.com/afomera/spin/internal/logger"
+	"github.com/afomera/spin/internal/script"
+)
+
+// Record describes a completed setup task
+type Record struct {
+	Hash        string    `json:"hash"` // Hash of the task and its inputs when it ran
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Status is the state of a setup task
+type Status string
+
+// States of a setup task
+const (
+	StatusPending  Status = "pending" // Never ran or ran with a different definition
+	StatusChanged  Status = "changed" // Ran, but its inputs changed since
+	StatusUpToDate Status = "up to date"
+)
+
+// TaskStatus is a setup task with its state
+type TaskStatus struct {
+	Task   config.SetupTask
+	Status Status
+	Record *Record
+}
+
+// Files in .spin/state of a project tracking completed setup tasks and the
+// steps of spin up
+const (
+	setupStateFile = "setup.json"
+	upStateFile    = "up.json"
+)
+
+// statePath returns a file that tracks completed tasks of a project
+func statePath(appPath string, file string) string {
+	return filepath.Join(appPath, ".spin", "state", file)
+}
+
+// loadState reads the completed tasks recorded in a state file
+func loadState(path string) (map[string]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]Record), nil
+		}
+		return nil, err
+	}
+
+	state := make(map[string]Record)
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse setup state: %w", err)
+	}
+	return state, nil
+}
+
+// saveState writes the completed tasks to a state file
+func saveState(path string, state map[string]Record) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// hashTask fingerprints a task and the contents of its inputs, so a task runs
+// again when either changes
+func hashTask(appPath string, task config.SetupTask) (string, error) {
+	h := sha256.New()
+	data, _ := json.Marshal(task)
+	h.Write(data)
+
+	var files []string
+	for _, pattern := range task.Inputs {
+		matches, err := filepath.Glob(filepath.Join(appPath, pattern))
+		if err != nil {
+			return "", fmt.Errorf("invalid input %q of setup task %s: %w", pattern, task.Name, err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		rel, _ := filepath.Rel(appPath, file)
+		fmt.Fprintf(h, "\x00%s\x00", rel)
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Statuses returns the setup tasks of a project with whether they need to run
+func Statuses(cfg *config.Config, appPath string) ([]TaskStatus, error) {
+	state, err := loadState(statePath(appPath, setupStateFile))
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]TaskStatus, 0, len(cfg.Setup))
+	for _, task := range cfg.Setup {
+		status := TaskStatus{Task: task, Status: StatusPending}
+		if record, ok := state[task.Name]; ok {
+			status.Record = &record
+			hash, err := hashTask(appPath, task)
+			if err != nil {
+				return nil, err
+			}
+			if record.Hash == hash {
+				status.Status = StatusUpToDate
+			} else {
+				status.Status = StatusChanged
+			}
+		}
+
+		// A task that creates a file is done as long as the file exists
+		if task.Creates != "" {
+			if _, err := os.Stat(filepath.Join(appPath, task.Creates)); err == nil {
+				status.Status = StatusUpToDate
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Reset forgets completed tasks so they run again on the next spin setup
+func Reset(appPath string) error {
+	if err := os.Remove(statePath(appPath, setupStateFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Run executes the setup tasks of a project in order, skipping those that
+// are up to date unless force is set, and those named in skip. Each task is
+// recorded as soon as it succeeds, so a failure only re-runs what is left.
+// Skipped tasks aren't recorded and run the next time.
+func Run(cfg *config.Config, appPath string, force bool, skip []string) error {
+	statuses, err := Statuses(cfg, appPath)
+	if err != nil {
+		return err
+	}
+	state, err := loadState(statePath(appPath, setupStateFile))
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		task := status.Task
+		if containsTask(skip, task.Name) {
+			fmt.Printf("  %s-%s %s %s(skipped)%s\n", logger.Yellow, logger.Reset, task.Name, logger.Blue, logger.Reset)
+			continue
+		}
+		if status.Status == StatusUpToDate && !force {
+			fmt.Printf("  %s✓%s %s %s(up to date)%s\n", logger.Green, logger.Reset, task.Name, logger.Blue, logger.Reset)
+			continue
+		}
+
+		fmt.Printf("  %s▶%s %s: %s\n", logger.Cyan, logger.Reset, task.Name, task.Command)
+		if err := runTask(cfg, appPath, task); err != nil {
+			return fmt.Errorf("setup task %s failed: %w", task.Name, err)
+		}
+
+		// Hash after running, tasks like bundle install may update their inputs
+		hash, err := hashTask(appPath, task)
+		if err != nil {
+			return err
+		}
+		state[task.Name] = Record{Hash: hash, CompletedAt: time.Now()}
+		if err := saveState(statePath(appPath, setupStateFile), state); err != nil {
+			return fmt.Errorf("failed to record setup task %s: %w", task.Name, err)
+		}
+	}
+	return nil
+}
+
+// UnknownTasks returns the names that aren't setup tasks of a project
+func UnknownTasks(cfg *config.Config, names []string) []string {
+	var unknown []string
+	for _, name := range names {
+		found := false
+		for _, task := range cfg.Setup {
+			if task.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+func containsTask(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runTask runs the command of a task through the shell, recording it in the
+// script history
+func runTask(cfg *config.Config, appPath string, task config.SetupTask) error {
+	name := "setup:" + task.Name
+	entry, opts := script.StartRun(cfg.Name, name, &script.RunOptions{
+		WorkDir: appPath,
+		Env:     cfg.GetEnvVars("development"),
+	})
+	if env, err := credentials.WithMasterKey(cfg.Name, appPath, opts.Env); err == nil {
+		opts.Env = env
+	}
+	err := script.NewScript(name, task.Command, "").Execute(opts)
+	entry.Finish(err)
+	return err
+}
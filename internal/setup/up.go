@@ -0,0 +1,58 @@
+package setup
+
+import (
+	"os"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+	"github.com/afomera/spin/internal/detector"
+)
+
+// NeedsRun reports whether a preflight task of spin up has to run, because
+// it never succeeded or its inputs changed since
+func NeedsRun(appPath string, task detector.PreflightTask) (bool, error) {
+	state, err := loadState(statePath(appPath, upStateFile))
+	if err != nil {
+		return true, err
+	}
+	record, ok := state[task.Name]
+	if !ok {
+		return true, nil
+	}
+	hash, err := hashTask(appPath, setupTask(task))
+	if err != nil {
+		return true, err
+	}
+	return record.Hash != hash, nil
+}
+
+// RecordRun records that a preflight task of spin up succeeded. It is
+// hashed after running, since installing and migrating update their inputs.
+func RecordRun(appPath string, task detector.PreflightTask) error {
+	path := statePath(appPath, upStateFile)
+	state, err := loadState(path)
+	if err != nil {
+		// A broken state only makes tasks run again
+		state = make(map[string]Record)
+	}
+	hash, err := hashTask(appPath, setupTask(task))
+	if err != nil {
+		return err
+	}
+	state[task.Name] = Record{Hash: hash, CompletedAt: time.Now()}
+	return saveState(path, state)
+}
+
+// ResetUp forgets the preflight tasks spin up ran, so they run again, like
+// after the database was removed
+func ResetUp(appPath string) error {
+	if err := os.Remove(statePath(appPath, upStateFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// setupTask hashes like the setup task running the same command
+func setupTask(task detector.PreflightTask) config.SetupTask {
+	return config.SetupTask{Name: task.Name, Command: task.Command, Inputs: task.Inputs}
+}
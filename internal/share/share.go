@@ -0,0 +1,130 @@
+// Package share exposes local processes on a public URL through ngrok or a
+// cloudflared quick tunnel
+package share
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Providers are the tunnel programs processes are shared with, in the order
+// they are looked for
+var Providers = []string{"ngrok", "cloudflared"}
+
+// cloudflaredURLPattern matches the URL of a cloudflared quick tunnel
+var cloudflaredURLPattern = regexp.MustCompile(`https://[a-z0-9-]+\.trycloudflare\.com`)
+
+// ProcessName returns the name a share of a process runs under, like
+// share-web
+func ProcessName(name string) string {
+	return "share-" + name
+}
+
+// Detect returns the provider to share with: the preferred one, or the first
+// one installed
+func Detect(preferred string) (string, error) {
+	if preferred != "" {
+		if !isProvider(preferred) {
+			return "", fmt.Errorf("unknown provider %q, use %s", preferred, strings.Join(Providers, " or "))
+		}
+		if _, err := exec.LookPath(preferred); err != nil {
+			return "", fmt.Errorf("%s is not installed", preferred)
+		}
+		return preferred, nil
+	}
+	for _, provider := range Providers {
+		if _, err := exec.LookPath(provider); err == nil {
+			return provider, nil
+		}
+	}
+	return "", fmt.Errorf("neither ngrok nor cloudflared is installed, install one of them to share processes")
+}
+
+func isProvider(name string) bool {
+	for _, provider := range Providers {
+		if provider == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Args returns the arguments of the provider for exposing a local port. ngrok
+// logs JSON to stdout, which carries the URL of the tunnel.
+func Args(provider string, port int) []string {
+	if provider == "cloudflared" {
+		return []string{"tunnel", "--no-autoupdate", "--url", "http://localhost:" + strconv.Itoa(port)}
+	}
+	return []string{"http", strconv.Itoa(port), "--log", "stdout", "--log-format", "json"}
+}
+
+// ParseURL returns the public URL in a line of the provider's output
+func ParseURL(provider string, line string) (string, bool) {
+	if provider == "cloudflared" {
+		url := cloudflaredURLPattern.FindString(line)
+		return url, url != ""
+	}
+
+	var entry struct {
+		Msg string `json:"msg"`
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Msg != "started tunnel" {
+		return "", false
+	}
+	return entry.URL, strings.HasPrefix(entry.URL, "https://")
+}
+
+// Run exposes a local port until ctx is done or the provider exits. Its
+// output is copied to out, and onURL is called with every public URL the
+// provider reports, also when it changes after a reconnect. The ngrok auth
+// token is passed in the environment rather than on the command line.
+func Run(ctx context.Context, provider string, port int, authToken string, out io.Writer, onURL func(string)) error {
+	cmd := exec.CommandContext(ctx, provider, Args(provider, port)...)
+	cmd.Env = os.Environ()
+	if provider == "ngrok" && authToken != "" {
+		cmd.Env = append(cmd.Env, "NGROK_AUTHTOKEN="+authToken)
+	}
+
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+		writer.Close()
+	}()
+
+	current := ""
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintln(out, line)
+		if url, ok := ParseURL(provider, line); ok && url != current {
+			current = url
+			onURL(url)
+		}
+	}
+	// Keep the provider from blocking on a line too long to scan
+	io.Copy(out, reader)
+
+	err := <-done
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err == nil {
+		err = fmt.Errorf("%s exited", provider)
+	}
+	return err
+}
@@ -0,0 +1,200 @@
+package spinerr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	lg "github.com/afomera/spin/internal/logger"
+)
+
+// Category is the kind of failure, shown as the heading of an error
+type Category int
+
+const (
+	// Script indicates an error with a script itself
+	Script Category = iota
+	// Hook indicates an error in a script or lifecycle hook
+	Hook
+	// Validation indicates a configuration validation error
+	Validation
+	// Execution indicates an error while running a command
+	Execution
+	// Config indicates spin.config.json can't be read or used
+	Config
+	// Docker indicates Docker isn't reachable or failed
+	Docker
+	// Service indicates a service failed to start, stop or become healthy
+	Service
+	// Process indicates a process failed to start or stop
+	Process
+)
+
+// String returns the heading of the category
+func (c Category) String() string {
+	switch c {
+	case Script:
+		return "Script Error"
+	case Hook:
+		return "Hook Error"
+	case Validation:
+		return "Validation Error"
+	case Execution:
+		return "Execution Error"
+	case Config:
+		return "Configuration Error"
+	case Docker:
+		return "Docker Error"
+	case Service:
+		return "Service Error"
+	case Process:
+		return "Process Error"
+	default:
+		return "Unknown Error"
+	}
+}
+
+// Exit codes of spin, so scripts can tell failures apart
+const (
+	ExitFailure       = 1 // Any other failure
+	ExitConfig        = 3 // spin.config.json is missing or invalid
+	ExitDocker        = 4 // Docker isn't reachable
+	ExitPortConflict  = 5 // A port is already in use
+	ExitHealthTimeout = 6 // A service didn't become healthy in time
+	ExitProcess       = 7 // A process failed to start or stop
+)
+
+// Error is a failure with what happened, why and how to fix it
+type Error struct {
+	Category Category
+	Message  string // What happened
+	Details  string // Why it happened
+	Fix      string // How to fix it
+	Code     int    // Exit code, that of the category when 0
+	Cause    error  // Underlying error, like the exit status of the command
+}
+
+// New creates an error of a category, with optional details
+func New(category Category, message string, details ...string) *Error {
+	e := &Error{
+		Category: category,
+		Message:  message,
+	}
+	if len(details) > 0 {
+		e.Details = details[0]
+	}
+	return e
+}
+
+// Wrap creates an error of a category caused by err, whose message becomes
+// the details. An *Error in err gives its own message, fix and exit code
+// instead.
+func Wrap(category Category, message string, err error) *Error {
+	e := New(category, message, err.Error()).WithCause(err)
+	var inner *Error
+	if errors.As(err, &inner) {
+		e.Details = inner.Error()
+		e.Fix = inner.Fix
+		if code := inner.ExitCode(); code != ExitFailure {
+			e.Code = code
+		}
+	}
+	return e
+}
+
+// Error implements the error interface. It is one line, like other errors,
+// so it reads well wrapped or in a table; Print shows the whole block.
+func (e *Error) Error() string {
+	if e.Details != "" {
+		return e.Message + ": " + e.Details
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// WithCause records the underlying error
+func (e *Error) WithCause(err error) *Error {
+	e.Cause = err
+	return e
+}
+
+// WithFix adds a fix suggestion to the error
+func (e *Error) WithFix(fix string) *Error {
+	e.Fix = fix
+	return e
+}
+
+// WithCode sets the exit code of the error, for failures more specific than
+// their category, like a port conflict
+func (e *Error) WithCode(code int) *Error {
+	e.Code = code
+	return e
+}
+
+// ExitCode returns the exit code of the error: its own, or that of its
+// category
+func (e *Error) ExitCode() int {
+	if e.Code != 0 {
+		return e.Code
+	}
+	switch e.Category {
+	case Config:
+		return ExitConfig
+	case Docker:
+		return ExitDocker
+	case Process:
+		return ExitProcess
+	}
+	return ExitFailure
+}
+
+// ExitCode returns the exit code for err, ExitFailure for errors that
+// aren't an *Error
+func ExitCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.ExitCode()
+	}
+	return ExitFailure
+}
+
+// Print writes err to w as a block saying what happened, why and how to fix
+// it. Context added by wrapping an *Error with fmt.Errorf is kept in front of
+// its message, and other errors are printed on one line.
+func Print(w io.Writer, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		fmt.Fprintf(w, "%sError: %v%s\n", lg.Red, err, lg.Reset)
+		return
+	}
+
+	message := e.Message
+	if text := err.Error(); text != e.Error() && strings.HasSuffix(text, e.Error()) {
+		message = strings.TrimSuffix(text, e.Error()) + message
+	}
+	fmt.Fprintf(w, "%s%s: %s%s\n", lg.Red, e.Category, message, lg.Reset)
+	details := e.Details
+	if details == "" && e.Cause != nil {
+		details = e.Cause.Error()
+	}
+	// Lines after the first are indented under the text
+	if details != "" {
+		fmt.Fprintf(w, "  %sWhy:%s %s\n", lg.Yellow, lg.Reset, strings.ReplaceAll(details, "\n", "\n       "))
+	}
+	if e.Fix != "" {
+		fmt.Fprintf(w, "  %sFix:%s %s\n", lg.Blue, lg.Reset, strings.ReplaceAll(e.Fix, "\n", "\n       "))
+	}
+}
+
+// Exit prints err to stderr like Print, keeping stdout for results, and
+// exits with its exit code
+func Exit(err error) {
+	Print(os.Stderr, err)
+	os.Exit(ExitCode(err))
+}
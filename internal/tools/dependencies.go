@@ -0,0 +1,255 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Dependency is a tool listed in dependencies.tools of spin.config.json,
+// like "node >=20", "ruby 3.3" or "yarn"
+type Dependency struct {
+	Name     string
+	Operator string // >=, >, <=, <, = or ~>, empty when any version will do
+	Version  string
+}
+
+// DependencyCheck is the result of looking for a dependency
+type DependencyCheck struct {
+	Dependency
+	Path      string // Where the tool was found
+	Installed string // Installed version, only read when the dependency has a constraint
+	OK        bool
+	Pending   bool // Not found, but provided by the project's packages once they are installed
+}
+
+// versionPattern finds a version number in the output of tool --version
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// operators are the version constraints a dependency can have, longest first
+var operators = []string{">=", "<=", "~>", "==", ">", "<", "="}
+
+// ParseDependency parses an entry of dependencies.tools
+func ParseDependency(spec string) (Dependency, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return Dependency{}, fmt.Errorf("empty tool")
+	}
+
+	dep := Dependency{Name: fields[0]}
+	constraint := strings.Join(fields[1:], "")
+	if constraint == "" {
+		return dep, nil
+	}
+
+	dep.Operator = "="
+	for _, op := range operators {
+		if strings.HasPrefix(constraint, op) {
+			dep.Operator = op
+			constraint = strings.TrimPrefix(constraint, op)
+			break
+		}
+	}
+	if dep.Operator == "==" {
+		dep.Operator = "="
+	}
+	dep.Version = strings.TrimPrefix(constraint, "v")
+	if !startsWithDigit(dep.Version) {
+		return Dependency{}, fmt.Errorf("invalid version constraint in %q", spec)
+	}
+	return dep, nil
+}
+
+// String formats the dependency the way it is written in the config
+func (d Dependency) String() string {
+	if d.Operator == "" {
+		return d.Name
+	}
+	if d.Operator == "=" {
+		return d.Name + " " + d.Version
+	}
+	return d.Name + " " + d.Operator + d.Version
+}
+
+// Satisfies reports whether an installed version meets the constraint
+func (d Dependency) Satisfies(installed string) bool {
+	if d.Operator == "" {
+		return true
+	}
+	if installed == "" {
+		return false
+	}
+
+	cmp := compareVersions(installed, d.Version)
+	switch d.Operator {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	case "~>":
+		// ~> 3.2 allows 3.x from 3.2 on, ~> 3.2.1 allows 3.2.x from 3.2.1 on
+		parts := strings.Split(d.Version, ".")
+		prefix := strings.Join(parts[:max(len(parts)-1, 1)], ".")
+		return cmp >= 0 && (installed == prefix || strings.HasPrefix(installed, prefix+"."))
+	default:
+		return installed == d.Version || strings.HasPrefix(installed, d.Version+".")
+	}
+}
+
+// CheckDependencies looks for the tools a project in dir depends on, on the
+// PATH and in the project's node_modules/.bin and bin directories
+func CheckDependencies(dir string, specs []string) ([]DependencyCheck, error) {
+	var checks []DependencyCheck
+	for _, spec := range specs {
+		dep, err := ParseDependency(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry in dependencies.tools: %w", err)
+		}
+		checks = append(checks, checkDependency(dir, dep))
+	}
+	return checks, nil
+}
+
+// checkDependency looks for a single tool and reads its version
+func checkDependency(dir string, dep Dependency) DependencyCheck {
+	check := DependencyCheck{Dependency: dep}
+
+	check.Path = lookPath(dir, dep.Name)
+	if check.Path == "" {
+		// Tools like eslint come with the project's packages
+		if exists(filepath.Join(dir, "package.json")) && !exists(filepath.Join(dir, "node_modules")) && !systemTool(dep.Name) {
+			check.Pending = true
+			check.OK = true
+		}
+		return check
+	}
+
+	if dep.Operator != "" {
+		check.Installed = InstalledVersion(dir, dep.Name)
+		if check.Installed == "" {
+			check.Installed = toolVersion(dir, check.Path)
+		}
+	}
+	check.OK = dep.Satisfies(check.Installed)
+	return check
+}
+
+// lookPath finds a tool on the PATH or in the bin directories of the project
+func lookPath(dir string, name string) string {
+	if path, err := exec.LookPath(name); err == nil {
+		return path
+	}
+	for _, bin := range []string{filepath.Join("node_modules", ".bin"), "bin"} {
+		path := filepath.Join(dir, bin, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return path
+		}
+	}
+	return ""
+}
+
+// toolVersion reads the version from the output of tool --version
+func toolVersion(dir string, path string) string {
+	cmd := exec.Command(path, "--version")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	return versionPattern.FindString(string(output))
+}
+
+// exists reports whether a file or directory exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// systemPackages maps tools to their package names for brew, apt and dnf,
+// when those differ from the name of the tool
+var systemPackages = map[string]map[string]string{
+	"ruby":      {"apt": "ruby-full"},
+	"node":      {"apt": "nodejs", "dnf": "nodejs"},
+	"go":        {"apt": "golang-go", "dnf": "golang"},
+	"python":    {"brew": "python", "apt": "python3", "dnf": "python3"},
+	"python3":   {"brew": "python"},
+	"psql":      {"brew": "libpq", "apt": "postgresql-client", "dnf": "postgresql"},
+	"pg_dump":   {"brew": "libpq", "apt": "postgresql-client", "dnf": "postgresql"},
+	"redis-cli": {"brew": "redis", "apt": "redis-tools", "dnf": "redis"},
+	"mysql":     {"brew": "mysql-client", "apt": "mysql-client", "dnf": "mysql"},
+	"convert":   {"brew": "imagemagick", "apt": "imagemagick", "dnf": "ImageMagick"},
+	"magick":    {"brew": "imagemagick", "apt": "imagemagick", "dnf": "ImageMagick"},
+	"vips":      {"apt": "libvips-tools", "dnf": "vips-tools"},
+}
+
+// languageInstalls are tools installed with a language's own package manager
+var languageInstalls = map[string][]string{
+	"bundler": {"gem", "install", "bundler"},
+	"bundle":  {"gem", "install", "bundler"},
+	"rails":   {"gem", "install", "rails"},
+	"foreman": {"gem", "install", "foreman"},
+	"yarn":    {"corepack", "enable", "yarn"},
+	"pnpm":    {"corepack", "enable", "pnpm"},
+	"npx":     {"npm", "install", "-g", "npm"},
+}
+
+// SystemPackageManager returns the package manager of the operating system,
+// brew on macOS and apt or dnf on Linux, or an empty string if there is none
+func SystemPackageManager() string {
+	candidates := []string{"apt-get", "dnf"}
+	if runtime.GOOS == "darwin" {
+		candidates = []string{"brew"}
+	}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate); err == nil {
+			return strings.TrimSuffix(candidate, "-get")
+		}
+	}
+	return ""
+}
+
+// InstallCommand returns the command that installs the dependency with a
+// version manager or the package manager of its language, or nil when it is
+// installed as a package of the operating system
+func (d Dependency) InstallCommand() []string {
+	if manager := Manager(d.Name); manager != "" && d.Version != "" {
+		return InstallCommand(manager, Requirement{Tool: d.Name, Version: d.Version})
+	}
+	return languageInstalls[d.Name]
+}
+
+// Hint describes how to install a missing tool or the required version
+func (c DependencyCheck) Hint() string {
+	if command := c.InstallCommand(); command != nil {
+		return fmt.Sprintf("run '%s'", strings.Join(command, " "))
+	}
+	if manager := SystemPackageManager(); manager != "" {
+		return fmt.Sprintf("run '%s'", strings.Join(InstallPackagesCommand(manager, []string{SystemPackage(c.Name, manager)}), " "))
+	}
+	return fmt.Sprintf("install %s and make sure it is on your PATH", c.Name)
+}
+
+// systemTool reports whether a tool is installed outside of the project's
+// packages, like node or yarn
+func systemTool(name string) bool {
+	_, language := languageInstalls[name]
+	_, system := systemPackages[name]
+	return language || system || name == "npm"
+}
+
+// SystemPackage returns the name of the package a tool is installed with by
+// brew, apt or dnf
+func SystemPackage(name string, manager string) string {
+	if pkg, ok := systemPackages[name][manager]; ok {
+		return pkg
+	}
+	return name
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// PackageInstalled reports whether a package of the operating system is
+// installed with brew, apt or dnf
+func PackageInstalled(manager string, pkg string) bool {
+	var cmd *exec.Cmd
+	switch manager {
+	case "brew":
+		cmd = exec.Command("brew", "list", "--versions", pkg)
+	case "apt":
+		cmd = exec.Command("dpkg-query", "-W", "-f=${Status}", pkg)
+		output, err := cmd.Output()
+		return err == nil && string(output) == "install ok installed"
+	case "dnf":
+		cmd = exec.Command("rpm", "-q", pkg)
+	default:
+		return false
+	}
+	output, err := cmd.Output()
+	return err == nil && len(output) > 0
+}
+
+// InstallPackagesCommand returns the command that installs packages of the
+// operating system, with sudo for apt and dnf unless spin runs as root
+func InstallPackagesCommand(manager string, pkgs []string) []string {
+	var command []string
+	switch manager {
+	case "brew":
+		return append([]string{"brew", "install"}, pkgs...)
+	case "apt":
+		command = append([]string{"apt-get", "install", "-y"}, pkgs...)
+	case "dnf":
+		command = append([]string{"dnf", "install", "-y"}, pkgs...)
+	default:
+		return nil
+	}
+	if os.Geteuid() != 0 {
+		command = append([]string{"sudo"}, command...)
+	}
+	return command
+}
+
+// RunInstall runs an install command, streaming its output
+func RunInstall(dir string, command []string) error {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", command[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RubyEnvironment is what processes need to run the Ruby a project asks for
+// from a version manager, even when the login shell doesn't activate it
+type RubyEnvironment struct {
+	Manager string            // mise, asdf, rbenv or rvm
+	Version string            // Installed version that satisfies the project
+	Source  string            // File the requirement was read from
+	Path    []string          // Directories to put in front of the PATH
+	Env     map[string]string // Variables that select the version, like RBENV_VERSION
+}
+
+// rubyManagers are the version managers rubies are looked up in, in order of
+// preference
+var rubyManagers = []string{"mise", "asdf", "rbenv", "rvm"}
+
+// RubyEnv finds the Ruby the project in dir asks for among the versions
+// installed by mise, asdf, rbenv or rvm. It returns nil when the project
+// doesn't ask for a Ruby version or no version manager is installed, and an
+// error when version managers are installed but none has the version.
+func RubyEnv(dir string) (*RubyEnvironment, error) {
+	var requirement Requirement
+	for _, r := range Requirements(dir) {
+		if r.Tool == "ruby" {
+			requirement = r
+		}
+	}
+	if !startsWithDigit(requirement.Version) {
+		return nil, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var available []string
+	for _, manager := range rubyManagers {
+		versionsDir := rubyVersionsDir(manager, home)
+		if versionsDir == "" {
+			continue
+		}
+		available = append(available, manager)
+
+		version, prefix := installedRuby(manager, versionsDir, requirement)
+		if prefix == "" {
+			continue
+		}
+		ruby := &RubyEnvironment{
+			Manager: manager,
+			Version: version,
+			Source:  requirement.Source,
+			Path:    []string{filepath.Join(prefix, "bin")},
+			Env:     make(map[string]string),
+		}
+		switch manager {
+		case "mise":
+			ruby.Env["MISE_RUBY_VERSION"] = version
+		case "asdf":
+			ruby.Env["ASDF_RUBY_VERSION"] = version
+		case "rbenv":
+			ruby.Env["RBENV_VERSION"] = version
+		case "rvm":
+			// rvm keeps gems outside of the ruby, in a gemset per version
+			gems := filepath.Join(filepath.Dir(versionsDir), "gems", "ruby-"+version)
+			ruby.Path = []string{filepath.Join(gems, "bin"), filepath.Join(gems+"@global", "bin"), filepath.Join(prefix, "bin")}
+			ruby.Env["GEM_HOME"] = gems
+			ruby.Env["GEM_PATH"] = gems + string(os.PathListSeparator) + gems + "@global"
+			ruby.Env["MY_RUBY_HOME"] = prefix
+		}
+		return ruby, nil
+	}
+
+	if len(available) == 0 {
+		return nil, nil
+	}
+	hint := "run 'spin tools install'"
+	if Manager("ruby") == "" {
+		hint = fmt.Sprintf("install it with %s", available[0])
+	}
+	return nil, fmt.Errorf("ruby %s from %s isn't installed with %s, %s", requirement.Version, requirement.Source, strings.Join(available, " or "), hint)
+}
+
+// rubyVersionsDir returns the directory a version manager installs rubies in,
+// or an empty string when the manager isn't installed
+func rubyVersionsDir(manager string, home string) string {
+	var dir string
+	switch manager {
+	case "mise":
+		dir = os.Getenv("MISE_DATA_DIR")
+		if dir == "" {
+			dir = filepath.Join(home, ".local", "share", "mise")
+			if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+				dir = filepath.Join(xdg, "mise")
+			}
+		}
+		dir = filepath.Join(dir, "installs", "ruby")
+	case "asdf":
+		dir = os.Getenv("ASDF_DATA_DIR")
+		if dir == "" {
+			dir = filepath.Join(home, ".asdf")
+		}
+		dir = filepath.Join(dir, "installs", "ruby")
+	case "rbenv":
+		dir = os.Getenv("RBENV_ROOT")
+		if dir == "" {
+			dir = filepath.Join(home, ".rbenv")
+		}
+		dir = filepath.Join(dir, "versions")
+	case "rvm":
+		dir = os.Getenv("rvm_path")
+		if dir == "" {
+			dir = filepath.Join(home, ".rvm")
+		}
+		dir = filepath.Join(dir, "rubies")
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+// installedRuby returns the newest ruby in versionsDir that satisfies the
+// requirement and the directory it is installed in
+func installedRuby(manager string, versionsDir string, requirement Requirement) (string, string) {
+	entries, err := os.ReadDir(versionsDir)
+	if err != nil {
+		return "", ""
+	}
+
+	var versions []string
+	dirs := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		version := name
+		if manager == "rvm" {
+			// rvm names rubies like ruby-3.3.0, other interpreters differently
+			if !strings.HasPrefix(name, "ruby-") {
+				continue
+			}
+			version = strings.TrimPrefix(name, "ruby-")
+		}
+		if _, err := os.Stat(filepath.Join(versionsDir, name, "bin", "ruby")); err != nil {
+			continue
+		}
+		if requirement.Satisfies(version) {
+			versions = append(versions, version)
+			dirs[version] = filepath.Join(versionsDir, name)
+		}
+	}
+	if len(versions) == 0 {
+		return "", ""
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return compareVersions(versions[i], versions[j]) > 0 })
+	return versions[0], dirs[versions[0]]
+}
+
+// Apply returns env with the Ruby's directories in front of the PATH and its
+// variables set
+func (r *RubyEnvironment) Apply(env []string) []string {
+	result := make([]string, 0, len(env)+len(r.Env)+1)
+	path := ""
+	for _, pair := range env {
+		key, value, _ := strings.Cut(pair, "=")
+		if key == "PATH" {
+			path = value
+			continue
+		}
+		if _, ok := r.Env[key]; ok {
+			continue
+		}
+		result = append(result, pair)
+	}
+
+	keys := make([]string, 0, len(r.Env))
+	for key := range r.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result = append(result, key+"="+r.Env[key])
+	}
+
+	// An empty PATH adds no entry, which would mean the working directory
+	dirs := append([]string{}, r.Path...)
+	if path != "" {
+		dirs = append(dirs, path)
+	}
+	return append(result, "PATH="+strings.Join(dirs, string(os.PathListSeparator)))
+}
@@ -0,0 +1,266 @@
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Requirement is a tool version a project asks for
+type Requirement struct {
+	Tool    string // ruby, node or go
+	Version string // Version or version prefix, like 3.3 or 20
+	Source  string // File the requirement was read from
+	Minimum bool   // Any version at least Version satisfies it, like the go directive of go.mod
+}
+
+// Check is the result of comparing a requirement with what is installed
+type Check struct {
+	Requirement
+	Installed string // Installed version, empty when the tool isn't installed
+	OK        bool
+}
+
+// versionManagers are the version managers spin can install versions with,
+// in order of preference
+var versionManagers = []string{"mise", "asdf", "rbenv", "nodenv"}
+
+// managedTools lists the tools each version manager handles
+var managedTools = map[string][]string{
+	"mise":   {"ruby", "node", "go"},
+	"asdf":   {"ruby", "node", "go"},
+	"rbenv":  {"ruby"},
+	"nodenv": {"node"},
+}
+
+// asdfNames maps .tool-versions plugin names to tools
+var asdfNames = map[string]string{
+	"ruby":   "ruby",
+	"nodejs": "node",
+	"node":   "node",
+	"golang": "go",
+	"go":     "go",
+}
+
+// goDirective matches the go directive of go.mod
+var goDirective = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+(?:\.\d+)?)\s*$`)
+
+// Requirements reads the tool versions a project in dir asks for from
+// .tool-versions, .ruby-version, .nvmrc, .node-version and go.mod. Files
+// specific to a tool take precedence over .tool-versions.
+func Requirements(dir string) []Requirement {
+	found := make(map[string]Requirement)
+	var order []string
+	add := func(r Requirement) {
+		if r.Version == "" {
+			return
+		}
+		if _, ok := found[r.Tool]; !ok {
+			order = append(order, r.Tool)
+		}
+		found[r.Tool] = r
+	}
+
+	if f, err := os.Open(filepath.Join(dir, ".tool-versions")); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(strings.SplitN(scanner.Text(), "#", 2)[0])
+			if len(fields) < 2 {
+				continue
+			}
+			if tool, ok := asdfNames[fields[0]]; ok {
+				add(Requirement{Tool: tool, Version: fields[1], Source: ".tool-versions"})
+			}
+		}
+		f.Close()
+	}
+
+	add(Requirement{Tool: "ruby", Version: readVersionFile(dir, ".ruby-version"), Source: ".ruby-version"})
+	for _, file := range []string{".node-version", ".nvmrc"} {
+		if version := readVersionFile(dir, file); version != "" {
+			add(Requirement{Tool: "node", Version: version, Source: file})
+			break
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		if match := goDirective.FindSubmatch(data); match != nil {
+			add(Requirement{Tool: "go", Version: string(match[1]), Source: "go.mod", Minimum: true})
+		}
+	}
+
+	requirements := make([]Requirement, 0, len(order))
+	for _, tool := range order {
+		requirements = append(requirements, found[tool])
+	}
+	return requirements
+}
+
+// readVersionFile returns the version in a file like .ruby-version, without
+// prefixes like ruby- or v
+func readVersionFile(dir string, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	version := strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0])
+	version = strings.TrimPrefix(version, "ruby-")
+	return strings.TrimPrefix(version, "v")
+}
+
+// InstalledVersion returns the version of a tool on the PATH, or an empty
+// string if it isn't installed
+func InstalledVersion(dir string, tool string) string {
+	var cmd *exec.Cmd
+	switch tool {
+	case "ruby":
+		cmd = exec.Command("ruby", "-e", "print RUBY_VERSION")
+	case "node":
+		cmd = exec.Command("node", "--version")
+	case "go":
+		cmd = exec.Command("go", "env", "GOVERSION")
+	default:
+		return ""
+	}
+	// Version managers pick the version from the files in the project
+	cmd.Dir = dir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	version := strings.TrimSpace(string(output))
+	version = strings.TrimPrefix(version, "v")
+	return strings.TrimPrefix(version, "go")
+}
+
+// Satisfies reports whether an installed version meets a requirement
+func (r Requirement) Satisfies(installed string) bool {
+	if installed == "" {
+		return false
+	}
+	// Aliases like lts/* or latest can't be checked without the manager
+	if !startsWithDigit(r.Version) {
+		return true
+	}
+	if r.Minimum {
+		return compareVersions(installed, r.Version) >= 0
+	}
+	return installed == r.Version || strings.HasPrefix(installed, r.Version+".")
+}
+
+// Constraint describes the versions that satisfy a requirement
+func (r Requirement) Constraint() string {
+	if r.Minimum {
+		return ">= " + r.Version
+	}
+	return r.Version
+}
+
+// CheckAll compares the requirements of a project with what is installed
+func CheckAll(dir string) []Check {
+	var checks []Check
+	for _, r := range Requirements(dir) {
+		installed := InstalledVersion(dir, r.Tool)
+		checks = append(checks, Check{Requirement: r, Installed: installed, OK: r.Satisfies(installed)})
+	}
+	return checks
+}
+
+// Manager returns the version manager that can install versions of a tool,
+// or an empty string if none is installed
+func Manager(tool string) string {
+	for _, manager := range versionManagers {
+		if _, err := exec.LookPath(manager); err != nil {
+			continue
+		}
+		for _, t := range managedTools[manager] {
+			if t == tool {
+				return manager
+			}
+		}
+	}
+	return ""
+}
+
+// InstallCommand returns the command that installs the required version of
+// a tool with a version manager
+func InstallCommand(manager string, r Requirement) []string {
+	switch manager {
+	case "mise":
+		return []string{"mise", "install", r.Tool + "@" + r.Version}
+	case "asdf":
+		plugin := r.Tool
+		switch r.Tool {
+		case "node":
+			plugin = "nodejs"
+		case "go":
+			plugin = "golang"
+		}
+		return []string{"asdf", "install", plugin, r.Version}
+	case "rbenv", "nodenv":
+		return []string{manager, "install", "--skip-existing", r.Version}
+	}
+	return nil
+}
+
+// Hint describes how to fix a failed check
+func (c Check) Hint() string {
+	if manager := Manager(c.Tool); manager != "" {
+		hint := fmt.Sprintf("run 'spin tools install' or '%s'", strings.Join(InstallCommand(manager, c.Requirement), " "))
+		if manager == "mise" || manager == "asdf" {
+			hint += fmt.Sprintf(", and make sure %s is activated in your shell", manager)
+		}
+		return hint
+	}
+	return fmt.Sprintf("install %s %s, or a version manager like mise or asdf", c.Tool, c.Version)
+}
+
+// Install installs the required version of a tool with the first version
+// manager that handles it, streaming its output
+func Install(dir string, r Requirement) error {
+	manager := Manager(r.Tool)
+	if manager == "" {
+		return fmt.Errorf("no version manager found for %s, install mise or asdf", r.Tool)
+	}
+
+	args := InstallCommand(manager, r)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// startsWithDigit reports whether a version is a number rather than an alias
+func startsWithDigit(version string) bool {
+	return version != "" && version[0] >= '0' && version[0] <= '9'
+}
+
+// compareVersions compares dotted version numbers, returning -1, 0 or 1
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(strings.TrimLeft(as[i], "v"))
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
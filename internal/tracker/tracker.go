@@ -3,6 +3,7 @@ package tracker
 // ProcessTracker is implemented by types that can track Docker processes
 type ProcessTracker interface {
 	StartDockerProcess(name string, containerID string, image string) error
+	StopDockerProcess(name string) error
 }
 
 // Global process tracker that will be set by the main application
@@ -0,0 +1,129 @@
+// Package tunnel keeps SSH port forwards to remote services open, like a
+// staging database behind a bastion host or a shared Kafka cluster
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/afomera/spin/internal/config"
+)
+
+const (
+	// maxFailedChecks is how many health checks in a row may fail before the
+	// connection is considered dead and ssh is restarted
+	maxFailedChecks = 3
+	// maxBackoff caps the wait between reconnects
+	maxBackoff = time.Minute
+)
+
+// ProcessName returns the name spin up runs a tunnel under, like
+// tunnel-staging-db
+func ProcessName(name string) string {
+	return "tunnel-" + name
+}
+
+// Args returns the arguments of ssh for a tunnel. ssh runs without a shell
+// or prompts, fails when the port can't be forwarded and exits when the
+// server stops answering, so a dead connection ends the process.
+func Args(cfg *config.TunnelConfig) []string {
+	args := []string{
+		"-N",
+		"-o", "BatchMode=yes",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=15",
+		"-o", "ServerAliveCountMax=3",
+		"-L", fmt.Sprintf("127.0.0.1:%d:%s:%d", cfg.LocalPort, cfg.GetRemoteHost(), cfg.RemotePort),
+	}
+	if cfg.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(cfg.Port))
+	}
+	if cfg.IdentityFile != "" {
+		args = append(args, "-i", cfg.IdentityFile)
+	}
+	destination := cfg.Host
+	if cfg.User != "" {
+		destination = cfg.User + "@" + cfg.Host
+	}
+	return append(args, destination)
+}
+
+// Healthy checks if the local end of a tunnel accepts connections
+func Healthy(cfg *config.TunnelConfig) bool {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", cfg.LocalPort), 2*time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Run keeps a tunnel open until ctx is done. ssh is restarted when it exits
+// or when the forward fails its health checks, waiting longer after each
+// connection that didn't last.
+func Run(ctx context.Context, name string, cfg *config.TunnelConfig, out io.Writer) error {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		return fmt.Errorf("ssh is not installed")
+	}
+
+	backoff := time.Second
+	for {
+		fmt.Fprintf(out, "Opening tunnel %s: localhost:%d -> %s:%d via %s\n", name, cfg.LocalPort, cfg.GetRemoteHost(), cfg.RemotePort, cfg.Host)
+		started := time.Now()
+		err := runOnce(ctx, cfg, out)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if time.Since(started) > maxBackoff {
+			backoff = time.Second
+		}
+		fmt.Fprintf(out, "Tunnel %s closed: %v, reconnecting in %s\n", name, err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// runOnce runs ssh until it exits or the forward stops accepting connections
+func runOnce(ctx context.Context, cfg *config.TunnelConfig, out io.Writer) error {
+	cmd := exec.CommandContext(ctx, "ssh", Args(cfg)...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	ticker := time.NewTicker(cfg.GetHealthInterval())
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case err := <-done:
+			if err == nil {
+				err = fmt.Errorf("ssh exited")
+			}
+			return err
+		case <-ticker.C:
+			if Healthy(cfg) {
+				failures = 0
+				continue
+			}
+			failures++
+			if failures >= maxFailedChecks {
+				cmd.Process.Kill()
+				<-done
+				return fmt.Errorf("localhost:%d stopped accepting connections", cfg.LocalPort)
+			}
+		}
+	}
+}
@@ -5,12 +5,89 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/filelock"
+	"github.com/afomera/spin/internal/forge"
+)
+
+// Process backends that can supervise processes started by spin
+const (
+	BackendTmux   = "tmux"   // Each process runs in its own tmux session
+	BackendNative = "native" // Each process runs in a pseudo-terminal owned by spin
 )
 
 // Config represents user-level configuration
 type Config struct {
-	DefaultOrganization string `json:"defaultOrganization"`
-	PreferSSH           bool   `json:"preferSSH"` // Whether to prefer SSH URLs for git operations
+	DefaultOrganization string             `json:"defaultOrganization"`
+	PreferSSH           bool               `json:"preferSSH"`                     // Whether to prefer SSH URLs for git operations
+	ProcessBackend      string             `json:"processBackend,omitempty"`      // How processes are supervised, tmux when empty
+	Notifications       bool               `json:"notifications,omitempty"`       // Notify when processes crash or services turn unhealthy
+	NotificationWebhook string             `json:"notificationWebhook,omitempty"` // URL notifications are also posted to
+	ScriptShell         string             `json:"scriptShell,omitempty"`         // Shell scripts run in, sh when empty
+	TemplatesRepo       string             `json:"templatesRepo,omitempty"`       // Git repository with the organization's project templates
+	GitHosts            map[string]GitHost `json:"gitHosts,omitempty"`            // Where the repositories of each organization are hosted
+	ShareProvider       string             `json:"shareProvider,omitempty"`       // ngrok or cloudflared for spin share, whichever is installed when empty
+	NgrokAuthToken      string             `json:"ngrokAuthToken,omitempty"`      // Auth token spin share passes to ngrok
+	AutostartProjects   []string           `json:"autostartProjects,omitempty"`   // Directories of the projects spin agent restores on login
+	Projects            map[string]Project `json:"projects,omitempty"`            // Projects spin was used in, keyed by directory
+	RailsMasterKeys     map[string]string  `json:"railsMasterKeys,omitempty"`     // Rails master keys, or op:// and cmd: references to them, keyed by project name
+	DatabaseClient      string             `json:"databaseClient,omitempty"`      // Client spin db gui opens, picked by the database when empty
+}
+
+// Project is a directory spin was used in
+type Project struct {
+	Name     string    `json:"name"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// projectTouchInterval is how often the last use of a project is recorded,
+// so the processes spin up starts don't all write the configuration
+const projectTouchInterval = time.Minute
+
+// TouchProject records that spin was used in the project in dir. It reports
+// whether anything changed and the configuration needs to be saved.
+func (c *Config) TouchProject(dir string, name string, now time.Time) bool {
+	project, ok := c.Projects[dir]
+	if ok && project.Name == name && now.Sub(project.LastUsed) < projectTouchInterval {
+		return false
+	}
+	if c.Projects == nil {
+		c.Projects = make(map[string]Project)
+	}
+	c.Projects[dir] = Project{Name: name, LastUsed: now}
+	return true
+}
+
+// GitHost is where the repositories of an organization are hosted
+type GitHost struct {
+	Host     string `json:"host"`               // Like gitlab.com or git.example.com
+	Provider string `json:"provider,omitempty"` // github, gitlab or bitbucket, guessed from the host when empty
+}
+
+// GetProvider returns the provider of the host, guessed from its name when
+// it isn't set
+func (h GitHost) GetProvider() string {
+	if h.Provider != "" {
+		return h.Provider
+	}
+	return forge.Provider(h.Host)
+}
+
+// HostFor returns where the repositories of an organization are hosted,
+// github.com unless another host is configured. Subgroups of a GitLab group
+// use the host of the group.
+func (c *Config) HostFor(organization string) GitHost {
+	if host, ok := c.GitHosts[organization]; ok {
+		return host
+	}
+	if group, _, found := strings.Cut(organization, "/"); found {
+		if host, ok := c.GitHosts[group]; ok {
+			return host
+		}
+	}
+	return GitHost{Host: forge.DefaultHost}
 }
 
 // DefaultConfig returns the default configuration
@@ -21,6 +98,22 @@ func DefaultConfig() *Config {
 	}
 }
 
+// Backend returns the process backend to use, defaulting to tmux
+func (c *Config) Backend() string {
+	if c.ProcessBackend == "" {
+		return BackendTmux
+	}
+	return c.ProcessBackend
+}
+
+// Shell returns the shell scripts run in, defaulting to sh
+func (c *Config) Shell() string {
+	if c.ScriptShell == "" {
+		return "sh"
+	}
+	return c.ScriptShell
+}
+
 // GetConfigDir returns the path to the configuration directory
 func GetConfigDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -66,8 +159,48 @@ func Load() (*Config, error) {
 	return &config, nil
 }
 
+// lock keeps other spin commands from writing the configuration until it is
+// released
+func lock() (*filelock.Lock, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return filelock.Acquire(configPath + ".lock")
+}
+
+// Update changes the configuration with change while no other spin command
+// writes it, and saves it when change reports that anything changed
+func Update(change func(c *Config) bool) error {
+	l, err := lock()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	c, err := Load()
+	if err != nil {
+		return err
+	}
+	if !change(c) {
+		return nil
+	}
+	return c.save()
+}
+
 // Save writes the configuration to disk
 func (c *Config) Save() error {
+	l, err := lock()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	return c.save()
+}
+
+// save writes the configuration through a temporary file renamed into
+// place, so readers never see it half written
+func (c *Config) save() error {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return err
@@ -84,7 +217,21 @@ func (c *Config) Save() error {
 		return fmt.Errorf("error marshaling config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	// The configuration holds tokens and master keys, only the user may
+	// read it. Temporary files are created that way.
+	tmp, err := os.CreateTemp(configDir, "config.json.*.tmp")
+	if err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), configPath); err != nil {
 		return fmt.Errorf("error writing config file: %w", err)
 	}
 
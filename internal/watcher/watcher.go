@@ -0,0 +1,197 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afomera/spin/internal/logger"
+	"github.com/fsnotify/fsnotify"
+)
+
+// debugLog writes the debug messages of the watcher, --verbose=watcher
+var debugLog = logger.For("watcher")
+
+// DefaultDebounce is how long changes are collected before a target fires
+const DefaultDebounce = 500 * time.Millisecond
+
+// skipDirs are never watched, they are either huge or written by the
+// processes themselves
+var skipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"tmp":          true,
+	"log":          true,
+}
+
+// Target is a set of glob patterns with the action to run when a matching
+// file changes
+type Target struct {
+	Name     string
+	Patterns []string
+	OnChange func(paths []string)
+}
+
+// Watcher watches a directory tree and fires targets whose patterns match
+// changed files, once per burst of changes
+type Watcher struct {
+	root     string
+	targets  []Target
+	debounce time.Duration
+	fs       *fsnotify.Watcher
+
+	mu      sync.Mutex
+	pending map[string][]string    // Changed paths per target name
+	timers  map[string]*time.Timer // Debounce timer per target name
+}
+
+// New creates a watcher for all directories below root
+func New(root string, targets []Target, debounce time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		root:     root,
+		targets:  targets,
+		debounce: debounce,
+		fs:       fsw,
+		pending:  make(map[string][]string),
+		timers:   make(map[string]*time.Timer),
+	}
+	if err := w.addTree(root); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// addTree adds a directory and all its subdirectories to the watch list
+func (w *Watcher) addTree(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Directories can disappear while walking
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != w.root && (skipDirs[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		return w.fs.Add(path)
+	})
+}
+
+// Run processes file events until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	defer w.fs.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			// Event queue overflows only lose events, keep watching
+			debugLog.Debugf("file watcher error: %v\n", err)
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		}
+	}
+}
+
+// handle dispatches a single file event
+func (w *Watcher) handle(event fsnotify.Event) {
+	// Watch directories created after startup
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			_ = w.addTree(event.Name)
+			return
+		}
+	}
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return
+	}
+
+	rel, err := filepath.Rel(w.root, event.Name)
+	if err != nil {
+		return
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, target := range w.targets {
+		for _, pattern := range target.Patterns {
+			if Match(pattern, rel) {
+				w.schedule(target, rel)
+				break
+			}
+		}
+	}
+}
+
+// schedule records a change for a target and (re)starts its debounce timer
+func (w *Watcher) schedule(target Target, path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending[target.Name] = append(w.pending[target.Name], path)
+	if timer, ok := w.timers[target.Name]; ok {
+		timer.Reset(w.debounce)
+		return
+	}
+
+	w.timers[target.Name] = time.AfterFunc(w.debounce, func() {
+		w.mu.Lock()
+		paths := w.pending[target.Name]
+		delete(w.pending, target.Name)
+		delete(w.timers, target.Name)
+		w.mu.Unlock()
+
+		target.OnChange(paths)
+	})
+}
+
+// Match reports whether a slash-separated relative path matches a glob
+// pattern. "**" matches any number of directories, and patterns without a
+// slash match the file name in any directory, so "*.go" matches "cmd/up.go".
+func Match(pattern, path string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := filepath.Match(pattern, filepath.Base(path))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+// matchSegments matches path segments against pattern segments
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try every possible number of directories for **
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(path) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
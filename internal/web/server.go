@@ -0,0 +1,193 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/afomera/spin/internal/api"
+	"golang.org/x/net/websocket"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// historyLines is the number of lines sent when a log is opened
+const historyLines = 200
+
+// pollInterval is how often a followed log is checked for new lines
+const pollInterval = 200 * time.Millisecond
+
+// Server serves the web dashboard and the control API on a local port
+type Server struct {
+	API  *api.Server
+	Port int
+}
+
+// URL returns the address the dashboard is served on
+func (s *Server) URL() string {
+	return fmt.Sprintf("http://localhost:%d", s.Port)
+}
+
+// Serve listens on localhost until ctx is cancelled
+func (s *Server) Serve(ctx context.Context) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", s.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", s.Port, err)
+	}
+
+	server := &http.Server{Handler: s.routes()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// routes returns the handler of the dashboard page, the log stream and the API
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.Handle("/v1/", s.API.Handler())
+	mux.Handle("/ws/logs", websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			if !s.sameOrigin(config.Origin) {
+				return fmt.Errorf("origin %s is not allowed", config.Origin)
+			}
+			return nil
+		},
+		Handler: s.handleLogs,
+	})
+	return s.localOnly(mux)
+}
+
+// localOnly rejects requests that don't come from the dashboard itself. The
+// API can stop processes, so other sites open in the browser must not be able
+// to call it, either directly or through a DNS rebinding.
+func (s *Server) localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil || (host != "localhost" && host != "127.0.0.1") {
+			http.Error(w, "spin only answers on localhost", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" && r.Method != http.MethodGet {
+			u, err := url.Parse(origin)
+			if err != nil || !s.sameOrigin(u) {
+				http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin reports whether a request was made by a page of the dashboard
+func (s *Server) sameOrigin(origin *url.URL) bool {
+	if origin == nil {
+		return false
+	}
+	host, port, err := net.SplitHostPort(origin.Host)
+	if err != nil {
+		return false
+	}
+	return (host == "localhost" || host == "127.0.0.1") && port == fmt.Sprint(s.Port)
+}
+
+// handleIndex serves the dashboard page
+//
+//	GET /
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	project := html.EscapeString(s.API.Config.Name)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(bytes.Replace(indexHTML, []byte(`<span id="project"></span>`), []byte(`<span id="project">`+project+`</span>`), 1))
+}
+
+// handleLogs sends the end of a process' log over a websocket and then every
+// line appended to it, until the browser disconnects
+//
+//	WS /ws/logs?name=web
+func (s *Server) handleLogs(ws *websocket.Conn) {
+	defer ws.Close()
+
+	name := ws.Request().URL.Query().Get("name")
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		websocket.Message.Send(ws, "spin: no process given")
+		return
+	}
+
+	path := api.LogFile(s.API.Config.Name, name)
+	history, err := api.TailLines(path, historyLines)
+	if err != nil {
+		websocket.Message.Send(ws, fmt.Sprintf("spin: no logs for %s", name))
+		return
+	}
+	for _, line := range history {
+		if err := websocket.Message.Send(ws, line); err != nil {
+			return
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		return
+	}
+
+	// Reading tells when the browser went away
+	closed := make(chan struct{})
+	go func() {
+		var discard string
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+		close(closed)
+	}()
+
+	reader := bufio.NewReader(file)
+	partial := ""
+	for {
+		text, err := reader.ReadString('\n')
+		partial += text
+		if err == nil {
+			line := strings.TrimRight(partial, "\r\n")
+			partial = ""
+			if err := websocket.Message.Send(ws, line); err != nil {
+				return
+			}
+			continue
+		}
+		if err != io.EOF {
+			return
+		}
+
+		select {
+		case <-closed:
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}